@@ -0,0 +1,439 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go-raytracing/rt"
+	"time"
+)
+
+// sceneFlags pick what gets rendered
+type sceneFlags struct {
+	name            *string
+	previewMaterial *string
+	palette         *string
+	furnace         *bool
+
+	previewMat rt.Material
+}
+
+func newSceneFlags() *sceneFlags {
+	return &sceneFlags{
+		name:            flag.String("scene", "hdri-test", "Scene to render (e.g. hdri-test, random, cornell, cornell-smoke)"),
+		previewMaterial: flag.String("preview-material", "", "Render a built-in material on the shader-ball preview scene instead of -scene (e.g. glass, gold, plaster)"),
+		palette:         flag.String("palette", "", "Load named colors from a JSON or ASE palette (sRGB); they restyle built-in scene colors of the same name (e.g. cornell-red) and can be used by name in override sidecars"),
+		furnace:         flag.Bool("furnace", false, "Run the white furnace energy audit on the built-in materials and exit"),
+	}
+}
+
+// parse loads the palette, which has to happen before any scene is built,
+// and looks up the preview material
+func (f *sceneFlags) parse() error {
+	if *f.palette != "" {
+		palette, err := rt.LoadPalette(*f.palette)
+		if err != nil {
+			return fmt.Errorf("palette: %w", err)
+		}
+		rt.UsePalette(palette)
+	}
+	if *f.previewMaterial != "" {
+		mat, err := rt.PreviewMaterial(*f.previewMaterial)
+		if err != nil {
+			return err
+		}
+		f.previewMat = mat
+	}
+	return nil
+}
+
+// load builds the shader-ball preview for -preview-material, else -scene
+func (f *sceneFlags) load() (*rt.HittableList, *rt.Camera, error) {
+	if f.previewMat != nil {
+		world, camera := rt.MaterialPreviewScene(f.previewMat)
+		return world, camera, nil
+	}
+	return loadScene(*f.name)
+}
+
+// outputFlags control the saved images, AOVs and the display transform
+type outputFlags struct {
+	hdrOutput       *string
+	backgroundAlpha *float64
+	fireflyFilter   *bool
+	nanCheck        *bool
+	toneMap         *string
+	autoExposure    *string
+	lightPath       *string
+	lightAOVs       *bool
+	aovs            *string
+	shadowPass      *string
+	reflectionPass  *string
+	clown           *bool
+
+	toneMapMode     rt.ToneMapMode
+	exposureMode    rt.AutoExposureMode
+	lightPathFilter rt.LightPathFilter
+	aovPasses       []rt.AOVPass
+	composite       rt.CompositePassConfig
+}
+
+func newOutputFlags() *outputFlags {
+	return &outputFlags{
+		hdrOutput:       flag.String("hdr-output", "", "Also write the raw linear render to this .pfm file (e.g. image.pfm)"),
+		backgroundAlpha: flag.Float64("background-alpha", 1, "Alpha of the background in the saved PNG (0 = transparent cut-out)"),
+		fireflyFilter:   flag.Bool("firefly-filter", false, "Clamp residual fireflies (neighborhood median outliers) in the final PNG"),
+		nanCheck:        flag.Bool("nan-check", false, "Replace NaN/Inf samples with magenta and log where they occur"),
+		toneMap:         flag.String("tonemap", "auto", "Display/PNG tone curve: clamp, highlight (soft shoulder that keeps bright bokeh and lights in hue), aces, auto (highlight when the camera has depth of field)"),
+		autoExposure:    flag.String("auto-exposure", "off", "Meter exposure from the preview pass: off, log-average, percentile"),
+		lightPath:       flag.String("light-path", "beauty", "Render only some light paths as an AOV: beauty, emission, background, direct, indirect, diffuse, diffuse-direct, diffuse-indirect, specular, light:N"),
+		lightAOVs:       flag.Bool("light-aovs", false, "Save each light's contribution as image_light<N>.png and print how much of the image every light adds for the samples it takes"),
+		aovs:            flag.String("aovs", "", "Comma-separated AOVs to save as image_<name>.pfm for compositing: position, curvature"),
+		shadowPass:      flag.String("shadow-pass", "", "Comma-separated scene object indices whose received shadows are saved as image_shadow.pfm (e.g. the ground)"),
+		reflectionPass:  flag.String("reflection-pass", "", "Comma-separated scene object indices whose reflections of the rest of the scene are saved as image_reflection.pfm"),
+		clown:           flag.Bool("clown", false, "Debug view: paint each object a stable random color instead of rendering it"),
+	}
+}
+
+func (f *outputFlags) parse() error {
+	var err error
+	if f.toneMapMode, err = rt.ParseToneMapMode(*f.toneMap); err != nil {
+		return err
+	}
+	if f.exposureMode, err = rt.ParseAutoExposureMode(*f.autoExposure); err != nil {
+		return err
+	}
+	if f.lightPathFilter, err = rt.ParseLightPathFilter(*f.lightPath); err != nil {
+		return err
+	}
+	f.aovPasses, err = rt.ParseAOVPasses(*f.aovs)
+	return err
+}
+
+// selectObjects looks up the -shadow-pass and -reflection-pass objects
+func (f *outputFlags) selectObjects(world *rt.HittableList) error {
+	var err error
+	if f.composite.ShadowReceivers, err = rt.SelectObjects(world, *f.shadowPass); err != nil {
+		return fmt.Errorf("shadow-pass: %w", err)
+	}
+	if f.composite.Reflectors, err = rt.SelectObjects(world, *f.reflectionPass); err != nil {
+		return fmt.Errorf("reflection-pass: %w", err)
+	}
+	return nil
+}
+
+func (f *outputFlags) applyCamera(camera *rt.Camera) {
+	if *f.backgroundAlpha < 1 {
+		camera.SetBackgroundAlpha(*f.backgroundAlpha)
+	}
+	camera.SetLightPathFilter(f.lightPathFilter)
+}
+
+func (f *outputFlags) applyRenderer(renderer *rt.BucketRenderer, camera *rt.Camera, bvh rt.Hittable) {
+	renderer.
+		SetHDROutput(*f.hdrOutput).
+		SetNaNCheck(nanCheckConfig(*f.nanCheck)).
+		SetFireflyFilter(fireflyFilterConfig(*f.fireflyFilter)).
+		SetAutoExposure(autoExposureConfig(f.exposureMode)).
+		SetToneMap(toneMapConfig(f.toneMapMode)).
+		SetLightAOVs(*f.lightAOVs).
+		SetAOVOutputs(f.aovPasses).
+		SetCompositePasses(f.composite)
+	if *f.clown {
+		renderer.SetPixelHook(rt.NewClownPass(camera, bvh))
+	}
+}
+
+// samplingFlags control how many samples are taken and where
+type samplingFlags struct {
+	bucketSize       *int
+	passes           *string
+	timeBudget       *time.Duration
+	seed             *int64
+	adaptiveNEE      *int
+	adaptiveEnv      *bool
+	envCache         *bool
+	hdriPreviewWidth *int
+	glossyFilter     *bool
+
+	passSchedule []rt.RenderPass
+}
+
+func newSamplingFlags() *samplingFlags {
+	return &samplingFlags{
+		bucketSize:       flag.Int("bucket-size", 0, "Bucket size in pixels (0 = auto, ~6 buckets per worker per pass)"),
+		passes:           flag.String("passes", "", "Custom pass schedule: comma-separated SPP or SPP:DEPTH, p suffix for display-only preview passes (e.g. 1:3p,16,48); replaces the SPP (default: 1 SPP preview, SPP/4, rest of SPP)"),
+		timeBudget:       flag.Duration("time-budget", 0, "Keep accumulating samples until this much time has passed (e.g. 5m), then save; replaces the fixed SPP (0 = off)"),
+		seed:             flag.Int64("seed", 0, "Seed the random streams so renders are reproducible and identical for any number of workers (0 = unseeded)"),
+		adaptiveNEE:      flag.Int("adaptive-nee", 0, "Max light samples per camera-ray hit in buckets whose direct light the preview pass found noisy (0 = one everywhere)"),
+		adaptiveEnv:      flag.Bool("adaptive-env", false, "Reweight the HDRI importance map by the environment visibility seen in the preview pass, so later passes waste fewer samples on occluded sky"),
+		envCache:         flag.Bool("env-cache", false, "Skip environment shadow rays a bucket keeps finding blocked in the preview/medium passes (faster HDRI interiors; final pass unaffected)"),
+		hdriPreviewWidth: flag.Int("hdri-preview-width", 0, "Max HDRI width for the preview/medium passes; the final pass uses full resolution (0 = off)"),
+		glossyFilter:     flag.Bool("glossy-filter", false, "Raise metal roughness on indirect bounces and treat deep glossy bounces as diffuse (faster, slightly biased)"),
+	}
+}
+
+func (f *samplingFlags) parse() error {
+	if *f.passes == "" {
+		return nil
+	}
+	var err error
+	if f.passSchedule, err = rt.ParsePassSchedule(*f.passes); err != nil {
+		return fmt.Errorf("passes: %w", err)
+	}
+	return nil
+}
+
+// seedRandom seeds the random streams for -seed, before any scene is built
+func (f *samplingFlags) seedRandom() {
+	if *f.seed != 0 {
+		rt.SeedRandom(*f.seed)
+	}
+}
+
+func (f *samplingFlags) applyCamera(camera *rt.Camera) {
+	if *f.glossyFilter {
+		config := rt.DefaultGlossyFilterConfig()
+		config.Enabled = true
+		camera.SetGlossyFilter(config)
+	}
+}
+
+func (f *samplingFlags) applyRenderer(renderer *rt.BucketRenderer) {
+	adaptiveLight := rt.DefaultAdaptiveLightConfig()
+	adaptiveLight.MaxSamples = *f.adaptiveNEE
+
+	renderer.
+		SetHDRIPreviewWidth(*f.hdriPreviewWidth).
+		SetEnvVisibilityCache(*f.envCache).
+		SetAdaptiveEnvironment(*f.adaptiveEnv).
+		SetAdaptiveLightSampling(adaptiveLight).
+		SetTimeBudget(*f.timeBudget)
+	if f.passSchedule != nil {
+		renderer.SetPassSchedule(f.passSchedule)
+	}
+}
+
+// cameraFlags override the scene's camera and lens
+type cameraFlags struct {
+	units       *string
+	aperture    *float64
+	focalLength *float64
+	sensor      *string
+	sensorFit   *string
+	fStop       *float64
+	nearClip    *float64
+	turntable   *int
+	frame       *int
+
+	sceneUnits *rt.SceneUnits
+	filmBack   rt.FilmBack
+	fit        rt.SensorFit
+}
+
+func newCameraFlags() *cameraFlags {
+	return &cameraFlags{
+		units:       flag.String("units", "", "Real-world size of a scene unit: meters, centimeters, millimeters, inches or meters per unit; scales ray offsets and -aperture (default: the scene's own)"),
+		aperture:    flag.Float64("aperture", 0, "Lens aperture diameter in meters, e.g. 0.025 for a 50mm lens at f/2; replaces the scene's defocus angle (0 = keep)"),
+		focalLength: flag.Float64("focal-length", 0, "Lens focal length in mm on the -sensor film back; replaces the scene's field of view (0 = keep)"),
+		sensor:      flag.String("sensor", "full-frame", "Film back for -focal-length: full-frame, aps-c, super35, mft, 1-inch or WIDTHxHEIGHT in mm"),
+		sensorFit:   flag.String("sensor-fit", "auto", "Film back side that spans the image: auto (longer side), horizontal or vertical"),
+		fStop:       flag.Float64("fstop", 0, "Lens f-number with -focal-length; sets the aperture (0 = keep)"),
+		nearClip:    flag.Float64("near-clip", 0, "Camera rays skip geometry closer than this many scene units along the view direction, e.g. to look through a wall; shadows, reflections and bounces still see it (0 = off)"),
+		turntable:   flag.Int("turntable", 0, "Orbit the camera around its look-at point in this many frames (0 = off); render one with -frame"),
+		frame:       flag.Int("frame", 0, "Frame to render with -turntable"),
+	}
+}
+
+func (f *cameraFlags) parse() error {
+	if *f.units != "" {
+		units, err := rt.ParseSceneUnits(*f.units)
+		if err != nil {
+			return err
+		}
+		f.sceneUnits = &units
+	}
+	var err error
+	if f.filmBack, err = rt.ParseFilmBack(*f.sensor); err != nil {
+		return err
+	}
+	f.fit, err = rt.ParseSensorFit(*f.sensorFit)
+	return err
+}
+
+// apply sets the units before the lens they scale, and parents the camera
+// to the turntable rig last
+func (f *cameraFlags) apply(camera *rt.Camera) {
+	if f.sceneUnits != nil {
+		camera.SetUnits(*f.sceneUnits)
+	}
+	if *f.aperture > 0 {
+		// The scene built the camera already; recompute the defocus disk
+		camera.SetAperture(*f.aperture).Initialize()
+	}
+	if *f.focalLength > 0 {
+		camera.SetFocalLength(*f.focalLength).SetFilmBack(f.filmBack, f.fit).SetFStop(*f.fStop).Initialize()
+	}
+	if *f.nearClip > 0 {
+		camera.SetNearClip(*f.nearClip)
+	}
+	if *f.turntable > 0 {
+		rig := rt.NewTurntable(camera.LookAt, 360/float64(*f.turntable)).SetFrame(*f.frame)
+		camera.SetParent(rig).Initialize()
+	}
+}
+
+// lightFlags control the scene's lights, sky and HDRI loading
+type lightFlags struct {
+	lightDecay         *string
+	lightDecayDistance *float64
+	skyBlend           *string
+	skyIntensity       *float64
+	sunIntensity       *float64
+	asyncAssets        *bool
+	hdriStream         *int
+
+	decayMode    rt.DecayMode
+	skyBlendMode rt.SkyBlendMode
+}
+
+func newLightFlags() *lightFlags {
+	return &lightFlags{
+		lightDecay:         flag.String("light-decay", "inverse-square", "Distance falloff of every registered light: inverse-square (physical), linear, none"),
+		lightDecayDistance: flag.Float64("light-decay-distance", 1, "Distance in scene units where every -light-decay mode matches the physical falloff"),
+		skyBlend:           flag.String("sky-blend", "", "Blend the scene's HDRI with the physical sky: hdri-sky (HDRI sky, analytic sun placed at the HDRI's sun) or hdri-sun (analytic sky, HDRI sun); empty = off"),
+		skyIntensity:       flag.Float64("sky-intensity", 1, "Sky scale with -sky-blend"),
+		sunIntensity:       flag.Float64("sun-intensity", 1, "Sun scale with -sky-blend"),
+		asyncAssets:        flag.Bool("async-assets", false, "Load image textures and HDRIs in the background; the preview starts with gray placeholders and the real assets are swapped in between passes"),
+		hdriStream:         flag.Int("hdri-stream", 0, "Stream HDRIs wider than 2K from 256px disk tiles through a cache of this many MB (0 = load maps whole)"),
+	}
+}
+
+func (f *lightFlags) parse() error {
+	var err error
+	if *f.skyBlend != "" {
+		if f.skyBlendMode, err = rt.ParseSkyBlendMode(*f.skyBlend); err != nil {
+			return err
+		}
+	}
+	f.decayMode, err = rt.ParseDecayMode(*f.lightDecay)
+	return err
+}
+
+// configureAssets sets how textures and HDRIs load, before any scene is built
+func (f *lightFlags) configureAssets() {
+	// Sky blending bakes from the real HDRI, not a placeholder
+	rt.SetAsyncAssetLoading(*f.asyncAssets && *f.skyBlend == "")
+	if *f.hdriStream > 0 {
+		streaming := rt.DefaultHDRIStreamConfig()
+		streaming.Enabled = true
+		streaming.CacheMB = *f.hdriStream
+		rt.SetHDRIStreaming(streaming)
+	}
+}
+
+func (f *lightFlags) apply(camera *rt.Camera) {
+	if *f.skyBlend != "" {
+		config := rt.DefaultSkyBlendConfig()
+		config.Mode = f.skyBlendMode
+		config.SkyIntensity = *f.skyIntensity
+		config.SunIntensity = *f.sunIntensity
+		// Rebuilds the light sampler for the new environment
+		camera.SetSkyBlend(config).Initialize()
+	}
+	if f.decayMode != rt.DecayInverseSquare {
+		camera.SetLightDecay(rt.LightDecay{Mode: f.decayMode, Distance: *f.lightDecayDistance})
+	}
+}
+
+// bvhFlags control BVH construction
+type bvhFlags struct {
+	builder           *string
+	leafSize          *int
+	quantize          *bool
+	parallelThreshold *int
+
+	options rt.BVHOptions
+}
+
+func newBVHFlags() *bvhFlags {
+	return &bvhFlags{
+		builder:           flag.String("bvh-builder", "median", "BVH builder: median, sah, lbvh"),
+		leafSize:          flag.Int("bvh-leaf-size", rt.DefaultBVHOptions().LeafMaxSize, "Max primitives per BVH leaf"),
+		quantize:          flag.Bool("bvh-quantize", false, "Store mesh BVHs with 8-bit quantized child bounds (less memory for very large meshes)"),
+		parallelThreshold: flag.Int("bvh-parallel-threshold", rt.DefaultBVHOptions().ParallelThreshold, "Min primitives before BVH construction goes parallel"),
+	}
+}
+
+func (f *bvhFlags) parse() error {
+	builder, err := rt.ParseBVHBuilder(*f.builder)
+	if err != nil {
+		return err
+	}
+	f.options = rt.DefaultBVHOptions()
+	f.options.Builder = builder
+	f.options.LeafMaxSize = *f.leafSize
+	f.options.ParallelThreshold = *f.parallelThreshold
+	f.options.Quantized = *f.quantize
+	return nil
+}
+
+// viewerFlags control the viewer's stats overlay and A/B comparison
+type viewerFlags struct {
+	overlay        *bool
+	corner         *string
+	opacity        *float64
+	stats          *bool
+	buckets        *string
+	dof            *bool
+	compareImage   *string
+	compareModeArg *string
+
+	config      rt.OverlayConfig
+	compareMode rt.CompareMode
+}
+
+func newViewerFlags() *viewerFlags {
+	return &viewerFlags{
+		overlay:        flag.Bool("overlay", true, "Show the stats overlay in the viewer (toggle with O)"),
+		corner:         flag.String("overlay-corner", "bottom-left", "Overlay corner: bottom-left, bottom-right, top-left, top-right (cycle with C)"),
+		opacity:        flag.Float64("overlay-opacity", 0.6, "Overlay background opacity [0, 1]"),
+		stats:          flag.Bool("overlay-stats", false, "Add rays/sec and samples/sec to the overlay"),
+		buckets:        flag.String("overlay-buckets", "off", "Tint buckets by sampling stats: off, speed, variance (cycle with B)"),
+		dof:            flag.Bool("overlay-dof", false, "Preview focus plane and DOF limits in the viewer (toggle with F, adjust with [ ] and - =)"),
+		compareImage:   flag.String("compare", "", "Previous render (PNG) to compare A/B against in the viewer; default is the last completed pass (keep the current frame with K)"),
+		compareModeArg: flag.String("compare-mode", "off", "A/B comparison: off, wipe, difference (cycle with A; drag or arrow keys move the wipe)"),
+	}
+}
+
+func (f *viewerFlags) parse() error {
+	corner, err := rt.ParseOverlayCorner(*f.corner)
+	if err != nil {
+		return err
+	}
+	bucketStats, err := rt.ParseBucketStatsMode(*f.buckets)
+	if err != nil {
+		return err
+	}
+	if f.compareMode, err = rt.ParseCompareMode(*f.compareModeArg); err != nil {
+		return err
+	}
+	f.config = rt.DefaultOverlayConfig()
+	f.config.Enabled = *f.overlay
+	f.config.Corner = corner
+	f.config.Opacity = *f.opacity
+	f.config.ShowRayStats = *f.stats
+	f.config.ShowDOF = *f.dof
+	f.config.BucketStats = bucketStats
+	return nil
+}
+
+func (f *viewerFlags) applyRenderer(renderer *rt.BucketRenderer) error {
+	renderer.SetOverlay(f.config).SetCompareMode(f.compareMode)
+	if *f.compareImage == "" {
+		return nil
+	}
+	if err := renderer.LoadCompareImage(*f.compareImage); err != nil {
+		return fmt.Errorf("compare: %w", err)
+	}
+	return nil
+}
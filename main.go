@@ -6,9 +6,12 @@ import (
 	"flag"
 	"fmt"
 	"go-raytracing/rt"
+	"image/png"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"syscall"
 
@@ -24,12 +27,58 @@ func main() {
 	blockProfile := flag.Bool("block-profile", false, "Enable block profiling (requires -profile)")
 	profileDir := flag.String("profile-dir", "profiles", "Directory to save profile files")
 	showMemStats := flag.Bool("mem-stats", false, "Show memory statistics after render")
-	sceneName := flag.String("scene", "hdri-test", "Scene to render (e.g. hdri-test, random, cornell, cornell-smoke)")
+	scaling := flag.Int("scaling", 0, "Benchmark -scene at 1, 2, 4, ... up to this many workers (16 SPP each) and report speedup and scaling efficiency, then exit (0 = off)")
+
+	// Scene, output/AOV, sampling/pass, camera/lens, lights/environment,
+	// BVH build and viewer flags (see flags.go)
+	scene := newSceneFlags()
+	output := newOutputFlags()
+	sampling := newSamplingFlags()
+	lens := newCameraFlags()
+	lights := newLightFlags()
+	bvhBuild := newBVHFlags()
+	viewer := newViewerFlags()
+
+	// Contact sheet flags
+	contactSheet := flag.String("contact-sheet", "", "Render every built-in and registered scene at draft quality into one tiled PNG at this path (e.g. contact_sheet.png) and exit")
+	contactSheetWidth := flag.Int("contact-sheet-width", rt.DefaultContactSheetConfig().CellWidth, "Thumbnail cell width in pixels for -contact-sheet")
+
+	// Environment baking and lookup flags
+	bakeHDRI := flag.String("bake-hdri", "", "Bake this HDRI (.hdr or .pfm) into a diffuse irradiance map and GGX-prefiltered specular levels saved as PFM files, and exit")
+	bakeOutput := flag.String("bake-output", "", "File prefix for -bake-hdri: writes <prefix>_irradiance.pfm and <prefix>_specular_<level>.pfm (default: the HDRI's name)")
+	prefilteredEnv := flag.String("prefiltered-env", "", "Prefix of maps baked with -bake-hdri from the scene's HDRI; rough metal reflections of the environment read them instead of the HDRI (fast, approximate)")
+
+	// Flipbook playback flags
+	playSequence := flag.String("play", "", "Play back an image sequence (directory or glob, e.g. 'frames/*.png') instead of rendering")
+	playFPS := flag.Float64("fps", 24, "Flipbook playback rate in frames per second")
+	playLoop := flag.Bool("loop", true, "Loop flipbook playback (toggle with L)")
 
 	flag.Parse()
 
-	// Configure profiler
-	profileConfig := &rt.ProfileConfig{
+	for _, group := range []interface{ parse() error }{viewer, output, lights, sampling, lens, scene, bvhBuild} {
+		exitOnError(group.parse())
+	}
+
+	if *scene.furnace {
+		if !rt.RunFurnaceAudit(rt.DefaultFurnaceCases(), 64, 0.02) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *bakeHDRI != "" {
+		if !bakeEnvironment(*bakeHDRI, *bakeOutput) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *playSequence != "" {
+		playFlipbook(*playSequence, *playFPS, *playLoop, viewer.config)
+		return
+	}
+
+	profiler := startProfiler(&rt.ProfileConfig{
 		Enabled:      *enableProfile,
 		CPUProfile:   *cpuProfile,
 		MemProfile:   *memProfile,
@@ -37,31 +86,27 @@ func main() {
 		BlockProfile: *blockProfile,
 		OutputDir:    *profileDir,
 		SampleRate:   100,
-	}
+	}, *showMemStats)
 
-	profiler := rt.NewProfiler(profileConfig)
+	// Also applies to the BVHs that scenes build for OBJ meshes
+	rt.SetDefaultBVHOptions(bvhBuild.options)
+	sampling.seedRandom()
+	lights.configureAssets()
 
-	// Start profiling if enabled
-	if *enableProfile {
-		fmt.Println("🔬 Profiling enabled")
-		if err := profiler.Start(); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to start profiler: %v\n", err)
+	if *contactSheet != "" {
+		if !renderContactSheet(*contactSheet, *contactSheetWidth) {
 			os.Exit(1)
 		}
+		return
+	}
 
-		// Handle graceful shutdown for profiling
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		go func() {
-			<-sigChan
-			fmt.Println("\n Interrupt received, saving profiles...")
-			profiler.Stop()
-			profiler.PrintTimingReport()
-			if *showMemStats {
-				rt.PrintMemStats()
-			}
-			os.Exit(0)
-		}()
+	if *scaling > 0 {
+		ok := runScalingBenchmark(*scene.name, *scaling, *sampling.bucketSize)
+		stopProfiler(profiler)
+		if !ok {
+			os.Exit(1)
+		}
+		return
 	}
 
 	// Reset render stats
@@ -69,21 +114,34 @@ func main() {
 
 	// Time BVH construction
 	bvhTimer := rt.NewTimer("BVH Construction")
-	world, camera, sceneErr := loadScene(*sceneName)
-	if sceneErr != nil {
-		fmt.Fprintf(os.Stderr, "Unknown scene '%s'. Use -help for options.\n", *sceneName)
-		os.Exit(1)
+	world, camera, err := scene.load()
+	exitOnError(err)
+	exitOnError(output.selectObjects(world))
+	output.applyCamera(camera)
+	lights.apply(camera)
+	sampling.applyCamera(camera)
+	if *prefilteredEnv != "" {
+		exitOnError(usePrefilteredEnvironment(camera, *prefilteredEnv))
 	}
+	lens.apply(camera)
 	bvh := rt.NewBVHNodeFromList(world)
 	bvhTime := bvhTimer.Stop()
 	rt.GlobalRenderStats.BVHConstructTime = bvhTime
 
-	rt.PrintRenderSettings(camera, len(world.Objects))
+	rt.PrintRenderSettings(camera, bvh)
 
-	bucketSize := 32
 	numWorkers := runtime.NumCPU()
+	bucketSize := *sampling.bucketSize
+	if bucketSize <= 0 {
+		bucketSize = rt.AutoBucketSize(camera.ImageWidth, camera.ImageHeight, numWorkers)
+	}
+	fmt.Printf("Buckets: %dx%d px, %d workers\n", bucketSize, bucketSize, numWorkers)
 
-	renderer := rt.NewBucketRenderer(camera, bvh, bucketSize, numWorkers)
+	renderer := rt.NewBucketRenderer(camera, bvh, bucketSize, numWorkers).
+		SetSceneName(strings.ToLower(*scene.name))
+	sampling.applyRenderer(renderer)
+	output.applyRenderer(renderer, camera, bvh)
+	exitOnError(viewer.applyRenderer(renderer))
 
 	// renderer := rt.NewProgressiveRenderer(camera, bvh)
 
@@ -95,17 +153,215 @@ func main() {
 	}
 
 	// Stop profiling and print reports
-	if *enableProfile {
+	stopProfiler(profiler)
+
+	if *showMemStats {
+		rt.PrintMemStats()
+	}
+}
+
+// exitOnError prints err and exits when it isn't nil
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// startProfiler starts profiling when config enables it and saves the
+// profiles on interrupt; it returns nil when profiling is off
+func startProfiler(config *rt.ProfileConfig, showMemStats bool) *rt.Profiler {
+	if !config.Enabled {
+		return nil
+	}
+	profiler := rt.NewProfiler(config)
+	fmt.Println("🔬 Profiling enabled")
+	if err := profiler.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start profiler: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Handle graceful shutdown for profiling
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\n Interrupt received, saving profiles...")
+		profiler.Stop()
+		profiler.PrintTimingReport()
+		if showMemStats {
+			rt.PrintMemStats()
+		}
+		os.Exit(0)
+	}()
+	return profiler
+}
+
+// stopProfiler saves the profiles and prints the timing report of a profiler
+// from startProfiler
+func stopProfiler(profiler *rt.Profiler) {
+	if profiler != nil {
 		profiler.Stop()
 		profiler.PrintTimingReport()
 	}
+}
 
-	if *showMemStats {
-		rt.PrintMemStats()
+// runScalingBenchmark runs the worker scaling benchmark on a scene and
+// reports whether it completed
+func runScalingBenchmark(sceneName string, maxWorkers, bucketSize int) bool {
+	config := rt.DefaultScalingConfig()
+	config.MaxWorkers = maxWorkers
+	config.BucketSize = bucketSize
+	results, err := rt.RunScalingBenchmark(func() (*rt.HittableList, *rt.Camera, error) { return loadScene(sceneName) }, config)
+	rt.PrintScalingReport(results)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	return true
+}
+
+// usePrefilteredEnvironment loads the maps baked under prefix for the
+// camera's rough metal reflections
+func usePrefilteredEnvironment(camera *rt.Camera, prefix string) error {
+	prefiltered, err := rt.LoadPrefilteredEnvironment(prefix)
+	if err != nil {
+		return fmt.Errorf("prefiltered-env: %w", err)
+	}
+	camera.SetPrefilteredEnvironment(prefiltered)
+	return nil
+}
+
+// nanCheckConfig returns the NaN/Inf check settings for the -nan-check flag
+func nanCheckConfig(enabled bool) rt.NaNCheckConfig {
+	config := rt.DefaultNaNCheckConfig()
+	config.Enabled = enabled
+	return config
+}
+
+// autoExposureConfig returns the auto exposure settings for the -auto-exposure flag
+func autoExposureConfig(mode rt.AutoExposureMode) rt.AutoExposureConfig {
+	config := rt.DefaultAutoExposureConfig()
+	config.Mode = mode
+	return config
+}
+
+// toneMapConfig returns the tone curve for the -tonemap flag
+func toneMapConfig(mode rt.ToneMapMode) rt.ToneMapConfig {
+	config := rt.DefaultToneMapConfig()
+	config.Mode = mode
+	return config
+}
+
+// fireflyFilterConfig returns the firefly filter settings for the -firefly-filter flag
+func fireflyFilterConfig(enabled bool) rt.FireflyFilterConfig {
+	config := rt.DefaultFireflyFilterConfig()
+	config.Enabled = enabled
+	return config
+}
+
+// playFlipbook opens the viewer in flipbook mode for a rendered image sequence
+func playFlipbook(pattern string, fps float64, loop bool, overlay rt.OverlayConfig) {
+	paths, err := rt.LoadImageSequence(pattern)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Loading %d frames...\n", len(paths))
+	flipbook, err := rt.NewFlipbook(paths, fps)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	flipbook.SetOverlay(overlay).SetLoop(loop)
+
+	ebiten.SetWindowSize(flipbook.Layout(0, 0))
+	ebiten.SetWindowTitle("Go Raytracer - Flipbook")
+
+	if err := ebiten.RunGame(flipbook); err != nil {
+		panic(err)
+	}
+}
+
+// bakeEnvironment prefilters the HDRI at path and saves the maps under
+// prefix, reporting whether it succeeded
+func bakeEnvironment(path, prefix string) bool {
+	if prefix == "" {
+		prefix = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 	}
+	env := rt.NewHDRIEnvironment(path)
+	prefiltered, err := rt.PrefilterEnvironment(env, rt.DefaultPrefilterConfig())
+	if err == nil {
+		err = prefiltered.Save(prefix)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bake-hdri:", err)
+		return false
+	}
+	fmt.Printf("✓ Baked %s into %s_irradiance.pfm and %d specular levels\n", path, prefix, len(prefiltered.Specular))
+	return true
+}
+
+// renderContactSheet renders every scene into one PNG at path and reports
+// whether all of them built
+func renderContactSheet(path string, cellWidth int) bool {
+	names := append(slices.Clone(builtinSceneNames), rt.RegisteredSceneNames()...)
+	scenes := make([]rt.ContactSheetScene, len(names))
+	for i, name := range names {
+		scenes[i] = rt.ContactSheetScene{
+			Name:  name,
+			Build: func() (*rt.HittableList, *rt.Camera, error) { return loadScene(name) },
+		}
+	}
+	config := rt.DefaultContactSheetConfig()
+	config.CellWidth = cellWidth
+	sheet, renderErr := rt.RenderContactSheet(scenes, config)
+	if sheet == nil {
+		fmt.Fprintln(os.Stderr, renderErr)
+		return false
+	}
+	file, err := os.Create(path)
+	if err == nil {
+		err = png.Encode(file, sheet)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "contact sheet:", err)
+		return false
+	}
+	fmt.Printf("✓ Contact sheet of %d scenes saved to %s\n", len(scenes), path)
+	if renderErr != nil {
+		fmt.Fprintf(os.Stderr, "Scenes that failed:\n%v\n", renderErr)
+		return false
+	}
+	return true
 }
 
+// builtinSceneNames lists one name per builtinScene case, in the order the
+// contact sheet shows them
+var builtinSceneNames = []string{
+	"hdri-test", "random", "checkered", "simple", "perlin", "earth", "quads",
+	"cornell", "cornell-glossy", "cornell-lucy", "cornell-smoke", "glossy-metal",
+	"primitives", "gobo", "sunset", "gems", "fur", "furnace",
+}
+
+// loadScene builds a built-in scene and applies its sidecar overrides
+// (e.g. cornell.overrides.json in the working directory) when present
 func loadScene(name string) (*rt.HittableList, *rt.Camera, error) {
+	world, camera, err := builtinScene(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := rt.ApplySceneOverridesFile(name, camera); err != nil {
+		return nil, nil, fmt.Errorf("scene overrides: %w", err)
+	}
+	return world, camera, nil
+}
+
+func builtinScene(name string) (*rt.HittableList, *rt.Camera, error) {
 	switch strings.ToLower(name) {
 	case "random", "randomscene":
 		w, c := rt.RandomScene()
@@ -143,10 +399,28 @@ func loadScene(name string) (*rt.HittableList, *rt.Camera, error) {
 	case "primitives", "primitives-scene":
 		w, c := rt.PrimitivesScene()
 		return w, c, nil
+	case "gobo", "spotlight":
+		w, c := rt.GoboScene()
+		return w, c, nil
+	case "sunset", "atmosphere":
+		w, c := rt.SunsetScene()
+		return w, c, nil
+	case "furnace", "white-furnace":
+		w, c := rt.FurnaceScene(rt.NewLambertian(rt.Color{X: 0.8, Y: 0.8, Z: 0.8}))
+		return w, c, nil
+	case "gems", "gem", "diamond":
+		w, c := rt.GemScene()
+		return w, c, nil
+	case "fur", "fur-ball", "hair":
+		w, c := rt.FurBallScene()
+		return w, c, nil
 	case "hdri", "hdri-test", "hdr":
 		w, c := rt.HDRITestScene()
 		return w, c, nil
 	default:
-		return nil, nil, fmt.Errorf("unknown scene: %s", name)
+		if w, c, err := rt.RegisteredScene(name); err == nil {
+			return w, c, nil
+		}
+		return nil, nil, fmt.Errorf("unknown scene '%s'. Use -help for options", name)
 	}
 }
@@ -0,0 +1,120 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+)
+
+// =============================================================================
+// ADAPTIVE LIGHT SAMPLING
+// =============================================================================
+
+// AdaptiveLightConfig decouples the number of light (NEE) samples from the
+// number of BSDF samples. During the preview pass every camera-ray hit takes
+// two light samples, whose difference estimates the variance of the direct
+// light in each bucket. Later passes give camera-ray hits in noisy buckets
+// (penumbrae, many lights, HDRIs behind blinds) more light samples per path,
+// averaged into one estimate, while quiet buckets keep one.
+type AdaptiveLightConfig struct {
+	MaxSamples     int     // Light samples per camera-ray hit in the noisiest buckets (<= 1 = off)
+	TargetVariance float64 // Relative direct-light variance one light sample is allowed
+}
+
+// DefaultAdaptiveLightConfig returns adaptive light sampling off (one light
+// sample everywhere), with a target relative variance of 0.25 once
+// MaxSamples is raised
+func DefaultAdaptiveLightConfig() AdaptiveLightConfig {
+	return AdaptiveLightConfig{
+		MaxSamples:     1,
+		TargetVariance: 0.25,
+	}
+}
+
+// neeBucket is the direct-light statistics and light sample count of one
+// bucket. A bucket belongs to one worker at a time.
+type neeBucket struct {
+	meanSum     float64 // Sum of per-hit mean direct luminance (preview pass)
+	varianceSum float64 // Sum of per-hit variance estimates (preview pass)
+	hits        int
+	samples     int // Light samples per camera-ray hit; 0 while measuring
+}
+
+// count returns the light samples to take at a camera-ray hit: two while
+// measuring, one for a nil bucket
+func (b *neeBucket) count() int {
+	if b == nil {
+		return 1
+	}
+	if b.samples == 0 {
+		return 2
+	}
+	return b.samples
+}
+
+// record adds the two direct-light estimates of a measured hit
+func (b *neeBucket) record(first, second Color) {
+	if b == nil || b.samples != 0 {
+		return
+	}
+	l1, l2 := Luminance(first), Luminance(second)
+	if math.IsNaN(l1+l2) || math.IsInf(l1+l2, 0) {
+		return
+	}
+	b.meanSum += (l1 + l2) / 2
+	b.varianceSum += (l1 - l2) * (l1 - l2) / 2
+	b.hits++
+}
+
+// decide sets the bucket's light samples from its measured variance
+func (b *neeBucket) decide(config AdaptiveLightConfig) {
+	b.samples = 1
+	if b.hits == 0 || b.meanSum <= 0 {
+		return
+	}
+	mean := b.meanSum / float64(b.hits)
+	relVariance := b.varianceSum / float64(b.hits) / (mean * mean)
+	needed := math.Ceil(relVariance / math.Max(config.TargetVariance, 1e-6))
+	b.samples = int(clampFloat(needed, 1, float64(config.MaxSamples)))
+}
+
+// SetAdaptiveLightSampling measures direct-light variance per bucket in the
+// preview pass and gives noisy buckets up to config.MaxSamples light samples
+// per camera-ray hit in the passes after it
+func (r *BucketRenderer) SetAdaptiveLightSampling(config AdaptiveLightConfig) *BucketRenderer {
+	r.adaptiveLight = config
+	r.neeBuckets = nil
+	if config.MaxSamples > 1 {
+		r.neeBuckets = make([]*neeBucket, len(r.buckets))
+		for i := range r.neeBuckets {
+			r.neeBuckets[i] = &neeBucket{}
+		}
+	}
+	return r
+}
+
+// neeBucket returns the adaptive light sampling state of a bucket, nil when
+// it is off
+func (r *BucketRenderer) neeBucket(bucket int) *neeBucket {
+	if r.neeBuckets == nil {
+		return nil
+	}
+	return r.neeBuckets[bucket]
+}
+
+// decideLightSamples fixes every bucket's light samples after the preview
+// pass and prints how they were spread
+func (r *BucketRenderer) decideLightSamples() {
+	if r.neeBuckets == nil {
+		return
+	}
+	boosted, total := 0, 0
+	for _, b := range r.neeBuckets {
+		b.decide(r.adaptiveLight)
+		total += b.samples
+		if b.samples > 1 {
+			boosted++
+		}
+	}
+	fmt.Printf("Adaptive light sampling: %d of %d buckets take extra light samples (%.2f per hit on average)\n",
+		boosted, len(r.neeBuckets), float64(total)/float64(len(r.neeBuckets)))
+}
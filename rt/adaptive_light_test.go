@@ -0,0 +1,76 @@
+package rt
+
+import "testing"
+
+func TestNEEBucketDecide(t *testing.T) {
+	config := DefaultAdaptiveLightConfig()
+	config.MaxSamples = 4
+
+	steady := &neeBucket{}
+	for range 16 {
+		steady.record(Color{X: 1, Y: 1, Z: 1}, Color{X: 1, Y: 1, Z: 1})
+	}
+	steady.decide(config)
+	if steady.samples != 1 {
+		t.Errorf("noise-free direct light takes %d samples, want 1", steady.samples)
+	}
+
+	// Half the light samples find the light blocked
+	noisy := &neeBucket{}
+	for range 16 {
+		noisy.record(Color{X: 2, Y: 2, Z: 2}, Color{})
+	}
+	noisy.decide(config)
+	if noisy.samples != config.MaxSamples {
+		t.Errorf("noisy direct light takes %d samples, want %d", noisy.samples, config.MaxSamples)
+	}
+
+	unlit := &neeBucket{}
+	unlit.decide(config)
+	if unlit.samples != 1 || unlit.count() != 1 {
+		t.Errorf("a bucket without hits takes %d samples, want 1", unlit.samples)
+	}
+	if (*neeBucket)(nil).count() != 1 {
+		t.Error("a nil bucket should take one light sample")
+	}
+}
+
+func TestAdaptiveLightSamplingBoostsNoisyBuckets(t *testing.T) {
+	SeedRandom(11)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	world, camera := lightPathScene()
+	// A light below the floor takes half the light samples and lights nothing
+	hidden := NewQuad(Point3{Y: -10}, Vec3{X: 2}, Vec3{Z: 2}, NewDiffuseLightColor(Color{X: 10, Y: 10, Z: 10}))
+	world.Add(hidden)
+	camera.AddLight(hidden)
+	camera.Initialize()
+
+	config := DefaultAdaptiveLightConfig()
+	config.MaxSamples = 4
+	r := NewBucketRenderer(camera, NewBVHNodeFromList(world), 4, 2).SetAdaptiveLightSampling(config)
+	defer r.Close()
+	r.renderPass()
+
+	boosted := 0
+	for _, b := range r.neeBuckets {
+		if b.samples < 1 || b.samples > config.MaxSamples {
+			t.Fatalf("bucket takes %d light samples, want 1..%d", b.samples, config.MaxSamples)
+		}
+		if b.samples > 1 {
+			boosted++
+		}
+	}
+	if boosted == 0 {
+		t.Error("no bucket got extra light samples despite the wasted light")
+	}
+
+	r.currentPass = 1
+	r.renderPass()
+	for y := 0; y < r.film.Height(); y++ {
+		for x := 0; x < r.film.Width(); x++ {
+			if !isFiniteColor(r.film.Resolve(x, y)) {
+				t.Fatalf("pixel (%d, %d) is not finite", x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package rt
+
+// =============================================================================
+// AMBIENT LIGHT
+// =============================================================================
+
+// AmbientConfig adds a constant fill light to every non-specular surface hit,
+// independent of lights and the HDRI/sky. It is not physically based: the
+// surface albedo is simply multiplied by the ambient color and added to its
+// emission, which lifts shadows for stylized renders or quick previews.
+// Hemispherical ambient blends from Ground (normal pointing down) to Sky
+// (normal pointing up), a cheap stand-in for sky/ground bounce.
+type AmbientConfig struct {
+	Enabled       bool
+	Sky           Color // Flat ambient color, or the color facing up when Hemispherical
+	Ground        Color // Color facing down (Hemispherical only)
+	Hemispherical bool
+}
+
+// DefaultAmbientConfig returns a disabled, dim neutral ambient
+func DefaultAmbientConfig() AmbientConfig {
+	return AmbientConfig{
+		Enabled: false,
+		Sky:     Color{X: 0.1, Y: 0.1, Z: 0.1},
+		Ground:  Color{X: 0.05, Y: 0.05, Z: 0.05},
+	}
+}
+
+// Radiance returns the ambient color seen by a surface with the given normal
+func (a AmbientConfig) Radiance(normal Vec3) Color {
+	if !a.Hemispherical {
+		return a.Sky
+	}
+	t := clampFloat(0.5*(normal.Unit().Y+1), 0, 1)
+	return a.Ground.Scale(1 - t).Add(a.Sky.Scale(t))
+}
+
+// SetAmbient enables a flat ambient fill of the given color
+func (c *Camera) SetAmbient(color Color) *Camera {
+	c.Ambient = AmbientConfig{Enabled: true, Sky: color, Ground: color}
+	return c
+}
+
+// SetHemisphereAmbient enables a two-color ambient fill that blends from
+// ground (facing down) to sky (facing up)
+func (c *Camera) SetHemisphereAmbient(sky, ground Color) *Camera {
+	c.Ambient = AmbientConfig{Enabled: true, Sky: sky, Ground: ground, Hemispherical: true}
+	return c
+}
+
+// ambientTerm returns the ambient light reflected at a hit. Pure specular and
+// emissive materials, and materials without an albedo, get none.
+func (c *Camera) ambientTerm(mat Material, rec *HitRecord) Color {
+	if !c.Ambient.Enabled {
+		return Color{}
+	}
+	if info, ok := mat.(MaterialInfo); ok {
+		if props := info.Properties(); props.isPureSpecular || props.isEmissive {
+			return Color{}
+		}
+	}
+	provider, ok := mat.(AlbedoProvider)
+	if !ok {
+		return Color{}
+	}
+	return provider.SurfaceAlbedo(rec.U, rec.V, rec.P).Mult(c.Ambient.Radiance(rec.Normal))
+}
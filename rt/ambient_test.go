@@ -0,0 +1,68 @@
+package rt
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+func TestAmbientLightsUnlitDiffuseSurface(t *testing.T) {
+	albedo := Color{X: 0.8, Y: 0.4, Z: 0.2}
+	world := &HittableList{Objects: []Hittable{NewSphere(Point3{}, 1, NewLambertian(albedo))}}
+
+	camera := NewCamera()
+	camera.MaxDepth = 1
+	camera.SetHemisphereAmbient(Color{X: 0.5, Y: 0.5, Z: 1}, Color{X: 0.1, Y: 0.1, Z: 0.1})
+	camera.Initialize()
+
+	// Hit the top of the sphere straight down: the normal faces the sky color
+	got := camera.RayColor(NewRay(Point3{Y: 5}, Vec3{Y: -1}, 0), camera.MaxDepth, world)
+	want := albedo.Mult(Color{X: 0.5, Y: 0.5, Z: 1})
+	if got.Sub(want).Len() > 1e-9 {
+		t.Errorf("top of sphere = %v, want albedo*sky = %v", got, want)
+	}
+
+	// The bottom faces the ground color
+	got = camera.RayColor(NewRay(Point3{Y: -5}, Vec3{Y: 1}, 0), camera.MaxDepth, world)
+	want = albedo.Mult(Color{X: 0.1, Y: 0.1, Z: 0.1})
+	if got.Sub(want).Len() > 1e-9 {
+		t.Errorf("bottom of sphere = %v, want albedo*ground = %v", got, want)
+	}
+
+	camera.Ambient.Enabled = false
+	if got := camera.RayColor(NewRay(Point3{Y: 5}, Vec3{Y: -1}, 0), camera.MaxDepth, world); got.Len() != 0 {
+		t.Errorf("disabled ambient still lit the sphere: %v", got)
+	}
+}
+
+func TestBackgroundAlphaCutsOutScene(t *testing.T) {
+	world := &HittableList{Objects: []Hittable{NewSphere(Point3{Z: -3}, 1, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))}}
+	camera := NewCamera()
+	camera.ImageWidth = 32
+	camera.SetBackground(Color{X: 1, Y: 1, Z: 1}).SetBackgroundAlpha(0)
+	camera.Initialize()
+
+	// A white scene over a white background renders as a uniformly white frame
+	frame := image.NewRGBA(image.Rect(0, 0, camera.ImageWidth, camera.ImageHeight))
+	draw.Draw(frame, frame.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	img := backgroundAlphaImage(frame, 1, ToneMapConfig{}, camera, world)
+
+	if a := img.NRGBAAt(0, 0).A; a != 0 {
+		t.Errorf("corner alpha = %d, want 0 (background)", a)
+	}
+	center := img.NRGBAAt(16, 16)
+	if center.A != 255 || center.R < 250 {
+		t.Errorf("center = %+v, want an opaque scene pixel", center)
+	}
+
+	// An edge pixel is partially covered, and the white background is
+	// removed from its color rather than left as a halo
+	for x := 0; x < camera.ImageWidth; x++ {
+		px := img.NRGBAAt(x, 16)
+		if px.A > 0 && px.A < 255 && math.Abs(float64(px.R)-255) > 2 {
+			t.Errorf("edge pixel %d = %+v, want the straight scene color", x, px)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// AOVS (ARBITRARY OUTPUT VARIABLES)
+// =============================================================================
+
+// PixelAOV holds the first-hit data of a pixel, traced with one pinhole ray
+// through the pixel center
+type PixelAOV struct {
+	Hit      bool
+	Depth    float64 // Distance along the camera ray (+Inf on a miss)
+	Position Point3  // World-space hit point
+	Normal   Vec3    // World-space normal facing the camera
+	Albedo   Color   // Surface color without lighting
+	U, V     float64 // Surface coordinates of the hit
+	Material Material
+	Object   Hittable // Top-level world object that was hit (see ObjectIDs)
+}
+
+// AlbedoProvider is implemented by materials that can report their unlit
+// surface color for the albedo AOV
+type AlbedoProvider interface {
+	SurfaceAlbedo(u, v float64, p Point3) Color
+}
+
+// TracePixelAOV traces the first-hit AOV data of pixel (i, j)
+func (c *Camera) TracePixelAOV(world Hittable, i, j int) PixelAOV {
+	ray := c.centerRay(i, j)
+	rec := &HitRecord{}
+	object := hitObject(world, ray, c.rayInterval(ray), rec)
+	if object == nil {
+		return PixelAOV{Depth: math.Inf(1)}
+	}
+
+	aov := PixelAOV{
+		Hit:      true,
+		Depth:    rec.T * ray.Direction().Len(),
+		Position: rec.P,
+		Normal:   rec.Normal,
+		U:        rec.U,
+		V:        rec.V,
+		Material: rec.Mat,
+		Object:   object,
+	}
+	if provider, ok := rec.Mat.(AlbedoProvider); ok {
+		aov.Albedo = provider.SurfaceAlbedo(rec.U, rec.V, rec.P)
+	}
+	return aov
+}
+
+// =============================================================================
+// PIXEL HOOKS
+// =============================================================================
+
+// PixelContext describes the pixel a PixelHook is processing
+type PixelContext struct {
+	X, Y    int
+	Samples int // Samples behind the value (accumulated over passes)
+	AOV     PixelAOV
+}
+
+// PixelHook post-processes a pixel's linear HDR value before it is gamma
+// encoded into the framebuffer, e.g. for a custom tonemap, stylization or NaN
+// detection. The film and HDR output keep the raw value. Hooks run on the
+// render workers and must be safe for concurrent use.
+type PixelHook func(ctx PixelContext, hdr Color) Color
+
+// applyPixelHook runs hook (if any) for pixel (i, j); the AOV is only traced
+// when a hook is installed
+func applyPixelHook(hook PixelHook, camera *Camera, world Hittable, i, j, samples int, hdr Color) Color {
+	if hook == nil {
+		return hdr
+	}
+	ctx := PixelContext{
+		X:       i,
+		Y:       j,
+		Samples: samples,
+		AOV:     camera.TracePixelAOV(world, i, j),
+	}
+	return hook(ctx, hdr)
+}
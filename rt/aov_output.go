@@ -0,0 +1,151 @@
+package rt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// AOV OUTPUT (POSITION AND CURVATURE)
+// =============================================================================
+
+// AOVPass selects a first-hit AOV saved alongside the render for compositing
+type AOVPass int
+
+const (
+	AOVPosition  AOVPass = iota // World-space hit point (relighting, fog, masks by height)
+	AOVCurvature                // Approximate mean curvature, 1/radius (wear and edge masks)
+)
+
+var aovPassNames = []string{"position", "curvature"}
+
+func (p AOVPass) String() string {
+	if int(p) < 0 || int(p) >= len(aovPassNames) {
+		return "unknown"
+	}
+	return aovPassNames[p]
+}
+
+// ParseAOVPass converts a pass name (e.g. "curvature") to an AOVPass
+func ParseAOVPass(name string) (AOVPass, error) {
+	for i, n := range aovPassNames {
+		if strings.EqualFold(name, n) {
+			return AOVPass(i), nil
+		}
+	}
+	return AOVPosition, fmt.Errorf("unknown AOV: %s (use %s)",
+		name, strings.Join(aovPassNames, ", "))
+}
+
+// ParseAOVPasses converts a comma-separated list (e.g. "position,curvature")
+// to AOVPasses. An empty list selects none.
+func ParseAOVPasses(list string) ([]AOVPass, error) {
+	var passes []AOVPass
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		pass, err := ParseAOVPass(name)
+		if err != nil {
+			return nil, err
+		}
+		passes = append(passes, pass)
+	}
+	return passes, nil
+}
+
+// TraceAOVImage traces the first-hit AOV data of every pixel, row by row
+func (c *Camera) TraceAOVImage(world Hittable) []PixelAOV {
+	aovs := make([]PixelAOV, c.ImageWidth*c.ImageHeight)
+	for j := 0; j < c.ImageHeight; j++ {
+		for i := 0; i < c.ImageWidth; i++ {
+			aovs[j*c.ImageWidth+i] = c.TracePixelAOV(world, i, j)
+		}
+	}
+	return aovs
+}
+
+// AOVFilm returns pass of a traced AOV image as a one-sample film, ready to
+// save as PFM. Misses are black. Curvature is stored in all three channels:
+// positive on convex surfaces, negative in creases and cavities.
+func AOVFilm(aovs []PixelAOV, width, height int, pass AOVPass) *Film {
+	film := NewFilm(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			aov := aovs[y*width+x]
+			var value Color
+			switch {
+			case !aov.Hit:
+			case pass == AOVPosition:
+				value = aov.Position
+			case pass == AOVCurvature:
+				k := pixelCurvature(aovs, width, height, x, y)
+				value = Color{X: k, Y: k, Z: k}
+			}
+			film.AddSamples(x, y, value, 1)
+		}
+	}
+	return film
+}
+
+// pixelCurvature estimates the mean curvature at pixel (x, y) from how the
+// normal turns toward its horizontal and vertical neighbours: along a step
+// dP across the surface, dN·dP/|dP|² is the normal curvature in that
+// direction (1/r on a sphere of radius r). Neighbours on another object or
+// off the geometry are replaced by the opposite neighbour.
+func pixelCurvature(aovs []PixelAOV, width, height, x, y int) float64 {
+	center := aovs[y*width+x]
+	along := func(dx, dy int) (float64, bool) {
+		for _, sign := range []int{1, -1} {
+			nx, ny := x+sign*dx, y+sign*dy
+			if nx < 0 || nx >= width || ny < 0 || ny >= height {
+				continue
+			}
+			n := aovs[ny*width+nx]
+			if !n.Hit || n.Object != center.Object {
+				continue
+			}
+			dp := n.Position.Sub(center.Position)
+			if lenSq := dp.Len2(); lenSq > 0 {
+				return Dot(n.Normal.Sub(center.Normal), dp) / lenSq, true
+			}
+		}
+		return 0, false
+	}
+
+	sum, count := 0.0, 0
+	if k, ok := along(1, 0); ok {
+		sum, count = sum+k, count+1
+	}
+	if k, ok := along(0, 1); ok {
+		sum, count = sum+k, count+1
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// SetAOVOutputs saves the given AOVs as image_<pass>.pfm when the render
+// finishes
+func (r *BucketRenderer) SetAOVOutputs(passes []AOVPass) *BucketRenderer {
+	r.aovOutputs = passes
+	return r
+}
+
+// saveAOVs traces and writes the requested AOVs
+func (r *BucketRenderer) saveAOVs() {
+	if len(r.aovOutputs) == 0 {
+		return
+	}
+	width, height := r.camera.ImageWidth, r.camera.ImageHeight
+	aovs := r.camera.TraceAOVImage(r.world)
+	for _, pass := range r.aovOutputs {
+		filename := fmt.Sprintf("image_%s.pfm", pass)
+		if err := AOVFilm(aovs, width, height, pass).SavePFM(filename); err != nil {
+			fmt.Printf("⚠ Could not save the %s AOV: %v\n", pass, err)
+			continue
+		}
+		fmt.Printf("✓ %s AOV saved to %s\n", pass, filename)
+	}
+}
@@ -0,0 +1,76 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTracePixelAOV(t *testing.T) {
+	world, cam := sphereWith(NewLambertian(Color{X: 0.2, Y: 0.4, Z: 0.8}))()
+
+	center := cam.TracePixelAOV(world, goldenSize/2, goldenSize/2)
+	if !center.Hit {
+		t.Fatal("center ray missed the sphere")
+	}
+	if center.Albedo != (Color{X: 0.2, Y: 0.4, Z: 0.8}) {
+		t.Errorf("albedo = %v", center.Albedo)
+	}
+	if Dot(center.Normal, cam.LookFrom.Sub(center.Position)) <= 0 {
+		t.Errorf("normal %v faces away from the camera", center.Normal)
+	}
+	if want := cam.LookFrom.Sub(center.Position).Len(); math.Abs(center.Depth-want) > 1e-9 {
+		t.Errorf("depth = %v, want %v", center.Depth, want)
+	}
+
+	sky := cam.TracePixelAOV(world, 0, 0)
+	if sky.Hit || !math.IsInf(sky.Depth, 1) {
+		t.Errorf("top-left ray should miss, got %+v", sky)
+	}
+}
+
+func TestPixelHook(t *testing.T) {
+	world, cam := sphereWith(goldenRed)()
+	cam.SamplesPerPixel = 1
+
+	var hits int
+	r := NewProgressiveRenderer(cam, world).SetPixelHook(func(ctx PixelContext, hdr Color) Color {
+		if ctx.AOV.Hit {
+			hits++
+		}
+		if ctx.Samples != 1 {
+			t.Errorf("pixel (%d, %d) reports %d samples", ctx.X, ctx.Y, ctx.Samples)
+		}
+		return Color{X: 0, Y: 1, Z: 0}
+	})
+	r.renderScanline(goldenSize / 2)
+
+	if hits != goldenSize {
+		t.Errorf("%d of %d pixels in the middle row hit geometry", hits, goldenSize)
+	}
+	if c := r.framebuffer.RGBAAt(3, goldenSize/2); c.R != 0 || c.G != 255 {
+		t.Errorf("hook output not displayed, got %v", c)
+	}
+	if r.film.Resolve(3, goldenSize/2) == (Color{X: 0, Y: 1, Z: 0}) {
+		t.Error("hook output leaked into the film")
+	}
+}
+
+func TestCurvatureAOV(t *testing.T) {
+	world, cam := sphereWith(NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))()
+	aovs := cam.TraceAOVImage(world)
+	curvature := AOVFilm(aovs, cam.ImageWidth, cam.ImageHeight, AOVCurvature)
+	position := AOVFilm(aovs, cam.ImageWidth, cam.ImageHeight, AOVPosition)
+
+	x, y := goldenSize/2, goldenSize/2
+	center := aovs[y*cam.ImageWidth+x]
+	const radius = 0.5 // sphereWith
+	if k := curvature.Resolve(x, y).X; math.Abs(k-1/radius) > 0.05/radius {
+		t.Errorf("curvature on the sphere = %g, want about 1/%g", k, radius)
+	}
+	if position.Resolve(x, y) != center.Position {
+		t.Errorf("position AOV = %v, want the hit point %v", position.Resolve(x, y), center.Position)
+	}
+	if curvature.Resolve(0, 0) != (Color{}) || position.Resolve(0, 0) != (Color{}) {
+		t.Error("a miss isn't black")
+	}
+}
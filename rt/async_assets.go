@@ -0,0 +1,149 @@
+package rt
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// =============================================================================
+// ASYNCHRONOUS ASSET LOADING
+// =============================================================================
+
+// placeholderColor stands in for image textures and HDRIs still loading
+var placeholderColor = Color{X: 0.5, Y: 0.5, Z: 0.5}
+
+// pendingAsset is an image or environment loading in the background
+type pendingAsset struct {
+	name string
+	done chan struct{}
+	swap func() // Installs the loaded asset; only called between passes
+}
+
+var asyncAssets struct {
+	enabled atomic.Bool
+	mu      sync.Mutex
+	pending []*pendingAsset
+}
+
+// SetAsyncAssetLoading makes image textures and HDRI environments created
+// afterwards load on background goroutines. Until they finish, textures are
+// a flat 50% gray and environments a uniform 50% gray sky, so the scene
+// builds and the preview starts right away. The bucket renderer swaps the
+// real assets in between passes and waits for any still loading before the
+// first pass that accumulates into the film, so the saved image never sees
+// a placeholder.
+func SetAsyncAssetLoading(enabled bool) {
+	asyncAssets.enabled.Store(enabled)
+}
+
+// PendingAssets returns the number of background loads not yet swapped in
+func PendingAssets() int {
+	asyncAssets.mu.Lock()
+	defer asyncAssets.mu.Unlock()
+	return len(asyncAssets.pending)
+}
+
+// loadAsync runs load on a goroutine. It returns the function that installs
+// the result, which swapLoadedAssets calls once the renderer can take it.
+func loadAsync(name string, load func() (swap func())) {
+	asset := &pendingAsset{name: name, done: make(chan struct{})}
+	asyncAssets.mu.Lock()
+	asyncAssets.pending = append(asyncAssets.pending, asset)
+	asyncAssets.mu.Unlock()
+	go func() {
+		asset.swap = load()
+		close(asset.done)
+	}()
+}
+
+// swapLoadedAssets installs every asset that finished loading, first waiting
+// for all of them if wait is set. Nothing may trace while it runs. It returns
+// the names of the assets swapped in.
+func swapLoadedAssets(wait bool) []string {
+	asyncAssets.mu.Lock()
+	pending := asyncAssets.pending
+	asyncAssets.mu.Unlock()
+
+	var swapped []string
+	var remaining []*pendingAsset
+	for _, asset := range pending {
+		if wait {
+			<-asset.done
+		}
+		select {
+		case <-asset.done:
+			asset.swap()
+			swapped = append(swapped, asset.name)
+		default:
+			remaining = append(remaining, asset)
+		}
+	}
+
+	// Keep loads queued while we were swapping
+	asyncAssets.mu.Lock()
+	asyncAssets.pending = append(remaining, asyncAssets.pending[len(pending):]...)
+	asyncAssets.mu.Unlock()
+	return swapped
+}
+
+// loadImageAsync fills img with a placeholder texel and loads path into it in
+// the background
+func loadImageAsync(img *ImageLoader, path string) {
+	img.data = []Color{placeholderColor}
+	img.imageWidth, img.imageHeight = 1, 1
+	img.bytesPerScanline = img.bytesPerPixel
+	loadAsync(path, func() func() {
+		loaded := NewImageLoader()
+		if !loaded.Load(path) {
+			fmt.Printf("Warning: Failed to load image '%s'\n", path)
+		}
+		return func() { *img = *loaded }
+	})
+}
+
+// loadHDRIAsync returns a uniform placeholder environment and loads filename
+// into it in the background. Rotation and importance sampling settings made
+// on the placeholder carry over.
+func loadHDRIAsync(filename string) *HDRIEnvironment {
+	env := NewUniformEnvironment(placeholderColor)
+	loadAsync(filename, func() func() {
+		loaded := loadHDRIEnvironment(filename)
+		return func() {
+			loaded.rotation = env.rotation
+			if !env.useImportanceSampling {
+				loaded.DisableImportanceSampling()
+			}
+			*env = *loaded
+		}
+	})
+	return env
+}
+
+// installAssets swaps in background loads before a pass: whatever is ready
+// for display-only passes, everything before the film accumulates. A new
+// environment needs its light sampler, preview copy and adaptive importance
+// map rebuilt.
+func (r *BucketRenderer) installAssets(accumulate bool) {
+	swapped := swapLoadedAssets(accumulate)
+	if len(swapped) == 0 {
+		return
+	}
+	fmt.Printf("Pass %d: swapped in %d asset(s) loaded in the background\n", r.currentPass+1, len(swapped))
+
+	if r.camera.Environment == nil {
+		return
+	}
+	if r.fullEnv != nil {
+		r.camera.Environment = r.fullEnv
+		r.SetHDRIPreviewWidth(r.previewWidth)
+	}
+	// adaptEnvironment refines the maps at the start of pass 1 itself
+	if r.envOcclusion != nil && r.currentPass > 1 {
+		r.camera.Environment.applyOcclusion(r.envOcclusion)
+		if r.previewEnv != nil {
+			r.previewEnv.applyOcclusion(r.envOcclusion)
+		}
+	}
+	r.camera.buildLightSampler()
+}
@@ -0,0 +1,48 @@
+package rt
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAsyncImageTextureSwapsIn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "red.png")
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for x := 0; x < 2; x++ {
+		for y := 0; y < 2; y++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	SetAsyncAssetLoading(true)
+	t.Cleanup(func() { SetAsyncAssetLoading(false) })
+	tex := NewImageTexture(path)
+	if got := tex.Value(0.5, 0.5, Point3{}); got != placeholderColor {
+		t.Errorf("before swap = %v, want placeholder %v", got, placeholderColor)
+	}
+	if PendingAssets() != 1 {
+		t.Fatalf("pending = %d, want 1", PendingAssets())
+	}
+
+	if swapped := swapLoadedAssets(true); len(swapped) != 1 {
+		t.Fatalf("swapped %v, want one asset", swapped)
+	}
+	if got := tex.Value(0.5, 0.5, Point3{}); got != (Color{X: 1}) {
+		t.Errorf("after swap = %v, want red", got)
+	}
+	if PendingAssets() != 0 {
+		t.Errorf("pending = %d after swap, want 0", PendingAssets())
+	}
+}
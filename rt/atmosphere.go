@@ -0,0 +1,274 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+)
+
+// =============================================================================
+// PLANETARY ATMOSPHERE SKY
+// =============================================================================
+
+// AtmosphereConfig describes a single-scattering Rayleigh/Mie atmosphere.
+// Distances are in meters, angles in degrees. Scattering coefficients are at
+// sea level per meter and fall off exponentially with their scale heights.
+type AtmosphereConfig struct {
+	SunElevation     float64 // Degrees above the horizon (negative = below)
+	SunAzimuth       float64 // Degrees around +Y, 0 = +X, 90 = +Z
+	SunIntensity     float64 // Radiance scale of the sun
+	SunAngularRadius float64 // Visible sun disk radius in degrees (enlarged for the map resolution)
+
+	PlanetRadius     float64
+	AtmosphereHeight float64 // Thickness of the atmosphere shell
+	Altitude         float64 // Viewer height above the ground
+
+	RayleighScattering  Color
+	RayleighScaleHeight float64
+	MieScattering       float64
+	MieScaleHeight      float64
+	MieAnisotropy       float64 // Henyey-Greenstein g for aerosols (forward scattering)
+	GroundAlbedo        Color   // Diffuse planet surface seen below the horizon
+	ViewSamples         int     // Ray-march steps along each view ray
+	LightSamples        int     // Ray-march steps towards the sun
+	Width               int     // Width of the baked equirectangular map (height = Width/2)
+}
+
+// DefaultAtmosphereConfig returns an Earth-like atmosphere with a low sun
+func DefaultAtmosphereConfig() AtmosphereConfig {
+	return AtmosphereConfig{
+		SunElevation:     10,
+		SunAzimuth:       -90,
+		SunIntensity:     20,
+		SunAngularRadius: 1.5,
+
+		PlanetRadius:     6360e3,
+		AtmosphereHeight: 60e3,
+		Altitude:         1,
+
+		RayleighScattering:  Color{X: 5.8e-6, Y: 13.5e-6, Z: 33.1e-6},
+		RayleighScaleHeight: 7994,
+		MieScattering:       21e-6,
+		MieScaleHeight:      1200,
+		MieAnisotropy:       0.76,
+		GroundAlbedo:        Color{X: 0.1, Y: 0.1, Z: 0.1},
+		ViewSamples:         16,
+		LightSamples:        8,
+		Width:               512,
+	}
+}
+
+// SunDirection returns the unit vector towards the sun
+func (config AtmosphereConfig) SunDirection() Vec3 {
+	elevation := config.SunElevation * math.Pi / 180
+	azimuth := config.SunAzimuth * math.Pi / 180
+	return Vec3{
+		X: math.Cos(elevation) * math.Cos(azimuth),
+		Y: math.Sin(elevation),
+		Z: math.Cos(elevation) * math.Sin(azimuth),
+	}
+}
+
+// NewAtmosphereEnvironment ray-marches the sky into an equirectangular map.
+// Being a regular environment, it is both the background and an importance
+// sampled light, so the sun and sky light the scene through NEE/MIS.
+func NewAtmosphereEnvironment(config AtmosphereConfig) *HDRIEnvironment {
+	width := max(config.Width, 16)
+	height := width / 2
+	sky := newAtmosphere(config)
+	image := bakeEquirect(width, height, func(_, _ float64, dir Vec3) Color {
+		return sky.radiance(dir)
+	})
+
+	fmt.Printf("Atmosphere: baked %dx%d sky (sun elevation %.1f°, azimuth %.1f°)\n",
+		width, height, config.SunElevation, config.SunAzimuth)
+
+	env := newEnvironmentFromImage(image)
+	env.nearest = true
+	return env
+}
+
+// bakeEquirect fills a width x height equirectangular map on all CPUs with
+// radiance(u, v, dir) at every texel center, dir in unrotated map space
+func bakeEquirect(width, height int, radiance func(u, v float64, dir Vec3) Color) *ImageLoader {
+	image := &ImageLoader{
+		data:        make([]Color, width*height),
+		imageWidth:  width,
+		imageHeight: height,
+		IsHDR:       true,
+	}
+	layout := &HDRIEnvironment{width: width, height: height}
+	parallelRows(height, func(y int) {
+		v := (float64(y) + 0.5) / float64(height)
+		for x := 0; x < width; x++ {
+			u := (float64(x) + 0.5) / float64(width)
+			image.data[y*width+x] = radiance(u, v, layout.UVToDirection(u, v))
+		}
+	})
+	return image
+}
+
+// atmosphere holds the derived quantities used while marching
+type atmosphere struct {
+	config AtmosphereConfig
+	origin Vec3 // Viewer, relative to the planet center
+	sunDir Vec3
+	// Solid angle of the faded sun disk, so its irradiance stays SunIntensity
+	sunDiskSolidAngle float64
+	topRadius         float64
+}
+
+func newAtmosphere(config AtmosphereConfig) *atmosphere {
+	a := &atmosphere{
+		config:    config,
+		origin:    Vec3{X: 0, Y: config.PlanetRadius + math.Max(config.Altitude, 1), Z: 0},
+		sunDir:    config.SunDirection(),
+		topRadius: config.PlanetRadius + config.AtmosphereHeight,
+	}
+
+	// Integrate the falloff over the cap numerically (∫ f(θ) 2π sinθ dθ)
+	radius := config.SunAngularRadius * math.Pi / 180
+	const steps = 64
+	for i := 0; i < steps; i++ {
+		theta := (float64(i) + 0.5) / steps * radius
+		falloff := 1 - smoothstep(0.5*radius, radius, theta)
+		a.sunDiskSolidAngle += falloff * 2 * math.Pi * math.Sin(theta) * radius / steps
+	}
+	return a
+}
+
+// raySphereExit returns the far intersection distance of a ray starting
+// inside a sphere centered at the origin
+func raySphereExit(origin, dir Vec3, radius float64) float64 {
+	b := Dot(origin, dir)
+	c := origin.Len2() - radius*radius
+	disc := b*b - c
+	if disc < 0 {
+		return 0
+	}
+	return -b + math.Sqrt(disc)
+}
+
+// rayHitsPlanet returns the distance to the ground, or false if the ray misses it
+func rayHitsPlanet(origin, dir Vec3, radius float64) (float64, bool) {
+	b := Dot(origin, dir)
+	c := origin.Len2() - radius*radius
+	disc := b*b - c
+	if disc < 0 {
+		return 0, false
+	}
+	t := -b - math.Sqrt(disc)
+	return t, t > 0
+}
+
+// densities returns the Rayleigh and Mie density at a point
+func (a *atmosphere) densities(p Vec3) (float64, float64) {
+	h := math.Max(p.Len()-a.config.PlanetRadius, 0)
+	return math.Exp(-h / a.config.RayleighScaleHeight), math.Exp(-h / a.config.MieScaleHeight)
+}
+
+// extinction converts optical depths into a per-channel transmittance
+func (a *atmosphere) transmittance(rayleighDepth, mieDepth float64) Color {
+	mie := a.config.MieScattering * 1.1 * mieDepth // Mie extinction ≈ 1.1 × scattering
+	return Color{
+		X: math.Exp(-(a.config.RayleighScattering.X*rayleighDepth + mie)),
+		Y: math.Exp(-(a.config.RayleighScattering.Y*rayleighDepth + mie)),
+		Z: math.Exp(-(a.config.RayleighScattering.Z*rayleighDepth + mie)),
+	}
+}
+
+// sunOpticalDepth marches from p towards the sun; ok is false when the
+// planet blocks it
+func (a *atmosphere) sunOpticalDepth(p Vec3) (rayleigh, mie float64, ok bool) {
+	if _, hit := rayHitsPlanet(p, a.sunDir, a.config.PlanetRadius); hit {
+		return 0, 0, false
+	}
+	steps := max(a.config.LightSamples, 1)
+	ds := raySphereExit(p, a.sunDir, a.topRadius) / float64(steps)
+	for i := 0; i < steps; i++ {
+		dr, dm := a.densities(p.Add(a.sunDir.Scale((float64(i) + 0.5) * ds)))
+		rayleigh += dr * ds
+		mie += dm * ds
+	}
+	return rayleigh, mie, true
+}
+
+// sunDisk returns the sun's radiance (before SunIntensity) at cosine mu from
+// its center. The disk integrates to an irradiance of 1 and fades out over its
+// outer half, so neighboring map texels don't jump from sky to sun.
+func (a *atmosphere) sunDisk(mu float64) float64 {
+	angle := math.Acos(clampFloat(mu, -1, 1))
+	radius := a.config.SunAngularRadius * math.Pi / 180
+	if angle >= radius || radius <= 0 {
+		return 0
+	}
+	falloff := 1 - smoothstep(0.5*radius, radius, angle)
+	return falloff / a.sunDiskSolidAngle
+}
+
+func smoothstep(edge0, edge1, x float64) float64 {
+	t := clampFloat((x-edge0)/(edge1-edge0), 0, 1)
+	return t * t * (3 - 2*t)
+}
+
+// radiance returns the sky radiance seen along dir from the viewer
+func (a *atmosphere) radiance(dir Vec3) Color {
+	sky, sun := a.radianceParts(dir)
+	return sky.Add(sun)
+}
+
+// radianceParts returns the radiance along dir split into the scattering sky
+// (with the ground) and the sun disk
+func (a *atmosphere) radianceParts(dir Vec3) (sky, sun Color) {
+	dir = dir.Unit()
+	tMax := raySphereExit(a.origin, dir, a.topRadius)
+	groundT, hitsGround := rayHitsPlanet(a.origin, dir, a.config.PlanetRadius)
+	if hitsGround {
+		tMax = groundT
+	}
+
+	steps := max(a.config.ViewSamples, 1)
+	ds := tMax / float64(steps)
+	var rayleighDepth, mieDepth float64
+	rayleighSum := Color{X: 0, Y: 0, Z: 0}
+	mieSum := Color{X: 0, Y: 0, Z: 0}
+	for i := 0; i < steps; i++ {
+		p := a.origin.Add(dir.Scale((float64(i) + 0.5) * ds))
+		dr, dm := a.densities(p)
+		rayleighDepth += dr * ds
+		mieDepth += dm * ds
+
+		sunR, sunM, lit := a.sunOpticalDepth(p)
+		if !lit {
+			continue
+		}
+		attenuation := a.transmittance(rayleighDepth+sunR, mieDepth+sunM)
+		rayleighSum = rayleighSum.Add(attenuation.Scale(dr * ds))
+		mieSum = mieSum.Add(attenuation.Scale(dm * ds))
+	}
+
+	mu := Dot(dir, a.sunDir)
+	g := a.config.MieAnisotropy
+	phaseR := 3 / (16 * math.Pi) * (1 + mu*mu)
+	phaseM := (1 - g*g) / (4 * math.Pi * math.Pow(1+g*g-2*g*mu, 1.5))
+
+	color := rayleighSum.Mult(a.config.RayleighScattering).Scale(phaseR).
+		Add(mieSum.Scale(a.config.MieScattering * phaseM))
+
+	viewTransmittance := a.transmittance(rayleighDepth, mieDepth)
+	if hitsGround {
+		// Lambertian ground lit by the attenuated sun
+		ground := a.origin.Add(dir.Scale(groundT))
+		normal := ground.Unit()
+		if cos := Dot(normal, a.sunDir); cos > 0 {
+			if sunR, sunM, lit := a.sunOpticalDepth(ground.Add(normal.Scale(1))); lit {
+				irradiance := a.transmittance(sunR, sunM).Scale(cos / math.Pi)
+				color = color.Add(a.config.GroundAlbedo.Mult(irradiance).Mult(viewTransmittance))
+			}
+		}
+	} else if disk := a.sunDisk(mu); disk > 0 {
+		// Sun disk, reddened by the atmosphere in front of it
+		sun = viewTransmittance.Scale(disk * a.config.SunIntensity)
+	}
+
+	return color.Scale(a.config.SunIntensity), sun
+}
@@ -0,0 +1,30 @@
+package rt
+
+import "testing"
+
+func TestAtmosphereColors(t *testing.T) {
+	config := DefaultAtmosphereConfig()
+	config.SunElevation = 60
+	config.ViewSamples = 8
+	config.LightSamples = 4
+	noon := newAtmosphere(config)
+
+	zenith := noon.radiance(Vec3{X: 0, Y: 1, Z: 0})
+	if !(zenith.Z > zenith.Y && zenith.Y > zenith.X) {
+		t.Errorf("noon zenith %v should be blue (B > G > R)", zenith)
+	}
+
+	config.SunElevation = 2
+	sunset := newAtmosphere(config)
+	sun := config.SunDirection()
+	towardsSun := sunset.radiance(Vec3{X: sun.X, Y: 0.05, Z: sun.Z})
+	if !(towardsSun.X > towardsSun.Z) {
+		t.Errorf("sunset horizon %v should be red-shifted (R > B)", towardsSun)
+	}
+
+	config.SunElevation = -20
+	night := newAtmosphere(config)
+	if dark := night.radiance(Vec3{X: 0, Y: 1, Z: 0}); Luminance(dark) >= Luminance(zenith)*0.01 {
+		t.Errorf("sky with the sun 20° below the horizon %v should be near black (noon %v)", dark, zenith)
+	}
+}
@@ -0,0 +1,191 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// =============================================================================
+// AUTO EXPOSURE
+// =============================================================================
+
+// AutoExposureMode selects how exposure is metered from the preview pass
+type AutoExposureMode int
+
+const (
+	AutoExposureOff        AutoExposureMode = iota
+	AutoExposureLogAverage                  // Log-average luminance maps to Key (Reinhard)
+	AutoExposurePercentile                  // Luminance at Percentile maps to white
+)
+
+var autoExposureModeNames = []string{"off", "log-average", "percentile"}
+
+func (m AutoExposureMode) String() string {
+	if int(m) < 0 || int(m) >= len(autoExposureModeNames) {
+		return "unknown"
+	}
+	return autoExposureModeNames[m]
+}
+
+// ParseAutoExposureMode converts a mode name (e.g. "log-average") to an
+// AutoExposureMode
+func ParseAutoExposureMode(name string) (AutoExposureMode, error) {
+	for i, n := range autoExposureModeNames {
+		if strings.EqualFold(name, n) {
+			return AutoExposureMode(i), nil
+		}
+	}
+	return AutoExposureOff, fmt.Errorf("unknown auto exposure mode: %s (use %s)",
+		name, strings.Join(autoExposureModeNames, ", "))
+}
+
+// AutoExposureConfig controls the exposure metered from the HDR preview pass
+// and applied to the displayed and saved PNG image of every later pass. The
+// film and HDR output keep the raw values.
+type AutoExposureConfig struct {
+	Mode       AutoExposureMode
+	Key        float64 // Linear value the log-average luminance maps to (middle gray)
+	Percentile float64 // Fraction of pixels at or below the luminance mapped to white
+	MinStops   float64 // Exposure is clamped to [MinStops, MaxStops] EV
+	MaxStops   float64
+}
+
+// DefaultAutoExposureConfig returns a disabled meter with a middle-gray key
+// of 0.18 and the 95th percentile as white point
+func DefaultAutoExposureConfig() AutoExposureConfig {
+	return AutoExposureConfig{
+		Mode:       AutoExposureOff,
+		Key:        0.18,
+		Percentile: 0.95,
+		MinStops:   -8,
+		MaxStops:   8,
+	}
+}
+
+const (
+	meteringGrid     = 16    // Metering cells along the longer image side
+	histogramMinLog2 = -16.0 // Luminance range of the metering histogram, in stops
+	histogramMaxLog2 = 16.0
+	histogramBins    = 128 // Quarter-stop bins
+)
+
+// luminanceHistogram counts pixel luminances in log2 bins. Black pixels
+// (nothing seen, or no sample found light) are left out.
+type luminanceHistogram struct {
+	counts []int
+	total  int
+	logSum float64 // Sum of log(luminance), for the log-average
+}
+
+func newLuminanceHistogram(luminance []float64) *luminanceHistogram {
+	h := &luminanceHistogram{counts: make([]int, histogramBins)}
+	binWidth := (histogramMaxLog2 - histogramMinLog2) / histogramBins
+	for _, lum := range luminance {
+		if !(lum > 0) || math.IsInf(lum, 0) {
+			continue
+		}
+		l := math.Max(lum, math.Exp2(histogramMinLog2))
+		bin := int((math.Log2(l) - histogramMinLog2) / binWidth)
+		h.counts[min(max(bin, 0), histogramBins-1)]++
+		h.logSum += math.Log(l)
+		h.total++
+	}
+	return h
+}
+
+// logAverage returns the geometric mean luminance
+func (h *luminanceHistogram) logAverage() float64 {
+	return math.Exp(h.logSum / float64(h.total))
+}
+
+// percentile returns the upper edge of the bin containing fraction p of the
+// pixels
+func (h *luminanceHistogram) percentile(p float64) float64 {
+	binWidth := (histogramMaxLog2 - histogramMinLog2) / histogramBins
+	target := clampFloat(p, 0, 1) * float64(h.total)
+	seen := 0
+	for bin, count := range h.counts {
+		seen += count
+		if float64(seen) >= target {
+			return math.Exp2(histogramMinLog2 + float64(bin+1)*binWidth)
+		}
+	}
+	return math.Exp2(histogramMaxLog2)
+}
+
+// MeterExposure returns the exposure multiplier for an image with the given
+// per-pixel luminances (1 when the mode is off or there is nothing to meter)
+func MeterExposure(luminance []float64, config AutoExposureConfig) float64 {
+	if config.Mode == AutoExposureOff {
+		return 1
+	}
+	h := newLuminanceHistogram(luminance)
+	if h.total == 0 {
+		return 1
+	}
+
+	var exposure float64
+	switch config.Mode {
+	case AutoExposurePercentile:
+		exposure = 1 / h.percentile(config.Percentile)
+	default:
+		exposure = config.Key / h.logAverage()
+	}
+	stops := clampFloat(math.Log2(exposure), config.MinStops, config.MaxStops)
+	return math.Exp2(stops)
+}
+
+// exposureMeter collects the HDR luminance of every pixel during the preview
+// pass. Each pixel belongs to one bucket, so workers never share an entry.
+type exposureMeter struct {
+	width, height int
+	luminance     []float64
+}
+
+func newExposureMeter(width, height int) *exposureMeter {
+	return &exposureMeter{width: width, height: height, luminance: make([]float64, width*height)}
+}
+
+// cells returns the mean luminance of square blocks, meteringGrid along the
+// longer side. At 1 SPP most preview pixels are black or a lucky hit on a
+// light, and the log-average of such noise is far below that of the
+// converged image; averaging blocks first meters the scene instead.
+func (m *exposureMeter) cells() []float64 {
+	meteringCell := max(1, (max(m.width, m.height)+meteringGrid-1)/meteringGrid)
+	cols := (m.width + meteringCell - 1) / meteringCell
+	rows := (m.height + meteringCell - 1) / meteringCell
+	sums := make([]float64, cols*rows)
+	counts := make([]int, cols*rows)
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			cell := (y/meteringCell)*cols + x/meteringCell
+			sums[cell] += m.luminance[y*m.width+x]
+			counts[cell]++
+		}
+	}
+	for i := range sums {
+		sums[i] /= float64(counts[i])
+	}
+	return sums
+}
+
+func (m *exposureMeter) record(x, y int, c Color) {
+	if m != nil {
+		m.luminance[y*m.width+x] = Luminance(c)
+	}
+}
+
+// SetAutoExposure meters exposure from the preview pass and applies it to
+// the display and saved PNG from the next pass on
+func (r *BucketRenderer) SetAutoExposure(config AutoExposureConfig) *BucketRenderer {
+	r.autoExposure = config
+	return r
+}
+
+// meterExposure sets the renderer's exposure from the preview pass
+func (r *BucketRenderer) meterExposure() {
+	r.exposure = MeterExposure(r.meter.cells(), r.autoExposure)
+	r.meter = nil
+	fmt.Printf("Auto exposure (%s): %+.2f EV\n", r.autoExposure.Mode, math.Log2(r.exposure))
+}
@@ -0,0 +1,111 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func uniformLuminance(n int, lum float64) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = lum
+	}
+	return values
+}
+
+func TestMeterExposureLogAverage(t *testing.T) {
+	config := DefaultAutoExposureConfig()
+	config.Mode = AutoExposureLogAverage
+
+	// A flat 0.045 image needs +2 stops to reach middle gray
+	if got := MeterExposure(uniformLuminance(100, 0.045), config); math.Abs(got-4) > 1e-9 {
+		t.Errorf("exposure = %v, want 4", got)
+	}
+
+	// The geometric mean of 0.01 and 1 is 0.1
+	values := append(uniformLuminance(50, 0.01), uniformLuminance(50, 1)...)
+	if got, want := MeterExposure(values, config), 1.8; math.Abs(got-want) > 1e-9 {
+		t.Errorf("exposure = %v, want %v", got, want)
+	}
+}
+
+func TestMeterExposurePercentile(t *testing.T) {
+	config := DefaultAutoExposureConfig()
+	config.Mode = AutoExposurePercentile
+
+	// 95% of the image at 0.25 and a few bright lights: the lights are
+	// ignored and 0.25 (within a quarter-stop bin) maps to white
+	values := append(uniformLuminance(95, 0.25), uniformLuminance(5, 100)...)
+	stops := math.Log2(MeterExposure(values, config))
+	if stops < 1.75 || stops > 2 {
+		t.Errorf("exposure = %+.2f EV, want about +2 EV", stops)
+	}
+}
+
+func TestMeterExposureLimits(t *testing.T) {
+	config := DefaultAutoExposureConfig()
+	if got := MeterExposure(uniformLuminance(10, 0.001), config); got != 1 {
+		t.Errorf("disabled meter returned %v, want 1", got)
+	}
+
+	config.Mode = AutoExposureLogAverage
+	if got := MeterExposure(uniformLuminance(10, 1e-6), config); got != math.Exp2(config.MaxStops) {
+		t.Errorf("near-black image exposure = %v, want the +%g EV limit", got, config.MaxStops)
+	}
+	// Black (background or unlit) and invalid pixels aren't metered
+	if got := MeterExposure(append(uniformLuminance(10, 0), 0.045, math.NaN(), math.Inf(1)), config); math.Abs(got-4) > 1e-9 {
+		t.Errorf("exposure = %v, want 4 from the one metered pixel", got)
+	}
+
+	if mode, err := ParseAutoExposureMode("Log-Average"); err != nil || mode != AutoExposureLogAverage {
+		t.Errorf("ParseAutoExposureMode = %v, %v", mode, err)
+	}
+	if _, err := ParseAutoExposureMode("spot"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestExposureMeterCellsAverageNoise(t *testing.T) {
+	// A 1 SPP-like image: one pixel in four found the light
+	m := newExposureMeter(32, 16)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			if (x+y)%4 == 0 {
+				m.record(x, y, Color{X: 1, Y: 1, Z: 1})
+			}
+		}
+	}
+	cells := m.cells()
+	if len(cells) != 16*8 {
+		t.Fatalf("got %d cells, want 16x8", len(cells))
+	}
+	for i, lum := range cells {
+		if math.Abs(lum-0.25) > 1e-9 {
+			t.Fatalf("cell %d luminance = %v, want the 0.25 mean", i, lum)
+		}
+	}
+}
+
+func TestBucketRendererMetersPreviewPass(t *testing.T) {
+	SeedRandom(1)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	// A grey sphere against a dim background comes out dark without exposure
+	world := NewHittableList()
+	world.Add(NewSphere(Point3{Z: -1}, 0.5, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})))
+	camera := NewCameraBuilder().SetResolution(16, 1).SetQuality(4, 4).
+		SetBackground(Color{X: 0.01, Y: 0.01, Z: 0.01}).Build()
+
+	config := DefaultAutoExposureConfig()
+	config.Mode = AutoExposureLogAverage
+	r := NewBucketRenderer(camera, world, 8, 2).SetAutoExposure(config)
+	defer r.Close()
+
+	r.renderPass()
+	if r.exposure <= 1 {
+		t.Fatalf("exposure after the preview pass = %v, want it raised for a dark scene", r.exposure)
+	}
+	if r.meter != nil {
+		t.Error("meter should be released after the preview pass")
+	}
+}
@@ -0,0 +1,98 @@
+package rt
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// =============================================================================
+// BACKGROUND ALPHA
+// =============================================================================
+
+// backgroundCoverageGrid is the per-axis count of pinhole rays used to measure
+// how much of a pixel the scene covers (antialiased alpha edges)
+const backgroundCoverageGrid = 4
+
+// SetBackgroundAlpha makes saved PNGs transparent where the background shows:
+// 0 cuts the render out for compositing, 1 keeps it opaque. Only the alpha
+// channel and the background's share of edge colors change; lighting, HDR
+// output and the viewer are unaffected.
+func (c *Camera) SetBackgroundAlpha(alpha float64) *Camera {
+	c.TransparentBackground = true
+	c.BackgroundAlpha = clampFloat(alpha, 0, 1)
+	return c
+}
+
+// pixelCoverage returns the fraction of pinhole rays through pixel (i, j)
+// that hit the scene
+func (c *Camera) pixelCoverage(world Hittable, i, j int) float64 {
+	hits := 0
+	rec := &HitRecord{}
+	for sy := 0; sy < backgroundCoverageGrid; sy++ {
+		for sx := 0; sx < backgroundCoverageGrid; sx++ {
+			dx := (float64(sx)+0.5)/backgroundCoverageGrid - 0.5
+			dy := (float64(sy)+0.5)/backgroundCoverageGrid - 0.5
+			target := c.pixel00Loc.
+				Add(c.pixelDeltaU.Scale(float64(i) + dx)).
+				Add(c.pixelDeltaV.Scale(float64(j) + dy))
+			ray := NewRay(c.center, target.Sub(c.center), 0).withKind(rayCamera)
+			if world.Hit(ray, c.rayInterval(ray), rec) {
+				hits++
+			}
+		}
+	}
+	return float64(hits) / (backgroundCoverageGrid * backgroundCoverageGrid)
+}
+
+// backgroundAlphaImage converts a rendered frame to straight-alpha RGBA. A
+// pixel with scene coverage k blended k*scene + (1-k)*background; its alpha
+// becomes k + (1-k)*BackgroundAlpha and the transparent part of the
+// background is removed from its color (in linear space, at the display
+// exposure and tone curve the frame was encoded with).
+func backgroundAlphaImage(frame *image.RGBA, exposure float64, toneMap ToneMapConfig, camera *Camera, world Hittable) *image.NRGBA {
+	bounds := frame.Bounds()
+	out := image.NewNRGBA(bounds)
+	bgAlpha := camera.BackgroundAlpha
+
+	var wg sync.WaitGroup
+	rows := make(chan int, bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		rows <- y
+	}
+	close(rows)
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					coverage := camera.pixelCoverage(world, x, y)
+					alpha := coverage + (1-coverage)*bgAlpha
+					if alpha <= 0 {
+						out.SetNRGBA(x, y, color.NRGBA{})
+						continue
+					}
+
+					px := frame.RGBAAt(x, y)
+					linear := Color{
+						X: GammaToLinear(float64(px.R) / 255),
+						Y: GammaToLinear(float64(px.G) / 255),
+						Z: GammaToLinear(float64(px.B) / 255),
+					}
+					background := toneMap.Apply(camera.backgroundRadiance(camera.centerRay(x, y), true).Scale(exposure))
+					removed := background.Scale((1 - coverage) * (1 - bgAlpha))
+					straight := linear.Sub(removed).Scale(1 / alpha)
+					straight = Color{X: math.Max(0, straight.X), Y: math.Max(0, straight.Y), Z: math.Max(0, straight.Z)}
+
+					rgba := LinearToRGBA(straight)
+					out.SetNRGBA(x, y, color.NRGBA{R: rgba.R, G: rgba.G, B: rgba.B, A: uint8(math.Round(255 * alpha))})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return out
+}
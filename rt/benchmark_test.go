@@ -145,6 +145,30 @@ func BenchmarkBVHConstruction(b *testing.B) {
 	}
 }
 
+// BenchmarkBVHBuilders compares construction time of the BVH builders
+func BenchmarkBVHBuilders(b *testing.B) {
+	objects := make([]Hittable, 20000)
+	for i := range objects {
+		center := Point3{
+			RandomDoubleRange(-50, 50),
+			RandomDoubleRange(-50, 50),
+			RandomDoubleRange(-50, 50),
+		}
+		objects[i] = NewSphere(center, 0.3, NewLambertian(Color{0.5, 0.5, 0.5}))
+	}
+	list := &HittableList{Objects: objects}
+
+	for _, builder := range []BVHBuilderType{BVHMedian, BVHSAH, BVHLBVH} {
+		opts := DefaultBVHOptions()
+		opts.Builder = builder
+		b.Run(builder.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = NewBVHNodeFromListWithOptions(list, opts)
+			}
+		})
+	}
+}
+
 // BenchmarkRayTracing benchmarks full ray tracing for a single pixel
 func BenchmarkRayTracing(b *testing.B) {
 	// Set up a simple scene
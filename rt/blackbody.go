@@ -0,0 +1,122 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// BLACKBODY (KELVIN) COLORS
+// =============================================================================
+
+const (
+	blackbodyMinKelvin = 500.0   // Below this a blackbody is a dull red glow at most
+	blackbodyMaxKelvin = 40000.0 // Above this the color barely changes
+
+	planckC2 = 1.4387769e-2 // Second radiation constant hc/k (m·K)
+)
+
+// ColorFromKelvin returns the linear sRGB color of a blackbody at temperature
+// kelvin, with luminance 1: about 1900 K for candlelight, 2700 K for a
+// tungsten bulb, 5500 K for midday sun and 6500 K for overcast daylight (close
+// to white). Scale it for intensity, e.g. NewDiffuseLightColor(ColorFromKelvin(3200).Scale(8)).
+// Temperatures are clamped to 500-40000 K.
+func ColorFromKelvin(kelvin float64) Color {
+	t := clampFloat(kelvin, blackbodyMinKelvin, blackbodyMaxKelvin)
+
+	// Integrate Planck's law against the CIE 1931 observer
+	var x, y, z float64
+	for lambda := 380.0; lambda <= 780; lambda += 5 {
+		m := lambda * 1e-9
+		radiance := 1 / (math.Pow(m, 5) * (math.Exp(planckC2/(m*t)) - 1))
+		cx, cy, cz := cieObserver(lambda)
+		x += radiance * cx
+		y += radiance * cy
+		z += radiance * cz
+	}
+
+	// XYZ to linear sRGB (D65); very warm colors fall outside the gamut
+	rgb := Color{
+		X: math.Max(0, 3.2406*x-1.5372*y-0.4986*z),
+		Y: math.Max(0, -0.9689*x+1.8758*y+0.0415*z),
+		Z: math.Max(0, 0.0557*x-0.2040*y+1.0570*z),
+	}
+	if lum := Luminance(rgb); lum > 0 {
+		return rgb.Scale(1 / lum)
+	}
+	return Color{X: 1, Y: 1, Z: 1}
+}
+
+// cieObserver returns the CIE 1931 2° color matching functions at lambda
+// (nm), using the multi-lobe Gaussian fit of Wyman, Sloan and Shirley
+func cieObserver(lambda float64) (x, y, z float64) {
+	g := func(mu, sigmaLow, sigmaHigh float64) float64 {
+		sigma := sigmaHigh
+		if lambda < mu {
+			sigma = sigmaLow
+		}
+		d := (lambda - mu) / sigma
+		return math.Exp(-0.5 * d * d)
+	}
+	x = 1.056*g(599.8, 37.9, 31.0) + 0.362*g(442.0, 16.0, 26.7) - 0.065*g(501.1, 20.4, 26.2)
+	y = 0.821*g(568.8, 46.9, 40.5) + 0.286*g(530.9, 16.3, 31.1)
+	z = 1.217*g(437.0, 11.8, 36.0) + 0.681*g(459.0, 26.0, 13.8)
+	return x, y, z
+}
+
+// NewDiffuseLightKelvin returns an emitter of color temperature kelvin whose
+// radiance has luminance intensity
+func NewDiffuseLightKelvin(kelvin, intensity float64) *DiffuseLight {
+	return NewDiffuseLightColor(ColorFromKelvin(kelvin).Scale(intensity))
+}
+
+// blackbodyTableSize is the number of temperatures a BlackbodyTexture
+// precomputes across its range
+const blackbodyTableSize = 256
+
+// BlackbodyTexture colors a temperature field: the luminance of temperature
+// (0 to 1) maps linearly onto minKelvin to maxKelvin. Use it as the emission
+// of fire, embers or hot metal. With physical intensity the brightness also
+// follows the Stefan-Boltzmann T⁴ law relative to maxKelvin, so cooler parts
+// are much dimmer as well as redder.
+type BlackbodyTexture struct {
+	temperature Texture
+	minKelvin   float64
+	maxKelvin   float64
+	physical    bool
+	table       []Color // Colors at evenly spaced temperatures across the range
+}
+
+func NewBlackbodyTexture(temperature Texture, minKelvin, maxKelvin float64) *BlackbodyTexture {
+	t := &BlackbodyTexture{
+		temperature: temperature,
+		minKelvin:   math.Min(minKelvin, maxKelvin),
+		maxKelvin:   math.Max(minKelvin, maxKelvin),
+		table:       make([]Color, blackbodyTableSize),
+	}
+	for i := range t.table {
+		t.table[i] = ColorFromKelvin(t.kelvin(float64(i) / (blackbodyTableSize - 1)))
+	}
+	return t
+}
+
+// SetPhysicalIntensity scales the emission by (T / maxKelvin)⁴
+func (t *BlackbodyTexture) SetPhysicalIntensity(enabled bool) *BlackbodyTexture {
+	t.physical = enabled
+	return t
+}
+
+// kelvin returns the temperature at fraction f of the range
+func (t *BlackbodyTexture) kelvin(f float64) float64 {
+	return t.minKelvin + f*(t.maxKelvin-t.minKelvin)
+}
+
+func (t *BlackbodyTexture) Value(u, v float64, p Point3) Color {
+	f := clampFloat(Luminance(t.temperature.Value(u, v, p)), 0, 1)
+	pos := f * (blackbodyTableSize - 1)
+	i := min(int(pos), blackbodyTableSize-2)
+	frac := pos - float64(i)
+	c := t.table[i].Scale(1 - frac).Add(t.table[i+1].Scale(frac))
+	if t.physical && t.maxKelvin > 0 {
+		r := t.kelvin(f) / t.maxKelvin
+		c = c.Scale(r * r * r * r)
+	}
+	return c
+}
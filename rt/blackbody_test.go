@@ -0,0 +1,40 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestColorFromKelvin(t *testing.T) {
+	for _, k := range []float64{1000, 1900, 2700, 4000, 6500, 10000, 20000} {
+		if lum := Luminance(ColorFromKelvin(k)); math.Abs(lum-1) > 1e-9 {
+			t.Errorf("%g K has luminance %g, want 1", k, lum)
+		}
+	}
+
+	warm, daylight, sky := ColorFromKelvin(2700), ColorFromKelvin(6500), ColorFromKelvin(15000)
+	if !(warm.X > warm.Y && warm.Y > warm.Z) {
+		t.Errorf("2700 K = %v, want orange (R > G > B)", warm)
+	}
+	if math.Abs(daylight.X-daylight.Z) > 0.1 || math.Abs(daylight.X-daylight.Y) > 0.1 {
+		t.Errorf("6500 K = %v, want close to white", daylight)
+	}
+	if !(sky.Z > sky.X) {
+		t.Errorf("15000 K = %v, want bluish", sky)
+	}
+}
+
+func TestBlackbodyTexture(t *testing.T) {
+	cold, hot := NewSolidColor(Color{}), NewSolidColor(Color{X: 1, Y: 1, Z: 1})
+	if got, want := NewBlackbodyTexture(hot, 1000, 3000).Value(0, 0, Point3{}), ColorFromKelvin(3000); got.Sub(want).Len() > 1e-9 {
+		t.Errorf("hottest = %v, want %v", got, want)
+	}
+	if got, want := NewBlackbodyTexture(cold, 1000, 3000).Value(0, 0, Point3{}), ColorFromKelvin(1000); got.Sub(want).Len() > 1e-9 {
+		t.Errorf("coldest = %v, want %v", got, want)
+	}
+
+	physical := NewBlackbodyTexture(cold, 1000, 2000).SetPhysicalIntensity(true)
+	if got := Luminance(physical.Value(0, 0, Point3{})); math.Abs(got-1.0/16) > 1e-9 {
+		t.Errorf("half the temperature has luminance %g, want 1/16", got)
+	}
+}
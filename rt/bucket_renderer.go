@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"image/png"
+	"image/draw"
+	"math"
 	"os"
 	"sort"
 	"sync"
@@ -12,9 +13,7 @@ import (
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/text/v2"
-	"golang.org/x/image/font/basicfont"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 // Bucket represents a tile/region of the image to render
@@ -34,6 +33,7 @@ type Bucket struct {
 // - crossbeam::channel or rayon::par_iter for work distribution
 type BucketRenderer struct {
 	framebuffer    *image.RGBA
+	film           *Film // Linear radiance accumulated across passes
 	camera         *Camera
 	world          Hittable
 	buckets        []Bucket
@@ -46,9 +46,36 @@ type BucketRenderer struct {
 	numWorkers     int
 	renderStarted  bool
 	currentPass    int
-	totalPasses    int
 	passComplete   atomic.Bool
 	mu             sync.Mutex // Protects framebuffer writes
+	overlay        OverlayConfig
+	dof            *dofPreview      // Created when the DOF preview is first shown
+	sceneName      string           // Recorded in saved image metadata
+	hdrOutput      string           // Optional path for the raw linear render (.pfm)
+	fullEnv        *HDRIEnvironment // Environment for the final pass
+	previewEnv     *HDRIEnvironment // Downsampled environment for earlier passes (nil = off)
+	previewWidth   int              // Max width of previewEnv, to rebuild it for a late-loading HDRI
+	stats          *bucketStats     // Samples/sec and variance per bucket for the overlay
+	workerStats    *workerStats     // Buckets and busy time per worker and pass
+	pool           *workerPool      // Render workers shared by all passes (created on first use)
+	poolMu         sync.Mutex       // Guards pool
+	pixelHook      PixelHook        // Optional per-pixel post-process before display
+	nanCheck       *nanChecker      // Replaces and logs NaN/Inf samples (nil = off)
+	fireflyFilter  FireflyFilterConfig
+	budget         *timeBudget // Accumulate until a wall-clock budget expires (nil = fixed passes)
+	autoExposure   AutoExposureConfig
+	exposure       float64               // Display/PNG multiplier applied before the pixel hook
+	meter          *exposureMeter        // Collects preview luminance for auto exposure (nil = not metering)
+	toneMap        ToneMapConfig         // Display/PNG tone curve applied after the pixel hook
+	envCaches      []*envVisibilityCache // Per-bucket environment visibility for display-only passes (nil = off)
+	compare        *comparison           // A/B reference for the viewer
+	lightAOVs      *lightAOVs            // Final render split by light (nil = off)
+	aovOutputs     []AOVPass             // First-hit AOVs saved when the render finishes
+	envOcclusion   *envOcclusionMap      // Preview-pass environment visibility for the adaptive map (nil = off)
+	adaptiveLight  AdaptiveLightConfig
+	neeBuckets     []*neeBucket        // Per-bucket light sample counts (nil = off)
+	composite      CompositePassConfig // Shadow and reflection passes saved at the end
+	schedule       []RenderPass        // Custom passes (nil = preview, SPP/4, rest of SPP)
 }
 
 func NewBucketRenderer(camera *Camera, world Hittable, bucketSize int, numWorkers int) *BucketRenderer {
@@ -59,6 +86,7 @@ func NewBucketRenderer(camera *Camera, world Hittable, bucketSize int, numWorker
 
 	return &BucketRenderer{
 		framebuffer:   framebuffer,
+		film:          NewFilm(camera.ImageWidth, camera.ImageHeight),
 		camera:        camera,
 		world:         world,
 		buckets:       buckets,
@@ -69,10 +97,103 @@ func NewBucketRenderer(camera *Camera, world Hittable, bucketSize int, numWorker
 		numWorkers:    numWorkers,
 		renderStarted: false,
 		currentPass:   0,
-		totalPasses:   3, // Preview (1 SPP) + Medium (SPP/4) + Final (full SPP)
+		overlay:       DefaultOverlayConfig(),
+		stats:         newBucketStats(camera.ImageWidth, camera.ImageHeight, bucketSize),
+		workerStats:   newWorkerStats(numWorkers),
+		autoExposure:  DefaultAutoExposureConfig(),
+		adaptiveLight: DefaultAdaptiveLightConfig(),
+		exposure:      1,
+		compare:       newComparison(camera.ImageWidth),
 	}
 }
 
+// WorkerStats returns per-pass, per-worker bucket counts and busy times
+// recorded so far
+func (r *BucketRenderer) WorkerStats() []PassWorkerStats {
+	return r.workerStats.snapshot()
+}
+
+// SetOverlay configures the viewer stats overlay
+func (r *BucketRenderer) SetOverlay(overlay OverlayConfig) *BucketRenderer {
+	r.overlay = overlay
+	return r
+}
+
+// SetHDROutput additionally saves the untonemapped linear render as a PFM
+// file at path when the render completes (empty disables it)
+func (r *BucketRenderer) SetHDROutput(path string) *BucketRenderer {
+	r.hdrOutput = path
+	return r
+}
+
+// SetSceneName sets the scene name recorded in the saved image metadata
+func (r *BucketRenderer) SetSceneName(name string) *BucketRenderer {
+	r.sceneName = name
+	return r
+}
+
+// SetPixelHook installs a callback that post-processes every pixel's HDR
+// value (with its AOV data) before it is written to the framebuffer
+func (r *BucketRenderer) SetPixelHook(hook PixelHook) *BucketRenderer {
+	r.pixelHook = hook
+	return r
+}
+
+// SetFireflyFilter enables outlier clamping of the finished image before it
+// is tonemapped for display and the PNG
+func (r *BucketRenderer) SetFireflyFilter(config FireflyFilterConfig) *BucketRenderer {
+	r.fireflyFilter = config
+	return r
+}
+
+// applyFireflyFilter redraws the framebuffer from the filtered film
+func (r *BucketRenderer) applyFireflyFilter() {
+	pixels := filteredFilm(r.film, r.fireflyFilter)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	writeFramebuffer(r.framebuffer, pixels, r.film, r.exposure, r.toneMap, r.pixelHook, r.camera, r.world)
+}
+
+// SetNaNCheck enables detection of NaN/Inf samples, which are then replaced
+// with config.DebugColor and logged instead of corrupting the film
+func (r *BucketRenderer) SetNaNCheck(config NaNCheckConfig) *BucketRenderer {
+	r.nanCheck = newNaNChecker(config)
+	return r
+}
+
+// SetHDRIPreviewWidth renders the preview and medium passes against a copy of
+// the environment map at most maxWidth pixels wide (0 disables). The medium
+// pass then only updates the display and the final pass traces the full SPP
+// at full resolution, so the saved image is unaffected.
+func (r *BucketRenderer) SetHDRIPreviewWidth(maxWidth int) *BucketRenderer {
+	r.previewWidth = maxWidth
+	r.fullEnv = r.camera.Environment
+	r.previewEnv = nil
+	if r.fullEnv != nil {
+		if small := r.fullEnv.Downsampled(maxWidth); small != r.fullEnv {
+			r.previewEnv = small
+		}
+	}
+	return r
+}
+
+const (
+	minBucketSize      = 8
+	maxBucketSize      = 128
+	bucketsPerWorker   = 6 // Aim for 4-8 buckets per worker per pass
+	bucketSizeRounding = 4
+)
+
+// AutoBucketSize picks a bucket size that gives every worker about
+// bucketsPerWorker buckets per pass: large enough to amortize scheduling on
+// small renders, small enough that high core counts don't sit idle
+func AutoBucketSize(width, height, numWorkers int) int {
+	numWorkers = max(numWorkers, 1)
+	area := float64(width*height) / float64(numWorkers*bucketsPerWorker)
+	size := int(math.Sqrt(area)) / bucketSizeRounding * bucketSizeRounding
+	return min(max(size, minBucketSize), maxBucketSize)
+}
+
 // generateBuckets creates a grid of buckets in spiral order (V-Ray style)
 func generateBuckets(width, height, bucketSize int) []Bucket {
 	var buckets []Bucket
@@ -125,6 +246,17 @@ func generateBuckets(width, height, bucketSize int) []Bucket {
 }
 
 func (r *BucketRenderer) Update() error {
+	r.overlay.handleInput()
+	if r.dof != nil && r.overlay.Enabled && r.overlay.ShowDOF {
+		r.dof.handleInput()
+	}
+	r.compare.handleInput(r.overlay, r.camera.ImageWidth)
+	if inpututil.IsKeyJustPressed(r.overlay.KeepKey) {
+		r.mu.Lock()
+		r.compare.keepCurrent(r.framebuffer)
+		r.mu.Unlock()
+	}
+
 	if r.completed {
 		return nil
 	}
@@ -134,129 +266,228 @@ func (r *BucketRenderer) Update() error {
 	if !r.renderStarted {
 		r.renderStarted = true
 		r.mu.Unlock()
+		r.budget.start()
 		go r.renderMultiPass()
 	} else {
 		r.mu.Unlock()
 	}
 
 	// Check if current pass is complete and start next pass
-	if r.passComplete.Load() && r.currentPass < r.totalPasses {
+	if r.passComplete.Load() {
 		r.passComplete.Store(false)
 		r.completedCount.Store(0)
 		r.currentPass++
 
-		if r.currentPass < r.totalPasses {
+		if r.morePasses() {
 			go r.renderPass()
 		} else {
-			// All passes done - currentPass is now equal to totalPasses
-			r.completed = true
-			r.renderEnd = time.Now()
-			r.drawStatsToFramebuffer()
+			// All passes done - currentPass is now equal to passCount()
+			r.finish()
 			_ = r.SaveImage("image.png")
+			if r.hdrOutput != "" {
+				_ = r.SaveHDR(r.hdrOutput)
+			}
+			r.saveLightAOVs()
+			r.saveAOVs()
+			r.saveCompositePasses()
 
 			// Print render stats
 			renderDuration := r.renderEnd.Sub(r.renderStart)
 			PrintRenderStats(renderDuration, r.camera.ImageWidth, r.camera.ImageHeight)
+			r.workerStats.report()
+			r.budget.report()
+			r.nanCheck.report()
 		}
 	}
 
 	return nil
 }
 
+// finish marks the render complete once the last pass is done: it filters
+// fireflies, stops the workers and records the SPP a time budget reached
+func (r *BucketRenderer) finish() {
+	r.completed = true
+	r.renderEnd = time.Now()
+	if r.fireflyFilter.Enabled {
+		r.applyFireflyFilter()
+	}
+	r.Close()
+	if r.budget != nil {
+		r.camera.SamplesPerPixel = int(r.budget.samples.Load())
+	}
+}
+
+// morePasses reports whether another pass should start after the current one
+func (r *BucketRenderer) morePasses() bool {
+	if r.budget != nil {
+		return !r.budget.expired()
+	}
+	return r.currentPass < r.passCount()
+}
+
 func (r *BucketRenderer) renderMultiPass() {
 	r.renderPass()
 }
 
+// passSettings returns the samples, depth and film accumulation for a pass
+// of the pass schedule (see SetPassSchedule) or time budget
+func (r *BucketRenderer) passSettings(pass int) (samples int, depth int, accumulate bool) {
+	if r.budget != nil {
+		return r.budget.passSettings(pass, r.camera.MaxDepth)
+	}
+	schedule := r.passSchedule()
+	if pass >= len(schedule) {
+		return r.camera.SamplesPerPixel, r.camera.MaxDepth, false
+	}
+	settings := schedule[pass]
+	depth = settings.MaxDepth
+	if depth <= 0 {
+		depth = r.camera.MaxDepth
+	}
+	return settings.Samples, depth, !settings.Preview
+}
+
 func (r *BucketRenderer) renderPass() {
+	// Swap in assets loaded in the background; the film only ever sees the
+	// real ones. A new HDRI can change the pass plan.
+	_, _, accumulate := r.passSettings(r.currentPass)
+	r.installAssets(accumulate)
+
 	// Determine samples for this pass
-	var samplesForPass int
-	var depthForPass int
-
-	switch r.currentPass {
-	case 0:
-		// Preview pass: 1 SPP, reduced depth
-		samplesForPass = 1
-		depthForPass = 3
-	case 1:
-		// Medium pass: 25% of target SPP
-		samplesForPass = max(1, r.camera.SamplesPerPixel/4)
-		depthForPass = max(3, r.camera.MaxDepth/2)
-	case 2:
-		// Final pass: Full quality
-		samplesForPass = r.camera.SamplesPerPixel
-		depthForPass = r.camera.MaxDepth
-	default:
-		samplesForPass = r.camera.SamplesPerPixel
-		depthForPass = r.camera.MaxDepth
+	samplesForPass, depthForPass, accumulate := r.passSettings(r.currentPass)
+
+	// The previous pass becomes the A/B reference before this one draws over it
+	if r.currentPass > 0 {
+		r.mu.Lock()
+		r.compare.keepPass(r.framebuffer, r.currentPass-1)
+		r.mu.Unlock()
 	}
 
-	// Use buffered channel for better performance
-	bucketChan := make(chan Bucket, r.numWorkers*2)
+	// Refine the environment importance map after the preview pass
+	r.adaptEnvironment(r.currentPass)
+
+	// Switch environment resolution between passes, never while tracing;
+	// only display-only passes may use the preview map
+	if r.previewEnv != nil {
+		env := r.fullEnv
+		if !accumulate {
+			env = r.previewEnv
+		}
+		r.camera.setEnvironment(env)
+	}
 
-	// Start worker goroutines
-	var wg sync.WaitGroup
-	for i := 0; i < r.numWorkers; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			r.workerMultiPass(bucketChan, samplesForPass, depthForPass)
-		}(i)
+	pass := r.workerStats.beginPass()
+	passStart := time.Now()
+	renderPass := r.currentPass
+	var skipped atomic.Bool
+	if renderPass == 0 && r.autoExposure.Mode != AutoExposureOff {
+		// A depth-limited preview is too dark to meter from
+		r.meter = newExposureMeter(r.camera.ImageWidth, r.camera.ImageHeight)
+		depthForPass = r.camera.MaxDepth
 	}
 
-	// Feed buckets into channel (in spiral order)
-	for _, bucket := range r.buckets {
-		bucketChan <- bucket
+	// Queue buckets in spiral order on the persistent workers
+	tasks := make([]poolTask, len(r.buckets))
+	for i, bucket := range r.buckets {
+		tasks[i] = func(workerID int) {
+			if r.budget.skipBucket(renderPass) {
+				skipped.Store(true)
+				r.completedCount.Add(1)
+				return
+			}
+			stats := r.renderPassBucket(bucket, samplesForPass, depthForPass, accumulate, r.envCache(i, accumulate), r.neeBucket(i))
+			r.workerStats.record(pass, workerID, stats)
+		}
+	}
+	r.workers().runAll(tasks)
+	if r.meter != nil {
+		r.meterExposure()
+	}
+	r.reportEnvCache(accumulate)
+	if renderPass == 0 {
+		r.decideLightSamples()
 	}
-	close(bucketChan)
 
-	wg.Wait()
+	r.workerStats.endPass(pass, time.Since(passStart))
+	r.budget.passDone(renderPass, samplesForPass, time.Since(passStart), !skipped.Load())
 	r.passComplete.Store(true)
 }
 
 func (r *BucketRenderer) renderParallel() {
-	// Use buffered channel for better performance
-	bucketChan := make(chan Bucket, r.numWorkers*2)
-
-	// Start worker goroutines first
-	var wg sync.WaitGroup
-	for i := 0; i < r.numWorkers; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			r.worker(bucketChan, workerID)
-		}(i)
+	tasks := make([]poolTask, len(r.buckets))
+	for i, bucket := range r.buckets {
+		tasks[i] = func(int) {
+			r.renderBucket(bucket)
+			r.completedCount.Add(1)
+		}
 	}
+	r.workers().runAll(tasks)
+}
 
-	// Feed buckets into channel (in spiral order)
-	for _, bucket := range r.buckets {
-		bucketChan <- bucket
+// workers returns the renderer's worker pool, starting it on first use
+func (r *BucketRenderer) workers() *workerPool {
+	r.poolMu.Lock()
+	defer r.poolMu.Unlock()
+	if r.pool == nil {
+		r.pool = newWorkerPool(r.numWorkers)
 	}
-	close(bucketChan)
+	return r.pool
+}
 
-	wg.Wait()
+// Pause stops the render workers from starting new buckets; buckets in
+// flight still finish
+func (r *BucketRenderer) Pause() {
+	r.workers().pause()
 }
 
-func (r *BucketRenderer) worker(buckets <-chan Bucket, workerID int) {
-	for bucket := range buckets {
-		r.renderBucket(bucket)
-		r.completedCount.Add(1)
+// Resume continues a paused render
+func (r *BucketRenderer) Resume() {
+	r.workers().resume()
+}
+
+// Close stops the render workers. The renderer calls it itself once the
+// final pass is done; a later render starts a new pool.
+func (r *BucketRenderer) Close() {
+	r.poolMu.Lock()
+	pool := r.pool
+	r.pool = nil
+	r.poolMu.Unlock()
+	if pool != nil {
+		pool.close()
 	}
 }
 
-func (r *BucketRenderer) workerMultiPass(buckets <-chan Bucket, samplesPerPixel int, maxDepth int) {
-	for bucket := range buckets {
-		r.renderBucketWithQuality(bucket, samplesPerPixel, maxDepth)
-		r.completedCount.Add(1)
+// renderPassBucket renders one bucket of a pass and returns the work done
+func (r *BucketRenderer) renderPassBucket(bucket Bucket, samplesPerPixel int, maxDepth int, accumulate bool, envCache *envVisibilityCache, nee *neeBucket) WorkerStats {
+	start := time.Now()
+	r.renderBucketWithQuality(bucket, samplesPerPixel, maxDepth, accumulate, envCache, nee)
+	r.completedCount.Add(1)
+	return WorkerStats{
+		Buckets: 1,
+		Busy:    time.Since(start),
+		Samples: int64(bucket.Width * bucket.Height * samplesPerPixel),
 	}
 }
 
 func (r *BucketRenderer) renderBucket(bucket Bucket) {
-	r.renderBucketWithQuality(bucket, r.camera.SamplesPerPixel, r.camera.MaxDepth)
+	r.renderBucketWithQuality(bucket, r.camera.SamplesPerPixel, r.camera.MaxDepth, false, nil, nil)
 }
 
-func (r *BucketRenderer) renderBucketWithQuality(bucket Bucket, samplesPerPixel int, maxDepth int) {
+// renderBucketWithQuality traces samplesPerPixel samples for every pixel of
+// the bucket. With accumulate set the samples are added to the film and the
+// displayed value is the film's running mean. envCache and nee (may be nil)
+// are the bucket's environment visibility cache and light sampling state.
+func (r *BucketRenderer) renderBucketWithQuality(bucket Bucket, samplesPerPixel int, maxDepth int, accumulate bool, envCache *envVisibilityCache, nee *neeBucket) {
 	// Create temporary buffer for this bucket
 	bucketBuffer := make([]color.RGBA, bucket.Width*bucket.Height)
+	bucketStart := time.Now()
+	pass := r.currentPass
+	relVarianceSum := 0.0
+	tally := r.lightTally(accumulate)
+	var tallySums lightTally
+	if tally != nil {
+		tallySums = newLightTally(len(r.camera.Lights))
+	}
 
 	for localY := 0; localY < bucket.Height; localY++ {
 		for localX := 0; localX < bucket.Width; localX++ {
@@ -264,30 +495,57 @@ func (r *BucketRenderer) renderBucketWithQuality(bucket Bucket, samplesPerPixel
 			globalY := bucket.Y + localY
 
 			pixelColor := Color{X: 0, Y: 0, Z: 0}
+			lumSum, lumSqSum := 0.0, 0.0
+			tallySums.reset()
 
 			// Sample the pixel
 			for sample := 0; sample < samplesPerPixel; sample++ {
-				ray := r.camera.GetRay(globalX, globalY)
-				pixelColor = pixelColor.Add(r.camera.RayColor(ray, maxDepth, r.world))
+				ray := r.camera.getRay(globalX, globalY, pixelSampler(globalX, globalY, pass, sample))
+				radiance := r.camera.rayColorCached(ray, maxDepth, r.world, envCache, nee, tally)
+				if tally != nil && isFiniteColor(radiance) {
+					for i, c := range tally {
+						tallySums[i] = tallySums[i].Add(c)
+					}
+				}
+				sampleColor := r.nanCheck.check(radiance, r.world, ray, globalX, globalY, sample)
+				pixelColor = pixelColor.Add(sampleColor)
+				lum := Luminance(sampleColor)
+				lumSum += lum
+				lumSqSum += lum * lum
 				GlobalRenderStats.SamplesComputed.Add(1)
 			}
 
-			// Average and gamma correct
-			scale := 1.0 / float64(samplesPerPixel)
-			pixelColor = pixelColor.Scale(scale)
+			if samplesPerPixel > 0 {
+				mean := lumSum / float64(samplesPerPixel)
+				variance := math.Max(0, lumSqSum/float64(samplesPerPixel)-mean*mean)
+				relVarianceSum += variance / (mean*mean + 1e-4)
+			}
 
-			intensity := NewInterval(0.0, 0.999)
-			bucketBuffer[localY*bucket.Width+localX] = color.RGBA{
-				R: uint8(256 * intensity.Clamp(LinearToGamma(pixelColor.X))),
-				G: uint8(256 * intensity.Clamp(LinearToGamma(pixelColor.Y))),
-				B: uint8(256 * intensity.Clamp(LinearToGamma(pixelColor.Z))),
-				A: 255,
+			// Average (over all accumulated passes) and gamma correct
+			samples := samplesPerPixel
+			if accumulate {
+				r.film.AddSamples(globalX, globalY, pixelColor, samplesPerPixel)
+				if tally != nil {
+					r.lightAOVs.addSamples(globalX, globalY, tallySums, samplesPerPixel)
+				}
+				pixelColor = r.film.Resolve(globalX, globalY)
+				samples = r.film.SampleCount(globalX, globalY)
+			} else {
+				pixelColor = pixelColor.Scale(1.0 / float64(samplesPerPixel))
 			}
+			r.meter.record(globalX, globalY, pixelColor)
+			pixelColor = pixelColor.Scale(r.exposure)
+			pixelColor = applyPixelHook(r.pixelHook, r.camera, r.world, globalX, globalY, samples, pixelColor)
+
+			bucketBuffer[localY*bucket.Width+localX] = LinearToRGBA(r.toneMap.Apply(pixelColor))
 
 			GlobalRenderStats.PixelsRendered.Add(1)
 		}
 	}
 
+	numPixels := bucket.Width * bucket.Height
+	r.stats.record(bucket, numPixels*samplesPerPixel, time.Since(bucketStart), relVarianceSum/float64(numPixels))
+
 	// Write bucket to framebuffer (synchronized)
 	r.mu.Lock()
 	for localY := 0; localY < bucket.Height; localY++ {
@@ -302,10 +560,17 @@ func (r *BucketRenderer) renderBucketWithQuality(bucket Bucket, samplesPerPixel
 
 func (r *BucketRenderer) Draw(screen *ebiten.Image) {
 	r.mu.Lock()
-	screen.WritePixels(r.framebuffer.Pix)
+	if r.compare.active() {
+		screen.WritePixels(r.compare.image(r.framebuffer).Pix)
+	} else {
+		screen.WritePixels(r.framebuffer.Pix)
+	}
 	r.mu.Unlock()
+	if r.compare.active() {
+		r.compare.drawDivider(screen)
+	}
 
-	// Draw render settings
+	// Draw the stats overlay (window only, never saved)
 	r.drawRenderSettings(screen)
 }
 
@@ -324,94 +589,97 @@ func (r *BucketRenderer) drawRenderSettings(screen *ebiten.Image) {
 		elapsed = time.Since(r.renderStart)
 	}
 
-	// Draw black bar at bottom
-	barHeight := 30
-	barY := r.camera.ImageHeight - barHeight
-	bgColor := color.RGBA{R: 0, G: 0, B: 0, A: 255}
-
-	r.mu.Lock()
-	for py := barY; py < r.camera.ImageHeight; py++ {
-		for px := 0; px < r.camera.ImageWidth; px++ {
-			r.framebuffer.Set(px, py, bgColor)
-		}
-	}
-	r.mu.Unlock()
-
-	textY := barY + 10
-	spacing := 15
-
 	var status string
-	var passName string
-
-	switch r.currentPass {
-	case 0:
-		passName = "PREVIEW"
-	case 1:
-		passName = "REFINING"
-	case 2:
-		passName = "FINAL"
-	default:
-		passName = "RENDERING"
-	}
+	passName := r.passName(r.currentPass)
 
 	if r.completed {
 		status = "COMPLETED"
 	} else {
 		status = fmt.Sprintf("%s | Buckets: %d/%d", passName, completedBuckets, r.totalBuckets)
-		// Workers display commented out
-		// status = fmt.Sprintf("%s | Buckets: %d/%d | Workers: %d", passName, completedBuckets, r.totalBuckets, r.numWorkers)
 	}
 
-	statsText := fmt.Sprintf("%dx%d | SPP:%d | Depth:%d | Pass:%d/%d | %.1f%% | %s | %s",
-		r.camera.ImageWidth,
-		r.camera.ImageHeight,
-		r.camera.SamplesPerPixel,
-		r.camera.MaxDepth,
-		min(r.currentPass+1, r.totalPasses), // Cap at totalPasses when completed
-		r.totalPasses,
-		progress,
-		FormatDuration(elapsed),
-		status,
-	)
+	lines := []string{
+		fmt.Sprintf("%dx%d | SPP:%d | Depth:%d | Pass:%d/%d | %.1f%% | %s",
+			r.camera.ImageWidth,
+			r.camera.ImageHeight,
+			r.camera.SamplesPerPixel,
+			r.camera.MaxDepth,
+			min(r.currentPass+1, r.passCount()), // Cap at the pass count when completed
+			r.passCount(),
+			progress,
+			FormatDuration(elapsed),
+		),
+		fmt.Sprintf("%s | Workers: %d", status, r.numWorkers),
+	}
+	if r.budget != nil {
+		// Passes repeat until the deadline, so show pass number and budget
+		lines[0] = fmt.Sprintf("%dx%d | Depth:%d | Pass:%d | %.1f%% | %s",
+			r.camera.ImageWidth,
+			r.camera.ImageHeight,
+			r.camera.MaxDepth,
+			min(r.currentPass+1, int(r.budget.passes.Load())+1),
+			progress,
+			FormatDuration(elapsed),
+		)
+		lines = append(lines, r.budget.status())
+	}
+	if r.overlay.ShowRayStats {
+		lines = append(lines, rayStatsLines(elapsed.Seconds())...)
+	}
+	if r.overlay.Enabled && r.overlay.BucketStats != BucketStatsOff {
+		lines = append(lines, r.stats.draw(screen, r.overlay.BucketStats))
+	}
+	if r.compare.mode != CompareOff {
+		lines = append(lines, r.compare.line())
+	}
+	if r.overlay.Enabled && r.overlay.ShowDOF {
+		if r.dof == nil {
+			r.dof = newDOFPreview(r.camera, r.world, r.overlay.DOFCoC)
+		}
+		r.dof.draw(screen)
+		lines = append(lines, r.dof.lines()...)
+	}
 
-	ebitenutil.DebugPrintAt(screen, statsText, spacing, textY)
+	r.overlay.draw(screen, lines)
 }
 
-func (r *BucketRenderer) drawStatsToFramebuffer() {
-	elapsed := r.renderEnd.Sub(r.renderStart)
+func (r *BucketRenderer) Layout(w, h int) (int, int) {
+	return r.camera.ImageWidth, r.camera.ImageHeight
+}
 
-	barHeight := 30
-	barY := r.camera.ImageHeight - barHeight
-	bgColor := color.RGBA{R: 0, G: 0, B: 0, A: 255}
+// RenderToImage runs every pass on the calling goroutine and returns the
+// finished image, without a window or writing files. A transparent
+// background comes back premultiplied.
+func (r *BucketRenderer) RenderToImage() (*image.RGBA, error) {
+	r.mu.Lock()
+	if r.renderStarted {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("render: already started")
+	}
+	r.renderStarted = true
+	r.mu.Unlock()
 
-	for py := barY; py < r.camera.ImageHeight; py++ {
-		for px := 0; px < r.camera.ImageWidth; px++ {
-			r.framebuffer.Set(px, py, bgColor)
+	r.budget.start()
+	for {
+		r.renderPass()
+		r.passComplete.Store(false)
+		r.completedCount.Store(0)
+		r.currentPass++
+		if !r.morePasses() {
+			break
 		}
 	}
+	r.finish()
 
-	textColor := color.RGBA{R: 255, G: 255, B: 255, A: 255}
-	face := text.NewGoXFace(basicfont.Face7x13)
-
-	statsText := fmt.Sprintf("%dx%d | SPP:%d | Depth:%d | 100.0%% | %s | Workers: %d",
-		r.camera.ImageWidth,
-		r.camera.ImageHeight,
-		r.camera.SamplesPerPixel,
-		r.camera.MaxDepth,
-		FormatDuration(elapsed),
-		r.numWorkers,
-	)
-
-	tempImg := ebiten.NewImageFromImage(r.framebuffer)
-	opts := &text.DrawOptions{}
-	opts.GeoM.Translate(15, float64(barY+10)) // Position text in the bar (13px font height needs room)
-	opts.ColorScale.ScaleWithColor(textColor)
-	text.Draw(tempImg, statsText, face, opts)
-	tempImg.ReadPixels(r.framebuffer.Pix)
-}
-
-func (r *BucketRenderer) Layout(w, h int) (int, int) {
-	return r.camera.ImageWidth, r.camera.ImageHeight
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.camera.TransparentBackground {
+		img := backgroundAlphaImage(r.framebuffer, r.exposure, r.toneMap, r.camera, r.world)
+		out := image.NewRGBA(img.Bounds())
+		draw.Draw(out, out.Bounds(), img, image.Point{}, draw.Src)
+		return out, nil
+	}
+	return cloneRGBA(r.framebuffer, nil), nil
 }
 
 func (r *BucketRenderer) SaveImage(filename string) error {
@@ -429,7 +697,13 @@ func (r *BucketRenderer) SaveImage(filename string) error {
 		}
 	}(file)
 
-	if err := png.Encode(file, r.framebuffer); err != nil {
+	var img image.Image = r.framebuffer
+	if r.camera.TransparentBackground {
+		img = backgroundAlphaImage(r.framebuffer, r.exposure, r.toneMap, r.camera, r.world)
+	}
+
+	meta := NewRenderMetadata(r.camera, r.sceneName, r.GetRenderDuration())
+	if err := EncodePNGWithMetadata(file, img, meta); err != nil {
 		return fmt.Errorf("error encoding PNG: %w", err)
 	}
 
@@ -437,6 +711,16 @@ func (r *BucketRenderer) SaveImage(filename string) error {
 	return nil
 }
 
+// SaveHDR writes the raw linear render to a PFM file
+func (r *BucketRenderer) SaveHDR(filename string) error {
+	if err := r.film.SavePFM(filename); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ HDR image saved to %s\n", filename)
+	return nil
+}
+
 func (r *BucketRenderer) IsCompleted() bool {
 	return r.completed
 }
@@ -0,0 +1,150 @@
+package rt
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// =============================================================================
+// PER-BUCKET SAMPLING STATISTICS
+// =============================================================================
+
+// BucketStatsMode selects what the bucket heatmap overlay shows
+type BucketStatsMode int
+
+const (
+	BucketStatsOff      BucketStatsMode = iota
+	BucketStatsSpeed                    // Samples/sec, slow buckets red
+	BucketStatsVariance                 // Relative sample variance, noisy buckets red
+)
+
+var bucketStatsModeNames = []string{"off", "speed", "variance"}
+
+func (m BucketStatsMode) String() string {
+	if int(m) < 0 || int(m) >= len(bucketStatsModeNames) {
+		return "unknown"
+	}
+	return bucketStatsModeNames[m]
+}
+
+// ParseBucketStatsMode converts a mode name (e.g. "speed") to a BucketStatsMode
+func ParseBucketStatsMode(name string) (BucketStatsMode, error) {
+	for i, n := range bucketStatsModeNames {
+		if strings.EqualFold(name, n) {
+			return BucketStatsMode(i), nil
+		}
+	}
+	return BucketStatsOff, fmt.Errorf("unknown bucket stats mode: %s (use %s)",
+		name, strings.Join(bucketStatsModeNames, ", "))
+}
+
+const bucketStatsAlpha = 90
+
+type bucketStat struct {
+	rendered      bool
+	samplesPerSec float64
+	variance      float64 // Mean per-pixel luminance variance relative to mean²
+}
+
+// bucketStats keeps the most recent statistics of every bucket; later passes
+// overwrite earlier ones as they reach each bucket
+type bucketStats struct {
+	mu         sync.Mutex
+	bucketSize int
+	cols       int
+	stats      []bucketStat
+}
+
+func newBucketStats(width, height, bucketSize int) *bucketStats {
+	cols := (width + bucketSize - 1) / bucketSize
+	rows := (height + bucketSize - 1) / bucketSize
+	return &bucketStats{
+		bucketSize: bucketSize,
+		cols:       cols,
+		stats:      make([]bucketStat, cols*rows),
+	}
+}
+
+// record stores the statistics of a finished bucket
+func (s *bucketStats) record(bucket Bucket, samples int, elapsed time.Duration, variance float64) {
+	sps := float64(samples) / math.Max(elapsed.Seconds(), 1e-9)
+	idx := (bucket.Y/s.bucketSize)*s.cols + bucket.X/s.bucketSize
+
+	s.mu.Lock()
+	s.stats[idx] = bucketStat{rendered: true, samplesPerSec: sps, variance: variance}
+	s.mu.Unlock()
+}
+
+// value returns the statistic shown for mode, oriented so larger means worse
+func (st bucketStat) value(mode BucketStatsMode) float64 {
+	if mode == BucketStatsSpeed {
+		return -math.Log(math.Max(st.samplesPerSec, 1e-9))
+	}
+	return math.Log(st.variance + 1e-6)
+}
+
+// draw tints every rendered bucket from green (fast / clean) to red (slow /
+// noisy), normalized over the rendered buckets, and returns a legend line
+func (s *bucketStats) draw(screen *ebiten.Image, mode BucketStatsMode) string {
+	if mode == BucketStatsOff {
+		return ""
+	}
+
+	s.mu.Lock()
+	stats := append([]bucketStat(nil), s.stats...)
+	s.mu.Unlock()
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	minSPS, maxSPS := math.Inf(1), 0.0
+	minVar, maxVar := math.Inf(1), 0.0
+	for _, st := range stats {
+		if !st.rendered {
+			continue
+		}
+		v := st.value(mode)
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+		minSPS, maxSPS = math.Min(minSPS, st.samplesPerSec), math.Max(maxSPS, st.samplesPerSec)
+		minVar, maxVar = math.Min(minVar, st.variance), math.Max(maxVar, st.variance)
+	}
+	if math.IsInf(lo, 1) {
+		return fmt.Sprintf("Buckets: %s (waiting)", mode)
+	}
+
+	for idx, st := range stats {
+		if !st.rendered {
+			continue
+		}
+		t := 0.5
+		if hi > lo {
+			t = (st.value(mode) - lo) / (hi - lo)
+		}
+		x := (idx % s.cols) * s.bucketSize
+		y := (idx / s.cols) * s.bucketSize
+		vector.FillRect(screen, float32(x), float32(y), float32(s.bucketSize), float32(s.bucketSize), heatColor(t), false)
+	}
+
+	if mode == BucketStatsSpeed {
+		return fmt.Sprintf("Buckets: samples/s %.0fk (red) - %.0fk (green)", minSPS/1000, maxSPS/1000)
+	}
+	return fmt.Sprintf("Buckets: rel. variance %.3f (green) - %.3f (red)", minVar, maxVar)
+}
+
+// heatColor maps t in [0, 1] to a translucent green-yellow-red ramp
+// (premultiplied alpha)
+func heatColor(t float64) color.RGBA {
+	t = clampFloat(t, 0, 1)
+	scale := float64(bucketStatsAlpha)
+	return color.RGBA{
+		R: uint8(scale * math.Min(1, 2*t)),
+		G: uint8(scale * math.Min(1, 2*(1-t))),
+		B: 0,
+		A: bucketStatsAlpha,
+	}
+}
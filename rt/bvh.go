@@ -1,8 +1,12 @@
 package rt
 
 import (
+	"fmt"
+	"math"
+	"math/bits"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 )
 
@@ -20,10 +24,15 @@ type BVHNode struct {
 // This reduces tree depth and improves cache locality
 type BVHLeaf struct {
 	objects []Hittable
+	kernel  leafKernel // Batched intersection for all-triangle/all-sphere leaves (nil otherwise)
 	bbox    AABB
 }
 
 func (l *BVHLeaf) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	if l.kernel != nil {
+		return l.kernel.hit(r, rayT, rec)
+	}
+
 	hitAnything := false
 	closest := rayT.Max
 
@@ -45,14 +54,88 @@ type bvhPrimitive struct {
 	index    int
 	bbox     AABB
 	centroid Vec3
+	morton   uint32 // Morton code of the centroid (LBVH builder only)
 }
 
-// Tuning constants
+// =============================================================================
+// BUILD OPTIONS
+// =============================================================================
+
+// BVHBuilderType selects how primitives are split into child nodes
+type BVHBuilderType int
+
 const (
-	bvhParallelThreshold = 8192 // Min objects to spawn goroutines
-	bvhLeafMaxSize       = 4    // Max primitives per leaf node
+	BVHMedian BVHBuilderType = iota // Centroid median on the longest axis (fast build)
+	BVHSAH                          // Binned surface area heuristic (best traversal)
+	BVHLBVH                         // Morton-code linear BVH (fastest build for huge meshes)
 )
 
+var bvhBuilderNames = []string{"median", "sah", "lbvh"}
+
+func (t BVHBuilderType) String() string {
+	if int(t) < 0 || int(t) >= len(bvhBuilderNames) {
+		return "unknown"
+	}
+	return bvhBuilderNames[t]
+}
+
+// ParseBVHBuilder converts a builder name (median, sah, lbvh) to a BVHBuilderType
+func ParseBVHBuilder(name string) (BVHBuilderType, error) {
+	for i, n := range bvhBuilderNames {
+		if strings.EqualFold(name, n) {
+			return BVHBuilderType(i), nil
+		}
+	}
+	return BVHMedian, fmt.Errorf("unknown BVH builder: %s (use %s)",
+		name, strings.Join(bvhBuilderNames, ", "))
+}
+
+// BVHOptions controls BVH construction. Sphere fields favour small leaves and
+// the median split; huge meshes build much faster with LBVH and benefit from
+// SAH when traversal time dominates.
+type BVHOptions struct {
+	LeafMaxSize       int            // Max primitives per leaf node
+	ParallelThreshold int            // Min primitives before work is split across goroutines
+	Builder           BVHBuilderType // Split strategy
+	SAHBuckets        int            // Bins per axis for the SAH builder
+	Quantized         bool           // Store mesh BVHs as QuantizedBVH (8-bit child bounds)
+}
+
+// DefaultBVHOptions returns the options used by NewBVHNode
+func DefaultBVHOptions() BVHOptions {
+	return BVHOptions{
+		LeafMaxSize:       4,
+		ParallelThreshold: 8192,
+		Builder:           BVHMedian,
+		SAHBuckets:        12,
+	}
+}
+
+// defaultBVHOptions is used by NewBVHNode and NewBVHNodeFromList, including
+// the BVHs built for OBJ meshes
+var defaultBVHOptions = DefaultBVHOptions()
+
+// SetDefaultBVHOptions changes the options used by NewBVHNode and
+// NewBVHNodeFromList (e.g. from command-line flags)
+func SetDefaultBVHOptions(opts BVHOptions) {
+	defaultBVHOptions = opts
+}
+
+// normalized replaces out-of-range values with their defaults
+func (opts BVHOptions) normalized() BVHOptions {
+	defaults := DefaultBVHOptions()
+	if opts.LeafMaxSize < 1 {
+		opts.LeafMaxSize = defaults.LeafMaxSize
+	}
+	if opts.ParallelThreshold < 2 {
+		opts.ParallelThreshold = defaults.ParallelThreshold
+	}
+	if opts.SAHBuckets < 2 {
+		opts.SAHBuckets = defaults.SAHBuckets
+	}
+	return opts
+}
+
 // bvhSemaphore limits concurrent goroutines during BVH construction
 var bvhSemaphore chan struct{}
 var bvhSemaphoreOnce sync.Once
@@ -62,12 +145,23 @@ func initBVHSemaphore() {
 }
 
 func NewBVHNodeFromList(list *HittableList) *BVHNode {
+	return NewBVHNodeFromListWithOptions(list, defaultBVHOptions)
+}
+
+// NewBVHNodeFromListWithOptions builds a BVH over the list with explicit options
+func NewBVHNodeFromListWithOptions(list *HittableList, opts BVHOptions) *BVHNode {
 	objects := list.Objects
-	return NewBVHNode(objects, 0, len(objects))
+	return NewBVHNodeWithOptions(objects, 0, len(objects), opts)
 }
 
 func NewBVHNode(objects []Hittable, start, end int) *BVHNode {
+	return NewBVHNodeWithOptions(objects, start, end, defaultBVHOptions)
+}
+
+// NewBVHNodeWithOptions builds a BVH over objects[start:end] with explicit options
+func NewBVHNodeWithOptions(objects []Hittable, start, end int, opts BVHOptions) *BVHNode {
 	bvhSemaphoreOnce.Do(initBVHSemaphore)
+	opts = opts.normalized()
 
 	n := end - start
 	if n == 0 {
@@ -78,7 +172,7 @@ func NewBVHNode(objects []Hittable, start, end int) *BVHNode {
 	primitives := make([]bvhPrimitive, n)
 
 	// Parallel bbox computation for large sets
-	if n > 10000 {
+	if n >= opts.ParallelThreshold {
 		numWorkers := runtime.NumCPU()
 		chunkSize := (n + numWorkers - 1) / numWorkers
 		var wg sync.WaitGroup
@@ -114,10 +208,25 @@ func NewBVHNode(objects []Hittable, start, end int) *BVHNode {
 		}
 	}
 
-	return buildBVHNode(objects, primitives, runtime.NumCPU())
+	// LBVH sorts once along the Morton curve; splits then only look at codes
+	if opts.Builder == BVHLBVH {
+		assignMortonCodes(primitives)
+		sort.Slice(primitives, func(i, j int) bool {
+			return primitives[i].morton < primitives[j].morton
+		})
+	}
+
+	b := &bvhBuilder{objects: objects, opts: opts}
+	return b.build(primitives, runtime.NumCPU())
 }
 
-func buildBVHNode(objects []Hittable, primitives []bvhPrimitive, parallelDepth int) *BVHNode {
+// bvhBuilder carries the state shared by all recursive build steps
+type bvhBuilder struct {
+	objects []Hittable
+	opts    BVHOptions
+}
+
+func (b *bvhBuilder) build(primitives []bvhPrimitive, parallelDepth int) *BVHNode {
 	n := len(primitives)
 
 	// Compute bounds of all primitives
@@ -130,38 +239,24 @@ func buildBVHNode(objects []Hittable, primitives []bvhPrimitive, parallelDepth i
 	}
 
 	// Create leaf for small sets
-	if n <= bvhLeafMaxSize {
+	if n <= b.opts.LeafMaxSize {
 		leaf := &BVHLeaf{
 			objects: make([]Hittable, n),
 			bbox:    bounds,
 		}
 		for i, p := range primitives {
-			leaf.objects[i] = objects[p.index]
+			leaf.objects[i] = b.objects[p.index]
 		}
+		leaf.kernel = newLeafKernel(leaf.objects)
 		return &BVHNode{left: leaf, right: leaf, bbox: bounds}
 	}
 
-	// Choose split axis based on centroid spread
-	axis := centroidBounds.LongestAxis()
-
-	// Sort by centroid on chosen axis (faster than full bbox sort)
-	sort.Slice(primitives, func(i, j int) bool {
-		switch axis {
-		case 0:
-			return primitives[i].centroid.X < primitives[j].centroid.X
-		case 1:
-			return primitives[i].centroid.Y < primitives[j].centroid.Y
-		default:
-			return primitives[i].centroid.Z < primitives[j].centroid.Z
-		}
-	})
-
-	mid := n / 2
+	mid := b.split(primitives, centroidBounds)
 
 	node := &BVHNode{bbox: bounds}
 
 	// Parallel construction for large subtrees
-	if parallelDepth > 0 && n >= bvhParallelThreshold {
+	if parallelDepth > 0 && n >= b.opts.ParallelThreshold {
 		// Try to acquire semaphore slots BEFORE spawning goroutines
 		// This prevents deadlock - if we can't get slots, go sequential
 		gotLeft := false
@@ -187,13 +282,13 @@ func buildBVHNode(objects []Hittable, primitives []bvhPrimitive, parallelDepth i
 			go func() {
 				defer wg.Done()
 				defer func() { <-bvhSemaphore }()
-				node.left = buildBVHNode(objects, primitives[:mid], parallelDepth-1)
+				node.left = b.build(primitives[:mid], parallelDepth-1)
 			}()
 
 			go func() {
 				defer wg.Done()
 				defer func() { <-bvhSemaphore }()
-				node.right = buildBVHNode(objects, primitives[mid:], parallelDepth-1)
+				node.right = b.build(primitives[mid:], parallelDepth-1)
 			}()
 
 			wg.Wait()
@@ -205,17 +300,187 @@ func buildBVHNode(objects []Hittable, primitives []bvhPrimitive, parallelDepth i
 			if gotRight {
 				<-bvhSemaphore
 			}
-			node.left = buildBVHNode(objects, primitives[:mid], 0)
-			node.right = buildBVHNode(objects, primitives[mid:], 0)
+			node.left = b.build(primitives[:mid], 0)
+			node.right = b.build(primitives[mid:], 0)
 		}
 	} else {
-		node.left = buildBVHNode(objects, primitives[:mid], 0)
-		node.right = buildBVHNode(objects, primitives[mid:], 0)
+		node.left = b.build(primitives[:mid], 0)
+		node.right = b.build(primitives[mid:], 0)
 	}
 
 	return node
 }
 
+// split reorders primitives so [0, mid) and [mid, n) become the children.
+// mid is always in (0, n).
+func (b *bvhBuilder) split(primitives []bvhPrimitive, centroidBounds AABB) int {
+	switch b.opts.Builder {
+	case BVHSAH:
+		if mid, ok := splitSAH(primitives, centroidBounds, b.opts.SAHBuckets); ok {
+			return mid
+		}
+	case BVHLBVH:
+		if mid, ok := splitMorton(primitives); ok {
+			return mid
+		}
+		// Identical codes: primitives are already in curve order
+		return len(primitives) / 2
+	}
+	return splitMedian(primitives, centroidBounds)
+}
+
+// splitMedian sorts by centroid on the longest axis and splits in the middle
+func splitMedian(primitives []bvhPrimitive, centroidBounds AABB) int {
+	// Choose split axis based on centroid spread
+	axis := centroidBounds.LongestAxis()
+
+	// Sort by centroid on chosen axis (faster than full bbox sort)
+	sort.Slice(primitives, func(i, j int) bool {
+		return axisValue(primitives[i].centroid, axis) < axisValue(primitives[j].centroid, axis)
+	})
+
+	return len(primitives) / 2
+}
+
+// splitSAH picks the bucket boundary with the lowest surface area cost over
+// all three axes and partitions the primitives around it
+func splitSAH(primitives []bvhPrimitive, centroidBounds AABB, numBuckets int) (int, bool) {
+	type sahBucket struct {
+		count int
+		bbox  AABB
+	}
+
+	bestCost := math.Inf(1)
+	bestAxis, bestSplit := -1, 0
+	buckets := make([]sahBucket, numBuckets)
+	rightArea := make([]float64, numBuckets)
+	rightCount := make([]int, numBuckets)
+
+	for axis := 0; axis < 3; axis++ {
+		extent := centroidBounds.AxisInterval(axis)
+		if extent.Size() <= 0 {
+			continue
+		}
+
+		for i := range buckets {
+			buckets[i] = sahBucket{bbox: EmptyAABB}
+		}
+		for _, p := range primitives {
+			bi := sahBucketIndex(p.centroid, axis, extent, numBuckets)
+			buckets[bi].count++
+			buckets[bi].bbox = NewAABBFromBoxes(buckets[bi].bbox, p.bbox)
+		}
+
+		// Sweep right-to-left for the cost of everything right of each boundary
+		box, count := EmptyAABB, 0
+		for i := numBuckets - 1; i > 0; i-- {
+			box = NewAABBFromBoxes(box, buckets[i].bbox)
+			count += buckets[i].count
+			rightArea[i], rightCount[i] = surfaceArea(box), count
+		}
+
+		// Sweep left-to-right combining with the right side
+		box, count = EmptyAABB, 0
+		for i := 0; i < numBuckets-1; i++ {
+			box = NewAABBFromBoxes(box, buckets[i].bbox)
+			count += buckets[i].count
+			if count == 0 || rightCount[i+1] == 0 {
+				continue
+			}
+			cost := float64(count)*surfaceArea(box) + float64(rightCount[i+1])*rightArea[i+1]
+			if cost < bestCost {
+				bestCost, bestAxis, bestSplit = cost, axis, i
+			}
+		}
+	}
+
+	if bestAxis < 0 {
+		return 0, false
+	}
+
+	// Partition in place: buckets <= bestSplit go left
+	extent := centroidBounds.AxisInterval(bestAxis)
+	mid := 0
+	for i := range primitives {
+		if sahBucketIndex(primitives[i].centroid, bestAxis, extent, numBuckets) <= bestSplit {
+			primitives[i], primitives[mid] = primitives[mid], primitives[i]
+			mid++
+		}
+	}
+
+	return mid, mid > 0 && mid < len(primitives)
+}
+
+func sahBucketIndex(centroid Vec3, axis int, extent Interval, numBuckets int) int {
+	t := (axisValue(centroid, axis) - extent.Min) / extent.Size()
+	return min(int(t*float64(numBuckets)), numBuckets-1)
+}
+
+func surfaceArea(box AABB) float64 {
+	dx, dy, dz := box.X.Size(), box.Y.Size(), box.Z.Size()
+	if dx < 0 || dy < 0 || dz < 0 {
+		return 0 // Empty box
+	}
+	return 2 * (dx*dy + dy*dz + dz*dx)
+}
+
+// assignMortonCodes quantizes centroids to a 10-bit grid per axis and
+// interleaves the bits into 30-bit Morton codes
+func assignMortonCodes(primitives []bvhPrimitive) {
+	bounds := NewAABBFromPoints(primitives[0].centroid, primitives[0].centroid)
+	for _, p := range primitives[1:] {
+		bounds = NewAABBFromBoxes(bounds, NewAABBFromPoints(p.centroid, p.centroid))
+	}
+
+	quantize := func(v float64, axis Interval) uint32 {
+		t := (v - axis.Min) / axis.Size()
+		return uint32(clampFloat(t*1024, 0, 1023))
+	}
+
+	for i := range primitives {
+		c := primitives[i].centroid
+		primitives[i].morton = expandBits(quantize(c.X, bounds.X))<<2 |
+			expandBits(quantize(c.Y, bounds.Y))<<1 |
+			expandBits(quantize(c.Z, bounds.Z))
+	}
+}
+
+// expandBits spreads the low 10 bits of v so two zero bits separate each one
+func expandBits(v uint32) uint32 {
+	v = (v * 0x00010001) & 0xFF0000FF
+	v = (v * 0x00000101) & 0x0F00F00F
+	v = (v * 0x00000011) & 0xC30C30C3
+	v = (v * 0x00000005) & 0x49249249
+	return v
+}
+
+// splitMorton splits Morton-sorted primitives at the highest bit in which the
+// first and last codes differ
+func splitMorton(primitives []bvhPrimitive) (int, bool) {
+	first := primitives[0].morton
+	last := primitives[len(primitives)-1].morton
+	if first == last {
+		return 0, false
+	}
+
+	highBit := uint32(1) << (31 - bits.LeadingZeros32(first^last))
+	mid := sort.Search(len(primitives), func(i int) bool {
+		return primitives[i].morton&highBit != 0
+	})
+	return mid, true
+}
+
+func axisValue(v Vec3, axis int) float64 {
+	switch axis {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	default:
+		return v.Z
+	}
+}
+
 func (b *BVHNode) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 	GlobalRenderStats.BVHIntersections.Add(1)
 
@@ -0,0 +1,214 @@
+package rt
+
+import "slices"
+
+// =============================================================================
+// DYNAMIC BVH (INCREMENTAL INSERTION AND REMOVAL)
+// =============================================================================
+
+// dynamicBVHMinEdits is the number of edits always allowed before a rebuild,
+// so small scenes aren't rebuilt on every insert
+const dynamicBVHMinEdits = 16
+
+// DynamicBVH is a BVH that takes a few insertions and removals without a full
+// rebuild. Insert walks down to the leaf whose bounds grow least and refits
+// the boxes on the way; Remove drops the object from its leaf, collapses
+// empty nodes and shrinks the boxes above it. Each edit costs O(depth), but
+// the tree degrades as edits pile up, so once the edits since the last build
+// exceed RebuildFraction of the objects the whole tree is rebuilt.
+//
+// Like any scene change, edits must not overlap tracing: make them between
+// renders or passes.
+type DynamicBVH struct {
+	root            *BVHNode
+	objects         []Hittable
+	opts            BVHOptions
+	edits           int     // Inserts and removes since the last build
+	RebuildFraction float64 // Edits, as a fraction of the objects, that trigger a rebuild
+}
+
+// NewDynamicBVH builds an editable BVH over objects with opts
+func NewDynamicBVH(objects []Hittable, opts BVHOptions) *DynamicBVH {
+	opts = opts.normalized()
+	opts.Quantized = false
+	d := &DynamicBVH{
+		objects:         slices.Clone(objects),
+		opts:            opts,
+		RebuildFraction: 0.25,
+	}
+	d.Rebuild()
+	return d
+}
+
+// NewDynamicBVHFromList builds an editable BVH over the list with the default
+// BVH options
+func NewDynamicBVHFromList(list *HittableList) *DynamicBVH {
+	return NewDynamicBVH(list.Objects, defaultBVHOptions)
+}
+
+// SetRebuildFraction sets the share of edits that triggers a full rebuild
+// (0 rebuilds after every edit past the first few)
+func (d *DynamicBVH) SetRebuildFraction(fraction float64) *DynamicBVH {
+	d.RebuildFraction = max(0, fraction)
+	return d
+}
+
+// Rebuild builds the tree from scratch over the current objects
+func (d *DynamicBVH) Rebuild() {
+	d.root = NewBVHNodeWithOptions(d.objects, 0, len(d.objects), d.opts)
+	d.edits = 0
+}
+
+// Len returns the number of objects in the BVH
+func (d *DynamicBVH) Len() int {
+	return len(d.objects)
+}
+
+// Children returns the objects in the BVH, so scene walkers descend into it.
+// The slice must not be modified.
+func (d *DynamicBVH) Children() []Hittable {
+	return d.objects
+}
+
+// Insert adds obj to the BVH
+func (d *DynamicBVH) Insert(obj Hittable) {
+	d.objects = append(d.objects, obj)
+	if d.edited() {
+		return
+	}
+	box := obj.BoundingBox()
+	if d.root.left == nil {
+		d.root = newBVHLeafNode([]Hittable{obj})
+		return
+	}
+	d.root = d.insert(d.root, obj, box)
+}
+
+// Remove deletes obj from the BVH and reports whether it was there. Objects
+// are matched by identity, so pass the same pointer that was inserted.
+func (d *DynamicBVH) Remove(obj Hittable) bool {
+	i := slices.Index(d.objects, obj)
+	if i < 0 {
+		return false
+	}
+	d.objects = slices.Delete(d.objects, i, i+1)
+	if d.edited() {
+		return true
+	}
+	if d.root.left == nil {
+		return true
+	}
+	root, found := d.remove(d.root, obj, obj.BoundingBox())
+	if !found {
+		// obj moved since it was inserted, so its box no longer leads to it
+		d.Rebuild()
+		return true
+	}
+	if root == nil {
+		root = &BVHNode{}
+	}
+	d.root = root
+	return true
+}
+
+// edited counts an edit and rebuilds the tree when too many have piled up.
+// It reports whether it rebuilt, in which case the edit is already applied.
+func (d *DynamicBVH) edited() bool {
+	d.edits++
+	if d.edits > max(dynamicBVHMinEdits, int(d.RebuildFraction*float64(len(d.objects)))) {
+		d.Rebuild()
+		return true
+	}
+	return false
+}
+
+// insert adds obj below node and returns the node that replaces it
+func (d *DynamicBVH) insert(node *BVHNode, obj Hittable, box AABB) *BVHNode {
+	if leaf, ok := node.left.(*BVHLeaf); ok {
+		if len(leaf.objects) < d.opts.LeafMaxSize {
+			return newBVHLeafNode(append(slices.Clone(leaf.objects), obj))
+		}
+		// A full leaf becomes a sibling of the new object
+		return &BVHNode{left: node, right: newBVHLeafNode([]Hittable{obj}), bbox: NewAABBFromBoxes(node.bbox, box)}
+	}
+
+	left, right := node.left.(*BVHNode), node.right.(*BVHNode)
+	leftGrowth := surfaceArea(NewAABBFromBoxes(left.bbox, box)) - surfaceArea(left.bbox)
+	rightGrowth := surfaceArea(NewAABBFromBoxes(right.bbox, box)) - surfaceArea(right.bbox)
+	if leftGrowth <= rightGrowth {
+		node.left = d.insert(left, obj, box)
+	} else {
+		node.right = d.insert(right, obj, box)
+	}
+	node.bbox = NewAABBFromBoxes(node.bbox, box)
+	return node
+}
+
+// remove deletes obj (with bounds box) below node. It returns the node that
+// replaces it, nil when nothing is left, and whether obj was found.
+func (d *DynamicBVH) remove(node *BVHNode, obj Hittable, box AABB) (*BVHNode, bool) {
+	if !boxContains(node.bbox, box) {
+		return node, false
+	}
+	if leaf, ok := node.left.(*BVHLeaf); ok {
+		i := slices.Index(leaf.objects, obj)
+		if i < 0 {
+			return node, false
+		}
+		if len(leaf.objects) == 1 {
+			return nil, true
+		}
+		return newBVHLeafNode(slices.Delete(slices.Clone(leaf.objects), i, i+1)), true
+	}
+
+	left, right := node.left.(*BVHNode), node.right.(*BVHNode)
+	newLeft, found := d.remove(left, obj, box)
+	newRight := right
+	if !found {
+		newRight, found = d.remove(right, obj, box)
+	}
+	switch {
+	case !found:
+		return node, false
+	case newLeft == nil:
+		return newRight, true
+	case newRight == nil:
+		return newLeft, true
+	}
+	node.left, node.right = newLeft, newRight
+	node.bbox = NewAABBFromBoxes(newLeft.bbox, newRight.bbox)
+	return node, true
+}
+
+// newBVHLeafNode wraps objects in a leaf the way the builders do
+func newBVHLeafNode(objects []Hittable) *BVHNode {
+	bounds := objects[0].BoundingBox()
+	for _, obj := range objects[1:] {
+		bounds = NewAABBFromBoxes(bounds, obj.BoundingBox())
+	}
+	leaf := &BVHLeaf{objects: objects, bbox: bounds}
+	leaf.kernel = newLeafKernel(objects)
+	return &BVHNode{left: leaf, right: leaf, bbox: bounds}
+}
+
+// boxContains reports whether inner lies within outer
+func boxContains(outer, inner AABB) bool {
+	for axis := 0; axis < 3; axis++ {
+		o, i := outer.AxisInterval(axis), inner.AxisInterval(axis)
+		if i.Min < o.Min || i.Max > o.Max {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *DynamicBVH) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	if d.root.left == nil {
+		return false
+	}
+	return d.root.Hit(r, rayT, rec)
+}
+
+func (d *DynamicBVH) BoundingBox() AABB {
+	return d.root.bbox
+}
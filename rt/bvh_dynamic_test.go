@@ -0,0 +1,92 @@
+package rt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkAgainstList compares the BVH's closest hits with a brute-force list
+func checkAgainstList(t *testing.T, d *DynamicBVH, rng *rand.Rand) {
+	t.Helper()
+	list := NewHittableList()
+	for _, obj := range d.Children() {
+		list.Add(obj)
+	}
+	for i := 0; i < 500; i++ {
+		origin := Point3{X: rng.Float64()*20 - 10, Y: rng.Float64()*20 - 10, Z: 15}
+		target := Point3{X: rng.Float64()*20 - 10, Y: rng.Float64()*20 - 10, Z: -15}
+		ray := NewRay(origin, target.Sub(origin), 0)
+		var want, got HitRecord
+		hitWant := list.Hit(ray, NewInterval(0.001, 1e9), &want)
+		hitGot := d.Hit(ray, NewInterval(0.001, 1e9), &got)
+		if hitWant != hitGot || (hitWant && want.T != got.T) {
+			t.Fatalf("ray %d: BVH hit=%v t=%v, list hit=%v t=%v", i, hitGot, got.T, hitWant, want.T)
+		}
+	}
+}
+
+func randomSphere(rng *rand.Rand) *Sphere {
+	center := Point3{X: rng.Float64()*16 - 8, Y: rng.Float64()*16 - 8, Z: rng.Float64()*16 - 8}
+	return NewSphere(center, 0.2+rng.Float64()*0.6, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))
+}
+
+func TestDynamicBVHInsertRemove(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var objects []Hittable
+	for i := 0; i < 64; i++ {
+		objects = append(objects, randomSphere(rng))
+	}
+	opts := DefaultBVHOptions()
+	opts.LeafMaxSize = 2
+	// Never rebuild, so every edit goes through the incremental path
+	d := NewDynamicBVH(objects, opts).SetRebuildFraction(1e6)
+
+	for i := 0; i < 20; i++ {
+		d.Insert(randomSphere(rng))
+	}
+	checkAgainstList(t, d, rng)
+
+	for i := 0; i < 30; i++ {
+		victim := d.Children()[rng.Intn(d.Len())]
+		if !d.Remove(victim) {
+			t.Fatalf("remove %d: object not found", i)
+		}
+	}
+	if d.Len() != 54 {
+		t.Fatalf("Len() = %d, want 54", d.Len())
+	}
+	checkAgainstList(t, d, rng)
+
+	if d.Remove(randomSphere(rng)) {
+		t.Error("removing an object that was never inserted should report false")
+	}
+}
+
+func TestDynamicBVHFromEmpty(t *testing.T) {
+	d := NewDynamicBVH(nil, DefaultBVHOptions())
+	ray := NewRay(Point3{Z: 5}, Vec3{Z: -1}, 0)
+	if d.Hit(ray, NewInterval(0.001, 1e9), &HitRecord{}) {
+		t.Fatal("an empty BVH should not be hit")
+	}
+	sphere := NewSphere(Point3{}, 1, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))
+	d.Insert(sphere)
+	if !d.Hit(ray, NewInterval(0.001, 1e9), &HitRecord{}) {
+		t.Fatal("the inserted sphere should be hit")
+	}
+	d.Remove(sphere)
+	if d.Hit(ray, NewInterval(0.001, 1e9), &HitRecord{}) {
+		t.Fatal("the removed sphere should not be hit")
+	}
+}
+
+func TestDynamicBVHRebuildsAfterManyEdits(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	d := NewDynamicBVH(nil, DefaultBVHOptions())
+	for i := 0; i < 100; i++ {
+		d.Insert(randomSphere(rng))
+	}
+	if d.edits >= 100 {
+		t.Error("100 inserts into an empty BVH should have triggered a rebuild")
+	}
+	checkAgainstList(t, d, rng)
+}
@@ -0,0 +1,259 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// =============================================================================
+// QUANTIZED BVH
+// =============================================================================
+
+// quantizedNoChild marks the unused second child of a single-leaf tree
+const quantizedNoChild = math.MaxInt32
+
+// quantizedNode is an interior node of a QuantizedBVH. Child boxes are stored
+// as 8-bit offsets in 1/255 steps of the node's own (decoded) box, rounded
+// outwards, so they are conservative. At 20 bytes it is a quarter of a
+// BVHNode and its two interface children.
+type quantizedNode struct {
+	lo, hi [2][3]uint8
+	child  [2]int32 // Index of the child node, or ^index into leaves
+}
+
+// QuantizedBVH is a compact, read-only copy of a BVH: interior nodes live in
+// one array with quantized child bounds, leaves keep their primitives and
+// batched kernels. Very large meshes then need far less memory for the tree
+// and more of it stays in cache, for a few multiply-adds per child decoded
+// during traversal. Only the root box is stored at full precision.
+type QuantizedBVH struct {
+	bbox   AABB
+	nodes  []quantizedNode
+	leaves []*BVHLeaf
+}
+
+// quantizedStackSize covers BVHs up to this depth without allocating
+const quantizedStackSize = 64
+
+// quantizedEntry is a node waiting to be visited, with its decoded box
+type quantizedEntry struct {
+	node int32
+	box  AABB
+}
+
+// NewQuantizedBVH converts a built BVH to the quantized format
+func NewQuantizedBVH(root *BVHNode) *QuantizedBVH {
+	q := &QuantizedBVH{bbox: root.bbox}
+	switch {
+	case root.left == nil:
+		// Empty BVH: no nodes, never hit
+	case root.left == root.right:
+		// A single leaf still gets a node so Hit has one code path
+		q.nodes = append(q.nodes, quantizedNode{})
+		q.setChild(0, 0, root.left, q.bbox)
+		q.nodes[0].child[1] = quantizedNoChild
+	default:
+		q.flatten(root, q.bbox)
+	}
+	return q
+}
+
+// flatten appends node and its subtree, with child boxes quantized against
+// box, and returns the node's index
+func (q *QuantizedBVH) flatten(node *BVHNode, box AABB) int32 {
+	index := int32(len(q.nodes))
+	q.nodes = append(q.nodes, quantizedNode{})
+	q.setChild(index, 0, node.left, box)
+	q.setChild(index, 1, node.right, box)
+	return index
+}
+
+// setChild quantizes child slot of node against box and links the child
+func (q *QuantizedBVH) setChild(index int32, slot int, child Hittable, box AABB) {
+	lo, hi := quantizeBox(child.BoundingBox(), box)
+	q.nodes[index].lo[slot], q.nodes[index].hi[slot] = lo, hi
+	decoded := dequantizeBox(box, lo, hi)
+
+	var link int32
+	switch c := child.(type) {
+	case *BVHNode:
+		if c.left == c.right {
+			link = q.addLeaf(c.left)
+		} else {
+			link = q.flatten(c, decoded)
+		}
+	default:
+		link = q.addLeaf(c)
+	}
+	q.nodes[index].child[slot] = link
+}
+
+// addLeaf stores a leaf (wrapping other hittables in one) and returns its link
+func (q *QuantizedBVH) addLeaf(object Hittable) int32 {
+	leaf, ok := object.(*BVHLeaf)
+	if !ok {
+		leaf = &BVHLeaf{objects: []Hittable{object}, bbox: object.BoundingBox()}
+	}
+	q.leaves = append(q.leaves, leaf)
+	return ^int32(len(q.leaves) - 1)
+}
+
+// quantizeBox returns child's bounds as 1/255 steps of parent, rounded out
+// so the decoded box always contains child
+func quantizeBox(child, parent AABB) (lo, hi [3]uint8) {
+	for axis := 0; axis < 3; axis++ {
+		p := parent.AxisInterval(axis)
+		c := child.AxisInterval(axis)
+		step := (p.Max - p.Min) / 255
+		if !(step > 0) || math.IsInf(step, 0) {
+			lo[axis], hi[axis] = 0, 255
+			continue
+		}
+		l := int(clampFloat(math.Floor((c.Min-p.Min)/step), 0, 255))
+		h := int(clampFloat(math.Ceil((c.Max-p.Min)/step), 0, 255))
+		// Guard against rounding in the decode below
+		for l > 0 && p.Min+float64(l)*step > c.Min {
+			l--
+		}
+		for h < 255 && p.Min+float64(h)*step < c.Max {
+			h++
+		}
+		lo[axis], hi[axis] = uint8(l), uint8(h)
+	}
+	return lo, hi
+}
+
+// dequantizeBox decodes a child box stored against parent
+func dequantizeBox(parent AABB, lo, hi [3]uint8) AABB {
+	sx := (parent.X.Max - parent.X.Min) / 255
+	sy := (parent.Y.Max - parent.Y.Min) / 255
+	sz := (parent.Z.Max - parent.Z.Min) / 255
+	if !(sx > 0 && sy > 0 && sz > 0) || math.IsInf(sx+sy+sz, 0) {
+		return dequantizeDegenerate(parent, lo, hi)
+	}
+	return AABB{
+		X: Interval{Min: parent.X.Min + float64(lo[0])*sx, Max: parent.X.Min + float64(hi[0])*sx},
+		Y: Interval{Min: parent.Y.Min + float64(lo[1])*sy, Max: parent.Y.Min + float64(hi[1])*sy},
+		Z: Interval{Min: parent.Z.Min + float64(lo[2])*sz, Max: parent.Z.Min + float64(hi[2])*sz},
+	}
+}
+
+// dequantizeDegenerate handles parents that are flat (or unbounded) on some
+// axis: those axes keep the parent's interval
+func dequantizeDegenerate(parent AABB, lo, hi [3]uint8) AABB {
+	var box AABB
+	for axis := 0; axis < 3; axis++ {
+		p := parent.AxisInterval(axis)
+		interval := p
+		if step := (p.Max - p.Min) / 255; step > 0 && !math.IsInf(step, 0) {
+			interval = Interval{Min: p.Min + float64(lo[axis])*step, Max: p.Min + float64(hi[axis])*step}
+		}
+		switch axis {
+		case 0:
+			box.X = interval
+		case 1:
+			box.Y = interval
+		default:
+			box.Z = interval
+		}
+	}
+	return box
+}
+
+func (q *QuantizedBVH) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	if len(q.nodes) == 0 || !q.bbox.Hit(r, rayT) {
+		return false
+	}
+
+	var buffer [quantizedStackSize]quantizedEntry
+	stack := append(buffer[:0], quantizedEntry{node: 0, box: q.bbox})
+	hitAnything := false
+	closest := rayT.Max
+
+	for len(stack) > 0 {
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		// Hits found since the node was pushed may already rule it out
+		if !entry.box.Hit(r, Interval{Min: rayT.Min, Max: closest}) {
+			continue
+		}
+		GlobalRenderStats.BVHIntersections.Add(1)
+
+		node := &q.nodes[entry.node]
+		// Push the right child first so the left subtree is visited first, like BVHNode
+		for slot := 1; slot >= 0; slot-- {
+			link := node.child[slot]
+			if link == quantizedNoChild {
+				continue
+			}
+			box := dequantizeBox(entry.box, node.lo[slot], node.hi[slot])
+			if !box.Hit(r, Interval{Min: rayT.Min, Max: closest}) {
+				continue
+			}
+			if link >= 0 {
+				stack = append(stack, quantizedEntry{node: link, box: box})
+				continue
+			}
+			if q.leaves[^link].Hit(r, Interval{Min: rayT.Min, Max: closest}, rec) {
+				hitAnything = true
+				closest = rec.T
+			}
+		}
+	}
+	return hitAnything
+}
+
+func (q *QuantizedBVH) BoundingBox() AABB {
+	return q.bbox
+}
+
+// depth returns the longest chain of nodes from the root to a leaf, counting
+// the leaf like the BVHNode that wraps it in the pointer BVH
+func (q *QuantizedBVH) depth() int {
+	if len(q.nodes) == 0 {
+		return 0
+	}
+	if q.nodes[0].child[1] == quantizedNoChild {
+		return 1 // Single leaf
+	}
+	deepest := 0
+	var visit func(node int32, depth int)
+	visit = func(node int32, depth int) {
+		for _, link := range q.nodes[node].child {
+			switch {
+			case link == quantizedNoChild:
+			case link >= 0:
+				visit(link, depth+1)
+			default:
+				deepest = max(deepest, depth+1)
+			}
+		}
+	}
+	visit(0, 1)
+	return deepest
+}
+
+// newMeshBVH builds the BVH over a mesh's triangles, quantized when the
+// default BVH options ask for it
+func newMeshBVH(triangles []Hittable) Hittable {
+	bvh := NewBVHNode(triangles, 0, len(triangles))
+	if !defaultBVHOptions.Quantized {
+		return bvh
+	}
+	q := NewQuantizedBVH(bvh)
+	interior, leaves := q.Nodes()
+	fmt.Printf("Quantized BVH: %d nodes, %d leaves, %.1f KB of nodes\n",
+		interior, leaves, float64(q.NodeBytes())/1024)
+	return q
+}
+
+// Nodes returns the number of interior nodes and leaves
+func (q *QuantizedBVH) Nodes() (interior, leaves int) {
+	return len(q.nodes), len(q.leaves)
+}
+
+// NodeBytes returns the memory held by the interior nodes
+func (q *QuantizedBVH) NodeBytes() int {
+	return len(q.nodes) * int(unsafe.Sizeof(quantizedNode{}))
+}
@@ -0,0 +1,127 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuantizedBVHMatchesBVH(t *testing.T) {
+	SeedRandom(11)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	objects := randomTriangleSoup(3000)
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	objects = append(objects,
+		NewSphere(Point3{X: 2}, 1, mat),
+		NewQuad(Point3{X: -6, Y: -6, Z: 6}, Vec3{X: 12}, Vec3{Y: 12}, mat), // Flat on Z
+	)
+	bvh := NewBVHNode(objects, 0, len(objects))
+	quantized := NewQuantizedBVH(bvh)
+
+	if interior, leaves := quantized.Nodes(); interior == 0 || leaves == 0 {
+		t.Fatalf("got %d interior nodes and %d leaves", interior, leaves)
+	}
+	if quantized.BoundingBox() != bvh.BoundingBox() {
+		t.Errorf("bounds %v, want %v", quantized.BoundingBox(), bvh.BoundingBox())
+	}
+
+	for i := 0; i < 2000; i++ {
+		origin := RandomVec3Range(-15, 15)
+		ray := NewRay(origin, RandomVec3Range(-5, 5).Sub(origin), 0)
+		want, got := &HitRecord{}, &HitRecord{}
+		wantHit := bvh.Hit(ray, NewInterval(0.001, math.Inf(1)), want)
+		gotHit := quantized.Hit(ray, NewInterval(0.001, math.Inf(1)), got)
+		if wantHit != gotHit || (wantHit && (want.T != got.T || want.Normal != got.Normal)) {
+			t.Fatalf("ray %d: quantized hit %v at t=%v, BVH hit %v at t=%v", i, gotHit, got.T, wantHit, want.T)
+		}
+	}
+}
+
+func TestQuantizeBoxIsConservative(t *testing.T) {
+	SeedRandom(5)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	parent := NewAABBFromPoints(Point3{X: -3.7, Y: 0.1, Z: 1e4}, Point3{X: 9.2, Y: 0.3, Z: 1e4 + 1e-3})
+	for i := 0; i < 1000; i++ {
+		a := Point3{X: RandomDoubleRange(-3.7, 9.2), Y: RandomDoubleRange(0.1, 0.3), Z: 1e4 + RandomDoubleRange(0, 1e-3)}
+		b := Point3{X: RandomDoubleRange(-3.7, 9.2), Y: RandomDoubleRange(0.1, 0.3), Z: 1e4 + RandomDoubleRange(0, 1e-3)}
+		child := AABB{
+			X: Interval{Min: math.Min(a.X, b.X), Max: math.Max(a.X, b.X)},
+			Y: Interval{Min: math.Min(a.Y, b.Y), Max: math.Max(a.Y, b.Y)},
+			Z: Interval{Min: math.Min(a.Z, b.Z), Max: math.Max(a.Z, b.Z)},
+		}
+		lo, hi := quantizeBox(child, parent)
+		decoded := dequantizeBox(parent, lo, hi)
+		for axis := 0; axis < 3; axis++ {
+			c, d := child.AxisInterval(axis), decoded.AxisInterval(axis)
+			if d.Min > c.Min || d.Max < c.Max {
+				t.Fatalf("axis %d: decoded %v doesn't contain %v", axis, d, c)
+			}
+			if step := parent.AxisInterval(axis).Size() / 255; d.Size() > c.Size()+2.01*step {
+				t.Fatalf("axis %d: decoded %v is looser than one step per side around %v", axis, d, c)
+			}
+		}
+	}
+}
+
+func TestQuantizedBVHSmallTrees(t *testing.T) {
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	empty := NewQuantizedBVH(NewBVHNode(nil, 0, 0))
+	if empty.Hit(NewRay(Point3{}, Vec3{Z: -1}, 0), NewInterval(0.001, math.Inf(1)), &HitRecord{}) {
+		t.Error("empty BVH reported a hit")
+	}
+
+	single := NewQuantizedBVH(NewBVHNode([]Hittable{NewSphere(Point3{Z: -3}, 1, mat)}, 0, 1))
+	rec := &HitRecord{}
+	if !single.Hit(NewRay(Point3{}, Vec3{Z: -1}, 0), NewInterval(0.001, math.Inf(1)), rec) || math.Abs(rec.T-2) > 1e-9 {
+		t.Errorf("single-leaf BVH: hit at t=%v, want 2", rec.T)
+	}
+}
+
+// BenchmarkQuantizedMeshTraversal is BenchmarkMeshTraversal on the
+// quantized copy of the same BVH
+func BenchmarkQuantizedMeshTraversal(b *testing.B) {
+	SeedRandom(1)
+	defer activeSeed.Store(nil)
+
+	opts := DefaultBVHOptions()
+	opts.LeafMaxSize = 8
+	objects := randomTriangleSoup(50000)
+	bvh := NewQuantizedBVH(NewBVHNodeWithOptions(objects, 0, len(objects), opts))
+
+	rays := make([]Ray, 1024)
+	for i := range rays {
+		origin := RandomVec3Range(-15, 15)
+		rays[i] = NewRay(origin, RandomVec3Range(-5, 5).Sub(origin).Unit(), 0)
+	}
+
+	rec := &HitRecord{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bvh.Hit(rays[i%len(rays)], NewInterval(0.001, math.Inf(1)), rec)
+	}
+}
+
+func TestQuantizedMeshBVHSceneStats(t *testing.T) {
+	SeedRandom(2)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	triangles := randomTriangleSoup(500)
+
+	pointer := CollectSceneStats(NewTranslate(newMeshBVH(triangles), Vec3{X: 1}), nil)
+
+	opts := DefaultBVHOptions()
+	opts.Quantized = true
+	SetDefaultBVHOptions(opts)
+	t.Cleanup(func() { SetDefaultBVHOptions(DefaultBVHOptions()) })
+	mesh := newMeshBVH(triangles)
+	if _, ok := mesh.(*QuantizedBVH); !ok {
+		t.Fatalf("mesh BVH is %T, want *QuantizedBVH", mesh)
+	}
+	quantized := CollectSceneStats(NewTranslate(mesh, Vec3{X: 1}), nil)
+
+	if quantized.Triangles != pointer.Triangles || quantized.BVHNodes != pointer.BVHNodes || quantized.BVHDepth != pointer.BVHDepth {
+		t.Errorf("quantized stats %d triangles, %d nodes, depth %d; pointer BVH %d, %d, %d",
+			quantized.Triangles, quantized.BVHNodes, quantized.BVHDepth,
+			pointer.Triangles, pointer.BVHNodes, pointer.BVHDepth)
+	}
+}
@@ -37,6 +37,21 @@ type Camera struct {
 	PhantomHDRI     bool // If true, HDRI invisible to primary rays (camera sees black)
 	Lights          []Hittable
 	Environment     *HDRIEnvironment // HDRI environment map
+	GlossyFilter    GlossyFilterConfig
+	Ambient         AmbientConfig
+	LightPath       LightPathFilter // Keep only matching light paths (nil = beauty)
+	Units           SceneUnits      // Real-world size of a scene unit (see SetUnits)
+	Aperture        float64         // Lens aperture diameter in meters; overrides DefocusAngle when set
+	RayEpsilon      float64         // Ray offset from surfaces in scene units (0 = 1 mm in Units)
+	FocalLength     float64         // Lens focal length in mm; replaces Vfov when set
+	FilmBack        FilmBack        // Sensor size in mm for FocalLength (zero = full frame)
+	SensorFit       SensorFit       // Which side of the film back matches the image
+	NearClip        float64         // Camera rays skip geometry closer than this along the view axis (0 = off)
+
+	// Saved PNGs get alpha BackgroundAlpha where the background shows (opaque
+	// unless TransparentBackground is set)
+	TransparentBackground bool
+	BackgroundAlpha       float64
 
 	pixelsSamplesScale float64
 	center             Point3
@@ -54,6 +69,23 @@ type Camera struct {
 	viewportWidth  float64
 	viewportU      Vec3
 	viewportV      Vec3
+
+	// Power-weighted light selection for NEE (built in Initialize)
+	lightSampler *lightSampler
+
+	// Light index of each area light material, for light path filters
+	emitterLights map[*DiffuseLight]int
+
+	// Collects environment shadow-ray visibility while set (see
+	// SetAdaptiveEnvironment)
+	envOcclusion *envOcclusionMap
+
+	// Pose relative to the object the camera is parented to (see SetParent)
+	rig *cameraRig
+
+	// Prefiltered HDRI for rough metal reflections (see
+	// SetPrefilteredEnvironment; nil = off)
+	prefiltered *PrefilteredEnvironment
 }
 
 // =============================================================================
@@ -246,6 +278,18 @@ func (c *Camera) SetEnvironmentMap(filename string) *Camera {
 	return c
 }
 
+// SetUniformEnvironment lights the scene with constant radiance from all directions
+func (c *Camera) SetUniformEnvironment(radiance Color) *Camera {
+	c.Environment = NewUniformEnvironment(radiance)
+	return c
+}
+
+// SetAtmosphere lights the scene with a ray-marched Rayleigh/Mie sky
+func (c *Camera) SetAtmosphere(config AtmosphereConfig) *Camera {
+	c.Environment = NewAtmosphereEnvironment(config)
+	return c
+}
+
 // SetEnvironmentRotation rotates the HDRI environment map (in degrees)
 func (c *Camera) SetEnvironmentRotation(degrees float64) *Camera {
 	if c.Environment != nil {
@@ -284,6 +328,7 @@ func (c *Camera) Build() *Camera {
 // =============================================================================
 
 func (c *Camera) Initialize() {
+	c.applyRig()
 
 	if c.CameraMotion {
 		velocity := c.LookFrom2.Sub(c.LookFrom)
@@ -300,6 +345,10 @@ func (c *Camera) Initialize() {
 
 	c.pixelsSamplesScale = 1.0 / float64(c.SamplesPerPixel)
 
+	if c.FocalLength > 0 {
+		c.Vfov = c.filmBackVFOV()
+	}
+
 	c.center = c.LookFrom
 
 	theta := DegreesToRadians(c.Vfov)
@@ -319,9 +368,10 @@ func (c *Camera) Initialize() {
 		c.w = c.center.Sub(c.LookAt).Unit()
 	}
 
-	c.u = Cross(c.Vup, c.w).Unit()
-
-	c.v = Cross(c.w, c.u)
+	// Degenerate when looking along Vup; NewONBWithUp then picks any stable frame
+	basis := NewONBWithUp(c.w, c.Vup)
+	c.u = basis.U
+	c.v = basis.V
 
 	viewportU := c.u.Scale(viewportWidth)
 
@@ -338,25 +388,48 @@ func (c *Camera) Initialize() {
 
 	c.pixel00Loc = viewportUpperLeft.Add(c.pixelDeltaU.Add(c.pixelDeltaV).Scale(0.5))
 
+	if c.Aperture > 0 {
+		c.DefocusAngle = c.apertureDefocusAngle()
+	}
 	defocusRadius := c.FocusDist * math.Tan(DegreesToRadians(c.DefocusAngle/2))
 	c.defocusDiskU = c.u.Scale(defocusRadius)
 	c.defocusDiskV = c.v.Scale(defocusRadius)
+
+	c.buildLightSampler()
+}
+
+// buildLightSampler rebuilds the power-weighted light selection
+func (c *Camera) buildLightSampler() {
+	// Scene extent proxy for weighing the environment against area lights
+	sceneRadius := math.Max(c.LookFrom.Sub(c.LookAt).Len(), c.FocusDist)
+	c.lightSampler = newLightSampler(c.Lights, c.Environment, sceneRadius)
+	c.buildEmitterLights()
+}
+
+// setEnvironment swaps the environment map, e.g. between render passes.
+// Not safe while rays are being traced.
+func (c *Camera) setEnvironment(env *HDRIEnvironment) {
+	if c.Environment == env {
+		return
+	}
+	c.Environment = env
+	c.buildLightSampler()
 }
 
-func (c *Camera) sampleSquare() Vec3 {
+func (c *Camera) sampleSquare(s *Sampler) Vec3 {
 	return Vec3{
-		X: RandomDouble() - 0.5,
-		Y: RandomDouble() - 0.5,
+		X: s.Float64() - 0.5,
+		Y: s.Float64() - 0.5,
 		Z: 0,
 	}
 }
 
-func (c *Camera) defocusDiskSample(center Point3, u, v Vec3) Point3 {
-	p := RandomInUnitDisk()
+func (c *Camera) defocusDiskSample(center Point3, u, v Vec3, s *Sampler) Point3 {
+	p := sampleConcentricDisk(s)
 	defocusRadius := c.FocusDist * math.Tan(DegreesToRadians(c.DefocusAngle/2))
 	defocusDiskU := u.Scale(defocusRadius)
 	defocusDiskV := v.Scale(defocusRadius)
-	p = RandomInUnitDisk()
+	p = sampleConcentricDisk(s)
 
 	return center.Add(defocusDiskU.Scale(p.X)).Add(defocusDiskV.Scale(p.Y))
 }
@@ -365,9 +438,21 @@ func (c *Camera) defocusDiskSample(center Point3, u, v Vec3) Point3 {
 // RAY GENERATION
 // =============================================================================
 
+// centerRay returns the pinhole ray through the center of pixel (i, j) at
+// time 0, ignoring jitter, defocus and motion (for AOVs and previews)
+func (c *Camera) centerRay(i, j int) Ray {
+	target := c.pixel00Loc.Add(c.pixelDeltaU.Scale(float64(i))).Add(c.pixelDeltaV.Scale(float64(j)))
+	return NewRay(c.center, target.Sub(c.center), 0).withKind(rayCamera)
+}
+
 func (c *Camera) GetRay(i, j int) Ray {
-	offset := c.sampleSquare()
-	rayTime := RandomDouble()
+	return c.getRay(i, j, nil)
+}
+
+// getRay is GetRay drawing from the pixel sample's stream s (nil = global)
+func (c *Camera) getRay(i, j int, s *Sampler) Ray {
+	offset := c.sampleSquare(s)
+	rayTime := s.Float64()
 
 	// Fast path: use cached values when camera is not moving
 	if !c.CameraMotion && !c.FreeCamera {
@@ -380,11 +465,11 @@ func (c *Camera) GetRay(i, j int) Ray {
 		if c.DefocusAngle <= 0 {
 			rayOrigin = c.center
 		} else {
-			rayOrigin = c.defocusDiskSample(c.center, c.u, c.v)
+			rayOrigin = c.defocusDiskSample(c.center, c.u, c.v, s)
 		}
 
 		rayDirection := pixelSample.Sub(rayOrigin)
-		return NewRay(rayOrigin, rayDirection, rayTime)
+		return NewRay(rayOrigin, rayDirection, rayTime).withKind(rayCamera).withSampler(s)
 	}
 
 	// Slow path: recalculate for camera motion or free camera
@@ -393,14 +478,12 @@ func (c *Camera) GetRay(i, j int) Ray {
 
 	if c.FreeCamera {
 		w = c.Forward.Neg()
-		u = Cross(c.Vup, w).Unit()
-		v = Cross(w, u)
 	} else {
 		currentLookAt := c.lookAtMotion.At(rayTime)
 		w = currentCenter.Sub(currentLookAt).Unit()
-		u = Cross(c.Vup, w).Unit()
-		v = Cross(w, u)
 	}
+	basis := NewONBWithUp(w, c.Vup)
+	u, v = basis.U, basis.V
 
 	// Use cached viewport dimensions
 	viewportU := u.Scale(c.viewportWidth)
@@ -427,20 +510,37 @@ func (c *Camera) GetRay(i, j int) Ray {
 		rayOrigin = currentCenter
 	} else {
 		// Defocus disk also moves with camera
-		rayOrigin = c.defocusDiskSample(currentCenter, u, v)
+		rayOrigin = c.defocusDiskSample(currentCenter, u, v, s)
 	}
 
 	rayDirection := pixelSample.Sub(rayOrigin)
-	return NewRay(rayOrigin, rayDirection, rayTime)
+	return NewRay(rayOrigin, rayDirection, rayTime).withKind(rayCamera).withSampler(s)
 }
 
 // sending out them color rays
 func (c *Camera) RayColor(r Ray, depth int, world Hittable) Color {
 	GlobalRenderStats.RayCount.Add(1)
-	return c.rayColorInternal(r, depth, world, true)
+	return c.rayColorInternal(r, depth, world, true, 0, pathState{})
+}
+
+// rayColorCached is RayColor with an environment visibility cache and the
+// adaptive light sampling state (both may be nil) for the camera-ray hit.
+// lights (may be nil) receives the sample split by light.
+func (c *Camera) rayColorCached(r Ray, depth int, world Hittable, envCache *envVisibilityCache, nee *neeBucket, lights lightTally) Color {
+	GlobalRenderStats.RayCount.Add(1)
+	path := pathState{envCache: envCache, nee: nee}
+	if lights != nil {
+		lights.reset()
+		path.lights, path.throughput = lights, Color{X: 1, Y: 1, Z: 1}
+	}
+	return c.rayColorInternal(r, depth, world, true, 0, path)
 }
 
-func (c *Camera) rayColorInternal(r Ray, depth int, world Hittable, allowLightHits bool) Color {
+// rayColorInternal traces a path segment. When allowLightHits is false the
+// previous vertex already sampled lights via NEE, and scatterPDF holds the
+// BRDF density of r so escaped rays can take their share of the MIS weight.
+// path tracks the scatter events so far for the light path filter.
+func (c *Camera) rayColorInternal(r Ray, depth int, world Hittable, allowLightHits bool, scatterPDF float64, path pathState) Color {
 	if depth <= 0 {
 		return Color{X: 0, Y: 0, Z: 0}
 	}
@@ -448,49 +548,72 @@ func (c *Camera) rayColorInternal(r Ray, depth int, world Hittable, allowLightHi
 	GlobalRenderStats.RayCount.Add(1)
 	rec := &HitRecord{}
 
-	if !world.Hit(r, NewInterval(0.001, math.Inf(1)), rec) {
-		// Check HDRI environment first
-		if c.Environment != nil && c.Environment.IsValid() {
-			// If phantom mode is enabled, primary rays see black instead of HDRI
-			// Secondary rays (reflections/refractions) still see the HDRI
-			isPrimaryRay := (depth == c.MaxDepth)
-			if c.PhantomHDRI && isPrimaryRay {
-				return Color{X: 0, Y: 0, Z: 0}
-			}
-			return c.Environment.Sample(r.Direction())
+	if !world.Hit(r, c.rayInterval(r), rec) {
+		radiance := c.backgroundRadiance(r, depth == c.MaxDepth)
+		if path.reflection.roughness > 0 {
+			radiance = c.prefiltered.SpecularAt(path.reflection.dir, path.reflection.roughness)
 		}
-		if c.UseSkyGradient {
-			return c.SkyGradient(r)
+		background := radiance.Scale(c.pathWeight(path, LightSourceBackground, -1))
+		if !allowLightHits && c.Environment != nil && c.Environment.IsValid() {
+			// The environment may also have been sampled by NEE at the
+			// previous vertex, so this BRDF sample gets the complementary weight
+			pdfEnv := c.lightSampler.EnvironmentPDF(r.Direction())
+			background = background.Scale(BalanceHeuristic(scatterPDF, pdfEnv))
 		}
-		return c.Background
+		path.tally(LightSourceBackground, -1, background)
+		return background
 	}
 
 	var attenuation Color
 	var scattered Ray
 
-	colorFromEmission := rec.Mat.Emitted(rec.U, rec.V, rec.P)
+	mat := c.filterMaterial(rec.Mat, c.MaxDepth-depth)
+	colorFromEmission := mat.Emitted(rec.U, rec.V, rec.P)
+	if r.kind != rayCamera {
+		// Light reaching a surface decays like it does for light sampling
+		colorFromEmission = colorFromEmission.Scale(emitterDecay(rec.Mat).scale(rec.T * r.Direction().Len()))
+	}
+	emitter := -1
+	if c.LightPath != nil || path.lights != nil {
+		emitter = c.emitterLight(rec.Mat)
+	}
+	if c.LightPath != nil {
+		colorFromEmission = colorFromEmission.Scale(c.pathWeight(path, LightSourceEmitter, emitter))
+	}
 
-	if !rec.Mat.Scatter(r, rec, &attenuation, &scattered) {
-		// Hit a light source - only return emission if we allow it
-		// When using MIS, we don't want BRDF paths to directly hit lights
-		// (we already sample them explicitly via NEE)
-		if allowLightHits {
-			return colorFromEmission
+	scatters := mat.Scatter(r, rec, &attenuation, &scattered)
+	scattered.sampler = r.sampler
+	if !scatters {
+		// Hit a light source - return full emission unless the previous
+		// vertex used NEE, in which case the lights it could have sampled in
+		// this direction share the contribution via the balance heuristic
+		if !allowLightHits {
+			pdfLight := c.lightSampler.AreaLightPDF(r.Origin(), r.Direction(), rec)
+			colorFromEmission = colorFromEmission.Scale(BalanceHeuristic(scatterPDF, pdfLight))
 		}
-		return Color{X: 0, Y: 0, Z: 0}
+		path.tally(LightSourceEmitter, emitter, colorFromEmission)
+		return colorFromEmission
 	}
 
+	next := path.scatter(pathEvent(mat), attenuation)
+	next.reflection = c.prefilterReflection(mat, r, rec)
+	nextDepth := scatterDepth(depth, mat, rec, &next)
+	ambient := c.ambientTerm(mat, rec).Scale(c.pathWeight(next, LightSourceAmbient, -1))
+	path.tally(LightSourceEmitter, emitter, colorFromEmission)
+	path.tally(LightSourceAmbient, -1, ambient)
+	colorFromEmission = colorFromEmission.Add(ambient)
+
 	// Check if material can use NEE/MIS
-	matInfo, implementsInfo := rec.Mat.(MaterialInfo)
-	pdfEval, implementsPDF := rec.Mat.(PDFEvaluator)
+	matInfo, implementsInfo := mat.(MaterialInfo)
+	pdfEval, implementsPDF := mat.(PDFEvaluator)
 
 	useMIS := implementsInfo && implementsPDF &&
 		matInfo.Properties().CanUseNEE &&
-		len(c.Lights) > 0
+		c.lightSampler.Len() > 0
 
 	if !useMIS {
 		// Pure BRDF sampling (works for everything)
-		colorFromScatter := attenuation.Mult(c.rayColorInternal(scattered, depth-1, world, true))
+		colorFromScatter := attenuation.Mult(c.rayColorInternal(scattered, nextDepth, world, true, 0, next))
 		return colorFromEmission.Add(colorFromScatter)
 	}
 
@@ -498,25 +621,58 @@ func (c *Camera) rayColorInternal(r Ray, depth int, world Hittable, allowLightHi
 	// MULTIPLE IMPORTANCE SAMPLING
 	// ============================================================
 
-	// NEE: Explicitly sample the light for direct illumination
-	lightIdx := int(RandomDouble() * float64(len(c.Lights)))
-	if lightIdx >= len(c.Lights) {
-		lightIdx = len(c.Lights) - 1
+	// NEE: Explicitly sample lights (each chosen by power) for direct
+	// illumination; adaptive light sampling may average several
+	neeSamples := path.nee.count()
+	var directLight, firstLight Color
+	for i := 0; i < neeSamples; i++ {
+		direct, light := c.sampleLightMIS(
+			rec.P, rec.Normal, r.Direction(),
+			world, attenuation, pdfEval, path.envCache, r.sampler,
+		)
+		if c.LightPath != nil || path.lights != nil {
+			source := LightSourceEmitter
+			if light == c.lightSampler.envIndex {
+				source, light = LightSourceBackground, -1
+			}
+			direct = direct.Scale(c.pathWeight(next, source, light))
+			path.tally(source, light, direct.Scale(1/float64(neeSamples)))
+		}
+		if i == 0 {
+			firstLight = direct
+		} else if i == 1 {
+			path.nee.record(firstLight, direct)
+		}
+		directLight = directLight.Add(direct)
 	}
-
-	directLight := c.sampleLightMIS(
-		rec.P, rec.Normal, r.Direction(),
-		world, lightIdx, attenuation, pdfEval,
-	)
+	directLight = directLight.Scale(1 / float64(neeSamples))
 
 	// BRDF path for indirect illumination only
 	// Disable direct light hits since we're using NEE
-	indirectLight := attenuation.Mult(c.rayColorInternal(scattered, depth-1, world, false))
+	brdfPDF := pdfEval.PDF(r.Direction().Neg().Unit(), scattered.Direction().Unit(), rec.Normal)
+	indirectLight := attenuation.Mult(c.rayColorInternal(scattered, nextDepth, world, false, brdfPDF, next))
 
 	// Combine: direct (NEE) + indirect (BRDF path)
 	return colorFromEmission.Add(directLight).Add(indirectLight)
 }
 
+// backgroundRadiance returns what a ray that escapes the scene sees: the
+// HDRI, then the sky gradient, then the flat background color
+func (c *Camera) backgroundRadiance(r Ray, isPrimaryRay bool) Color {
+	if c.Environment != nil && c.Environment.IsValid() {
+		// If phantom mode is enabled, primary rays see black instead of HDRI
+		// Secondary rays (reflections/refractions) still see the HDRI
+		if c.PhantomHDRI && isPrimaryRay {
+			return Color{X: 0, Y: 0, Z: 0}
+		}
+		return c.Environment.Sample(r.Direction())
+	}
+	if c.UseSkyGradient {
+		return c.SkyGradient(r)
+	}
+	return c.Background
+}
+
 func (c *Camera) SkyGradient(r Ray) Color {
 	unitDirection := r.Direction().Unit()
 	a := 0.5 * (unitDirection.Y + 1.0)
@@ -535,39 +691,49 @@ var (
 	BackgroundNight    = Color{X: 0.05, Y: 0.05, Z: 0.2} // Dark blue night sky
 )
 
+// sampleLightMIS returns the MIS-weighted direct light from one light picked
+// by power, and the index of that light. envCache (may be nil) lets
+// environment samples skip shadow rays in known-blocked directions.
 func (c *Camera) sampleLightMIS(
 	hitPoint Point3, hitNormal Vec3, rayDirection Vec3,
-	world Hittable, lightIdx int,
-	attenuation Color, pdfEval PDFEvaluator,
-) Color {
-	var totalContribution Color
+	world Hittable, attenuation Color, pdfEval PDFEvaluator,
+	envCache *envVisibilityCache, sampler *Sampler,
+) (Color, int) {
+	// Pick one light (area lights and environment together) by power
+	lightIdx, selectPDF := c.lightSampler.Sample(sampler.Float64())
+	if selectPDF <= 0 {
+		return Color{X: 0, Y: 0, Z: 0}, lightIdx
+	}
 
 	// ==========================================================================
 	// HDRI ENVIRONMENT SAMPLING
 	// ==========================================================================
-	if c.Environment != nil && c.Environment.IsValid() && c.Environment.useImportanceSampling {
-		hdriContrib := c.sampleHDRILight(hitPoint, hitNormal, rayDirection, world, attenuation, pdfEval)
-		totalContribution = totalContribution.Add(hdriContrib)
+	if lightIdx == c.lightSampler.envIndex {
+		return c.sampleHDRILight(hitPoint, hitNormal, rayDirection, world, selectPDF, attenuation, pdfEval, envCache, sampler), lightIdx
 	}
 
 	// ==========================================================================
-	// AREA LIGHT SAMPLING
+	// POINT / SPOT LIGHT SAMPLING
 	// ==========================================================================
-	if len(c.Lights) > 0 && lightIdx < len(c.Lights) {
-		areaContrib := c.sampleAreaLight(hitPoint, hitNormal, rayDirection, world, lightIdx, attenuation, pdfEval)
-		totalContribution = totalContribution.Add(areaContrib)
+	if spot := c.lightSampler.spots[lightIdx]; spot != nil {
+		return c.sampleSpotLight(hitPoint, hitNormal, rayDirection, world, spot, selectPDF, attenuation, pdfEval, sampler), lightIdx
 	}
 
-	return totalContribution
+	// ==========================================================================
+	// AREA LIGHT SAMPLING
+	// ==========================================================================
+	return c.sampleAreaLight(hitPoint, hitNormal, rayDirection, world, lightIdx, selectPDF, attenuation, pdfEval, sampler), lightIdx
 }
 
 // sampleHDRILight samples the HDRI environment map for direct lighting
 func (c *Camera) sampleHDRILight(
 	hitPoint Point3, hitNormal Vec3, rayDirection Vec3,
-	world Hittable, attenuation Color, pdfEval PDFEvaluator,
+	world Hittable, selectPDF float64,
+	attenuation Color, pdfEval PDFEvaluator, envCache *envVisibilityCache, sampler *Sampler,
 ) Color {
 	// Sample direction from HDRI using importance sampling
-	lightDir, emission, pdfHDRI := c.Environment.SampleDirection()
+	lightDir, emission, pdfHDRI := c.Environment.sampleDirection(sampler)
+	pdfHDRI *= selectPDF
 
 	// Check if light is on the same side as surface normal
 	cosTheta := Dot(hitNormal, lightDir)
@@ -575,11 +741,19 @@ func (c *Camera) sampleHDRILight(
 		return Color{X: 0, Y: 0, Z: 0}
 	}
 
+	// Directions this bucket always found blocked are assumed blocked
+	if envCache.blocked(lightDir) {
+		return Color{X: 0, Y: 0, Z: 0}
+	}
+
 	// Shadow ray test - check if anything blocks the path to infinity
-	shadowRay := NewRay(hitPoint, lightDir, 0)
+	shadowRay := NewRay(hitPoint, lightDir, 0).withKind(rayShadow).withSampler(sampler)
 	shadowRec := &HitRecord{}
 
-	if world.Hit(shadowRay, NewInterval(0.001, math.Inf(1)), shadowRec) {
+	blocked := world.Hit(shadowRay, NewInterval(c.rayEpsilon(), math.Inf(1)), shadowRec)
+	envCache.record(lightDir, !blocked)
+	c.envOcclusion.record(c.Environment, lightDir, !blocked)
+	if blocked {
 		// Something is blocking the environment
 		return Color{X: 0, Y: 0, Z: 0}
 	}
@@ -590,11 +764,12 @@ func (c *Camera) sampleHDRILight(
 	pdfBRDF := pdfEval.PDF(wi, wo, hitNormal)
 
 	// MIS weight using balance heuristic
-	weight := pdfHDRI / (pdfHDRI + pdfBRDF)
+	weight := BalanceHeuristic(pdfHDRI, pdfBRDF)
 
 	// Light contribution with MIS weighting
-	// For environment lights: L = emission * cos(theta) / pdf * weight
-	contribution := emission.Scale(cosTheta / pdfHDRI * weight)
+	// Scatter samples proportional to f*cos/attenuation, so f*cos = attenuation * pdfBRDF
+	// For environment lights: L = emission * f * cos(theta) / pdf * weight
+	contribution := emission.Scale(pdfBRDF / pdfHDRI * weight)
 	contribution = contribution.Mult(attenuation)
 
 	// Clamp to prevent fireflies
@@ -606,11 +781,11 @@ func (c *Camera) sampleHDRILight(
 	return contribution
 }
 
-// sampleAreaLight samples an area light for direct lighting (original implementation)
+// sampleAreaLight samples an area light for direct lighting
 func (c *Camera) sampleAreaLight(
 	hitPoint Point3, hitNormal Vec3, rayDirection Vec3,
-	world Hittable, lightIdx int,
-	attenuation Color, pdfEval PDFEvaluator,
+	world Hittable, lightIdx int, selectPDF float64,
+	attenuation Color, pdfEval PDFEvaluator, sampler *Sampler,
 ) Color {
 	light := c.Lights[lightIdx]
 	lightQuad, ok := light.(*Quad)
@@ -619,7 +794,7 @@ func (c *Camera) sampleAreaLight(
 	}
 
 	// Sample random point on light surface
-	lightPoint := lightQuad.SamplePoint()
+	lightPoint := lightQuad.samplePoint(sampler)
 
 	// Direction from hit point to light sample
 	toLight := lightPoint.Sub(hitPoint)
@@ -633,16 +808,17 @@ func (c *Camera) sampleAreaLight(
 	}
 
 	// Shadow ray test
-	shadowRay := NewRay(hitPoint, lightDir, 0)
+	shadowRay := NewRay(hitPoint, lightDir, 0).withKind(rayShadow).withSampler(sampler)
 	shadowRec := &HitRecord{}
 
-	if world.Hit(shadowRay, NewInterval(0.001, distanceToLight-0.001), shadowRec) {
+	epsilon := c.rayEpsilon()
+	if world.Hit(shadowRay, NewInterval(epsilon, distanceToLight-epsilon), shadowRec) {
 		// Something is blocking the light
 		return Color{X: 0, Y: 0, Z: 0}
 	}
 
-	// Get light emission
-	emission := lightQuad.mat.Emitted(0, 0, lightPoint)
+	// Get light emission, with the light's decay over the distance
+	emission := lightQuad.mat.Emitted(0, 0, lightPoint).Scale(emitterDecay(lightQuad.mat).scale(distanceToLight))
 
 	// Calculate light PDF (area sampling → solid angle)
 	lightArea := lightQuad.Area()
@@ -652,7 +828,8 @@ func (c *Camera) sampleAreaLight(
 		return Color{X: 0, Y: 0, Z: 0}
 	}
 
-	pdfLight := (distanceToLight * distanceToLight) / (cosLightAngle * lightArea)
+	// Include the probability of having selected this light
+	pdfLight := selectPDF * (distanceToLight * distanceToLight) / (cosLightAngle * lightArea)
 
 	// Calculate BRDF PDF
 	wi := rayDirection.Neg().Unit()
@@ -660,13 +837,11 @@ func (c *Camera) sampleAreaLight(
 	pdfBRDF := pdfEval.PDF(wi, wo, hitNormal)
 
 	// MIS weight using balance heuristic: w = pdf_light / (pdf_light + pdf_brdf)
-	weight := pdfLight / (pdfLight + pdfBRDF)
-
-	// Light contribution with MIS weighting
-	contribution := emission.Scale(cosTheta / pdfLight * weight)
+	weight := BalanceHeuristic(pdfLight, pdfBRDF)
 
-	// Multiply by number of lights and material attenuation
-	contribution = contribution.Mult(attenuation).Scale(float64(len(c.Lights)))
+	// Light contribution with MIS weighting (f*cos = attenuation * pdfBRDF)
+	contribution := emission.Scale(pdfBRDF / pdfLight * weight)
+	contribution = contribution.Mult(attenuation)
 
 	// Clamp to prevent fireflies
 	maxComponent := 20.0
@@ -682,27 +857,50 @@ func (c *Camera) sampleAreaLight(
 // =============================================================================
 
 func (c *Camera) Render(world Hittable) {
+	img, err := c.renderImage(world, true)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Fprintln(os.Stderr)
+	c.saveImage(img, "image.png")
+	fmt.Fprintln(os.Stdout, "Done. Image written to image.png")
+}
+
+// RenderToImage renders world single-threaded and returns the image in
+// memory, without printing progress or writing any file
+func (c *Camera) RenderToImage(world Hittable) (*image.RGBA, error) {
+	return c.renderImage(world, false)
+}
+
+// renderImage traces every pixel, optionally drawing a progress bar
+func (c *Camera) renderImage(world Hittable, progress bool) (*image.RGBA, error) {
+	if world == nil {
+		return nil, fmt.Errorf("render: no world to render")
+	}
 	c.Initialize()
+	if c.ImageWidth <= 0 || c.ImageHeight <= 0 {
+		return nil, fmt.Errorf("render: invalid image size %dx%d", c.ImageWidth, c.ImageHeight)
+	}
 
 	img := image.NewRGBA(image.Rect(0, 0, c.ImageWidth, c.ImageHeight))
 
 	const barWidth = 40
 
 	for j := range c.ImageHeight {
-		c.progressBar(j+1, c.ImageHeight, barWidth)
+		if progress {
+			c.progressBar(j+1, c.ImageHeight, barWidth)
+		}
 		for i := range c.ImageWidth {
 			pixelColor := Color{X: 0, Y: 0, Z: 0}
 			for sample := 0; sample < c.SamplesPerPixel; sample++ {
-				r := c.GetRay(i, j)
+				r := c.getRay(i, j, pixelSampler(i, j, 0, sample))
 				pixelColor = pixelColor.Add(c.RayColor(r, c.MaxDepth, world))
 			}
 			c.writeColor(img, i, j, pixelColor)
 		}
 	}
-
-	fmt.Fprintln(os.Stderr)
-	c.saveImage(img, "image.png")
-	fmt.Fprintln(os.Stdout, "Done. Image written to image.png")
+	return img, nil
 }
 
 // =============================================================================
@@ -0,0 +1,139 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// CAMERA RIGS (PARENTING THE CAMERA TO OBJECTS)
+// =============================================================================
+
+// RigParent is anything a camera can be parented to. It places a point given
+// in its local space in the world at a shutter time (0 = open, 1 = close).
+type RigParent interface {
+	LocalToWorld(p Point3, time float64) Point3
+}
+
+// LocalToWorld applies the transform to p: scale, rotate, then translate,
+// like Apply does to an object. A transform doesn't move during the shutter.
+func (t *Transform) LocalToWorld(p Point3, time float64) Point3 {
+	p = Point3{X: p.X * t.Scale.X, Y: p.Y * t.Scale.Y, Z: p.Z * t.Scale.Z}
+	switch {
+	case t.Orientation != nil:
+		p = t.Orientation.Rotate(p)
+	case t.Rotation != (Vec3{}):
+		p = NewQuaternionFromEuler(t.Rotation, t.RotationOrder).Rotate(p)
+	}
+	return p.Add(t.Position)
+}
+
+// LocalToWorld offsets p by the sphere's center at time, so a camera riding
+// a moving sphere moves with it during the shutter
+func (s *Sphere) LocalToWorld(p Point3, time float64) Point3 {
+	return p.Add(s.Center.At(time))
+}
+
+// followRig tracks the position of its parent but not its rotation or scale
+type followRig struct {
+	parent RigParent
+}
+
+// Follow returns a parent that moves with the local origin of parent without
+// turning with it: a follow-cam keeps its offset and heading while the
+// object it follows spins
+func Follow(parent RigParent) RigParent {
+	return followRig{parent: parent}
+}
+
+func (f followRig) LocalToWorld(p Point3, time float64) Point3 {
+	return p.Add(f.parent.LocalToWorld(Point3{}, time))
+}
+
+// Turntable spins whatever is parented to it around an axis through Center,
+// by DegreesPerFrame each frame. Local space is the world at frame 0, so a
+// camera keeps its SetPosition pose there.
+type Turntable struct {
+	Center          Point3
+	Axis            Vec3    // Spin axis (default +Y)
+	StartDegrees    float64 // Angle at frame 0
+	DegreesPerFrame float64
+	ShutterDegrees  float64 // Extra rotation while the shutter is open, for motion blur
+	Frame           int
+}
+
+// NewTurntable returns a turntable around the vertical axis through center.
+// 360/frames degrees per frame gives a loop of that many frames.
+func NewTurntable(center Point3, degreesPerFrame float64) *Turntable {
+	return &Turntable{
+		Center:          center,
+		Axis:            Vec3{X: 0, Y: 1, Z: 0},
+		DegreesPerFrame: degreesPerFrame,
+	}
+}
+
+// SetFrame selects the frame to render
+func (t *Turntable) SetFrame(frame int) *Turntable {
+	t.Frame = frame
+	return t
+}
+
+// SetShutter adds motion blur: degrees of rotation while the shutter is
+// open (e.g. DegreesPerFrame/2 for a 180° shutter)
+func (t *Turntable) SetShutter(degrees float64) *Turntable {
+	t.ShutterDegrees = degrees
+	return t
+}
+
+func (t *Turntable) LocalToWorld(p Point3, time float64) Point3 {
+	degrees := t.StartDegrees + t.DegreesPerFrame*float64(t.Frame) + t.ShutterDegrees*time
+	return t.Center.Add(NewQuaternionAxisAngle(t.Axis, degrees).Rotate(p.Sub(t.Center)))
+}
+
+// cameraRig is the camera's pose in its parent's local space
+type cameraRig struct {
+	parent   RigParent
+	from, at Point3
+	up       Vec3
+	forward  Vec3
+}
+
+// SetParent parents the camera to an object: its current LookFrom, LookAt,
+// Vup (and Forward for a free camera) are taken as its pose in the parent's
+// local space, and from then on follow the parent. If the parent moves
+// while the shutter is open the camera moves with it (replacing SetMotion),
+// so an object-mounted camera sees its mount sharp. Reparenting keeps the
+// camera's world pose; nil unparents it there, without motion.
+func (c *Camera) SetParent(parent RigParent) *Camera {
+	if c.rig != nil {
+		c.applyRig()
+		c.CameraMotion = false
+		c.rig = nil
+	}
+	if parent != nil {
+		c.rig = &cameraRig{parent: parent, from: c.LookFrom, at: c.LookAt, up: c.Vup, forward: c.Forward}
+	}
+	return c
+}
+
+// applyRig places the camera in the world from its parent at shutter open
+// and close
+func (c *Camera) applyRig() {
+	rig := c.rig
+	if rig == nil {
+		return
+	}
+	toWorld := func(p Point3, time float64) Point3 { return rig.parent.LocalToWorld(p, time) }
+	direction := func(from Point3, v Vec3) Vec3 {
+		d := toWorld(from.Add(v), 0).Sub(toWorld(from, 0))
+		if d.Len2() == 0 || math.IsNaN(d.X) {
+			return v
+		}
+		return d.Unit()
+	}
+
+	c.LookFrom, c.LookAt = toWorld(rig.from, 0), toWorld(rig.at, 0)
+	c.Vup = direction(rig.from, rig.up)
+	if c.FreeCamera {
+		c.Forward = direction(rig.from, rig.forward)
+	}
+	c.LookFrom2, c.LookAt2 = toWorld(rig.from, 1), toWorld(rig.at, 1)
+	c.CameraMotion = c.LookFrom2 != c.LookFrom || c.LookAt2 != c.LookAt
+}
@@ -0,0 +1,57 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCameraRidesMovingSphere(t *testing.T) {
+	ball := NewMovingSphere(Point3{X: 0}, Point3{X: 2}, 0.5, NewLambertian(Color{}))
+	camera := NewCamera().SetPosition(Point3{Y: 1, Z: 3}, Point3{}, Vec3{Y: 1}).SetParent(ball)
+	camera.Initialize()
+
+	if camera.LookFrom != (Point3{Y: 1, Z: 3}) || camera.LookFrom2 != (Point3{X: 2, Y: 1, Z: 3}) {
+		t.Errorf("camera at %v -> %v, want to ride the sphere", camera.LookFrom, camera.LookFrom2)
+	}
+	if !camera.CameraMotion || camera.LookAt2 != (Point3{X: 2}) {
+		t.Errorf("camera should follow the sphere over the shutter (look at %v)", camera.LookAt2)
+	}
+
+	// Unparenting keeps the world pose at shutter open
+	camera.SetParent(nil)
+	camera.Initialize()
+	if camera.CameraMotion || camera.LookFrom != (Point3{Y: 1, Z: 3}) {
+		t.Errorf("unparented camera at %v (motion %v)", camera.LookFrom, camera.CameraMotion)
+	}
+}
+
+func TestTurntableAndTransformParents(t *testing.T) {
+	rig := NewTurntable(Point3{}, 90)
+	camera := NewCamera().SetPosition(Point3{Z: 4}, Point3{}, Vec3{Y: 1}).SetParent(rig)
+	rig.SetFrame(1)
+	camera.Initialize()
+	if d := camera.LookFrom.Sub(Point3{X: 4}); d.Len() > 1e-9 {
+		t.Errorf("frame 1 of a 4-frame turntable: camera at %v, want (4, 0, 0)", camera.LookFrom)
+	}
+	if camera.CameraMotion {
+		t.Error("a turntable without shutter rotation should not blur")
+	}
+
+	// A transform scales, rotates and moves the camera's local pose
+	mount := NewTransform().SetRotation(Vec3{Z: 90}).SetPosition(Vec3{X: 5})
+	camera = NewCamera().SetPosition(Point3{X: 1}, Point3{X: 2}, Vec3{Y: 1}).SetParent(mount)
+	camera.Initialize()
+	if d := camera.LookFrom.Sub(Point3{X: 5, Y: 1}); d.Len() > 1e-9 {
+		t.Errorf("camera at %v, want (5, 1, 0)", camera.LookFrom)
+	}
+	if math.Abs(camera.Vup.X+1) > 1e-9 {
+		t.Errorf("up %v, want -X after a 90° roll", camera.Vup)
+	}
+
+	// Follow ignores the rotation
+	camera = NewCamera().SetPosition(Point3{X: 1}, Point3{}, Vec3{Y: 1}).SetParent(Follow(mount))
+	camera.Initialize()
+	if d := camera.LookFrom.Sub(Point3{X: 6}); d.Len() > 1e-9 {
+		t.Errorf("follow-cam at %v, want (6, 0, 0)", camera.LookFrom)
+	}
+}
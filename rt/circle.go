@@ -55,17 +55,12 @@ func (c *Circle) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 	rec.Mat = c.mat
 	rec.SetFaceNormal(r, c.normal)
 
-	var u, v Vec3
+	up := Vec3{X: 0, Y: 1, Z: 0}
 	if math.Abs(c.normal.Y) > 0.9 {
-		u = Cross(Vec3{X: 1, Y: 0, Z: 0}, c.normal).Unit()
-	} else {
-		u = Cross(Vec3{X: 0, Y: 1, Z: 0}, c.normal).Unit()
+		up = Vec3{X: 1, Y: 0, Z: 0}
 	}
-	v = Cross(c.normal, u)
-
-	localPoint := intersection.Sub(c.center)
-	x := Dot(localPoint, u)
-	y := Dot(localPoint, v)
+	local := NewONBWithUp(c.normal, up).ToLocal(intersection.Sub(c.center))
+	x, y := local.X, local.Y
 
 	rec.U = (x/c.radius + 1.0) * 0.5
 	rec.V = (y/c.radius + 1.0) * 0.5
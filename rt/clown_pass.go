@@ -0,0 +1,147 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// CLOWN PASS (RANDOM COLOR PER OBJECT)
+// =============================================================================
+
+// The clown pass paints every scene object a flat random color so
+// overlapping geometry, duplicated instances and stray meshes stand out. An
+// object is an entry of the world list, i.e. a leaf entry of the top-level
+// BVH: a whole mesh or transformed instance, not its individual triangles.
+// IDs follow the depth-first order of the world, so colors stay the same
+// from one render of a scene to the next.
+
+// hitObject intersects r with world like world.Hit, and also returns the
+// top-level object that was hit (nil on a miss)
+func hitObject(world Hittable, r Ray, rayT Interval, rec *HitRecord) Hittable {
+	switch node := world.(type) {
+	case *BVHNode:
+		if !node.bbox.Hit(r, rayT) {
+			return nil
+		}
+		object := hitObject(node.left, r, rayT, rec)
+		if node.right == node.left {
+			return object
+		}
+		if object != nil {
+			rayT.Max = rec.T
+		}
+		if right := hitObject(node.right, r, rayT, rec); right != nil {
+			return right
+		}
+		return object
+	case *BVHLeaf:
+		return hitObjectList(node.objects, r, rayT, rec)
+	case *DynamicBVH:
+		if node.root.left == nil {
+			return nil
+		}
+		return hitObject(node.root, r, rayT, rec)
+	case *HittableList:
+		return hitObjectList(node.Objects, r, rayT, rec)
+	}
+	if world.Hit(r, rayT, rec) {
+		return world
+	}
+	return nil
+}
+
+func hitObjectList(objects []Hittable, r Ray, rayT Interval, rec *HitRecord) Hittable {
+	var hit Hittable
+	for _, obj := range objects {
+		if obj.Hit(r, rayT, rec) {
+			hit = obj
+			rayT.Max = rec.T
+		}
+	}
+	return hit
+}
+
+// ObjectIDs numbers the top-level objects of world in depth-first order
+func ObjectIDs(world Hittable) map[Hittable]int {
+	ids := make(map[Hittable]int)
+	var walk func(Hittable)
+	add := func(objects []Hittable) {
+		for _, obj := range objects {
+			if _, seen := ids[obj]; !seen {
+				ids[obj] = len(ids)
+			}
+		}
+	}
+	walk = func(object Hittable) {
+		switch node := object.(type) {
+		case *BVHNode:
+			walk(node.left)
+			if node.right != node.left {
+				walk(node.right)
+			}
+		case *BVHLeaf:
+			add(node.objects)
+		case *DynamicBVH:
+			add(node.objects)
+		case *HittableList:
+			add(node.Objects)
+		default:
+			add([]Hittable{object})
+		}
+	}
+	walk(world)
+	return ids
+}
+
+// ClownColor returns the linear color of object id: a saturated display
+// color picked by hashing the id, converted to linear so the saved image
+// shows it as chosen
+func ClownColor(id int) Color {
+	// splitmix64 finalizer spreads consecutive ids across the hue circle
+	h := uint64(id) + 0x9e3779b97f4a7c15
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	h ^= h >> 31
+
+	hue := float64(h&0xffff) / 0x10000
+	saturation := 0.55 + 0.4*float64((h>>16)&0xff)/0xff
+	value := 0.75 + 0.25*float64((h>>24)&0xff)/0xff
+	display := hsvToRGB(hue, saturation, value)
+	return Color{X: GammaToLinear(display.X), Y: GammaToLinear(display.Y), Z: GammaToLinear(display.Z)}
+}
+
+// hsvToRGB converts hue in [0, 1) and saturation/value in [0, 1] to RGB
+func hsvToRGB(h, s, v float64) Color {
+	h6 := h * 6
+	sector := math.Floor(h6)
+	f := h6 - sector
+	p, q, t := v*(1-s), v*(1-s*f), v*(1-s*(1-f))
+	switch int(sector) % 6 {
+	case 0:
+		return Color{X: v, Y: t, Z: p}
+	case 1:
+		return Color{X: q, Y: v, Z: p}
+	case 2:
+		return Color{X: p, Y: v, Z: t}
+	case 3:
+		return Color{X: p, Y: q, Z: v}
+	case 4:
+		return Color{X: t, Y: p, Z: v}
+	}
+	return Color{X: v, Y: p, Z: q}
+}
+
+// NewClownPass returns a PixelHook that replaces the render with the clown
+// color of the object under each pixel, shaded by how directly the surface
+// faces the camera so overlapping and intersecting shapes stay readable.
+// Pixels that see the background are black.
+func NewClownPass(camera *Camera, world Hittable) PixelHook {
+	ids := ObjectIDs(world)
+	return func(ctx PixelContext, hdr Color) Color {
+		id, ok := ids[ctx.AOV.Object]
+		if !ctx.AOV.Hit || !ok {
+			return Color{}
+		}
+		view := camera.center.Sub(ctx.AOV.Position).Unit()
+		facing := math.Abs(Dot(ctx.AOV.Normal, view))
+		return ClownColor(id).Scale(0.35 + 0.65*facing)
+	}
+}
@@ -0,0 +1,49 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClownPassIdentifiesTopLevelObjects(t *testing.T) {
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	mesh := NewBVHNode([]Hittable{
+		NewTriangle(Point3{X: -1, Y: -1}, Point3{X: 1, Y: -1}, Point3{Y: 1}, mat),
+		NewTriangle(Point3{X: -1, Y: -1, Z: -0.5}, Point3{X: 1, Y: -1, Z: -0.5}, Point3{Y: 1, Z: -0.5}, mat),
+	}, 0, 2)
+	left := NewTranslate(mesh, Vec3{X: -2})
+	right := NewTranslate(mesh, Vec3{X: 2})
+	sphere := NewSphere(Point3{Z: -5}, 1, mat)
+	world := NewBVHNodeFromList(&HittableList{Objects: []Hittable{left, right, sphere}})
+
+	ids := ObjectIDs(world)
+	if len(ids) != 3 {
+		t.Fatalf("found %d objects, want 3 (two instances sharing a mesh BVH and a sphere)", len(ids))
+	}
+	if ClownColor(ids[left]) == ClownColor(ids[right]) {
+		t.Error("the two instances got the same clown color")
+	}
+
+	for _, tc := range []struct {
+		x    float64
+		want Hittable
+	}{{-2, left}, {2, right}, {0, sphere}} {
+		r := NewRay(Point3{X: tc.x, Z: 5}, Vec3{Z: -1}, 0)
+		var rec, ref HitRecord
+		got := hitObject(world, r, NewInterval(0.001, math.Inf(1)), &rec)
+		if got != tc.want {
+			t.Errorf("ray at x=%g hit object %d, want %d", tc.x, ids[got], ids[tc.want])
+		}
+		if !world.Hit(r, NewInterval(0.001, math.Inf(1)), &ref) || ref.T != rec.T {
+			t.Errorf("ray at x=%g: hitObject t=%g, world.Hit t=%g", tc.x, rec.T, ref.T)
+		}
+	}
+
+	// Rebuilding the same scene gives the same ids
+	again := ObjectIDs(NewBVHNodeFromList(&HittableList{Objects: []Hittable{left, right, sphere}}))
+	for obj, id := range ids {
+		if again[obj] != id {
+			t.Errorf("object id changed across BVH builds: %d -> %d", id, again[obj])
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package rt
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// =============================================================================
+// A/B COMPARISON
+// =============================================================================
+
+// CompareMode selects how the viewer shows a reference image (A) against
+// the current framebuffer (B)
+type CompareMode int
+
+const (
+	CompareOff        CompareMode = iota
+	CompareWipe                   // A left of a movable divider, B right of it
+	CompareDifference             // |A - B|, amplified
+)
+
+var compareModeNames = []string{"off", "wipe", "difference"}
+
+func (m CompareMode) String() string {
+	if int(m) < 0 || int(m) >= len(compareModeNames) {
+		return "unknown"
+	}
+	return compareModeNames[m]
+}
+
+// ParseCompareMode converts a mode name (e.g. "wipe") to a CompareMode
+func ParseCompareMode(name string) (CompareMode, error) {
+	for i, n := range compareModeNames {
+		if strings.EqualFold(name, n) {
+			return CompareMode(i), nil
+		}
+	}
+	return CompareOff, fmt.Errorf("unknown compare mode: %s (use %s)",
+		name, strings.Join(compareModeNames, ", "))
+}
+
+const (
+	compareDifferenceGain = 8  // Difference mode amplification
+	compareDividerStep    = 16 // Pixels the divider moves per arrow key press
+)
+
+// comparison holds the A side of an A/B comparison. Without a loaded image,
+// A is the framebuffer as it was at the end of the previous pass (or when
+// the keep key was last pressed).
+type comparison struct {
+	mode      CompareMode
+	reference *image.RGBA // A; nil until a pass completes or an image is loaded
+	label     string
+	pinned    bool // reference was loaded or kept by hand; passes don't replace it
+	divider   int  // Wipe position in pixels
+	dragging  bool
+	composite *image.RGBA
+}
+
+func newComparison(width int) *comparison {
+	return &comparison{divider: width / 2}
+}
+
+// LoadCompareImage loads a previous render as the A side of the A/B
+// comparison. It must match the render resolution.
+func (r *BucketRenderer) LoadCompareImage(filename string) error {
+	img, err := decodeFrame(filename)
+	if err != nil {
+		return err
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() != r.camera.ImageWidth || bounds.Dy() != r.camera.ImageHeight {
+		return fmt.Errorf("%s is %dx%d, the render is %dx%d",
+			filename, bounds.Dx(), bounds.Dy(), r.camera.ImageWidth, r.camera.ImageHeight)
+	}
+	reference := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(reference, reference.Bounds(), img, bounds.Min, draw.Src)
+	r.compare.reference, r.compare.label, r.compare.pinned = reference, filename, true
+	return nil
+}
+
+// SetCompareMode sets the initial A/B mode (cycle with the compare key)
+func (r *BucketRenderer) SetCompareMode(mode CompareMode) *BucketRenderer {
+	r.compare.mode = mode
+	return r
+}
+
+// keepPass snapshots the framebuffer as A at the end of a pass, unless A was
+// loaded or kept by hand. Call with r.mu held.
+func (c *comparison) keepPass(framebuffer *image.RGBA, pass int) {
+	if !c.pinned {
+		c.reference = cloneRGBA(framebuffer, c.reference)
+		c.label = fmt.Sprintf("pass %d", pass+1)
+	}
+}
+
+// keepCurrent pins the framebuffer as A. Call with r.mu held.
+func (c *comparison) keepCurrent(framebuffer *image.RGBA) {
+	c.reference = cloneRGBA(framebuffer, c.reference)
+	c.label = "kept frame"
+	c.pinned = true
+}
+
+// handleInput applies the compare hotkeys and divider dragging
+func (c *comparison) handleInput(overlay OverlayConfig, width int) {
+	if inpututil.IsKeyJustPressed(overlay.CompareKey) {
+		c.mode = (c.mode + 1) % CompareMode(len(compareModeNames))
+	}
+	if c.mode != CompareWipe {
+		c.dragging = false
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		c.divider -= compareDividerStep
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		c.divider += compareDividerStep
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		c.dragging = true
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		c.dragging = false
+	}
+	if c.dragging {
+		c.divider, _ = ebiten.CursorPosition()
+	}
+	c.divider = min(max(c.divider, 0), width)
+}
+
+// active reports whether the comparison replaces the plain framebuffer
+func (c *comparison) active() bool {
+	return c.mode != CompareOff && c.reference != nil
+}
+
+// image returns the framebuffer composed with A for the current mode. Call
+// with r.mu held.
+func (c *comparison) image(framebuffer *image.RGBA) *image.RGBA {
+	if c.composite == nil || c.composite.Bounds() != framebuffer.Bounds() {
+		c.composite = image.NewRGBA(framebuffer.Bounds())
+	}
+	composeComparison(c.composite, c.reference, framebuffer, c.mode, c.divider)
+	return c.composite
+}
+
+// drawDivider marks the wipe position
+func (c *comparison) drawDivider(screen *ebiten.Image) {
+	if c.mode == CompareWipe {
+		h := float32(screen.Bounds().Dy())
+		vector.FillRect(screen, float32(c.divider)-1, 0, 2, h, color.RGBA{R: 255, G: 255, B: 255, A: 200}, false)
+	}
+}
+
+// line returns the overlay text for the comparison
+func (c *comparison) line() string {
+	if c.reference == nil {
+		return fmt.Sprintf("A/B %s: no reference yet (wait for a pass or keep a frame)", c.mode)
+	}
+	if c.mode == CompareDifference {
+		return fmt.Sprintf("A/B difference x%d | A: %s | B: current", compareDifferenceGain, c.label)
+	}
+	return fmt.Sprintf("A/B wipe | A (left): %s | B (right): current", c.label)
+}
+
+// composeComparison writes a and b composed for mode into dst: a left of
+// divider in wipe mode, or the amplified per-channel difference
+func composeComparison(dst, a, b *image.RGBA, mode CompareMode, divider int) {
+	bounds := b.Bounds()
+	if mode != CompareDifference {
+		copy(dst.Pix, b.Pix)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			start := a.PixOffset(bounds.Min.X, y)
+			end := a.PixOffset(bounds.Min.X+min(max(divider, 0), bounds.Dx()), y)
+			copy(dst.Pix[start:end], a.Pix[start:end])
+		}
+		return
+	}
+	for i := 0; i < len(b.Pix); i += 4 {
+		for ch := 0; ch < 3; ch++ {
+			diff := int(a.Pix[i+ch]) - int(b.Pix[i+ch])
+			dst.Pix[i+ch] = uint8(min(abs(diff)*compareDifferenceGain, 255))
+		}
+		dst.Pix[i+3] = 255
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// cloneRGBA copies src into dst (reused when it has the same size)
+func cloneRGBA(src, dst *image.RGBA) *image.RGBA {
+	if dst == nil || dst.Bounds() != src.Bounds() {
+		dst = image.NewRGBA(src.Bounds())
+	}
+	copy(dst.Pix, src.Pix)
+	return dst
+}
@@ -0,0 +1,46 @@
+package rt
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestComposeComparison(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	b := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			a.SetRGBA(x, y, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+			b.SetRGBA(x, y, color.RGBA{R: 110, G: 90, B: 100, A: 255})
+		}
+	}
+	dst := image.NewRGBA(a.Bounds())
+
+	composeComparison(dst, a, b, CompareWipe, 1)
+	for y := 0; y < 2; y++ {
+		if got := dst.RGBAAt(0, y); got != a.RGBAAt(0, y) {
+			t.Errorf("wipe (0, %d) = %v, want A", y, got)
+		}
+		if got := dst.RGBAAt(1, y); got != b.RGBAAt(1, y) {
+			t.Errorf("wipe (1, %d) = %v, want B", y, got)
+		}
+	}
+
+	composeComparison(dst, a, b, CompareDifference, 0)
+	want := color.RGBA{R: 10 * compareDifferenceGain, G: 10 * compareDifferenceGain, A: 255}
+	if got := dst.RGBAAt(2, 1); got != want {
+		t.Errorf("difference = %v, want %v", got, want)
+	}
+}
+
+func TestParseCompareMode(t *testing.T) {
+	for _, name := range compareModeNames {
+		if mode, err := ParseCompareMode(name); err != nil || mode.String() != name {
+			t.Errorf("ParseCompareMode(%q) = %v, %v", name, mode, err)
+		}
+	}
+	if _, err := ParseCompareMode("split"); err == nil {
+		t.Error("accepted an unknown mode")
+	}
+}
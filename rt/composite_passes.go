@@ -0,0 +1,211 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// SHADOW AND REFLECTION PASSES
+// =============================================================================
+
+// CompositePassConfig selects the objects of the shadow-only and
+// reflection-only passes used to put CG onto a live-action plate: the shadows
+// the CG casts onto a stand-in for the real ground, and the CG reflected in
+// it. Objects are top-level world objects, matched by identity. They are
+// found even when hidden from the camera, as such stand-ins usually are (see
+// HiddenFromCamera).
+type CompositePassConfig struct {
+	ShadowReceivers []Hittable // Objects whose received shadows make the shadow pass
+	Reflectors      []Hittable // Objects whose reflections make the reflection pass
+	Samples         int        // Samples per pixel (0 = the camera's SPP)
+}
+
+// SelectObjects returns the top-level objects of world at a comma-separated
+// list of indices in scene order (e.g. "0,3"). An empty list selects none.
+func SelectObjects(world *HittableList, list string) ([]Hittable, error) {
+	var objects []Hittable
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		i, err := strconv.Atoi(field)
+		if err != nil || i < 0 || i >= len(world.Objects) {
+			return nil, fmt.Errorf("invalid object index: %s (the scene has objects 0-%d)", field, len(world.Objects)-1)
+		}
+		objects = append(objects, world.Objects[i])
+	}
+	return objects, nil
+}
+
+// compositeHit finds the sample's first hit on one of objects, unless a
+// camera-visible object is in front of it
+func (c *Camera) compositeHit(world Hittable, objects []Hittable, ray Ray, rec *HitRecord) bool {
+	rayT := c.rayInterval(ray)
+	front := &HitRecord{}
+	if world.Hit(ray, rayT, front) {
+		rayT.Max = front.T + c.rayEpsilon()
+	}
+	return hitObjectList(objects, ray.withKind(raySecondary), rayT, rec) != nil
+}
+
+// TraceShadowPass renders the shadow matte of receivers: 1 where the rest of
+// the world blocks all direct light from reaching them, 0 where it blocks
+// none, per channel so colored lights give colored shadows. Each sample
+// compares the direct light of a white diffuse surface with and without the
+// other objects, using the same light sample for both. Shadows the
+// receivers cast on each other belong to the plate and are left out. Pixels
+// not covered by a receiver are 0.
+func (c *Camera) TraceShadowPass(world Hittable, receivers []Hittable, samples int) *Film {
+	film := NewFilm(c.ImageWidth, c.ImageHeight)
+	if len(receivers) == 0 || c.lightSampler == nil || c.lightSampler.Len() == 0 {
+		return film
+	}
+	receiverWorld := NewHittableList()
+	for _, obj := range receivers {
+		receiverWorld.Add(obj)
+	}
+	white := Color{X: 1, Y: 1, Z: 1}
+	matte := NewLambertian(white)
+	seed, _ := RandomSeed()
+
+	parallelRows(c.ImageHeight, func(y int) {
+		for x := 0; x < c.ImageWidth; x++ {
+			var lit, unshadowed Color
+			for sample := 0; sample < samples; sample++ {
+				ray := c.getRay(x, y, NewSampler(seed, x, y, -1, sample))
+				rec := &HitRecord{}
+				if !c.compositeHit(world, receivers, ray, rec) {
+					continue
+				}
+				// The same stream draws the same light sample for both
+				shadowed, _ := c.sampleLightMIS(rec.P, rec.Normal, ray.Direction(), world,
+					white, matte, nil, NewSampler(seed, x, y, -2, sample))
+				open, _ := c.sampleLightMIS(rec.P, rec.Normal, ray.Direction(), receiverWorld,
+					white, matte, nil, NewSampler(seed, x, y, -2, sample))
+				lit = lit.Add(shadowed)
+				unshadowed = unshadowed.Add(open)
+			}
+			film.AddSamples(x, y, shadowMatte(lit, unshadowed), 1)
+		}
+	})
+	return film
+}
+
+// shadowMatte returns the share of the unshadowed light that was blocked
+func shadowMatte(lit, unshadowed Color) Color {
+	channel := func(l, u float64) float64 {
+		if u <= 0 {
+			return 0
+		}
+		return clampFloat(1-l/u, 0, 1)
+	}
+	return Color{
+		X: channel(lit.X, unshadowed.X),
+		Y: channel(lit.Y, unshadowed.Y),
+		Z: channel(lit.Z, unshadowed.Z),
+	}
+}
+
+// TraceReflectionPass renders what reflectors pick up from the rest of the
+// scene: each sample scatters off the reflector's material once and keeps
+// the fully shaded radiance of the object it reaches. Rays that escape to
+// the background add nothing, since the plate already holds the real
+// surroundings. Glossy reflectors give reflections, diffuse ones the bounce
+// light the CG throws onto them. Pixels not covered by a reflector are 0.
+func (c *Camera) TraceReflectionPass(world Hittable, reflectors []Hittable, samples int) *Film {
+	film := NewFilm(c.ImageWidth, c.ImageHeight)
+	if len(reflectors) == 0 {
+		return film
+	}
+	seed, _ := RandomSeed()
+
+	parallelRows(c.ImageHeight, func(y int) {
+		for x := 0; x < c.ImageWidth; x++ {
+			var sum Color
+			for sample := 0; sample < samples; sample++ {
+				s := NewSampler(seed, x, y, -1, sample)
+				ray := c.getRay(x, y, s)
+				rec := &HitRecord{}
+				if !c.compositeHit(world, reflectors, ray, rec) {
+					continue
+				}
+				var attenuation Color
+				var scattered Ray
+				if !rec.Mat.Scatter(ray, rec, &attenuation, &scattered) {
+					continue
+				}
+				scattered.sampler = s
+				if !world.Hit(scattered, NewInterval(c.rayEpsilon(), math.Inf(1)), &HitRecord{}) {
+					continue
+				}
+				radiance := attenuation.Mult(c.rayColorInternal(scattered, c.MaxDepth-1, world, true, 0, pathState{}))
+				if isFiniteColor(radiance) {
+					sum = sum.Add(radiance)
+				}
+			}
+			film.AddSamples(x, y, sum, samples)
+		}
+	})
+	return film
+}
+
+// parallelRows calls fn for every row on all CPUs
+func parallelRows(height int, fn func(y int)) {
+	rows := make(chan int, height)
+	for y := 0; y < height; y++ {
+		rows <- y
+	}
+	close(rows)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				fn(y)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// SetCompositePasses saves the shadow pass as image_shadow.pfm and the
+// reflection pass as image_reflection.pfm when the render finishes, for the
+// objects config selects
+func (r *BucketRenderer) SetCompositePasses(config CompositePassConfig) *BucketRenderer {
+	r.composite = config
+	return r
+}
+
+// saveCompositePasses traces and writes the configured passes
+func (r *BucketRenderer) saveCompositePasses() {
+	config := r.composite
+	samples := config.Samples
+	if samples <= 0 {
+		samples = max(r.camera.SamplesPerPixel, 1)
+	}
+	passes := []struct {
+		name    string
+		objects []Hittable
+		trace   func(Hittable, []Hittable, int) *Film
+	}{
+		{"shadow", config.ShadowReceivers, r.camera.TraceShadowPass},
+		{"reflection", config.Reflectors, r.camera.TraceReflectionPass},
+	}
+	for _, pass := range passes {
+		if len(pass.objects) == 0 {
+			continue
+		}
+		filename := fmt.Sprintf("image_%s.pfm", pass.name)
+		if err := pass.trace(r.world, pass.objects, samples).SavePFM(filename); err != nil {
+			fmt.Printf("⚠ Could not save the %s pass: %v\n", pass.name, err)
+			continue
+		}
+		fmt.Printf("✓ %s pass saved to %s\n", pass.name, filename)
+	}
+}
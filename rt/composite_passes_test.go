@@ -0,0 +1,82 @@
+package rt
+
+import "testing"
+
+// compositeScene is a red sphere on a stand-in ground hidden from the camera,
+// lit by an area light to its left
+func compositeScene(ground Material) (*HittableList, *Camera, Hittable) {
+	world := NewHittableList()
+	floor := HiddenFromCamera(NewQuad(Point3{X: -10, Z: -10}, Vec3{X: 20}, Vec3{Z: 20}, ground))
+	world.Add(floor)
+	world.Add(NewSphere(Point3{Y: 0.5}, 0.5, NewLambertian(Color{X: 0.8, Y: 0.1, Z: 0.1})))
+	light := NewQuad(Point3{X: -3, Y: 3, Z: -0.5}, Vec3{X: 1}, Vec3{Z: 1}, NewDiffuseLightColor(Color{X: 15, Y: 15, Z: 15}))
+	world.Add(light)
+
+	camera := NewCameraBuilder().SetResolution(32, 1).SetQuality(16, 4).
+		SetPosition(Point3{Y: 2, Z: 4}, Point3{Y: 0.3}, Vec3{Y: 1}).
+		SetBackground(Color{X: 0.5, Y: 0.6, Z: 0.8}).Build()
+	camera.AddLight(light)
+	camera.Initialize()
+	return world, camera, floor
+}
+
+func TestShadowPass(t *testing.T) {
+	SeedRandom(4)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	world, camera, floor := compositeScene(NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))
+
+	film := camera.TraceShadowPass(world, []Hittable{floor}, 16)
+	darkest, lit := 0.0, 0
+	for y := 0; y < film.Height(); y++ {
+		for x := 0; x < film.Width(); x++ {
+			v := film.Resolve(x, y)
+			if v.X < 0 || v.X > 1 {
+				t.Fatalf("pixel (%d, %d) matte %v outside [0, 1]", x, y, v)
+			}
+			darkest = max(darkest, v.X)
+			if v.X == 0 {
+				lit++
+			}
+		}
+	}
+	if darkest < 0.9 {
+		t.Errorf("strongest shadow = %v, want the sphere's umbra near 1", darkest)
+	}
+	if lit < film.Width()*film.Height()/2 {
+		t.Errorf("%d shadow-free pixels, want most of the image free of shadow", lit)
+	}
+}
+
+func TestReflectionPass(t *testing.T) {
+	SeedRandom(4)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	world, camera, floor := compositeScene(NewMetal(Color{X: 0.9, Y: 0.9, Z: 0.9}, 0))
+
+	film := camera.TraceReflectionPass(world, []Hittable{floor}, 4)
+	red := 0
+	for y := 0; y < film.Height(); y++ {
+		for x := 0; x < film.Width(); x++ {
+			if v := film.Resolve(x, y); v.X > 2*v.Z {
+				red++
+			}
+		}
+	}
+	if red == 0 {
+		t.Error("the red sphere is not reflected in the ground")
+	}
+	// The near corner reflects only sky, which the plate provides
+	if v := film.Resolve(0, film.Height()-1); v != (Color{}) {
+		t.Errorf("corner reflection = %v, want black", v)
+	}
+}
+
+func TestSelectObjects(t *testing.T) {
+	world, _, floor := compositeScene(NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))
+	objects, err := SelectObjects(world, " 0, 2")
+	if err != nil || len(objects) != 2 || objects[0] != floor {
+		t.Errorf("SelectObjects = %v, %v", objects, err)
+	}
+	if _, err := SelectObjects(world, "3"); err == nil {
+		t.Error("an index past the scene's objects should fail")
+	}
+}
@@ -0,0 +1,125 @@
+package rt
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"runtime"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// =============================================================================
+// CONTACT SHEET
+// =============================================================================
+
+// contactSheetLabelHeight is the height of the name strip under each thumbnail
+const contactSheetLabelHeight = 18
+
+// ContactSheetConfig controls RenderContactSheet. Every scene is rendered at
+// draft quality into a CellWidth x CellWidth*3/4 cell, letterboxed to keep
+// its aspect ratio, with its name underneath.
+type ContactSheetConfig struct {
+	CellWidth int // Thumbnail cell width in pixels
+	Columns   int // Cells per row (0 = about square sheet)
+	Samples   int // Samples per pixel of each thumbnail
+	MaxDepth  int // Max bounces of each thumbnail
+	Workers   int // Render workers per thumbnail
+}
+
+// DefaultContactSheetConfig returns 240 px cells at 16 samples and 8 bounces
+// on all CPUs
+func DefaultContactSheetConfig() ContactSheetConfig {
+	return ContactSheetConfig{
+		CellWidth: 240,
+		Samples:   16,
+		MaxDepth:  8,
+		Workers:   runtime.NumCPU(),
+	}
+}
+
+// ContactSheetScene is one named scene of a contact sheet
+type ContactSheetScene struct {
+	Name  string
+	Build func() (*HittableList, *Camera, error)
+}
+
+// RenderContactSheet renders scenes into one tiled image in order. A scene
+// that fails to build, with an error or a panic, gets a red cell and its
+// error is part of the returned error; the sheet is still complete.
+func RenderContactSheet(scenes []ContactSheetScene, config ContactSheetConfig) (*image.RGBA, error) {
+	if len(scenes) == 0 {
+		return nil, fmt.Errorf("contact sheet: no scenes")
+	}
+	cellW := max(config.CellWidth, 16)
+	cellH := cellW * 3 / 4
+	columns := config.Columns
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(scenes)))))
+	}
+	rows := (len(scenes) + columns - 1) / columns
+	rowH := cellH + contactSheetLabelHeight
+
+	sheet := image.NewRGBA(image.Rect(0, 0, columns*cellW, rows*rowH))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.RGBA{R: 24, G: 24, B: 24, A: 255}), image.Point{}, draw.Src)
+
+	var errs []error
+	for i, scene := range scenes {
+		fmt.Printf("Contact sheet: %s (%d/%d)\n", scene.Name, i+1, len(scenes))
+		cell := image.Rect(0, 0, cellW, cellH).Add(image.Pt(i%columns*cellW, i/columns*rowH))
+		thumb, err := renderThumbnail(scene, cellW, cellH, config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", scene.Name, err))
+			draw.Draw(sheet, cell, image.NewUniform(color.RGBA{R: 160, G: 24, B: 24, A: 255}), image.Point{}, draw.Src)
+		} else {
+			// Center the thumbnail in its cell
+			offset := image.Pt((cellW-thumb.Bounds().Dx())/2, (cellH-thumb.Bounds().Dy())/2)
+			draw.Draw(sheet, thumb.Bounds().Add(cell.Min).Add(offset), thumb, image.Point{}, draw.Over)
+		}
+		// Long names are clipped to the cell
+		label := image.Rect(cell.Min.X, cell.Max.Y, cell.Max.X, cell.Max.Y+contactSheetLabelHeight)
+		drawLabel(sheet.SubImage(label).(*image.RGBA), scene.Name, cell.Min.X+4, label.Max.Y-5)
+	}
+	return sheet, errors.Join(errs...)
+}
+
+// renderThumbnail renders one scene at the largest size that fits the cell.
+// Panics while building and setting up are returned as errors.
+func renderThumbnail(scene ContactSheetScene, cellW, cellH int, config ContactSheetConfig) (img *image.RGBA, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			img, err = nil, fmt.Errorf("panic: %v", p)
+		}
+	}()
+	world, camera, err := scene.Build()
+	if err != nil {
+		return nil, err
+	}
+	if world == nil || camera == nil {
+		return nil, fmt.Errorf("scene has no world or camera")
+	}
+	camera.ImageWidth = max(1, min(cellW, int(float64(cellH)*camera.AspectRatio)))
+	camera.SamplesPerPixel = max(1, config.Samples)
+	camera.MaxDepth = max(1, config.MaxDepth)
+	camera.Initialize()
+
+	workers := max(1, config.Workers)
+	bucketSize := AutoBucketSize(camera.ImageWidth, camera.ImageHeight, workers)
+	return NewBucketRenderer(camera, NewBVHNodeFromList(world), bucketSize, workers).RenderToImage()
+}
+
+// drawLabel writes text in white with its baseline at (x, y)
+func drawLabel(img draw.Image, text string, x, y int) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.White),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
@@ -0,0 +1,52 @@
+package rt
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestRenderContactSheet(t *testing.T) {
+	t.Chdir(t.TempDir())
+	scenes := []ContactSheetScene{
+		{Name: "sphere", Build: func() (*HittableList, *Camera, error) {
+			world, camera := renderImageScene()
+			return world, camera, nil
+		}},
+		{Name: "missing", Build: func() (*HittableList, *Camera, error) {
+			return nil, nil, errors.New("no such scene")
+		}},
+		{Name: "broken", Build: func() (*HittableList, *Camera, error) {
+			panic("bad asset")
+		}},
+	}
+	config := DefaultContactSheetConfig()
+	config.CellWidth = 32
+	config.Samples = 2
+	config.Workers = 2
+
+	sheet, err := RenderContactSheet(scenes, config)
+	if sheet == nil {
+		t.Fatal(err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "missing: no such scene") || !strings.Contains(err.Error(), "broken: panic") {
+		t.Errorf("error %v should name both failed scenes", err)
+	}
+
+	// Two columns of 32x24 cells with 18 px labels, two rows
+	if b := sheet.Bounds(); b.Dx() != 64 || b.Dy() != 2*(24+contactSheetLabelHeight) {
+		t.Fatalf("sheet is %v", b)
+	}
+	// The 32x16 thumbnail is centered vertically; failed cells are red
+	if c := sheet.RGBAAt(1, 12); c.B <= c.R {
+		t.Errorf("thumbnail edge %v should be the blue background", c)
+	}
+	if c := sheet.RGBAAt(1, 1); c != (color.RGBA{R: 24, G: 24, B: 24, A: 255}) {
+		t.Errorf("letterbox %v should be the sheet background", c)
+	}
+	red := color.RGBA{R: 160, G: 24, B: 24, A: 255}
+	if c := sheet.RGBAAt(48, 12); c != red {
+		t.Errorf("failed cell is %v, want red", c)
+	}
+}
@@ -0,0 +1,186 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"os"
+)
+
+// =============================================================================
+// DEFORMING MESHES (DEFORMATION MOTION BLUR)
+// =============================================================================
+
+// VertexTimeline supplies the vertex positions of a mesh at a frame. Every
+// frame must return the same number of vertices in the same order, so a fixed
+// face list can be shared across the whole animation.
+type VertexTimeline func(frame int) ([]Point3, error)
+
+// DeformingTriangle is a triangle whose vertices move linearly from the
+// shutter-open pose (time 0) to the shutter-close pose (time 1). Unlike a
+// Transform or moving sphere, each vertex moves independently.
+type DeformingTriangle struct {
+	open, close [3]Point3 // Vertex positions at time 0 and time 1
+	mat         Material
+	bbox        AABB // Bounds of both poses (vertices move linearly in between)
+}
+
+// NewDeformingTriangle creates a triangle that deforms from (a0, b0, c0)
+// at time 0 to (a1, b1, c1) at time 1
+func NewDeformingTriangle(a0, b0, c0, a1, b1, c1 Point3, mat Material) *DeformingTriangle {
+	t := &DeformingTriangle{
+		open:  [3]Point3{a0, b0, c0},
+		close: [3]Point3{a1, b1, c1},
+		mat:   mat,
+	}
+	t.bbox = NewAABBFromBoxes(triangleBounds(a0, b0, c0), triangleBounds(a1, b1, c1))
+	return t
+}
+
+// triangleBounds returns the box around three points
+func triangleBounds(a, b, c Point3) AABB {
+	return NewAABBFromPoints(
+		Point3{X: math.Min(a.X, math.Min(b.X, c.X)), Y: math.Min(a.Y, math.Min(b.Y, c.Y)), Z: math.Min(a.Z, math.Min(b.Z, c.Z))},
+		Point3{X: math.Max(a.X, math.Max(b.X, c.X)), Y: math.Max(a.Y, math.Max(b.Y, c.Y)), Z: math.Max(a.Z, math.Max(b.Z, c.Z))},
+	)
+}
+
+func (t *DeformingTriangle) BoundingBox() AABB {
+	return t.bbox
+}
+
+// vertices returns the triangle's pose at a shutter time in [0, 1]
+func (t *DeformingTriangle) vertices(time float64) (Point3, Point3, Point3) {
+	lerp := func(a, b Point3) Point3 {
+		return a.Add(b.Sub(a).Scale(time))
+	}
+	return lerp(t.open[0], t.close[0]), lerp(t.open[1], t.close[1]), lerp(t.open[2], t.close[2])
+}
+
+// Hit interpolates the vertices to the ray time and intersects the resulting
+// triangle with Möller-Trumbore
+func (t *DeformingTriangle) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	v0, v1, v2 := t.vertices(r.Time())
+	edge1 := v1.Sub(v0)
+	edge2 := v2.Sub(v0)
+
+	h := Cross(r.Direction(), edge2)
+	a := Dot(edge1, h)
+	if math.Abs(a) < 1e-8 {
+		return false
+	}
+
+	f := 1.0 / a
+	s := r.Origin().Sub(v0)
+	u := f * Dot(s, h)
+	if u < 0.0 || u > 1.0 {
+		return false
+	}
+
+	q := Cross(s, edge1)
+	v := f * Dot(r.Direction(), q)
+	if v < 0.0 || u+v > 1.0 {
+		return false
+	}
+
+	hitT := f * Dot(edge2, q)
+	if !rayT.Contains(hitT) {
+		return false
+	}
+
+	rec.T = hitT
+	rec.P = r.At(hitT)
+	rec.Mat = t.mat
+	rec.SetFaceNormal(r, Cross(edge1, edge2).Unit())
+	rec.U = u
+	rec.V = v
+
+	return true
+}
+
+// NewDeformingMesh builds a BVH of triangles that deform from the open
+// positions (time 0) to the close positions (time 1). Both position sets index
+// the same faces, e.g. the same character mesh on two consecutive frames.
+func NewDeformingMesh(faces [][3]int, open, close []Point3, material Material) (Hittable, error) {
+	if len(open) != len(close) {
+		return nil, fmt.Errorf("deforming mesh: %d open vertices but %d close vertices", len(open), len(close))
+	}
+	if len(faces) == 0 {
+		return nil, fmt.Errorf("deforming mesh has no faces")
+	}
+
+	triangles := make([]Hittable, 0, len(faces))
+	for i, f := range faces {
+		for _, index := range f {
+			if index < 0 || index >= len(open) {
+				return nil, fmt.Errorf("deforming mesh: face %d: vertex index %d out of bounds", i, index)
+			}
+		}
+		triangles = append(triangles, NewDeformingTriangle(
+			open[f[0]], open[f[1]], open[f[2]],
+			close[f[0]], close[f[1]], close[f[2]],
+			material,
+		))
+	}
+	return newMeshBVH(triangles), nil
+}
+
+// NewDeformingMeshAtFrame builds the deforming mesh for one rendered frame:
+// the shutter opens on timeline(frame) and closes on timeline(frame+1)
+func NewDeformingMeshAtFrame(faces [][3]int, timeline VertexTimeline, frame int, material Material) (Hittable, error) {
+	open, err := timeline(frame)
+	if err != nil {
+		return nil, fmt.Errorf("deforming mesh: frame %d: %w", frame, err)
+	}
+	close, err := timeline(frame + 1)
+	if err != nil {
+		return nil, fmt.Errorf("deforming mesh: frame %d: %w", frame+1, err)
+	}
+	return NewDeformingMesh(faces, open, close, material)
+}
+
+// LoadOBJDeformation loads two topology-identical OBJ files (the same mesh
+// posed at shutter open and shutter close) and returns a deforming mesh
+func LoadOBJDeformation(openFile, closeFile string, material Material) (Hittable, error) {
+	open, err := readOBJMesh(openFile)
+	if err != nil {
+		return nil, err
+	}
+	close, err := readOBJMesh(closeFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := sameTopology(open, close); err != nil {
+		return nil, fmt.Errorf("%s and %s: %w", openFile, closeFile, err)
+	}
+	return NewDeformingMesh(open.faces, open.vertices, close.vertices, material)
+}
+
+func readOBJMesh(filename string) (*meshData, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OBJ file: %w", err)
+	}
+	defer file.Close()
+
+	mesh, err := parseOBJMesh(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return mesh, nil
+}
+
+// sameTopology reports whether two meshes share vertex count and face list
+func sameTopology(a, b *meshData) error {
+	if len(a.vertices) != len(b.vertices) {
+		return fmt.Errorf("vertex count differs (%d vs %d)", len(a.vertices), len(b.vertices))
+	}
+	if len(a.faces) != len(b.faces) {
+		return fmt.Errorf("face count differs (%d vs %d)", len(a.faces), len(b.faces))
+	}
+	for i := range a.faces {
+		if a.faces[i] != b.faces[i] {
+			return fmt.Errorf("face %d differs", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package rt
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeformingTriangleFollowsRayTime(t *testing.T) {
+	// A unit triangle at x in [0, 1] that slides to x in [2, 3] over the shutter
+	tri := NewDeformingTriangle(
+		Point3{X: 0, Y: 0, Z: 0}, Point3{X: 1, Y: 0, Z: 0}, Point3{X: 0, Y: 1, Z: 0},
+		Point3{X: 2, Y: 0, Z: 0}, Point3{X: 3, Y: 0, Z: 0}, Point3{X: 2, Y: 1, Z: 0},
+		NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}),
+	)
+
+	tests := []struct {
+		x, time float64
+		hit     bool
+	}{
+		{0.25, 0, true},
+		{0.25, 1, false},
+		{2.25, 1, true},
+		{2.25, 0, false},
+		{1.25, 0.5, true},
+	}
+	for _, tt := range tests {
+		var rec HitRecord
+		r := NewRay(Point3{X: tt.x, Y: 0.25, Z: 1}, Vec3{Z: -1}, tt.time)
+		if got := tri.Hit(r, NewInterval(0.001, math.Inf(1)), &rec); got != tt.hit {
+			t.Errorf("x=%g time=%g: hit=%v, want %v", tt.x, tt.time, got, tt.hit)
+		}
+	}
+
+	box := tri.BoundingBox()
+	if box.X.Min > 0 || box.X.Max < 3 {
+		t.Errorf("bounding box X = [%g, %g], want to cover both poses [0, 3]", box.X.Min, box.X.Max)
+	}
+}
+
+func TestLoadOBJDeformationRejectsTopologyMismatch(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	open := write("open.obj", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n")
+	moved := write("moved.obj", "v 0 0 1\nv 1 0 1\nv 0 1 1\nf 1 2 3\n")
+	flipped := write("flipped.obj", "v 0 0 1\nv 1 0 1\nv 0 1 1\nf 1 3 2\n")
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+
+	if _, err := LoadOBJDeformation(open, moved, mat); err != nil {
+		t.Fatalf("matching topology: %v", err)
+	}
+	if _, err := LoadOBJDeformation(open, flipped, mat); err == nil {
+		t.Fatal("expected an error for differing face lists")
+	}
+}
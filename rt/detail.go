@@ -0,0 +1,115 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// PROCEDURAL MICRO-DETAIL
+// =============================================================================
+
+// DetailConfig controls the noise-driven surface detail added by WithDetail.
+// Scales are noise frequencies in world units (higher = finer detail).
+type DetailConfig struct {
+	BumpScale       float64 // Frequency of the bump pattern
+	BumpStrength    float64 // How far normals tilt (0 disables bump)
+	RoughnessScale  float64 // Frequency of the roughness variation
+	RoughnessAmount float64 // Max fuzz offset for rough materials (0 disables)
+}
+
+// DefaultDetailConfig returns a subtle plaster-like detail suitable for walls
+func DefaultDetailConfig() DetailConfig {
+	return DetailConfig{
+		BumpScale:       0.05,
+		BumpStrength:    0.3,
+		RoughnessScale:  0.02,
+		RoughnessAmount: 0.15,
+	}
+}
+
+// DetailMaterial wraps a material with procedural normal perturbation and
+// roughness variation. No geometry is displaced: the shading normal is tilted
+// along the gradient of a Perlin field before the wrapped material scatters.
+type DetailMaterial struct {
+	Mat    Material
+	Config DetailConfig
+	noise  *Perlin
+}
+
+// WithDetail adds noise-driven bump and roughness variation to any material
+func WithDetail(mat Material, config DetailConfig) *DetailMaterial {
+	return &DetailMaterial{
+		Mat:    mat,
+		Config: config,
+		noise:  NewPerlin(),
+	}
+}
+
+// roughnessVariant is implemented by materials whose roughness can vary per hit
+type roughnessVariant interface {
+	withRoughnessOffset(offset float64) Material
+}
+
+func (d *DetailMaterial) Properties() MaterialProperties {
+	info, ok := d.Mat.(MaterialInfo)
+	if !ok {
+		return MaterialProperties{}
+	}
+	props := info.Properties()
+	if _, ok := d.Mat.(PDFEvaluator); !ok {
+		props.CanUseNEE = false
+	}
+	return props
+}
+
+// Scatter perturbs rec.Normal in place so that light sampling after the
+// scatter sees the same shading normal
+func (d *DetailMaterial) Scatter(rIn Ray, rec *HitRecord, attenuation *Color, scattered *Ray) bool {
+	if d.Config.BumpStrength > 0 {
+		rec.Normal = d.bumpNormal(rec.P, rec.Normal)
+	}
+
+	mat := d.Mat
+	if variant, ok := mat.(roughnessVariant); ok && d.Config.RoughnessAmount > 0 {
+		offset := d.Config.RoughnessAmount * d.noise.Noise(rec.P.Scale(d.Config.RoughnessScale).Add(Vec3{X: 17.3, Y: 5.1, Z: 9.7}))
+		mat = variant.withRoughnessOffset(offset)
+	}
+
+	return mat.Scatter(rIn, rec, attenuation, scattered)
+}
+
+func (d *DetailMaterial) PDF(wi, wo, normal Vec3) float64 {
+	if pdfEval, ok := d.Mat.(PDFEvaluator); ok {
+		return pdfEval.PDF(wi, wo, normal)
+	}
+	return 0
+}
+
+func (d *DetailMaterial) Emitted(u, v float64, p Point3) Color {
+	return d.Mat.Emitted(u, v, p)
+}
+
+func (d *DetailMaterial) SurfaceAlbedo(u, v float64, p Point3) Color {
+	if provider, ok := d.Mat.(AlbedoProvider); ok {
+		return provider.SurfaceAlbedo(u, v, p)
+	}
+	return Color{X: 0, Y: 0, Z: 0}
+}
+
+// bumpNormal tilts the normal against the tangential noise gradient
+// (central differences). Tilts that would flip the normal are discarded.
+func (d *DetailMaterial) bumpNormal(p Point3, normal Vec3) Vec3 {
+	const eps = 0.01
+
+	q := p.Scale(d.Config.BumpScale)
+	grad := Vec3{
+		X: d.noise.Noise(q.Add(Vec3{X: eps})) - d.noise.Noise(q.Sub(Vec3{X: eps})),
+		Y: d.noise.Noise(q.Add(Vec3{Y: eps})) - d.noise.Noise(q.Sub(Vec3{Y: eps})),
+		Z: d.noise.Noise(q.Add(Vec3{Z: eps})) - d.noise.Noise(q.Sub(Vec3{Z: eps})),
+	}.Scale(1 / (2 * eps))
+
+	tangential := grad.Sub(normal.Scale(Dot(grad, normal)))
+	bumped := normal.Sub(tangential.Scale(d.Config.BumpStrength)).Unit()
+	if math.IsNaN(bumped.X) || Dot(bumped, normal) <= 0 {
+		return normal
+	}
+	return bumped
+}
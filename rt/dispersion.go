@@ -0,0 +1,70 @@
+package rt
+
+// =============================================================================
+// DISPERSION (PER-CHANNEL IOR)
+// =============================================================================
+
+// Wavelengths (nm) the R, G and B channels stand for, and the Fraunhofer
+// lines the Abbe number is defined at
+const (
+	dispersionRed   = 650.0
+	dispersionGreen = 550.0
+	dispersionBlue  = 450.0
+
+	fraunhoferD = 587.6 // Where RefractionIndex is measured
+	fraunhoferF = 486.1
+	fraunhoferC = 656.3
+)
+
+// SetDispersion makes the glass split light into colors. abbe is the Abbe
+// number: about 60 for crown glass, 30-40 for flint glass and prisms, 55 for
+// diamond (whose high IOR still gives strong fire); lower disperses more.
+// The per-channel IORs follow Cauchy's equation through RefractionIndex at
+// the sodium D line. abbe <= 0 turns dispersion off.
+func (d *Dielectric) SetDispersion(abbe float64) *Dielectric {
+	d.DispersionIOR = Color{}
+	if abbe > 0 {
+		// n(λ) = A + B/λ², with n(D) = RefractionIndex and (n(D)-1)/(n(F)-n(C)) = abbe
+		b := (d.RefractionIndex - 1) / (abbe * (1/(fraunhoferF*fraunhoferF) - 1/(fraunhoferC*fraunhoferC)))
+		a := d.RefractionIndex - b/(fraunhoferD*fraunhoferD)
+		ior := func(lambda float64) float64 { return a + b/(lambda*lambda) }
+		d.DispersionIOR = Color{X: ior(dispersionRed), Y: ior(dispersionGreen), Z: ior(dispersionBlue)}
+	}
+	return d
+}
+
+// dispersedIOR returns the IOR for a path carrying channel (1-3), or picks a
+// channel at random for a white path (0) and weights attenuation by 3 for
+// that channel only, which keeps the expected color unchanged. The channel
+// stays on the ray through further glass; other materials drop it and the
+// next dispersive surface picks again (still unbiased, just noisier).
+func (d *Dielectric) dispersedIOR(channel int, attenuation *Color, s *Sampler) (float64, int) {
+	if channel == 0 {
+		channel = 1 + min(int(s.Float64()*3), 2)
+		*attenuation = channelColor(channel).Scale(3)
+	}
+	switch channel {
+	case 1:
+		return d.DispersionIOR.X, channel
+	case 2:
+		return d.DispersionIOR.Y, channel
+	}
+	return d.DispersionIOR.Z, channel
+}
+
+// channelColor returns the unit color of channel (1-3)
+func channelColor(channel int) Color {
+	switch channel {
+	case 1:
+		return Color{X: 1}
+	case 2:
+		return Color{Y: 1}
+	}
+	return Color{Z: 1}
+}
+
+// withChannel returns the ray carrying a dispersed path's color channel
+func (r Ray) withChannel(channel int) Ray {
+	r.channel = channel
+	return r
+}
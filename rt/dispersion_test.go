@@ -0,0 +1,77 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSetDispersion(t *testing.T) {
+	glass := NewDielectric(1.62).SetDispersion(36)
+	ior := glass.DispersionIOR
+	if !(ior.X < ior.Y && ior.Y < ior.Z) {
+		t.Fatalf("IORs %v should rise from red to blue", ior)
+	}
+	// Cauchy through n(D) with the requested Abbe number
+	b := (ior.Z - ior.X) / (1/(dispersionBlue*dispersionBlue) - 1/(dispersionRed*dispersionRed))
+	a := ior.X - b/(dispersionRed*dispersionRed)
+	n := func(lambda float64) float64 { return a + b/(lambda*lambda) }
+	if math.Abs(n(fraunhoferD)-1.62) > 1e-9 {
+		t.Errorf("n(D) = %g, want 1.62", n(fraunhoferD))
+	}
+	if abbe := (n(fraunhoferD) - 1) / (n(fraunhoferF) - n(fraunhoferC)); math.Abs(abbe-36) > 1e-6 {
+		t.Errorf("Abbe number = %g, want 36", abbe)
+	}
+	if glass.SetDispersion(0).DispersionIOR != (Color{}) {
+		t.Error("abbe 0 should turn dispersion off")
+	}
+}
+
+func TestDispersionConservesEnergy(t *testing.T) {
+	SeedRandom(2)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	// Channel selection is noisier than plain glass, so take more samples
+	// than the furnace audit
+	fc := FurnaceCase{Name: "dispersive", Material: NewDielectric(1.5).SetDispersion(30), Expected: Color{X: 1, Y: 1, Z: 1}}
+	if result := RunFurnaceTest(fc, 256, 0.02); !result.Pass {
+		t.Errorf("measured %v, expected white (error %.2f%%)", result.Measured, result.Error*100)
+	}
+}
+
+func TestDispersionSplitsColors(t *testing.T) {
+	SeedRandom(6)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	// A ray entering a slab at an angle leaves on a different path per channel
+	glass := NewDielectric(1.62).SetDispersion(20)
+	rec := &HitRecord{P: Point3{}, Normal: Vec3{Y: 1}, FrontFace: true}
+	in := NewRay(Point3{X: -1, Y: 1}, Vec3{X: 1, Y: -1}, 0)
+	refracted := map[int]Vec3{}
+	for range 200 {
+		var attenuation Color
+		var out Ray
+		glass.Scatter(in, rec, &attenuation, &out)
+		if out.Direction().Y > 0 {
+			continue // Fresnel reflection
+		}
+		if attenuation != channelColor(out.channel).Scale(3) {
+			t.Fatalf("channel %d carries attenuation %v", out.channel, attenuation)
+		}
+		refracted[out.channel] = out.Direction()
+
+		// Further glass keeps the channel and its weight
+		var again Color
+		var through Ray
+		glass.Scatter(out, &HitRecord{Normal: Vec3{Y: 1}, FrontFace: false}, &again, &through)
+		if through.channel != out.channel || again != (Color{X: 1, Y: 1, Z: 1}) {
+			t.Fatalf("second interface: channel %d -> %d, attenuation %v", out.channel, through.channel, again)
+		}
+	}
+	if len(refracted) != 3 {
+		t.Fatalf("refracted channels %v, want all three", refracted)
+	}
+	// Blue bends most, toward the normal
+	if !(refracted[3].X < refracted[2].X && refracted[2].X < refracted[1].X) {
+		t.Errorf("refraction order red %v, green %v, blue %v", refracted[1], refracted[2], refracted[3])
+	}
+}
@@ -0,0 +1,184 @@
+package rt
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// =============================================================================
+// DEPTH OF FIELD PREVIEW OVERLAY
+// =============================================================================
+
+// DOFLimits returns the near and far distances that stay acceptably sharp for
+// a thin lens focused at focusDist. A point is sharp while its blur circle is
+// at most cocPixels wide; far is +Inf past the hyperfocal distance.
+func DOFLimits(focusDist, defocusAngle, vfov float64, imageHeight int, cocPixels float64) (near, far float64) {
+	lens := math.Tan(DegreesToRadians(defocusAngle / 2))
+	if lens <= 0 || imageHeight <= 0 {
+		return 0, math.Inf(1)
+	}
+
+	// Blur diameter at depth d, measured on the focus plane, is
+	// 2*f*lens*|d-f|/d; one pixel there is 2*f*tan(vfov/2)/imageHeight
+	coc := cocPixels * math.Tan(DegreesToRadians(vfov/2)) / float64(imageHeight)
+
+	near = lens * focusDist / (lens + coc)
+	far = math.Inf(1)
+	if lens > coc {
+		far = lens * focusDist / (lens - coc)
+	}
+	return near, far
+}
+
+const (
+	dofPreviewStep      = 4    // Depth is traced every dofPreviewStep pixels
+	dofFocusBand        = 0.02 // Relative depth band drawn as the focus plane
+	dofFocusStep        = 1.05 // Focus distance multiplier per key press
+	dofAngleStep        = 0.1  // Defocus angle change per key press (degrees)
+	dofPreviewTintAlpha = 90
+)
+
+// dofPreview tints the viewer image by depth of field: red in front of the
+// sharp zone, blue behind it and green on the focus plane. The lens values
+// can be adjusted with hotkeys to try settings before re-rendering; they
+// never change the running render.
+type dofPreview struct {
+	camera       *Camera
+	world        Hittable
+	focusDist    float64
+	defocusAngle float64
+	cocPixels    float64
+
+	depth  []float64 // Planar depth per preview cell, +Inf on misses
+	cols   int
+	rows   int
+	tint   *ebiten.Image
+	pixels []byte
+	dirty  bool
+}
+
+func newDOFPreview(camera *Camera, world Hittable, cocPixels float64) *dofPreview {
+	return &dofPreview{
+		camera:       camera,
+		world:        world,
+		focusDist:    camera.FocusDist,
+		defocusAngle: camera.DefocusAngle,
+		cocPixels:    cocPixels,
+		dirty:        true,
+	}
+}
+
+// handleInput applies the lens hotkeys: [ ] focus distance, - = aperture
+func (d *dofPreview) handleInput() {
+	changed := false
+	if inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft) {
+		d.focusDist /= dofFocusStep
+		changed = true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBracketRight) {
+		d.focusDist *= dofFocusStep
+		changed = true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
+		d.defocusAngle = math.Max(0, d.defocusAngle-dofAngleStep)
+		changed = true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) {
+		d.defocusAngle += dofAngleStep
+		changed = true
+	}
+
+	if changed {
+		d.dirty = true
+		fmt.Printf("DOF preview: FocusDist=%.3f DefocusAngle=%.2f\n", d.focusDist, d.defocusAngle)
+	}
+}
+
+// limits returns the sharp zone for the current preview lens settings
+func (d *dofPreview) limits() (near, far float64) {
+	return DOFLimits(d.focusDist, d.defocusAngle, d.camera.Vfov, d.camera.ImageHeight, d.cocPixels)
+}
+
+// lines formats the lens readout for the stats overlay
+func (d *dofPreview) lines() []string {
+	near, far := d.limits()
+	farText := "inf"
+	if !math.IsInf(far, 1) {
+		farText = fmt.Sprintf("%.2f", far)
+	}
+	return []string{
+		fmt.Sprintf("Focus: %.2f | Aperture: %.2f deg | Sharp: %.2f - %s", d.focusDist, d.defocusAngle, near, farText),
+	}
+}
+
+// traceDepth casts one pinhole ray per preview cell and stores the hit depth
+// along the view axis, matching the camera's planar focus surface
+func (d *dofPreview) traceDepth() {
+	c := d.camera
+	d.cols = (c.ImageWidth + dofPreviewStep - 1) / dofPreviewStep
+	d.rows = (c.ImageHeight + dofPreviewStep - 1) / dofPreviewStep
+	d.depth = make([]float64, d.cols*d.rows)
+
+	forward := c.w.Neg()
+	for row := 0; row < d.rows; row++ {
+		for col := 0; col < d.cols; col++ {
+			i := min(col*dofPreviewStep+dofPreviewStep/2, c.ImageWidth-1)
+			j := min(row*dofPreviewStep+dofPreviewStep/2, c.ImageHeight-1)
+			ray := c.centerRay(i, j)
+
+			depth := math.Inf(1)
+			rec := &HitRecord{}
+			if d.world.Hit(ray, c.rayInterval(ray), rec) {
+				depth = rec.T * Dot(ray.Direction(), forward)
+			}
+			d.depth[row*d.cols+col] = depth
+		}
+	}
+}
+
+// rebuild recolors the tint image from the depth buffer
+func (d *dofPreview) rebuild() {
+	if d.depth == nil {
+		d.traceDepth()
+		d.tint = ebiten.NewImage(d.cols, d.rows)
+		d.pixels = make([]byte, 4*d.cols*d.rows)
+	}
+
+	near, far := d.limits()
+	nearTint := color.RGBA{R: dofPreviewTintAlpha, A: dofPreviewTintAlpha}
+	farTint := color.RGBA{B: dofPreviewTintAlpha, A: dofPreviewTintAlpha}
+	focusTint := color.RGBA{G: 2 * dofPreviewTintAlpha, A: 2 * dofPreviewTintAlpha}
+
+	for idx, depth := range d.depth {
+		var tint color.RGBA // Sharp zone stays untinted
+		switch {
+		case math.Abs(depth-d.focusDist) <= dofFocusBand*d.focusDist:
+			tint = focusTint
+		case depth < near:
+			tint = nearTint
+		case depth > far:
+			tint = farTint
+		}
+		d.pixels[4*idx+0] = tint.R // Premultiplied alpha
+		d.pixels[4*idx+1] = tint.G
+		d.pixels[4*idx+2] = tint.B
+		d.pixels[4*idx+3] = tint.A
+	}
+	d.tint.WritePixels(d.pixels)
+	d.dirty = false
+}
+
+// draw tints the screen by focus zone; the depth buffer is traced on first use
+func (d *dofPreview) draw(screen *ebiten.Image) {
+	if d.dirty {
+		d.rebuild()
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(dofPreviewStep, dofPreviewStep)
+	screen.DrawImage(d.tint, op)
+}
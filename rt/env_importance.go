@@ -0,0 +1,125 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// =============================================================================
+// OCCLUSION-AWARE ENVIRONMENT IMPORTANCE SAMPLING
+// =============================================================================
+
+const (
+	envOcclusionCols     = 64   // Bins across the equirectangular map
+	envOcclusionRows     = 32   // Bins down the map
+	envOcclusionMinTests = 4    // Shadow rays a bin needs before it is reweighted
+	envOcclusionFloor    = 0.05 // Lowest weight of a bin, keeps every lit direction sampleable
+)
+
+// envOcclusionMap counts how many environment shadow rays got through, per
+// direction bin in the map's UV space, over the whole image. Safe for
+// concurrent use by the render workers.
+type envOcclusionMap struct {
+	tests   [envOcclusionCols * envOcclusionRows]atomic.Uint32
+	visible [envOcclusionCols * envOcclusionRows]atomic.Uint32
+}
+
+// bin returns the bin of environment UV coordinates
+func (m *envOcclusionMap) bin(u, v float64) int {
+	col := clamp(int(u*envOcclusionCols), 0, envOcclusionCols)
+	row := clamp(int(v*envOcclusionRows), 0, envOcclusionRows)
+	return row*envOcclusionCols + col
+}
+
+// record stores the result of a traced environment shadow ray. A nil map
+// records nothing.
+func (m *envOcclusionMap) record(env *HDRIEnvironment, dir Vec3, visible bool) {
+	if m == nil {
+		return
+	}
+	b := m.bin(env.DirectionToUV(dir))
+	m.tests[b].Add(1)
+	if visible {
+		m.visible[b].Add(1)
+	}
+}
+
+// visibility returns the weight of the bin at (u, v): the fraction of its
+// shadow rays that got through, at least envOcclusionFloor. Bins with too
+// few tests keep their full weight.
+func (m *envOcclusionMap) visibility(u, v float64) float64 {
+	b := m.bin(u, v)
+	tests := m.tests[b].Load()
+	if tests < envOcclusionMinTests {
+		return 1
+	}
+	return math.Max(envOcclusionFloor, float64(m.visible[b].Load())/float64(tests))
+}
+
+// occludedShare returns the fraction of env's samples that would land in
+// directions the map found blocked
+func (m *envOcclusionMap) occludedShare(env *HDRIEnvironment) float64 {
+	share := 0.0
+	for y := 0; y < env.height; y++ {
+		for x := 0; x < env.width; x++ {
+			u, v := (float64(x)+0.5)/float64(env.width), (float64(y)+0.5)/float64(env.height)
+			b := m.bin(u, v)
+			if tests := m.tests[b].Load(); tests >= envOcclusionMinTests {
+				share += env.pdf[y*env.width+x] * (1 - float64(m.visible[b].Load())/float64(tests))
+			}
+		}
+	}
+	return share
+}
+
+// applyOcclusion rebuilds env's importance distribution weighted by the
+// visibility in m. Directions found blocked are sampled less, but never
+// below envOcclusionFloor of their radiance weight, so NEE stays unbiased
+// wherever light does get through. Returns the occluded share of samples
+// before and after. Must not run while the environment is being sampled.
+func (env *HDRIEnvironment) applyOcclusion(m *envOcclusionMap) (before, after float64) {
+	if !env.IsValid() || !env.useImportanceSampling || env.pdf == nil {
+		return 0, 0
+	}
+	before = m.occludedShare(env)
+	env.buildDistribution(m.visibility)
+	return before, m.occludedShare(env)
+}
+
+// SetAdaptiveEnvironment gathers environment shadow-ray visibility over the
+// whole image during the preview pass, then rebuilds the HDRI importance
+// map so later passes send fewer samples into directions that are mostly
+// occluded (e.g. the sky behind the walls of an interior).
+func (r *BucketRenderer) SetAdaptiveEnvironment(enabled bool) *BucketRenderer {
+	r.envOcclusion = nil
+	if enabled {
+		r.envOcclusion = &envOcclusionMap{}
+	}
+	return r
+}
+
+// adaptEnvironment starts gathering visibility before the preview pass and
+// refines the environment maps once it is done
+func (r *BucketRenderer) adaptEnvironment(pass int) {
+	if r.envOcclusion == nil || r.camera.Environment == nil {
+		return
+	}
+	switch {
+	case pass == 0:
+		r.camera.envOcclusion = r.envOcclusion
+	case r.camera.envOcclusion != nil:
+		r.camera.envOcclusion = nil
+		refined := map[*HDRIEnvironment]bool{}
+		for _, env := range []*HDRIEnvironment{r.camera.Environment, r.fullEnv, r.previewEnv} {
+			if env == nil || refined[env] {
+				continue
+			}
+			refined[env] = true
+			if before, after := env.applyOcclusion(r.envOcclusion); before > 0 {
+				fmt.Printf("Adaptive env map: occluded environment samples %.0f%% -> %.0f%% (%dx%d)\n",
+					100*before, 100*after, env.width, env.height)
+			}
+		}
+	}
+}
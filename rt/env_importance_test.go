@@ -0,0 +1,55 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAdaptiveEnvironmentAvoidsOccludedDirections(t *testing.T) {
+	SeedRandom(4)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	// A floor under a roof: the environment is only visible sideways
+	world := NewHittableList()
+	gray := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	world.Add(NewQuad(Point3{X: -2, Z: -2}, Vec3{X: 4}, Vec3{Z: 4}, gray))
+	world.Add(NewQuad(Point3{X: -2, Y: 1, Z: -2}, Vec3{X: 4}, Vec3{Z: 4}, gray))
+	camera := NewCameraBuilder().SetResolution(64, 1).SetQuality(4, 4).
+		SetPosition(Point3{Y: 0.5, Z: 2}, Point3{}, Vec3{Y: 1}).Build()
+	camera.SetUniformEnvironment(Color{X: 1, Y: 1, Z: 1})
+	camera.Initialize()
+	env := camera.Environment
+	// Texel centers about 40 degrees up and just above the horizon
+	up, side := env.UVToDirection(0.3, 8.5/32), env.UVToDirection(0.3, 15.5/32)
+	if math.Abs(env.PDF(up)-env.PDF(side)) > 1e-9*env.PDF(side) {
+		t.Fatal("uniform environment isn't sampled uniformly")
+	}
+
+	r := NewBucketRenderer(camera, world, 16, 2).SetAdaptiveEnvironment(true)
+	defer r.Close()
+	for range 16 {
+		r.renderPass()
+	}
+	r.adaptEnvironment(1)
+	if camera.envOcclusion != nil {
+		t.Error("still gathering visibility after the preview pass")
+	}
+	if env.PDF(up) >= env.PDF(side)/4 {
+		t.Errorf("pdf up %g, sideways %g: the roof should make up much less likely", env.PDF(up), env.PDF(side))
+	}
+	if env.PDF(up) <= 0 {
+		t.Error("blocked directions can no longer be sampled")
+	}
+
+	// The refined map is still a normalized density: E[1/pdf] is the
+	// sphere's solid angle
+	sum := 0.0
+	const n = 200000
+	for range n {
+		_, _, pdf := env.SampleDirection()
+		sum += 1 / pdf
+	}
+	if got := sum / n; math.Abs(got-4*math.Pi) > 0.05*4*math.Pi {
+		t.Errorf("E[1/pdf] = %g, want 4π", got)
+	}
+}
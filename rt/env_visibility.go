@@ -0,0 +1,109 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+)
+
+// =============================================================================
+// ENVIRONMENT VISIBILITY CACHE
+// =============================================================================
+
+const (
+	envVisibilityThetaBins = 4 // Bins in cos(theta) (equal solid angle)
+	envVisibilityPhiBins   = 8 // Bins in azimuth
+	envVisibilityMinTests  = 8 // Shadow rays a bin needs before it may be skipped
+)
+
+// envVisibilityCache remembers which environment directions are blocked for
+// the camera-ray hits of one bucket. In an HDRI interior most environment
+// samples leave through the walls; once every shadow ray in a direction bin
+// has been blocked, later samples in that bin are assumed blocked without
+// tracing. That darkens light through small openings, so the renderer only
+// uses it for display-only passes. A cache belongs to one bucket and is used
+// by one worker at a time.
+type envVisibilityCache struct {
+	tests   [envVisibilityThetaBins * envVisibilityPhiBins]uint16
+	visible [envVisibilityThetaBins * envVisibilityPhiBins]uint16
+	skipped int64 // Shadow rays skipped as known-blocked
+	traced  int64 // Shadow rays traced
+}
+
+// bin returns the direction bin of a unit vector
+func (c *envVisibilityCache) bin(dir Vec3) int {
+	theta := int((clampFloat(dir.Y, -1, 1) + 1) / 2 * envVisibilityThetaBins)
+	phi := int((math.Atan2(dir.Z, dir.X) + math.Pi) / (2 * math.Pi) * envVisibilityPhiBins)
+	theta = min(theta, envVisibilityThetaBins-1)
+	phi = min(phi, envVisibilityPhiBins-1)
+	return theta*envVisibilityPhiBins + phi
+}
+
+// blocked reports whether dir can be skipped as occluded; it counts the
+// skip. A nil cache never skips.
+func (c *envVisibilityCache) blocked(dir Vec3) bool {
+	if c == nil {
+		return false
+	}
+	b := c.bin(dir)
+	if c.tests[b] >= envVisibilityMinTests && c.visible[b] == 0 {
+		c.skipped++
+		return true
+	}
+	return false
+}
+
+// record stores the result of a traced shadow ray
+func (c *envVisibilityCache) record(dir Vec3, visible bool) {
+	if c == nil {
+		return
+	}
+	c.traced++
+	b := c.bin(dir)
+	if c.tests[b] < math.MaxUint16 {
+		c.tests[b]++
+		if visible {
+			c.visible[b]++
+		}
+	}
+}
+
+// SetEnvVisibilityCache skips environment shadow rays that the bucket has
+// found blocked in the same direction during the preview and medium passes.
+// Like SetHDRIPreviewWidth it makes the medium pass display-only, so the
+// final pass traces the full SPP without the cache and stays unbiased.
+func (r *BucketRenderer) SetEnvVisibilityCache(enabled bool) *BucketRenderer {
+	r.envCaches = nil
+	if enabled {
+		r.envCaches = make([]*envVisibilityCache, len(r.buckets))
+		for i := range r.envCaches {
+			r.envCaches[i] = &envVisibilityCache{}
+		}
+	}
+	return r
+}
+
+// envCache returns the visibility cache of a bucket for a pass, nil when the
+// pass must not use one
+func (r *BucketRenderer) envCache(bucket int, accumulate bool) *envVisibilityCache {
+	if r.envCaches == nil || accumulate || r.camera.Environment == nil {
+		return nil
+	}
+	return r.envCaches[bucket]
+}
+
+// reportEnvCache prints how many environment shadow rays the caches have
+// saved after a pass that used them
+func (r *BucketRenderer) reportEnvCache(accumulate bool) {
+	if r.envCaches == nil || accumulate {
+		return
+	}
+	var skipped, traced int64
+	for _, c := range r.envCaches {
+		skipped += c.skipped
+		traced += c.traced
+	}
+	if total := skipped + traced; total > 0 {
+		fmt.Printf("Env visibility cache: skipped %d of %d environment shadow rays (%.0f%%)\n",
+			skipped, total, 100*float64(skipped)/float64(total))
+	}
+}
@@ -0,0 +1,73 @@
+package rt
+
+import "testing"
+
+func TestEnvVisibilityCacheSkipsOnlyAlwaysBlockedBins(t *testing.T) {
+	c := &envVisibilityCache{}
+	up, side := Vec3{Y: 1}, Vec3{X: 1}
+	for i := 0; i < envVisibilityMinTests; i++ {
+		if c.blocked(up) || c.blocked(side) {
+			t.Fatalf("bins skipped after %d tests, want %d first", i, envVisibilityMinTests)
+		}
+		c.record(up, false)
+		c.record(side, i != 3) // Seen once
+	}
+	if !c.blocked(up) {
+		t.Error("a bin that was always blocked is not skipped")
+	}
+	if c.blocked(side) {
+		t.Error("a bin that was visible once is skipped")
+	}
+	if c.skipped != 1 || c.traced != 2*envVisibilityMinTests {
+		t.Errorf("skipped %d, traced %d; want 1 and %d", c.skipped, c.traced, 2*envVisibilityMinTests)
+	}
+
+	var disabled *envVisibilityCache
+	disabled.record(up, false)
+	if disabled.blocked(up) {
+		t.Error("a nil cache must never skip")
+	}
+}
+
+func TestEnvVisibilityCacheUsedOnlyInDisplayPasses(t *testing.T) {
+	SeedRandom(5)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	// A floor under a roof: the environment is only visible sideways
+	world := NewHittableList()
+	gray := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	world.Add(NewQuad(Point3{X: -20, Z: -20}, Vec3{X: 40}, Vec3{Z: 40}, gray))
+	world.Add(NewQuad(Point3{X: -20, Y: 1, Z: -20}, Vec3{X: 40}, Vec3{Z: 40}, gray))
+	camera := NewCameraBuilder().SetResolution(16, 1).SetQuality(8, 4).
+		SetPosition(Point3{Y: 0.5, Z: 2}, Point3{}, Vec3{Y: 1}).Build()
+	camera.SetUniformEnvironment(Color{X: 1, Y: 1, Z: 1})
+	camera.Initialize()
+
+	r := NewBucketRenderer(camera, world, 16, 2).SetEnvVisibilityCache(true)
+	defer r.Close()
+	if _, _, accumulate := r.passSettings(1); accumulate {
+		t.Fatal("medium pass accumulates into the film with the cache on")
+	}
+
+	skipped := func() (n int64) {
+		for _, c := range r.envCaches {
+			n += c.skipped
+		}
+		return n
+	}
+	r.renderPass()
+	r.currentPass++
+	r.renderPass()
+	display := skipped()
+	if display == 0 {
+		t.Error("preview and medium passes skipped no environment shadow rays under the roof")
+	}
+	r.currentPass++
+	r.renderPass()
+	if skipped() != display {
+		t.Error("the final pass used the visibility cache")
+	}
+	if samples, _, _ := r.passSettings(2); r.film.SampleCount(0, 0) != samples {
+		t.Errorf("film has %d samples, want the final pass's %d", r.film.SampleCount(0, 0), samples)
+	}
+}
@@ -0,0 +1,115 @@
+package rt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Film accumulates linear radiance samples per pixel so that successive
+// render passes refine the same estimate instead of replacing it.
+//
+// Buckets never overlap, so workers may write to the film concurrently
+// without locking as long as each pixel is owned by a single bucket.
+// RUST PORT NOTE: Vec<Color> + Vec<u32>, split per bucket with chunks_mut
+type Film struct {
+	width   int
+	height  int
+	sum     []Color // Running sum of radiance samples
+	samples []int   // Number of samples accumulated per pixel
+}
+
+// NewFilm creates an empty film for an image of the given size
+func NewFilm(width, height int) *Film {
+	return &Film{
+		width:   width,
+		height:  height,
+		sum:     make([]Color, width*height),
+		samples: make([]int, width*height),
+	}
+}
+
+// Width returns the film width in pixels
+func (f *Film) Width() int {
+	return f.width
+}
+
+// Height returns the film height in pixels
+func (f *Film) Height() int {
+	return f.height
+}
+
+// AddSamples adds the sum of count radiance samples to pixel (x, y)
+func (f *Film) AddSamples(x, y int, sum Color, count int) {
+	idx := y*f.width + x
+	f.sum[idx] = f.sum[idx].Add(sum)
+	f.samples[idx] += count
+}
+
+// Resolve returns the mean radiance of pixel (x, y)
+func (f *Film) Resolve(x, y int) Color {
+	idx := y*f.width + x
+	if f.samples[idx] == 0 {
+		return Color{X: 0, Y: 0, Z: 0}
+	}
+	return f.sum[idx].Scale(1.0 / float64(f.samples[idx]))
+}
+
+// SampleCount returns the number of samples accumulated for pixel (x, y)
+func (f *Film) SampleCount(x, y int) int {
+	return f.samples[y*f.width+x]
+}
+
+// Reset clears all accumulated samples
+func (f *Film) Reset() {
+	for i := range f.sum {
+		f.sum[i] = Color{X: 0, Y: 0, Z: 0}
+		f.samples[i] = 0
+	}
+}
+
+// WritePFM writes the resolved linear radiance as a little-endian RGB
+// Portable Float Map. Values are not clamped or gamma corrected, so the
+// image can be regraded later without re-rendering.
+func (f *Film) WritePFM(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	// Negative scale marks little-endian data
+	if _, err := fmt.Fprintf(bw, "PF\n%d %d\n-1.0\n", f.width, f.height); err != nil {
+		return err
+	}
+
+	// PFM scanlines are stored bottom-to-top
+	row := make([]byte, f.width*3*4)
+	for y := f.height - 1; y >= 0; y-- {
+		for x := 0; x < f.width; x++ {
+			c := f.Resolve(x, y)
+			offset := x * 12
+			binary.LittleEndian.PutUint32(row[offset:], math.Float32bits(float32(c.X)))
+			binary.LittleEndian.PutUint32(row[offset+4:], math.Float32bits(float32(c.Y)))
+			binary.LittleEndian.PutUint32(row[offset+8:], math.Float32bits(float32(c.Z)))
+		}
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// SavePFM writes the film to a .pfm file
+func (f *Film) SavePFM(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating HDR file: %w", err)
+	}
+	defer file.Close()
+
+	if err := f.WritePFM(file); err != nil {
+		return fmt.Errorf("error encoding PFM: %w", err)
+	}
+	return file.Close()
+}
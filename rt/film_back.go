@@ -0,0 +1,137 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// FILM BACK AND FOCAL LENGTH
+// =============================================================================
+
+// FilmBack is a camera sensor (or film gate) size in millimeters
+type FilmBack struct {
+	Width  float64
+	Height float64
+}
+
+// Common sensor sizes
+var (
+	FilmBackFullFrame = FilmBack{Width: 36, Height: 24}       // 35mm still, the default
+	FilmBackAPSC      = FilmBack{Width: 23.6, Height: 15.6}   // Nikon/Sony APS-C
+	FilmBackSuper35   = FilmBack{Width: 24.89, Height: 18.66} // Cinema
+	FilmBackMFT       = FilmBack{Width: 17.3, Height: 13}     // Micro Four Thirds
+	FilmBackOneInch   = FilmBack{Width: 13.2, Height: 8.8}
+)
+
+var filmBackNames = []string{"full-frame", "aps-c", "super35", "mft", "1-inch"}
+
+var filmBacks = []FilmBack{FilmBackFullFrame, FilmBackAPSC, FilmBackSuper35, FilmBackMFT, FilmBackOneInch}
+
+func (f FilmBack) String() string {
+	for i, fb := range filmBacks {
+		if fb == f {
+			return filmBackNames[i]
+		}
+	}
+	return fmt.Sprintf("%gx%gmm", f.Width, f.Height)
+}
+
+// ParseFilmBack converts a sensor name (e.g. "super35") or a size in
+// millimeters (e.g. "36x24") to a FilmBack
+func ParseFilmBack(name string) (FilmBack, error) {
+	for i, n := range filmBackNames {
+		if strings.EqualFold(name, n) {
+			return filmBacks[i], nil
+		}
+	}
+	if w, h, ok := strings.Cut(strings.TrimSuffix(strings.ToLower(name), "mm"), "x"); ok {
+		width, errW := strconv.ParseFloat(w, 64)
+		height, errH := strconv.ParseFloat(h, 64)
+		if errW == nil && errH == nil && width > 0 && height > 0 {
+			return FilmBack{Width: width, Height: height}, nil
+		}
+	}
+	return FilmBackFullFrame, fmt.Errorf("unknown film back: %s (use %s, or WIDTHxHEIGHT in mm)",
+		name, strings.Join(filmBackNames, ", "))
+}
+
+// SensorFit selects which side of the film back matches the image when
+// their aspect ratios differ
+type SensorFit int
+
+const (
+	SensorFitAuto       SensorFit = iota // The longer side of the film back spans the longer side of the image
+	SensorFitHorizontal                  // Image width spans the film back width
+	SensorFitVertical                    // Image height spans the film back height
+)
+
+var sensorFitNames = []string{"auto", "horizontal", "vertical"}
+
+func (f SensorFit) String() string {
+	if int(f) < 0 || int(f) >= len(sensorFitNames) {
+		return "unknown"
+	}
+	return sensorFitNames[f]
+}
+
+// ParseSensorFit converts a fit name (e.g. "horizontal") to a SensorFit
+func ParseSensorFit(name string) (SensorFit, error) {
+	for i, n := range sensorFitNames {
+		if strings.EqualFold(name, n) {
+			return SensorFit(i), nil
+		}
+	}
+	return SensorFitAuto, fmt.Errorf("unknown sensor fit: %s (use %s)",
+		name, strings.Join(sensorFitNames, ", "))
+}
+
+// SetFocalLength specifies the field of view physically, by a lens focal
+// length in millimeters on the camera's film back (full frame unless
+// SetFilmBack chose another). It replaces Vfov from the next Initialize.
+func (c *Camera) SetFocalLength(mm float64) *Camera {
+	c.FocalLength = math.Max(0, mm)
+	return c
+}
+
+// SetFilmBack sets the sensor size used with the focal length and how it is
+// fitted to the image
+func (c *Camera) SetFilmBack(filmBack FilmBack, fit SensorFit) *Camera {
+	c.FilmBack = filmBack
+	c.SensorFit = fit
+	return c
+}
+
+// SetFStop sets the aperture from the focal length and an f-number, e.g. 50
+// mm at f/2 is a 25 mm aperture. Needs a focal length; see SetAperture.
+func (c *Camera) SetFStop(fNumber float64) *Camera {
+	if c.FocalLength > 0 && fNumber > 0 {
+		c.SetAperture(c.FocalLength / 1000 / fNumber)
+	}
+	return c
+}
+
+// filmBackVFOV returns the vertical field of view in degrees that the focal
+// length gives on the film back for an image of the camera's aspect ratio
+func (c *Camera) filmBackVFOV() float64 {
+	filmBack := c.FilmBack
+	if filmBack.Width <= 0 || filmBack.Height <= 0 {
+		filmBack = FilmBackFullFrame
+	}
+	aspect := float64(c.ImageWidth) / float64(max(c.ImageHeight, 1))
+
+	var halfHeight float64
+	switch long := math.Max(filmBack.Width, filmBack.Height); {
+	case c.SensorFit == SensorFitHorizontal:
+		halfHeight = filmBack.Width / 2 / aspect
+	case c.SensorFit == SensorFitVertical:
+		halfHeight = filmBack.Height / 2
+	case aspect >= 1:
+		halfHeight = long / 2 / aspect
+	default:
+		halfHeight = long / 2
+	}
+	return 2 * math.Atan(halfHeight/c.FocalLength) * 180 / math.Pi
+}
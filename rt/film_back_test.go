@@ -0,0 +1,48 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFilmBackVFOV(t *testing.T) {
+	c := NewCamera()
+	c.ImageWidth = 300
+	c.AspectRatio = 1.5
+	c.SetFocalLength(50).Initialize()
+	// 24mm tall full frame behind a 50mm lens
+	want := 2 * math.Atan(12.0/50) * 180 / math.Pi
+	if math.Abs(c.Vfov-want) > 1e-9 {
+		t.Errorf("vfov = %v, want %v", c.Vfov, want)
+	}
+
+	// A square image: auto fits the 36mm side, vertical the 24mm side
+	c.AspectRatio = 1
+	c.Initialize()
+	if want := 2 * math.Atan(18.0/50) * 180 / math.Pi; math.Abs(c.Vfov-want) > 1e-9 {
+		t.Errorf("auto square vfov = %v, want %v", c.Vfov, want)
+	}
+	c.SetFilmBack(FilmBackFullFrame, SensorFitVertical).Initialize()
+	if want := 2 * math.Atan(12.0/50) * 180 / math.Pi; math.Abs(c.Vfov-want) > 1e-9 {
+		t.Errorf("vertical square vfov = %v, want %v", c.Vfov, want)
+	}
+}
+
+func TestParseFilmBack(t *testing.T) {
+	if fb, err := ParseFilmBack("Super35"); err != nil || fb != FilmBackSuper35 {
+		t.Errorf("super35 = %v, %v", fb, err)
+	}
+	if fb, err := ParseFilmBack("36x24mm"); err != nil || fb != FilmBackFullFrame {
+		t.Errorf("36x24mm = %v, %v", fb, err)
+	}
+	if _, err := ParseFilmBack("36x"); err == nil {
+		t.Error("expected error for 36x")
+	}
+}
+
+func TestSetFStop(t *testing.T) {
+	c := NewCamera().SetFocalLength(50).SetFStop(2)
+	if math.Abs(c.Aperture-0.025) > 1e-12 {
+		t.Errorf("aperture = %v, want 0.025", c.Aperture)
+	}
+}
@@ -0,0 +1,117 @@
+package rt
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// =============================================================================
+// FIREFLY POST-FILTER
+// =============================================================================
+
+// FireflyFilterConfig controls the outlier rejection applied to the resolved
+// HDR image before tonemapping. A pixel is a firefly when its luminance
+// exceeds the neighborhood median by more than Threshold robust standard
+// deviations (1.4826 × median absolute deviation); it is then scaled down to
+// that limit. Only the displayed and saved PNG image is filtered; the film and
+// HDR output keep the raw values.
+type FireflyFilterConfig struct {
+	Enabled   bool
+	Radius    int     // Neighborhood radius in pixels (1 = 3x3)
+	Threshold float64 // Allowed deviation above the median, in robust sigmas
+	MinSpread float64 // Spread floor relative to the median, so flat regions keep their grain
+}
+
+// DefaultFireflyFilterConfig returns a disabled 3x3 filter
+func DefaultFireflyFilterConfig() FireflyFilterConfig {
+	return FireflyFilterConfig{
+		Enabled:   false,
+		Radius:    1,
+		Threshold: 4.0,
+		MinSpread: 0.1,
+	}
+}
+
+// FilterFireflies clamps outlier pixels of a width×height linear image in
+// place and returns how many were clamped. Neighborhood statistics are taken
+// from the unfiltered image so clamping never cascades.
+func FilterFireflies(pixels []Color, width, height int, config FireflyFilterConfig) int {
+	if len(pixels) != width*height || width == 0 || height == 0 {
+		return 0
+	}
+	radius := max(config.Radius, 1)
+
+	luminance := make([]float64, len(pixels))
+	for i, c := range pixels {
+		luminance[i] = Luminance(c)
+	}
+
+	window := make([]float64, 0, (2*radius+1)*(2*radius+1))
+	clamped := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			lum := luminance[y*width+x]
+			if !(lum > 0) {
+				continue
+			}
+
+			window = window[:0]
+			for ny := max(0, y-radius); ny <= min(height-1, y+radius); ny++ {
+				for nx := max(0, x-radius); nx <= min(width-1, x+radius); nx++ {
+					window = append(window, luminance[ny*width+nx])
+				}
+			}
+			median := medianOf(window)
+			for i, v := range window {
+				window[i] = math.Abs(v - median)
+			}
+			sigma := math.Max(1.4826*medianOf(window), config.MinSpread*median)
+
+			limit := median + config.Threshold*sigma
+			if lum > limit {
+				pixels[y*width+x] = pixels[y*width+x].Scale(limit / lum)
+				clamped++
+			}
+		}
+	}
+	return clamped
+}
+
+// medianOf returns the median of values, reordering them
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return 0.5 * (values[n/2-1] + values[n/2])
+}
+
+// filteredFilm resolves the film and runs the firefly filter over it
+func filteredFilm(film *Film, config FireflyFilterConfig) []Color {
+	pixels := make([]Color, film.Width()*film.Height())
+	for y := 0; y < film.Height(); y++ {
+		for x := 0; x < film.Width(); x++ {
+			pixels[y*film.Width()+x] = film.Resolve(x, y)
+		}
+	}
+
+	clamped := FilterFireflies(pixels, film.Width(), film.Height(), config)
+	fmt.Printf("Firefly filter: clamped %d pixels\n", clamped)
+	return pixels
+}
+
+// writeFramebuffer redraws fb from resolved linear pixels scaled by the
+// display exposure and tone curve, running the pixel hook (if any) like the
+// render loop does
+func writeFramebuffer(fb *image.RGBA, pixels []Color, film *Film, exposure float64, toneMap ToneMapConfig, hook PixelHook, camera *Camera, world Hittable) {
+	width := film.Width()
+	for y := 0; y < film.Height(); y++ {
+		for x := 0; x < width; x++ {
+			c := applyPixelHook(hook, camera, world, x, y, film.SampleCount(x, y), pixels[y*width+x].Scale(exposure))
+			fb.Set(x, y, LinearToRGBA(toneMap.Apply(c)))
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package rt
+
+import "testing"
+
+func TestFilterFireflies(t *testing.T) {
+	const size = 8
+	config := DefaultFireflyFilterConfig()
+
+	// A single hot pixel in a flat region is clamped near its neighbors
+	flat := make([]Color, size*size)
+	for i := range flat {
+		flat[i] = Color{X: 0.5, Y: 0.5, Z: 0.5}
+	}
+	flat[3*size+4] = Color{X: 50, Y: 50, Z: 50}
+
+	if n := FilterFireflies(flat, size, size, config); n != 1 {
+		t.Fatalf("clamped %d pixels, want 1", n)
+	}
+	if lum := Luminance(flat[3*size+4]); lum > 1 {
+		t.Errorf("firefly luminance %.3f after filtering", lum)
+	}
+	if flat[0] != (Color{X: 0.5, Y: 0.5, Z: 0.5}) {
+		t.Errorf("neighbor changed to %v", flat[0])
+	}
+
+	// Smooth gradients are left alone
+	ramp := make([]Color, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			v := 0.1 + 0.2*float64(x)
+			ramp[y*size+x] = Color{X: v, Y: v, Z: v}
+		}
+	}
+	if n := FilterFireflies(ramp, size, size, config); n != 0 {
+		t.Errorf("clamped %d pixels of a smooth gradient", n)
+	}
+}
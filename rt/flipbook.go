@@ -0,0 +1,290 @@
+package rt
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// =============================================================================
+// IMAGE SEQUENCE FLIPBOOK
+// =============================================================================
+
+const flipbookTimelineHeight = 12 // Scrub bar height in pixels
+
+// Flipbook plays back a rendered image sequence in the viewer.
+//
+// Controls:
+//   - Space: play/pause
+//   - Left/Right: step one frame (pauses playback)
+//   - Home/End: jump to first/last frame
+//   - Up/Down: double/halve the playback rate
+//   - L: toggle looping
+//   - Click or drag on the timeline: scrub
+type Flipbook struct {
+	names   []string        // Source file per frame
+	images  []image.Image   // Decoded frames
+	frames  []*ebiten.Image // GPU copies, created lazily on first draw
+	width   int
+	height  int
+	fps     float64
+	current int
+	playing bool
+	loop    bool
+
+	scrubbing  bool
+	lastUpdate time.Time
+	elapsed    float64 // Time accumulated towards the next frame (seconds)
+	overlay    OverlayConfig
+}
+
+// LoadImageSequence resolves a directory or glob pattern to a sorted list of
+// PNG/JPEG frame paths
+func LoadImageSequence(pattern string) ([]string, error) {
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*")
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sequence pattern %q: %w", pattern, err)
+	}
+
+	var paths []string
+	for _, path := range matches {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".png", ".jpg", ".jpeg":
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no PNG or JPEG frames match %q", pattern)
+	}
+
+	// Frame numbers are zero-padded, so lexical order is frame order
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// NewFlipbook decodes the given frames for playback at fps frames per second.
+// All frames must share the size of the first one.
+func NewFlipbook(paths []string, fps float64) (*Flipbook, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("flipbook needs at least one frame")
+	}
+	if fps <= 0 {
+		return nil, fmt.Errorf("invalid playback rate: %g fps", fps)
+	}
+
+	fb := &Flipbook{
+		fps:        fps,
+		playing:    true,
+		loop:       true,
+		lastUpdate: time.Now(),
+		overlay:    DefaultOverlayConfig(),
+	}
+
+	for _, path := range paths {
+		img, err := decodeFrame(path)
+		if err != nil {
+			return nil, err
+		}
+
+		bounds := img.Bounds()
+		if len(fb.images) == 0 {
+			fb.width, fb.height = bounds.Dx(), bounds.Dy()
+		} else if bounds.Dx() != fb.width || bounds.Dy() != fb.height {
+			return nil, fmt.Errorf("frame %s is %dx%d, expected %dx%d",
+				path, bounds.Dx(), bounds.Dy(), fb.width, fb.height)
+		}
+
+		fb.names = append(fb.names, path)
+		fb.images = append(fb.images, img)
+	}
+	fb.frames = make([]*ebiten.Image, len(fb.images))
+
+	return fb, nil
+}
+
+func decodeFrame(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening frame: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding frame %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// SetOverlay configures the viewer stats overlay
+func (fb *Flipbook) SetOverlay(overlay OverlayConfig) *Flipbook {
+	fb.overlay = overlay
+	return fb
+}
+
+// SetLoop sets whether playback wraps around at the last frame
+func (fb *Flipbook) SetLoop(loop bool) *Flipbook {
+	fb.loop = loop
+	return fb
+}
+
+// Width returns the frame width in pixels
+func (fb *Flipbook) Width() int {
+	return fb.width
+}
+
+// Height returns the frame height in pixels (excluding the timeline)
+func (fb *Flipbook) Height() int {
+	return fb.height
+}
+
+func (fb *Flipbook) Update() error {
+	fb.overlay.handleInput()
+
+	now := time.Now()
+	dt := now.Sub(fb.lastUpdate).Seconds()
+	fb.lastUpdate = now
+
+	last := len(fb.images) - 1
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		fb.playing = !fb.playing
+		fb.elapsed = 0
+		// Restart from the beginning when playing a finished non-looping sequence
+		if fb.playing && !fb.loop && fb.current == last {
+			fb.current = 0
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		fb.loop = !fb.loop
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		fb.fps = min(fb.fps*2, 240)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		fb.fps = max(fb.fps/2, 0.5)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.KeyPressDuration(ebiten.KeyRight) > 15 {
+		fb.playing = false
+		fb.step(1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.KeyPressDuration(ebiten.KeyLeft) > 15 {
+		fb.playing = false
+		fb.step(-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyHome) {
+		fb.current = 0
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnd) {
+		fb.current = last
+	}
+
+	// Scrubbing starts on a click inside the timeline and follows the
+	// cursor until the button is released
+	mx, my := ebiten.CursorPosition()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && my >= fb.height {
+		fb.scrubbing = true
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		fb.scrubbing = false
+	}
+	if fb.scrubbing {
+		fb.current = fb.frameAt(mx)
+		fb.elapsed = 0
+		return nil
+	}
+
+	if fb.playing {
+		fb.elapsed += dt
+		frameTime := 1.0 / fb.fps
+		for fb.elapsed >= frameTime {
+			fb.elapsed -= frameTime
+			if fb.current == last && !fb.loop {
+				fb.playing = false
+				fb.elapsed = 0
+				break
+			}
+			fb.step(1)
+		}
+	}
+
+	return nil
+}
+
+// step moves n frames forward (or backward), wrapping at either end
+func (fb *Flipbook) step(n int) {
+	count := len(fb.images)
+	fb.current = ((fb.current+n)%count + count) % count
+}
+
+// frameAt maps a timeline x coordinate to a frame index
+func (fb *Flipbook) frameAt(x int) int {
+	t := clampFloat(float64(x)/float64(fb.width), 0, 1)
+	return min(int(t*float64(len(fb.images))), len(fb.images)-1)
+}
+
+func (fb *Flipbook) Draw(screen *ebiten.Image) {
+	if fb.frames[fb.current] == nil {
+		fb.frames[fb.current] = ebiten.NewImageFromImage(fb.images[fb.current])
+	}
+	screen.DrawImage(fb.frames[fb.current], nil)
+
+	fb.drawTimeline(screen)
+
+	// Keep the overlay off the timeline
+	frameArea := screen.SubImage(image.Rect(0, 0, fb.width, fb.height)).(*ebiten.Image)
+	fb.drawPlaybackInfo(frameArea)
+}
+
+func (fb *Flipbook) drawTimeline(screen *ebiten.Image) {
+	y := float32(fb.height)
+	w := float32(fb.width)
+	h := float32(flipbookTimelineHeight)
+	count := float32(len(fb.images))
+
+	vector.FillRect(screen, 0, y, w, h, color.RGBA{R: 30, G: 30, B: 30, A: 255}, false)
+
+	// Played portion and playhead
+	played := w * float32(fb.current+1) / count
+	vector.FillRect(screen, 0, y, played, h, color.RGBA{R: 70, G: 110, B: 160, A: 255}, false)
+	headW := max(w/count, 2)
+	vector.FillRect(screen, played-headW, y, headW, h, color.RGBA{R: 230, G: 230, B: 230, A: 255}, false)
+}
+
+func (fb *Flipbook) drawPlaybackInfo(screen *ebiten.Image) {
+	state := "PAUSED"
+	if fb.playing {
+		state = "PLAYING"
+	}
+	loop := "once"
+	if fb.loop {
+		loop = "loop"
+	}
+
+	lines := []string{
+		fmt.Sprintf("Frame %d/%d | %.1f fps | %s | %s",
+			fb.current+1, len(fb.images), fb.fps, state, loop),
+		filepath.Base(fb.names[fb.current]),
+	}
+
+	fb.overlay.draw(screen, lines)
+}
+
+func (fb *Flipbook) Layout(w, h int) (int, int) {
+	return fb.width, fb.height + flipbookTimelineHeight
+}
@@ -0,0 +1,135 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+)
+
+// =============================================================================
+// WHITE FURNACE TEST
+// =============================================================================
+
+// In a uniform white environment a convex object with a non-absorbing
+// material is invisible: every path eventually escapes to the environment and
+// returns radiance 1. A material with albedo a returns a. Deviations mean the
+// BRDF or the NEE/MIS weighting gains or loses energy.
+
+// FurnaceCase is one material checked against its expected pixel value
+type FurnaceCase struct {
+	Name     string
+	Material Material
+	Expected Color
+}
+
+// FurnaceResult reports the mean radiance over the pixels covering the object
+type FurnaceResult struct {
+	Case     FurnaceCase
+	Measured Color
+	Error    float64 // Largest per-channel relative error
+	Pass     bool
+}
+
+// DefaultFurnaceCases covers the built-in materials that should conserve energy
+func DefaultFurnaceCases() []FurnaceCase {
+	white := Color{X: 1, Y: 1, Z: 1}
+	grey := Color{X: 0.5, Y: 0.5, Z: 0.5}
+	tinted := Color{X: 0.9, Y: 0.6, Z: 0.3}
+	return []FurnaceCase{
+		{Name: "lambertian-white", Material: NewLambertian(white), Expected: white},
+		{Name: "lambertian-grey", Material: NewLambertian(grey), Expected: grey},
+		{Name: "lambertian-tinted", Material: NewLambertian(tinted), Expected: tinted},
+		{Name: "mirror", Material: NewMetal(tinted, 0), Expected: tinted},
+		{Name: "glass", Material: NewDielectric(1.5), Expected: white},
+		{Name: "detail-lambertian", Material: WithDetail(NewLambertian(white), DefaultDetailConfig()), Expected: white},
+	}
+}
+
+// FurnaceScene places a unit sphere of the given material in a uniform white
+// environment. Rendered as a scene, a correct non-absorbing material vanishes.
+func FurnaceScene(mat Material) (*HittableList, *Camera) {
+	world := NewHittableList()
+	world.Add(NewSphere(Point3{X: 0, Y: 0, Z: 0}, 1.0, mat))
+
+	camera := NewCameraBuilder().
+		SetResolution(400, 1.0).
+		SetQuality(64, 50).
+		SetPosition(
+			Point3{X: 0, Y: 0, Z: 4},
+			Point3{X: 0, Y: 0, Z: 0},
+			Vec3{X: 0, Y: 1, Z: 0},
+		).
+		SetLens(40, 0, 4).
+		SetUniformEnvironment(Color{X: 1, Y: 1, Z: 1}).
+		Build()
+
+	return world, camera
+}
+
+// RunFurnaceTest renders the case at a small resolution and compares the mean
+// over camera samples that hit the sphere against the expected value
+func RunFurnaceTest(fc FurnaceCase, samplesPerPixel int, tolerance float64) FurnaceResult {
+	world, camera := FurnaceScene(fc.Material)
+	camera.SetResolution(32, 1.0)
+	camera.Initialize()
+
+	sum := Color{X: 0, Y: 0, Z: 0}
+	count := 0
+	for j := 0; j < camera.ImageHeight; j++ {
+		for i := 0; i < camera.ImageWidth; i++ {
+			for s := 0; s < samplesPerPixel; s++ {
+				// Samples that miss see the environment directly and would bias
+				// silhouette pixels towards white
+				ray := camera.GetRay(i, j)
+				if !world.Hit(ray, NewInterval(camera.rayEpsilon(), math.Inf(1)), &HitRecord{}) {
+					continue
+				}
+				sum = sum.Add(camera.RayColor(ray, camera.MaxDepth, world))
+				count++
+			}
+		}
+	}
+
+	result := FurnaceResult{Case: fc}
+	if count == 0 {
+		return result
+	}
+	result.Measured = sum.Scale(1 / float64(count))
+	result.Error = math.Max(relativeError(result.Measured.X, fc.Expected.X),
+		math.Max(relativeError(result.Measured.Y, fc.Expected.Y), relativeError(result.Measured.Z, fc.Expected.Z)))
+	result.Pass = result.Error <= tolerance
+	return result
+}
+
+func relativeError(measured, expected float64) float64 {
+	if expected == 0 {
+		return math.Abs(measured)
+	}
+	return math.Abs(measured-expected) / expected
+}
+
+// RunFurnaceAudit runs every case, prints a table and reports whether all passed
+func RunFurnaceAudit(cases []FurnaceCase, samplesPerPixel int, tolerance float64) bool {
+	fmt.Println("\n========================================")
+	fmt.Println("         WHITE FURNACE AUDIT")
+	fmt.Println("========================================")
+	fmt.Printf("Samples Per Pixel:  %d\n", samplesPerPixel)
+	fmt.Printf("Tolerance:          %.1f%%\n", tolerance*100)
+
+	allPass := true
+	for _, fc := range cases {
+		result := RunFurnaceTest(fc, samplesPerPixel, tolerance)
+		status := "PASS"
+		if !result.Pass {
+			status = "FAIL"
+			allPass = false
+		}
+		fmt.Printf("%-20s %s  expected (%.3f, %.3f, %.3f)  measured (%.3f, %.3f, %.3f)  error %.2f%%\n",
+			fc.Name, status,
+			fc.Expected.X, fc.Expected.Y, fc.Expected.Z,
+			result.Measured.X, result.Measured.Y, result.Measured.Z,
+			result.Error*100)
+	}
+
+	fmt.Println("========================================")
+	return allPass
+}
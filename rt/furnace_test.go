@@ -0,0 +1,14 @@
+package rt
+
+import "testing"
+
+func TestWhiteFurnace(t *testing.T) {
+	for _, fc := range DefaultFurnaceCases() {
+		t.Run(fc.Name, func(t *testing.T) {
+			result := RunFurnaceTest(fc, 16, 0.03)
+			if !result.Pass {
+				t.Errorf("measured %v, expected %v (error %.2f%%)", result.Measured, fc.Expected, result.Error*100)
+			}
+		})
+	}
+}
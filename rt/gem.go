@@ -0,0 +1,148 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// =============================================================================
+// GEMS
+// =============================================================================
+
+// GemPreset selects the optical constants of a gemstone for NewGem
+type GemPreset int
+
+const (
+	GemDiamond       GemPreset = iota // IOR 2.42, Abbe 55
+	GemCubicZirconia                  // IOR 2.15, Abbe 33: more fire than diamond
+	GemMoissanite                     // IOR 2.65, Abbe 21: the most fire
+	GemSapphire                       // IOR 1.77, Abbe 72
+	GemQuartz                         // IOR 1.54, Abbe 70
+)
+
+var gemPresetNames = []string{"diamond", "cubic-zirconia", "moissanite", "sapphire", "quartz"}
+
+// gemConstants holds the IOR (at the sodium D line) and Abbe number of each
+// preset
+var gemConstants = []struct{ ior, abbe float64 }{
+	{2.417, 55},
+	{2.15, 33},
+	{2.65, 21},
+	{1.77, 72},
+	{1.544, 70},
+}
+
+// defaultGemInternalBounces is how many bounces a path may take inside a
+// gem. Light entering a brilliant cut usually leaves within a few
+// reflections, but a camera MaxDepth of 10 would still cut some of them.
+const defaultGemInternalBounces = 24
+
+func (g GemPreset) String() string {
+	if int(g) < 0 || int(g) >= len(gemPresetNames) {
+		return "unknown"
+	}
+	return gemPresetNames[g]
+}
+
+// ParseGemPreset converts a preset name (e.g. "diamond") to a GemPreset
+func ParseGemPreset(name string) (GemPreset, error) {
+	for i, n := range gemPresetNames {
+		if strings.EqualFold(name, n) {
+			return GemPreset(i), nil
+		}
+	}
+	return GemDiamond, fmt.Errorf("unknown gem: %s (use %s)",
+		name, strings.Join(gemPresetNames, ", "))
+}
+
+// NewGem returns a dispersive dielectric with the preset's IOR and Abbe
+// number whose internal bounces have their own limit (see
+// SetMaxInternalBounces)
+func NewGem(preset GemPreset) *Dielectric {
+	constants := gemConstants[GemDiamond]
+	if int(preset) >= 0 && int(preset) < len(gemConstants) {
+		constants = gemConstants[preset]
+	}
+	return NewDielectric(constants.ior).
+		SetDispersion(constants.abbe).
+		SetMaxInternalBounces(defaultGemInternalBounces)
+}
+
+// SetMaxInternalBounces lets paths bounce up to n times inside the material
+// (hits on its inner side) without using up the camera's MaxDepth; a path
+// still inside after n bounces ends there. High-IOR facets trap light through
+// total internal reflection, so gems go dark under a scene-wide depth that
+// suits everything else. 0 counts internal bounces against MaxDepth.
+func (d *Dielectric) SetMaxInternalBounces(n int) *Dielectric {
+	d.MaxInternalBounces = max(0, n)
+	return d
+}
+
+// scatterDepth returns the depth left for the ray scattered at rec, and
+// counts the bounce in path when it is one of a material's free internal
+// bounces
+func scatterDepth(depth int, mat Material, rec *HitRecord, path *pathState) int {
+	glass, ok := mat.(*Dielectric)
+	if !ok || glass.MaxInternalBounces == 0 || rec.FrontFace {
+		path.internal = 0
+		return depth - 1
+	}
+	if path.internal >= glass.MaxInternalBounces {
+		return 0
+	}
+	path.internal++
+	return depth
+}
+
+// Round brilliant proportions, as fractions of the girdle diameter
+const (
+	gemTable    = 0.56 // Table (top facet) diameter
+	gemCrown    = 0.15 // Crown height above the girdle
+	gemGirdle   = 0.02 // Girdle thickness
+	gemPavilion = 0.43 // Pavilion depth below the girdle
+)
+
+// Gem builds a faceted round brilliant of girdle radius radius, with the
+// girdle centered on center and the table facing +Y. facets is the number of
+// sides of the girdle (8 gives a simple cut, 16 or more a rounder stone).
+// The triangles are wound outward, so the stone is a closed solid for
+// refraction and back-face culling.
+func Gem(center Point3, radius float64, facets int, mat Material) Hittable {
+	facets = max(3, facets)
+	d := 2 * radius
+	girdleTop, girdleBottom := d*gemGirdle/2, -d*gemGirdle/2
+	ring := func(r, y, offset float64) []Point3 {
+		points := make([]Point3, facets)
+		for i := range points {
+			phi := 2 * math.Pi * (float64(i) + offset) / float64(facets)
+			points[i] = center.Add(Vec3{X: r * math.Cos(phi), Y: y, Z: r * math.Sin(phi)})
+		}
+		return points
+	}
+	table := ring(radius*gemTable, girdleTop+d*gemCrown, 0.5)
+	upper := ring(radius, girdleTop, 0)
+	lower := ring(radius, girdleBottom, 0)
+	tableCenter := center.Add(Vec3{Y: girdleTop + d*gemCrown})
+	culet := center.Add(Vec3{Y: girdleBottom - d*gemPavilion})
+
+	// The solid is convex and contains center, so a face is outward when its
+	// normal points away from center
+	stone := NewHittableList()
+	add := func(a, b, c Point3) {
+		if Dot(Cross(b.Sub(a), c.Sub(a)), a.Add(b).Add(c).Scale(1.0/3).Sub(center)) < 0 {
+			b, c = c, b
+		}
+		stone.Add(NewTriangle(a, b, c, mat))
+	}
+	for i := range facets {
+		j := (i + 1) % facets
+		add(tableCenter, table[i], table[j]) // Table
+		add(upper[i], upper[j], table[i])    // Crown main facets
+		add(table[i], upper[j], table[j])    // Crown star facets
+		add(upper[i], lower[i], lower[j])    // Girdle
+		add(upper[i], lower[j], upper[j])
+		add(lower[i], lower[j], culet) // Pavilion
+	}
+	return stone
+}
@@ -0,0 +1,66 @@
+package rt
+
+import "testing"
+
+func TestGemIsClosedAndOutward(t *testing.T) {
+	center := Point3{X: 1, Y: 2, Z: 3}
+	stone := Gem(center, 1.5, 8, NewGem(GemDiamond)).(*HittableList)
+	if want := 8 * 6; len(stone.Objects) != want {
+		t.Fatalf("%d triangles, want %d", len(stone.Objects), want)
+	}
+
+	// A closed, consistently wound mesh uses every directed edge exactly
+	// once, and its reverse exactly once
+	edges := map[[2]Point3]int{}
+	for _, obj := range stone.Objects {
+		tri := obj.(*Triangle)
+		centroid := tri.v0.Add(tri.v1).Add(tri.v2).Scale(1.0 / 3)
+		if Dot(tri.normal, centroid.Sub(center)) <= 0 {
+			t.Errorf("triangle at %v faces inward", centroid)
+		}
+		edges[[2]Point3{tri.v0, tri.v1}]++
+		edges[[2]Point3{tri.v1, tri.v2}]++
+		edges[[2]Point3{tri.v2, tri.v0}]++
+	}
+	for edge, n := range edges {
+		if n != 1 || edges[[2]Point3{edge[1], edge[0]}] != 1 {
+			t.Errorf("edge %v is used %d times, its reverse %d", edge, n, edges[[2]Point3{edge[1], edge[0]}])
+		}
+	}
+}
+
+func TestGemInternalBounces(t *testing.T) {
+	gem := NewGem(GemDiamond).SetMaxInternalBounces(2)
+	inside := &HitRecord{FrontFace: false}
+	outside := &HitRecord{FrontFace: true}
+
+	var path pathState
+	if d := scatterDepth(5, gem, outside, &path); d != 4 {
+		t.Errorf("entering the gem: depth %d, want 4", d)
+	}
+	for i := range 2 {
+		if d := scatterDepth(5, gem, inside, &path); d != 5 {
+			t.Errorf("internal bounce %d: depth %d, want 5 (free)", i+1, d)
+		}
+	}
+	if d := scatterDepth(5, gem, inside, &path); d != 0 {
+		t.Errorf("past the limit: depth %d, want the path to end", d)
+	}
+
+	// Plain glass counts every bounce
+	path = pathState{}
+	if d := scatterDepth(5, NewDielectric(1.5), inside, &path); d != 4 {
+		t.Errorf("plain glass: depth %d, want 4", d)
+	}
+}
+
+func TestParseGemPreset(t *testing.T) {
+	for _, name := range gemPresetNames {
+		if preset, err := ParseGemPreset(name); err != nil || preset.String() != name {
+			t.Errorf("ParseGemPreset(%q) = %v, %v", name, preset, err)
+		}
+	}
+	if _, err := ParseGemPreset("glass"); err == nil {
+		t.Error("accepted an unknown gem")
+	}
+}
@@ -0,0 +1,69 @@
+package rt
+
+// =============================================================================
+// GLOSSY FILTER (PATH REGULARIZATION)
+// =============================================================================
+
+// GlossyFilterConfig trades a little accuracy in indirect reflections for much
+// faster convergence. Reflections of reflections are rarely resolved by the
+// eye, yet sharp glossy chains are the noisiest paths to sample (metals can't
+// use light sampling). From StartBounce on, glossy materials get at least
+// MinRoughness; from DiffuseBounce on they are replaced by a diffuse surface
+// of the same color, which NEE can then light directly. Bounce 0 is the
+// camera hit, which is never filtered.
+type GlossyFilterConfig struct {
+	Enabled       bool
+	StartBounce   int     // First bounce that gets the roughness floor
+	MinRoughness  float64 // Roughness (Metal fuzz) floor on filtered bounces
+	DiffuseBounce int     // First bounce where glossy turns diffuse (0 = never)
+}
+
+// DefaultGlossyFilterConfig returns a disabled filter with V-Ray-like settings
+func DefaultGlossyFilterConfig() GlossyFilterConfig {
+	return GlossyFilterConfig{
+		Enabled:       false,
+		StartBounce:   1,
+		MinRoughness:  0.2,
+		DiffuseBounce: 3,
+	}
+}
+
+// glossyFilterable is implemented by materials the glossy filter can simplify
+type glossyFilterable interface {
+	withMinRoughness(roughness float64) Material
+	diffuseApproximation() Material
+}
+
+// SetGlossyFilter enables roughness clamping and specular-to-diffuse
+// conversion on deeper bounces
+func (c *Camera) SetGlossyFilter(config GlossyFilterConfig) *Camera {
+	c.GlossyFilter = config
+	return c
+}
+
+// filterMaterial returns the material to shade a hit at the given bounce with
+func (c *Camera) filterMaterial(mat Material, bounce int) Material {
+	config := c.GlossyFilter
+	if !config.Enabled || bounce < 1 || bounce < config.StartBounce {
+		return mat
+	}
+	filterable, ok := mat.(glossyFilterable)
+	if !ok {
+		return mat
+	}
+	if config.DiffuseBounce > 0 && bounce >= config.DiffuseBounce {
+		return filterable.diffuseApproximation()
+	}
+	return filterable.withMinRoughness(config.MinRoughness)
+}
+
+func (m *Metal) withMinRoughness(roughness float64) Material {
+	if m.Fuzz >= roughness {
+		return m
+	}
+	return &Metal{Albedo: m.Albedo, Fuzz: clampFloat(roughness, 0, 1)}
+}
+
+func (m *Metal) diffuseApproximation() Material {
+	return &Lambertian{tex: &SolidColor{Albedo: m.Albedo}}
+}
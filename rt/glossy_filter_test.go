@@ -0,0 +1,29 @@
+package rt
+
+import "testing"
+
+func TestGlossyFilterMaterial(t *testing.T) {
+	mirror := NewMetal(Color{X: 0.9, Y: 0.8, Z: 0.7}, 0)
+	camera := NewCamera().SetGlossyFilter(GlossyFilterConfig{
+		Enabled: true, StartBounce: 1, MinRoughness: 0.25, DiffuseBounce: 3,
+	})
+
+	if got := camera.filterMaterial(mirror, 0); got != mirror {
+		t.Errorf("camera hit was filtered: %#v", got)
+	}
+	if got, ok := camera.filterMaterial(mirror, 1).(*Metal); !ok || got.Fuzz != 0.25 || got.Albedo != mirror.Albedo {
+		t.Errorf("bounce 1 = %#v, want the mirror with fuzz 0.25", got)
+	}
+	if got, ok := camera.filterMaterial(mirror, 3).(*Lambertian); !ok || got.SurfaceAlbedo(0, 0, Point3{}) != mirror.Albedo {
+		t.Errorf("bounce 3 = %#v, want a Lambertian of the mirror's albedo", got)
+	}
+
+	rough := NewMetal(Color{X: 1, Y: 1, Z: 1}, 0.6)
+	if got := camera.filterMaterial(rough, 2); got != rough {
+		t.Errorf("rougher than the floor should be kept, got %#v", got)
+	}
+	glass := NewDielectric(1.5)
+	if got := camera.filterMaterial(glass, 5); got != glass {
+		t.Errorf("dielectrics are not filtered, got %#v", got)
+	}
+}
@@ -0,0 +1,251 @@
+package rt
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Golden-image regression tests
+//
+// Each case renders a tiny fixed-seed scene exercising one primitive or
+// material and compares it against a checked-in reference in
+// testdata/golden. Regenerate the references after an intentional change:
+//
+//	go test ./rt -run TestGolden -update
+
+var updateGolden = flag.Bool("update", false, "rewrite golden reference images")
+
+const (
+	goldenSize     = 64
+	goldenSPP      = 32
+	goldenDepth    = 8
+	goldenSeed     = 1
+	goldenDir      = "testdata/golden"
+	goldenBlock    = 8    // Block size for the noise-tolerant comparison
+	blockTolerance = 0.08 // Max mean channel difference per block [0, 1]
+	meanTolerance  = 0.02 // Max mean channel difference over the image [0, 1]
+)
+
+// goldenCase builds a scene; it runs after seeding so procedural textures
+// are reproducible too
+type goldenCase struct {
+	name  string
+	build func() (*HittableList, *Camera)
+}
+
+var (
+	goldenGray  = NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	goldenRed   = NewLambertian(Color{X: 0.8, Y: 0.2, Z: 0.2})
+	goldenFloor = Point3{X: 0, Y: -0.5, Z: 0}
+	goldenUp    = Vec3{X: 0, Y: 1, Z: 0}
+)
+
+// goldenCamera looks at the origin from the front under a sky gradient
+func goldenCamera() *Camera {
+	return NewCameraBuilder().
+		SetResolution(goldenSize, 1.0).
+		SetQuality(goldenSPP, goldenDepth).
+		SetPosition(Point3{X: 0, Y: 0.5, Z: 3}, Point3{X: 0, Y: 0, Z: 0}, goldenUp).
+		SetLens(45, 0, 3).
+		EnableSkyGradient(true)
+}
+
+// singleObject places obj above a gray ground plane
+func singleObject(obj Hittable) func() (*HittableList, *Camera) {
+	return func() (*HittableList, *Camera) {
+		world := NewHittableList()
+		world.Add(NewPlane(goldenFloor, goldenUp, goldenGray))
+		world.Add(obj)
+		return world, goldenCamera().Build()
+	}
+}
+
+// sphereWith places a sphere with the given material above the ground plane
+func sphereWith(mat Material) func() (*HittableList, *Camera) {
+	return func() (*HittableList, *Camera) {
+		return singleObject(NewSphere(Point3{X: 0, Y: 0, Z: 0}, 0.5, mat))()
+	}
+}
+
+var goldenCases = []goldenCase{
+	// Primitives
+	{"sphere", singleObject(NewSphere(Point3{X: 0, Y: 0, Z: 0}, 0.5, goldenRed))},
+	{"quad", singleObject(NewQuad(Point3{X: -0.5, Y: -0.5, Z: 0}, Vec3{X: 1, Y: 0, Z: 0}, Vec3{X: 0, Y: 1, Z: 0}, goldenRed))},
+	{"triangle", singleObject(NewTriangle(Point3{X: -0.6, Y: -0.5, Z: 0}, Point3{X: 0.6, Y: -0.5, Z: 0}, Point3{X: 0, Y: 0.6, Z: 0}, goldenRed))},
+	{"circle", singleObject(NewCircle(Point3{X: 0, Y: 0, Z: 0}, Vec3{X: 0, Y: 0, Z: 1}, 0.5, goldenRed))},
+	{"box", singleObject(Ry(Box(Point3{X: -0.4, Y: -0.5, Z: -0.4}, Point3{X: 0.4, Y: 0.3, Z: 0.4}, goldenRed), 30))},
+	{"pyramid", singleObject(Pyramid(Point3{X: 0, Y: -0.5, Z: 0}, 1, 1, goldenRed))},
+	{"scaled-translated", singleObject(NewTranslate(NewScale(NewSphere(Point3{X: 0, Y: 0, Z: 0}, 0.5, goldenRed), Vec3{X: 1.5, Y: 0.6, Z: 1}), Vec3{X: 0, Y: -0.2, Z: 0}))},
+	{"material-override", singleObject(NewTransform().SetPosition(Vec3{X: 0, Y: 0.1, Z: 0}).SetMaterial(NewMetal(Color{X: 0.8, Y: 0.6, Z: 0.2}, 0.1)).Apply(NewSphere(Point3{X: 0, Y: 0, Z: 0}, 0.5, goldenRed)))},
+	{"volume", singleObject(NewVolumeFromColor(NewSphere(Point3{X: 0, Y: 0, Z: 0}, 0.5, goldenGray), 2, Color{X: 0.9, Y: 0.9, Z: 0.9}))},
+
+	// Materials
+	{"lambertian", sphereWith(NewLambertian(Color{X: 0.2, Y: 0.4, Z: 0.8}))},
+	{"metal", sphereWith(NewMetal(Color{X: 0.8, Y: 0.6, Z: 0.2}, 0))},
+	{"metal-fuzz", sphereWith(NewMetal(Color{X: 0.8, Y: 0.8, Z: 0.8}, 0.4))},
+	{"dielectric", sphereWith(NewDielectric(1.5))},
+	{"checker", sphereWith(NewLambertianTexture(NewCheckerTextureFromColors(0.2, Color{X: 0.1, Y: 0.1, Z: 0.1}, Color{X: 0.9, Y: 0.9, Z: 0.9})))},
+	{"noise", func() (*HittableList, *Camera) {
+		// Perlin tables are random, so build the texture after seeding
+		return sphereWith(NewLambertianTexture(NewNoiseTexture(4)))()
+	}},
+	{"detail", func() (*HittableList, *Camera) {
+		detail := DetailConfig{BumpScale: 8, BumpStrength: 0.2, RoughnessScale: 4, RoughnessAmount: 0.3}
+		return sphereWith(WithDetail(NewMetal(Color{X: 0.8, Y: 0.8, Z: 0.8}, 0.1), detail))()
+	}},
+	{"area-light", func() (*HittableList, *Camera) {
+		light := NewQuad(Point3{X: -0.5, Y: 1.5, Z: -0.5}, Vec3{X: 1, Y: 0, Z: 0}, Vec3{X: 0, Y: 0, Z: 1},
+			NewDiffuseLightColor(Color{X: 8, Y: 8, Z: 8}))
+
+		world := NewHittableList()
+		world.Add(NewPlane(goldenFloor, goldenUp, goldenGray))
+		world.Add(NewSphere(Point3{X: 0, Y: 0, Z: 0}, 0.5, goldenRed))
+		world.Add(light)
+
+		camera := goldenCamera().
+			EnableSkyGradient(false).
+			SetBackground(BackgroundBlack).
+			AddLight(light).
+			Build()
+		return world, camera
+	}},
+}
+
+func TestGoldenImages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("golden renders are skipped in -short mode")
+	}
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	for _, tc := range goldenCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := renderGolden(tc.build)
+			path := filepath.Join(goldenDir, tc.name+".png")
+
+			if *updateGolden {
+				if err := writeGoldenPNG(path, got); err != nil {
+					t.Fatalf("writing reference: %v", err)
+				}
+				return
+			}
+
+			want, err := readGoldenPNG(path)
+			if err != nil {
+				t.Fatalf("reading reference (run with -update to create it): %v", err)
+			}
+			compareGolden(t, got, want)
+		})
+	}
+}
+
+// renderGolden renders a case single-threaded so the seeded stream of random
+// numbers, and therefore the image, is reproducible
+func renderGolden(build func() (*HittableList, *Camera)) *image.RGBA {
+	SeedRandom(goldenSeed)
+
+	world, camera := build()
+	bvh := NewBVHNodeFromList(world)
+
+	img := image.NewRGBA(image.Rect(0, 0, camera.ImageWidth, camera.ImageHeight))
+	for j := range camera.ImageHeight {
+		for i := range camera.ImageWidth {
+			pixelColor := Color{X: 0, Y: 0, Z: 0}
+			for range camera.SamplesPerPixel {
+				pixelColor = pixelColor.Add(camera.RayColor(camera.GetRay(i, j), camera.MaxDepth, bvh))
+			}
+			camera.writeColor(img, i, j, pixelColor)
+		}
+	}
+	return img
+}
+
+// compareGolden checks the images block by block so that differences in the
+// noise pattern (e.g. from FMA on other architectures) pass while changes in
+// shading or geometry fail
+func compareGolden(t *testing.T, got, want *image.RGBA) {
+	t.Helper()
+
+	if got.Bounds() != want.Bounds() {
+		t.Fatalf("size mismatch: got %v, want %v", got.Bounds(), want.Bounds())
+	}
+
+	bounds := got.Bounds()
+	totalDiff := 0.0
+	for by := 0; by < bounds.Dy(); by += goldenBlock {
+		for bx := 0; bx < bounds.Dx(); bx += goldenBlock {
+			var gotSum, wantSum [3]float64
+			n := 0
+			for y := by; y < min(by+goldenBlock, bounds.Dy()); y++ {
+				for x := bx; x < min(bx+goldenBlock, bounds.Dx()); x++ {
+					g, w := got.RGBAAt(x, y), want.RGBAAt(x, y)
+					gotSum[0] += float64(g.R)
+					gotSum[1] += float64(g.G)
+					gotSum[2] += float64(g.B)
+					wantSum[0] += float64(w.R)
+					wantSum[1] += float64(w.G)
+					wantSum[2] += float64(w.B)
+					totalDiff += channelDiff(g, w)
+					n++
+				}
+			}
+
+			for c := range 3 {
+				diff := math.Abs(gotSum[c]-wantSum[c]) / float64(n) / 255
+				if diff > blockTolerance {
+					t.Fatalf("block (%d, %d) channel %d differs by %.3f (tolerance %.3f)",
+						bx, by, c, diff, blockTolerance)
+				}
+			}
+		}
+	}
+
+	mean := totalDiff / float64(bounds.Dx()*bounds.Dy()*3) / 255
+	if mean > meanTolerance {
+		t.Fatalf("mean difference %.4f exceeds tolerance %.4f", mean, meanTolerance)
+	}
+}
+
+func channelDiff(a, b color.RGBA) float64 {
+	return math.Abs(float64(a.R)-float64(b.R)) +
+		math.Abs(float64(a.G)-float64(b.G)) +
+		math.Abs(float64(a.B)-float64(b.B))
+}
+
+func readGoldenPNG(path string) (*image.RGBA, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoded, err := png.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(decoded.Bounds())
+	for y := decoded.Bounds().Min.Y; y < decoded.Bounds().Max.Y; y++ {
+		for x := decoded.Bounds().Min.X; x < decoded.Bounds().Max.X; x++ {
+			img.Set(x, y, decoded.At(x, y))
+		}
+	}
+	return img, nil
+}
+
+func writeGoldenPNG(path string, img *image.RGBA) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return png.Encode(file, img)
+}
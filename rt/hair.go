@@ -0,0 +1,243 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// HAIR (MARSCHNER-STYLE FIBER SCATTERING)
+// =============================================================================
+
+// Melanin absorption per unit fiber radius (d'Eon et al.), mixed by the
+// eumelanin (brown-black) and pheomelanin (red-yellow) concentrations
+var (
+	eumelaninSigmaA   = Color{X: 0.419, Y: 0.697, Z: 1.37}
+	pheomelaninSigmaA = Color{X: 0.187, Y: 0.4, Z: 1.05}
+)
+
+// Hair scatters light like a dielectric fiber with pigmented interior,
+// following the Marschner lobes: R (white reflection off the cuticle),
+// TT (transmitted through the fiber, colored) and TRT plus all higher orders
+// (reflected inside, colored, the secondary highlight). Each scatter picks
+// one lobe at random by its energy, a random walk through the lobes.
+//
+// The material shades the surface it is on as the outside of a fiber running
+// along Direction. Model strands as closed tubes (see HairStrand, which sets
+// Direction per segment): light that scatters into the fiber passes through
+// its inner side unchanged, since the lobes already cover the path through
+// the fiber. Until curve primitives exist, tubes stand in for them. It is
+// sampled by BRDF only (no NEE), like other glossy materials.
+type Hair struct {
+	SigmaA    Color   // Absorption per unit fiber radius
+	IOR       float64 // Of the fiber (1.55 for keratin)
+	Tilt      float64 // Cuticle scale angle in degrees, shifts the lobes along the fiber
+	Roughness float64 // Longitudinal lobe width [0, 1]
+	Azimuthal float64 // Azimuthal lobe width [0, 1]
+	Direction Vec3    // Fiber direction (root to tip) in world space
+}
+
+// NewHair returns a hair colored by its melanin concentrations: eumelanin
+// from 0 (blond) through 1.3 (brown) to 8 (black), pheomelanin adding red
+func NewHair(eumelanin, pheomelanin float64) *Hair {
+	return newHair(eumelaninSigmaA.Scale(math.Max(0, eumelanin)).
+		Add(pheomelaninSigmaA.Scale(math.Max(0, pheomelanin))))
+}
+
+// NewHairColor returns a hair whose multiply scattered color approaches
+// color (e.g. a dyed or fur color that melanin cannot produce)
+func NewHairColor(c Color) *Hair {
+	h := newHair(Color{})
+	h.SigmaA = hairSigmaAFromColor(c, h.Azimuthal)
+	return h
+}
+
+func newHair(sigmaA Color) *Hair {
+	return &Hair{
+		SigmaA:    sigmaA,
+		IOR:       1.55,
+		Tilt:      2,
+		Roughness: 0.3,
+		Azimuthal: 0.3,
+		Direction: Vec3{X: 0, Y: 1, Z: 0},
+	}
+}
+
+// hairSigmaAFromColor inverts the multiple scattering color of a fiber with
+// azimuthal roughness beta (Chiang et al. 2016)
+func hairSigmaAFromColor(c Color, beta float64) Color {
+	d := 5.969 - 0.215*beta + 2.532*beta*beta - 10.73*math.Pow(beta, 3) +
+		5.574*math.Pow(beta, 4) + 0.245*math.Pow(beta, 5)
+	channel := func(v float64) float64 {
+		l := math.Log(clampFloat(v, 1e-4, 1)) / d
+		return l * l
+	}
+	return Color{X: channel(c.X), Y: channel(c.Y), Z: channel(c.Z)}
+}
+
+// SetRoughness sets the longitudinal and azimuthal lobe widths [0, 1]
+func (h *Hair) SetRoughness(longitudinal, azimuthal float64) *Hair {
+	h.Roughness = clampFloat(longitudinal, 0.01, 1)
+	h.Azimuthal = clampFloat(azimuthal, 0.01, 1)
+	return h
+}
+
+// SetDirection sets the fiber direction for surfaces without their own, e.g.
+// a combed direction for a fur mesh
+func (h *Hair) SetDirection(dir Vec3) *Hair {
+	h.Direction = dir.Unit()
+	return h
+}
+
+// alongFiber returns a copy running along dir
+func (h *Hair) alongFiber(dir Vec3) *Hair {
+	fiber := *h
+	return fiber.SetDirection(dir)
+}
+
+func (h *Hair) Properties() MaterialProperties {
+	return MaterialProperties{}
+}
+
+func (h *Hair) Emitted(u, v float64, p Point3) Color {
+	return Color{X: 0, Y: 0, Z: 0}
+}
+
+// hairLobes is the number of lobes: R, TT and TRT+
+const hairLobes = 3
+
+// lobeWeights returns the energy of each lobe for light arriving at
+// longitudinal angle sinThetaO and fiber offset offset [-1, 1]
+func (h *Hair) lobeWeights(sinThetaO, cosThetaO, offset float64) (weights [hairLobes]Color, gammaT float64) {
+	cosGammaO := math.Sqrt(math.Max(0, 1-offset*offset))
+	f := reflectance(cosThetaO*cosGammaO, h.IOR)
+
+	// Refracted direction inside the fiber, projected across and along it
+	sinThetaT := sinThetaO / h.IOR
+	cosThetaT := math.Sqrt(math.Max(0, 1-sinThetaT*sinThetaT))
+	etaP := math.Sqrt(math.Max(0, h.IOR*h.IOR-sinThetaO*sinThetaO)) / math.Max(cosThetaO, 1e-4)
+	sinGammaT := clampFloat(offset/etaP, -1, 1)
+	gammaT = math.Asin(sinGammaT)
+	cosGammaT := math.Sqrt(1 - sinGammaT*sinGammaT)
+
+	// Transmittance of one pass through the pigmented interior
+	length := 2 * cosGammaT / math.Max(cosThetaT, 1e-4)
+	t := Color{
+		X: math.Exp(-h.SigmaA.X * length),
+		Y: math.Exp(-h.SigmaA.Y * length),
+		Z: math.Exp(-h.SigmaA.Z * length),
+	}
+
+	weights[0] = Color{X: f, Y: f, Z: f}
+	weights[1] = t.Scale((1 - f) * (1 - f))
+	// TRT and every higher order: a geometric series in f*T
+	trt := func(tc float64) float64 {
+		return (1 - f) * (1 - f) * f * tc * tc / (1 - f*tc)
+	}
+	weights[2] = Color{X: trt(t.X), Y: trt(t.Y), Z: trt(t.Z)}
+	return weights, gammaT
+}
+
+// logisticSample draws from a logistic distribution of scale width centered
+// on 0
+func logisticSample(sampler *Sampler, width float64) float64 {
+	u := clampFloat(sampler.Float64(), 1e-6, 1-1e-6)
+	return -width * math.Log(1/u-1)
+}
+
+func (h *Hair) Scatter(rIn Ray, rec *HitRecord, attenuation *Color, scattered *Ray) bool {
+	dir := rIn.Direction().Unit()
+	if !rec.FrontFace {
+		// Leaving the inside of the fiber tube
+		*attenuation = Color{X: 1, Y: 1, Z: 1}
+		*scattered = NewRay(rec.P, dir, rIn.Time())
+		return true
+	}
+
+	// Frame: U along the fiber, W the surface normal, V across the fiber
+	basis := NewONBFromTangent(rec.Normal, h.Direction)
+	wo := dir.Neg()
+	sinThetaO := clampFloat(Dot(wo, basis.U), -1, 1)
+	cosThetaO := math.Sqrt(1 - sinThetaO*sinThetaO)
+	phiO := math.Atan2(Dot(wo, basis.V), Dot(wo, basis.W))
+	offset := clampFloat(math.Sin(phiO), -1, 1)
+	gammaO := math.Asin(offset)
+
+	weights, gammaT := h.lobeWeights(sinThetaO, cosThetaO, offset)
+	var lum [hairLobes]float64
+	total := 0.0
+	for p, w := range weights {
+		lum[p] = Luminance(w)
+		total += lum[p]
+	}
+	if total <= 0 {
+		return false
+	}
+
+	// Pick a lobe by energy
+	p, pick := 0, rIn.sampler.Float64()*total
+	for p < hairLobes-1 && pick >= lum[p] {
+		pick -= lum[p]
+		p++
+	}
+
+	// Longitudinal: mirrored about the fiber's normal plane, shifted by the
+	// cuticle tilt (2α for R, -α for TT, -4α for TRT); TT is half as wide as
+	// R and TRT twice as wide
+	tilt := h.Tilt * math.Pi / 180
+	shift := [hairLobes]float64{2 * tilt, -tilt, -4 * tilt}[p]
+	width := h.Roughness * [hairLobes]float64{0.5, 0.25, 1}[p]
+	thetaI := clampFloat(-math.Asin(sinThetaO)+shift+logisticSample(rIn.sampler, width), -math.Pi/2+1e-3, math.Pi/2-1e-3)
+
+	// Azimuthal: Φ(p) = 2pγt - 2γo + pπ, blurred by the azimuthal roughness
+	phi := phiO + 2*float64(p)*gammaT - 2*gammaO + float64(p)*math.Pi + logisticSample(rIn.sampler, h.Azimuthal)
+
+	scatterDir := basis.U.Scale(math.Sin(thetaI)).Add(
+		basis.W.Scale(math.Cos(thetaI) * math.Cos(phi)).Add(basis.V.Scale(math.Cos(thetaI) * math.Sin(phi))))
+	*scattered = NewRay(rec.P, scatterDir, rIn.Time())
+	*attenuation = weights[p].Scale(total / lum[p])
+	return true
+}
+
+// HairStrand builds a tube of the given radius and sides through points
+// (root to tip), tapering to a fifth of the radius at the tip. Each segment
+// gets a copy of mat running along it. The inner faces are culled, so light
+// sent into the fiber by its lobes leaves without extra bounces.
+func HairStrand(points []Point3, radius float64, sides int, mat *Hair) Hittable {
+	strand := NewHittableList()
+	if len(points) < 2 {
+		return strand
+	}
+	sides = max(3, sides)
+	opts := DefaultTriangleOptions()
+	opts.Cull = true
+
+	rings := make([][]Point3, len(points))
+	for i, p := range points {
+		tangent := points[min(i+1, len(points)-1)].Sub(points[max(i-1, 0)]).Unit()
+		frame := NewONB(tangent)
+		r := radius * (1 - 0.8*float64(i)/float64(len(points)-1))
+		rings[i] = make([]Point3, sides)
+		for k := range rings[i] {
+			phi := 2 * math.Pi * float64(k) / float64(sides)
+			rings[i][k] = p.Add(frame.U.Scale(r * math.Cos(phi))).Add(frame.V.Scale(r * math.Sin(phi)))
+		}
+	}
+
+	for i := 0; i+1 < len(points); i++ {
+		axis := points[i+1].Sub(points[i])
+		segmentMat := mat.alongFiber(axis)
+		mid := points[i].Add(axis.Scale(0.5))
+		for k := 0; k < sides; k++ {
+			a, b := rings[i][k], rings[i][(k+1)%sides]
+			c, d := rings[i+1][k], rings[i+1][(k+1)%sides]
+			for _, tri := range [][3]Point3{{a, b, d}, {a, d, c}} {
+				// Wind outward, away from the segment's axis
+				center := tri[0].Add(tri[1]).Add(tri[2]).Scale(1.0 / 3)
+				away := center.Sub(mid).Sub(axis.Unit().Scale(Dot(center.Sub(mid), axis.Unit())))
+				if Dot(Cross(tri[1].Sub(tri[0]), tri[2].Sub(tri[0])), away) < 0 {
+					tri[1], tri[2] = tri[2], tri[1]
+				}
+				strand.Add(NewTriangle(tri[0], tri[1], tri[2], segmentMat).SetOptions(opts))
+			}
+		}
+	}
+	return strand
+}
@@ -0,0 +1,56 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+// Hair lobes conserve energy: without pigment the fiber scatters everything,
+// with melanin it absorbs, and more eumelanin makes it darker and redder
+func TestHairLobeEnergy(t *testing.T) {
+	total := func(h *Hair, offset float64) Color {
+		weights, _ := h.lobeWeights(0.3, math.Sqrt(1-0.09), offset)
+		return weights[0].Add(weights[1]).Add(weights[2])
+	}
+	for _, offset := range []float64{0, 0.5, 0.95} {
+		clear := total(NewHair(0, 0), offset)
+		if math.Abs(clear.X-1) > 1e-9 || math.Abs(clear.Z-1) > 1e-9 {
+			t.Errorf("offset %g: unpigmented fiber scatters %v, want 1", offset, clear)
+		}
+	}
+
+	blond, brown := total(NewHair(0.3, 0), 0.2), total(NewHair(1.3, 0), 0.2)
+	if Luminance(brown) >= Luminance(blond) {
+		t.Errorf("brown hair (%v) should be darker than blond (%v)", brown, blond)
+	}
+	if brown.X <= brown.Z {
+		t.Errorf("brown hair should scatter more red than blue: %v", brown)
+	}
+}
+
+func TestHairScatterLeavesFiberTubes(t *testing.T) {
+	SeedRandom(7)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	strand := HairStrand([]Point3{{Y: -1}, {Y: 0}, {Y: 1}}, 0.1, 8, NewHair(1, 0))
+	ray := NewRay(Point3{Z: 2}, Vec3{Z: -1}, 0)
+	rec := &HitRecord{}
+	if !strand.Hit(ray, NewInterval(0.001, math.Inf(1)), rec) {
+		t.Fatal("ray missed the strand")
+	}
+	for range 200 {
+		var attenuation Color
+		var scattered Ray
+		if !rec.Mat.Scatter(ray, rec, &attenuation, &scattered) {
+			t.Fatal("hair absorbed the whole sample")
+		}
+		if !isFiniteColor(attenuation) || attenuation.X < 0 {
+			t.Fatalf("bad attenuation %v", attenuation)
+		}
+		// Culled inner faces: the scattered ray never hits its own tube from inside
+		next := &HitRecord{}
+		if strand.Hit(scattered, NewInterval(0.001, math.Inf(1)), next) && !next.FrontFace {
+			t.Fatalf("scattered ray hit the inside of the tube")
+		}
+	}
+}
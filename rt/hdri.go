@@ -15,6 +15,12 @@ type HDRIEnvironment struct {
 	width    int
 	height   int
 	rotation float64 // Rotation in radians
+	// Nearest-texel lookups, so radiance matches the piecewise-constant
+	// sampling PDF exactly (generated maps with a tiny, very bright sun)
+	nearest bool
+	// Full-resolution tiles read on demand; image is then a smaller proxy
+	// for importance sampling (nil = image is the full map)
+	stream *streamedImage
 
 	// Importance sampling data (optional)
 	useImportanceSampling bool
@@ -25,8 +31,31 @@ type HDRIEnvironment struct {
 	totalPower            float64     // Total integrated luminance
 }
 
-// NewHDRIEnvironment creates a new HDRI environment map from a file
+// NewHDRIEnvironment creates a new HDRI environment map from a file. With
+// SetAsyncAssetLoading the file loads in the background.
 func NewHDRIEnvironment(filename string) *HDRIEnvironment {
+	if asyncAssets.enabled.Load() {
+		return loadHDRIAsync(filename)
+	}
+	return loadHDRIEnvironment(filename)
+}
+
+// loadHDRIEnvironment loads filename and builds its importance sampling,
+// streaming it from tiles when SetHDRIStreaming is on and the map is large
+func loadHDRIEnvironment(filename string) *HDRIEnvironment {
+	if hdriStreaming.Enabled {
+		if path, err := FindAsset(filename, "hdri"); err == nil {
+			env, err := loadStreamedHDRI(path, hdriStreaming)
+			if err != nil {
+				fmt.Printf("Warning: Failed to stream HDRI '%s': %v\n", filename, err)
+				return &HDRIEnvironment{useImportanceSampling: true}
+			}
+			if env != nil {
+				return env
+			}
+		}
+	}
+
 	env := &HDRIEnvironment{
 		useImportanceSampling: true,
 		rotation:              0,
@@ -47,6 +76,39 @@ func NewHDRIEnvironment(filename string) *HDRIEnvironment {
 	return env
 }
 
+// NewUniformEnvironment creates an environment of constant radiance, e.g. the
+// white furnace used to check that materials neither gain nor lose energy.
+// It is a small equirectangular map so it also exercises importance sampling.
+func NewUniformEnvironment(radiance Color) *HDRIEnvironment {
+	const width, height = 64, 32
+
+	image := &ImageLoader{
+		data:        make([]Color, width*height),
+		imageWidth:  width,
+		imageHeight: height,
+		IsHDR:       true,
+	}
+	for i := range image.data {
+		image.data[i] = radiance
+	}
+
+	return newEnvironmentFromImage(image)
+}
+
+// newEnvironmentFromImage wraps a generated equirectangular image with
+// importance sampling enabled
+func newEnvironmentFromImage(image *ImageLoader) *HDRIEnvironment {
+	env := &HDRIEnvironment{
+		image:                 image,
+		width:                 image.Width(),
+		height:                image.Height(),
+		useImportanceSampling: true,
+	}
+	env.BuildDistribution()
+
+	return env
+}
+
 // SetRotation sets the rotation of the environment map in degrees
 func (env *HDRIEnvironment) SetRotation(degrees float64) {
 	env.rotation = degrees * math.Pi / 180.0
@@ -67,6 +129,32 @@ func (env *HDRIEnvironment) IsValid() bool {
 	return env.image != nil && env.image.data != nil
 }
 
+// Downsampled returns a box-filtered copy at most maxWidth pixels wide, with
+// its own importance sampling distribution. Interactive preview passes use it
+// to avoid touching the full-resolution map (and its CDFs) on large HDRIs.
+// Returns env itself when it is already small enough.
+func (env *HDRIEnvironment) Downsampled(maxWidth int) *HDRIEnvironment {
+	if !env.IsValid() || maxWidth <= 0 || env.width <= maxWidth {
+		return env
+	}
+
+	factor := (env.width + maxWidth - 1) / maxWidth
+	small := &HDRIEnvironment{
+		image:                 env.image.Downsample(factor),
+		rotation:              env.rotation,
+		nearest:               env.nearest,
+		useImportanceSampling: env.useImportanceSampling,
+	}
+	small.width = small.image.Width()
+	small.height = small.image.Height()
+
+	if small.useImportanceSampling {
+		small.BuildDistribution()
+	}
+
+	return small
+}
+
 // =============================================================================
 // EQUIRECTANGULAR MAPPING
 // =============================================================================
@@ -124,6 +212,14 @@ func (env *HDRIEnvironment) Sample(dir Vec3) Color {
 	}
 
 	u, v := env.DirectionToUV(dir)
+	switch {
+	case env.stream != nil && env.nearest:
+		return env.stream.nearest(u, v)
+	case env.stream != nil:
+		return env.stream.bilinear(u, v)
+	case env.nearest:
+		return env.image.PixelDataUV(u, v)
+	}
 	return env.image.PixelDataBilinear(u, v)
 }
 
@@ -134,6 +230,9 @@ func (env *HDRIEnvironment) SampleNearest(dir Vec3) Color {
 	}
 
 	u, v := env.DirectionToUV(dir)
+	if env.stream != nil {
+		return env.stream.nearest(u, v)
+	}
 	return env.image.PixelDataUV(u, v)
 }
 
@@ -146,6 +245,17 @@ func (env *HDRIEnvironment) BuildDistribution() {
 	if !env.IsValid() {
 		return
 	}
+	env.buildDistribution(nil)
+
+	fmt.Printf("HDRI: Built importance sampling distribution (%dx%d, total power: %.2f)\n",
+		env.width, env.height, env.totalPower)
+}
+
+// buildDistribution builds the sampling PDF and CDFs from luminance, scaled
+// per pixel by visibility(u, v) at the pixel center when it is non-nil.
+// totalPower stays the unscaled luminance integral, so the environment's
+// share of light selection is unaffected.
+func (env *HDRIEnvironment) buildDistribution(visibility func(u, v float64) float64) {
 
 	width := env.width
 	height := env.height
@@ -158,6 +268,7 @@ func (env *HDRIEnvironment) BuildDistribution() {
 
 	// Compute luminance-weighted PDF with sin(theta) correction
 	env.totalPower = 0
+	weightedPower := 0.0
 	rowSums := make([]float64, height)
 
 	for y := 0; y < height; y++ {
@@ -175,8 +286,7 @@ func (env *HDRIEnvironment) BuildDistribution() {
 			idx := y*width + x
 			color := env.image.PixelData(x, y)
 
-			// Luminance (Rec. 709)
-			luminance := 0.2126*color.X + 0.7152*color.Y + 0.0722*color.Z
+			luminance := Luminance(color)
 
 			// Weight by sin(theta) for uniform solid angle sampling
 			weight := luminance * sinTheta
@@ -184,9 +294,14 @@ func (env *HDRIEnvironment) BuildDistribution() {
 				weight = 0
 			}
 
+			env.totalPower += weight
+			if visibility != nil {
+				weight *= visibility((float64(x)+0.5)/float64(width), v)
+			}
+
 			env.pdf[idx] = weight
 			rowSums[y] += weight
-			env.totalPower += weight
+			weightedPower += weight
 
 			// Build conditional CDF for this row
 			env.conditionalCDFs[y][x+1] = env.conditionalCDFs[y][x] + weight
@@ -209,48 +324,49 @@ func (env *HDRIEnvironment) BuildDistribution() {
 	}
 
 	// Normalize marginal CDF
-	if env.totalPower > 0 {
+	if weightedPower > 0 {
 		for y := 0; y <= height; y++ {
-			env.marginalCDF[y] /= env.totalPower
+			env.marginalCDF[y] /= weightedPower
 		}
 		// Normalize PDF
 		for i := range env.pdf {
-			env.pdf[i] /= env.totalPower
+			env.pdf[i] /= weightedPower
 		}
 	}
-
-	fmt.Printf("HDRI: Built importance sampling distribution (%dx%d, total power: %.2f)\n",
-		width, height, env.totalPower)
 }
 
 // SampleDirection samples a direction from the HDRI using importance sampling
 // Returns: direction, emission color, and PDF value
 func (env *HDRIEnvironment) SampleDirection() (Vec3, Color, float64) {
+	return env.sampleDirection(nil)
+}
+
+func (env *HDRIEnvironment) sampleDirection(s *Sampler) (Vec3, Color, float64) {
 	if !env.IsValid() || !env.useImportanceSampling || env.totalPower == 0 {
 		// Fallback to uniform sphere sampling
-		dir := RandomUnitVector()
-		emission := env.Sample(dir)
-		pdf := 1.0 / (4.0 * math.Pi)
-		return dir, emission, pdf
+		dir := sampleUniformSphere(s)
+		return dir, env.Sample(dir), UniformSpherePDF()
 	}
 
 	// Sample row using marginal CDF (inverse transform sampling)
-	xi1 := RandomDouble()
+	xi1 := s.Float64()
 	y := env.searchCDF(env.marginalCDF, xi1)
 
 	// Sample column using conditional CDF for selected row
-	xi2 := RandomDouble()
+	xi2 := s.Float64()
 	x := env.searchCDF(env.conditionalCDFs[y], xi2)
 
-	// Convert to UV
-	u := (float64(x) + 0.5) / float64(env.width)
-	v := (float64(y) + 0.5) / float64(env.height)
+	// Jitter within the selected pixel so the sampled density is piecewise
+	// constant over the image, matching what PDF() evaluates
+	u := (float64(x) + s.Float64()) / float64(env.width)
+	v := (float64(y) + s.Float64()) / float64(env.height)
 
-	// Convert to direction
+	// Convert to direction (undoes rotation)
 	dir := env.UVToDirection(u, v)
 
-	// Get emission
-	emission := env.image.PixelData(x, y)
+	// Emission goes through the same rotated, bilinear lookup as escaped rays
+	// so NEE and BRDF-sampled paths agree on radiance
+	emission := env.Sample(dir)
 
 	// Compute PDF
 	pdf := env.PDF(dir)
@@ -261,32 +377,29 @@ func (env *HDRIEnvironment) SampleDirection() (Vec3, Color, float64) {
 // PDF returns the probability density for sampling a given direction
 func (env *HDRIEnvironment) PDF(dir Vec3) float64 {
 	if !env.IsValid() || !env.useImportanceSampling || env.totalPower == 0 {
-		return 1.0 / (4.0 * math.Pi) // Uniform sphere
+		return UniformSpherePDF()
 	}
 
+	// DirectionToUV applies the rotation, so (x, y) is the same pixel the
+	// sampler picked for this direction
 	u, v := env.DirectionToUV(dir)
 
 	// Get pixel coordinates
-	x := int(u * float64(env.width))
-	y := int(v * float64(env.height))
-
-	// Clamp to valid range
-	x = clamp(x, 0, env.width)
-	y = clamp(y, 0, env.height)
+	x := clamp(int(u*float64(env.width)), 0, env.width)
+	y := clamp(int(v*float64(env.height)), 0, env.height)
 
 	idx := y*env.width + x
 
-	// Convert from per-pixel PDF to solid angle PDF
-	// PDF_solidAngle = PDF_pixel * (width * height) / (4 * π)
-	// But we also need to account for the sin(theta) factor
+	// Jacobian of the equirectangular mapping: dω = 2π² sin(θ) du dv, where
+	// sin(θ) of the polar angle is cos() of our elevation angle
 	theta := (0.5 - v) * math.Pi
 	sinTheta := math.Cos(theta)
 	if sinTheta < 1e-10 {
 		sinTheta = 1e-10
 	}
 
-	// The stored PDF is already weighted by sin(theta), so we need to divide by it
-	// and multiply by the Jacobian
+	// The stored PDF is per pixel; scale by pixel count to get a density over
+	// [0,1]² and divide by the Jacobian for solid angle
 	pdfSolidAngle := env.pdf[idx] * float64(env.width*env.height) / (2.0 * math.Pi * math.Pi * sinTheta)
 
 	if pdfSolidAngle < 1e-10 {
@@ -321,6 +434,19 @@ func (env *HDRIEnvironment) searchCDF(cdf []float64, xi float64) int {
 	return low
 }
 
+// Power returns the power the environment delivers into a scene of the given
+// bounding radius (π r² ∫L dω), used to weigh it against area lights
+func (env *HDRIEnvironment) Power(sceneRadius float64) float64 {
+	if !env.IsValid() || env.totalPower == 0 {
+		return 0
+	}
+
+	// totalPower is a sin(θ)-weighted luminance sum, so scaling by the
+	// per-pixel solid angle factor gives the integral over the sphere
+	integratedRadiance := env.totalPower * 2.0 * math.Pi * math.Pi / float64(env.width*env.height)
+	return math.Pi * sceneRadius * sceneRadius * integratedRadiance
+}
+
 // TotalPower returns the total integrated power of the environment map
 func (env *HDRIEnvironment) TotalPower() float64 {
 	return env.totalPower
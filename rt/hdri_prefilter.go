@@ -0,0 +1,296 @@
+package rt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+)
+
+// =============================================================================
+// HDRI PREFILTERING (IRRADIANCE AND SPECULAR MAPS)
+// =============================================================================
+
+// PrefilterConfig controls PrefilterEnvironment
+type PrefilterConfig struct {
+	IrradianceWidth int // Width of the irradiance map (height = width/2)
+	SpecularWidth   int // Width of the sharpest specular level; each level halves it (min 8)
+	Levels          int // Specular levels, from roughness 0 to 1
+	Samples         int // GGX samples per texel of each rough level
+}
+
+// DefaultPrefilterConfig returns a 64 px irradiance map and six specular
+// levels from 256 px down, at 256 samples per texel
+func DefaultPrefilterConfig() PrefilterConfig {
+	return PrefilterConfig{
+		IrradianceWidth: 64,
+		SpecularWidth:   256,
+		Levels:          6,
+		Samples:         256,
+	}
+}
+
+// PrefilteredEnvironment is an HDRI baked for image-based lighting the way
+// real-time engines use it: a diffuse irradiance map and the split-sum GGX
+// prefiltered specular levels. All maps are equirectangular in the HDRI's
+// own layout.
+type PrefilteredEnvironment struct {
+	Irradiance *ImageLoader   // Cosine-convolved radiance (irradiance / π): multiply by albedo for Lambertian shading
+	Specular   []*ImageLoader // GGX-prefiltered radiance; level i is roughness i/(len-1)
+	Rotation   float64        // Rotation in radians, as on the source HDRIEnvironment
+}
+
+// PrefilterEnvironment bakes env into irradiance and specular maps. Large
+// maps are downsampled first; streamed maps use their in-memory proxy.
+func PrefilterEnvironment(env *HDRIEnvironment, config PrefilterConfig) (*PrefilteredEnvironment, error) {
+	if env == nil || !env.IsValid() {
+		return nil, fmt.Errorf("prefilter: no environment map loaded")
+	}
+	if config.IrradianceWidth < 2 || config.SpecularWidth < 2 || config.Levels < 1 || config.Samples < 1 {
+		return nil, fmt.Errorf("prefilter: invalid config %+v", config)
+	}
+
+	// Mip chain of the source for filtered importance sampling
+	mips := []*ImageLoader{downsampleToWidth(env.image, 1024)}
+	for last := mips[0]; last.imageWidth > 8; {
+		last = last.Downsample(2)
+		mips = append(mips, last)
+	}
+
+	p := &PrefilteredEnvironment{
+		Irradiance: bakeIrradiance(downsampleToWidth(env.image, 128), config.IrradianceWidth),
+		Rotation:   env.rotation,
+	}
+	for level := 0; level < config.Levels; level++ {
+		width := max(8, config.SpecularWidth>>level)
+		roughness := 0.0
+		if config.Levels > 1 {
+			roughness = float64(level) / float64(config.Levels-1)
+		}
+		p.Specular = append(p.Specular, bakeSpecular(mips, width, roughness, config.Samples))
+	}
+	return p, nil
+}
+
+// downsampleToWidth box-filters img to at most maxWidth pixels wide
+func downsampleToWidth(img *ImageLoader, maxWidth int) *ImageLoader {
+	return img.Downsample((img.imageWidth + maxWidth - 1) / maxWidth)
+}
+
+// bakeIrradiance integrates the cosine lobe around every texel's direction
+// over all texels of src
+func bakeIrradiance(src *ImageLoader, width int) *ImageLoader {
+	layout := &HDRIEnvironment{width: src.imageWidth, height: src.imageHeight}
+	dirs := make([]Vec3, len(src.data))
+	weighted := make([]Color, len(src.data))
+	texelAngle := (2 * math.Pi / float64(src.imageWidth)) * (math.Pi / float64(src.imageHeight))
+	for i, c := range src.data {
+		u := (float64(i%src.imageWidth) + 0.5) / float64(src.imageWidth)
+		v := (float64(i/src.imageWidth) + 0.5) / float64(src.imageHeight)
+		dirs[i] = layout.UVToDirection(u, v)
+		// Texel solid angle shrinks with cos(latitude) = sqrt(1 - y²)
+		solidAngle := texelAngle * math.Sqrt(math.Max(0, 1-dirs[i].Y*dirs[i].Y))
+		weighted[i] = c.Scale(solidAngle / math.Pi)
+	}
+
+	return bakeEquirect(width, max(1, width/2), func(_, _ float64, normal Vec3) Color {
+		var sum Color
+		for i, dir := range dirs {
+			if cos := Dot(normal, dir); cos > 0 {
+				sum = sum.Add(weighted[i].Scale(cos))
+			}
+		}
+		return sum
+	})
+}
+
+// bakeSpecular prefilters mips with a GGX lobe of the given roughness,
+// assuming the view and normal lie along the lookup direction (split sum).
+// Each sample reads the mip whose texels match its share of the lobe's solid
+// angle, which keeps bright spots from turning into speckles.
+func bakeSpecular(mips []*ImageLoader, width int, roughness float64, samples int) *ImageLoader {
+	base := mips[0]
+	layout := &HDRIEnvironment{}
+	lookup := func(img *ImageLoader, dir Vec3) Color {
+		u, v := layout.DirectionToUV(dir)
+		return img.PixelDataBilinear(u, v)
+	}
+	if roughness == 0 {
+		mirror := downsampleMip(mips, width)
+		return bakeEquirect(width, max(1, width/2), func(_, _ float64, dir Vec3) Color {
+			return lookup(mirror, dir)
+		})
+	}
+
+	alpha := roughness * roughness
+	texelSolidAngle := 4 * math.Pi / float64(base.imageWidth*base.imageHeight)
+	return bakeEquirect(width, max(1, width/2), func(_, _ float64, n Vec3) Color {
+		basis := NewONB(n)
+		var sum Color
+		weight := 0.0
+		for i := 0; i < samples; i++ {
+			x, y := hammersley(i, samples)
+			h := basis.Local(ggxHalfVector(x, y, alpha))
+			l := Reflect(n.Neg(), h)
+			cosL := Dot(n, l)
+			if cosL <= 0 {
+				continue
+			}
+			// With n = v the sample density of l is D(h)/4
+			pdf := GGXD(Dot(n, h), alpha) / 4
+			sampleSolidAngle := 1 / (float64(samples) * pdf)
+			level := clampFloat(0.5*math.Log2(sampleSolidAngle/texelSolidAngle)+1, 0, float64(len(mips)-1))
+			sum = sum.Add(lookup(mips[int(level+0.5)], l).Scale(cosL))
+			weight += cosL
+		}
+		if weight == 0 {
+			return lookup(base, n)
+		}
+		return sum.Scale(1 / weight)
+	})
+}
+
+// downsampleMip returns the smallest mip at least width pixels wide
+func downsampleMip(mips []*ImageLoader, width int) *ImageLoader {
+	for i := len(mips) - 1; i >= 0; i-- {
+		if mips[i].imageWidth >= width {
+			return mips[i]
+		}
+	}
+	return mips[0]
+}
+
+// hammersley returns the i-th of n points of the Hammersley set
+func hammersley(i, n int) (float64, float64) {
+	return (float64(i) + 0.5) / float64(n), float64(bits.Reverse32(uint32(i))) / (1 << 32)
+}
+
+// ggxHalfVector maps a point of the unit square to a GGX-distributed half
+// vector around +Z (SampleGGXHalfVector for a given point)
+func ggxHalfVector(x, y float64, alpha float64) Vec3 {
+	phi := 2 * math.Pi * y
+	cosTheta := math.Sqrt((1 - x) / (1 + (alpha*alpha-1)*x))
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	return Vec3{X: sinTheta * math.Cos(phi), Y: sinTheta * math.Sin(phi), Z: cosTheta}
+}
+
+// IrradianceAt returns the diffuse lighting (irradiance / π) of a surface
+// with the given normal
+func (p *PrefilteredEnvironment) IrradianceAt(normal Vec3) Color {
+	layout := &HDRIEnvironment{rotation: p.Rotation}
+	u, v := layout.DirectionToUV(normal)
+	return p.Irradiance.PixelDataBilinear(u, v)
+}
+
+// SpecularAt returns the prefiltered radiance around reflection direction dir
+// for a roughness in [0, 1], blending the two nearest levels
+func (p *PrefilteredEnvironment) SpecularAt(dir Vec3, roughness float64) Color {
+	layout := &HDRIEnvironment{rotation: p.Rotation}
+	u, v := layout.DirectionToUV(dir)
+	if len(p.Specular) == 1 {
+		return p.Specular[0].PixelDataBilinear(u, v)
+	}
+	level := clampFloat(roughness, 0, 1) * float64(len(p.Specular)-1)
+	lower := min(int(level), len(p.Specular)-2)
+	t := level - float64(lower)
+	a := p.Specular[lower].PixelDataBilinear(u, v)
+	b := p.Specular[lower+1].PixelDataBilinear(u, v)
+	return a.Scale(1 - t).Add(b.Scale(t))
+}
+
+// Save writes the maps as prefix_irradiance.pfm and prefix_specular_<i>.pfm
+func (p *PrefilteredEnvironment) Save(prefix string) error {
+	if err := saveImagePFM(p.Irradiance, prefix+"_irradiance.pfm"); err != nil {
+		return err
+	}
+	for i, level := range p.Specular {
+		if err := saveImagePFM(level, fmt.Sprintf("%s_specular_%d.pfm", prefix, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadPrefilteredEnvironment reads maps written by Save. The specular levels
+// are read from 0 up to the first one missing.
+func LoadPrefilteredEnvironment(prefix string) (*PrefilteredEnvironment, error) {
+	irradiance, err := loadImagePFM(prefix + "_irradiance.pfm")
+	if err != nil {
+		return nil, err
+	}
+	p := &PrefilteredEnvironment{Irradiance: irradiance}
+	for i := 0; ; i++ {
+		level, err := loadImagePFM(fmt.Sprintf("%s_specular_%d.pfm", prefix, i))
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.Specular = append(p.Specular, level)
+	}
+	if len(p.Specular) == 0 {
+		return nil, fmt.Errorf("no specular levels found for %s", prefix)
+	}
+	return p, nil
+}
+
+// saveImagePFM writes img through a one-sample film
+func saveImagePFM(img *ImageLoader, filename string) error {
+	film := NewFilm(img.imageWidth, img.imageHeight)
+	for i, c := range img.data {
+		film.AddSamples(i%img.imageWidth, i/img.imageWidth, c, 1)
+	}
+	return film.SavePFM(filename)
+}
+
+// loadImagePFM reads a .pfm file
+func loadImagePFM(filename string) (*ImageLoader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img := NewImageLoader()
+	if err := img.DecodePFM(file); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return img, nil
+}
+
+// =============================================================================
+// PREFILTERED ROUGH REFLECTIONS
+// =============================================================================
+
+// prefilterReflection is the mirror direction and roughness of a rough
+// reflection whose escaping rays read the prefiltered environment
+type prefilterReflection struct {
+	dir       Vec3
+	roughness float64 // 0 = not a prefiltered reflection
+}
+
+// SetPrefilteredEnvironment makes rays that rough metals reflect into the
+// environment read p's specular levels around the mirror direction, at the
+// metal's fuzz as roughness, instead of the HDRI along the sampled
+// direction. Rough reflections of the sky then converge with few samples,
+// at the cost of the approximations real-time engines make (fuzz is taken
+// as GGX roughness, and the split sum assumes a view along the normal).
+// Occlusion is still traced. p should be baked from the camera's HDRI.
+func (c *Camera) SetPrefilteredEnvironment(p *PrefilteredEnvironment) *Camera {
+	if p != nil && c.Environment != nil {
+		p.Rotation = c.Environment.rotation
+	}
+	c.prefiltered = p
+	return c
+}
+
+// prefilterReflection returns the prefiltered lookup for rays scattered by
+// mat at rec (zero when prefiltering is off or mat isn't a rough metal)
+func (c *Camera) prefilterReflection(mat Material, r Ray, rec *HitRecord) prefilterReflection {
+	metal, ok := mat.(*Metal)
+	if c.prefiltered == nil || !ok || metal.Fuzz <= 0 {
+		return prefilterReflection{}
+	}
+	return prefilterReflection{dir: Reflect(r.Direction().Unit(), rec.Normal), roughness: metal.Fuzz}
+}
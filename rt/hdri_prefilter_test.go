@@ -0,0 +1,124 @@
+package rt
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// testGradientHDRI is a map that is bright at the top and dark at the bottom
+func testGradientHDRI() *HDRIEnvironment {
+	const width, height = 64, 32
+	image := &ImageLoader{
+		data:        make([]Color, width*height),
+		imageWidth:  width,
+		imageHeight: height,
+		IsHDR:       true,
+	}
+	for i := range image.data {
+		y := float64(i/width) / height
+		image.data[i] = Color{X: 2 - 2*y, Y: 1, Z: 0.5}
+	}
+	return newEnvironmentFromImage(image)
+}
+
+func smallPrefilterConfig() PrefilterConfig {
+	return PrefilterConfig{IrradianceWidth: 16, SpecularWidth: 32, Levels: 3, Samples: 64}
+}
+
+func TestPrefilterUniformEnvironment(t *testing.T) {
+	radiance := Color{X: 0.25, Y: 0.5, Z: 1}
+	p, err := PrefilterEnvironment(NewUniformEnvironment(radiance), smallPrefilterConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A uniform environment has irradiance π·L, and every lobe sees L
+	for _, dir := range []Vec3{{Y: 1}, {Y: -1}, {X: 1}, {X: -0.6, Y: 0.8}} {
+		if got := p.IrradianceAt(dir); !colorNear(got, radiance, 0.03) {
+			t.Errorf("irradiance toward %v = %v, want %v", dir, got, radiance)
+		}
+		for _, roughness := range []float64{0, 0.3, 1} {
+			if got := p.SpecularAt(dir, roughness); !colorNear(got, radiance, 0.01) {
+				t.Errorf("specular toward %v at roughness %v = %v, want %v", dir, roughness, got, radiance)
+			}
+		}
+	}
+}
+
+func TestPrefilterBlursWithRoughness(t *testing.T) {
+	p, err := PrefilterEnvironment(testGradientHDRI(), smallPrefilterConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Red falls from 2 at the zenith to 0 at the nadir; wider lobes pull the
+	// zenith value toward the mean
+	up := Vec3{Y: 1}
+	sharp, rough := p.SpecularAt(up, 0).X, p.SpecularAt(up, 1).X
+	if sharp < 1.8 || rough >= sharp || rough <= 1 {
+		t.Errorf("zenith red: sharp %v, rough %v", sharp, rough)
+	}
+	if irradiance := p.IrradianceAt(up).X; irradiance >= sharp || irradiance <= 1 {
+		t.Errorf("zenith irradiance red %v, sharp %v", irradiance, sharp)
+	}
+}
+
+func TestPrefilteredEnvironmentSaveLoad(t *testing.T) {
+	p, err := PrefilterEnvironment(testGradientHDRI(), smallPrefilterConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := filepath.Join(t.TempDir(), "env")
+	if err := p.Save(prefix); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPrefilteredEnvironment(prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Specular) != len(p.Specular) {
+		t.Fatalf("loaded %d specular levels, want %d", len(loaded.Specular), len(p.Specular))
+	}
+	// PFM stores float32
+	for _, dir := range []Vec3{{Y: 1}, {X: 0.6, Y: -0.8}} {
+		if got, want := loaded.IrradianceAt(dir), p.IrradianceAt(dir); !colorNear(got, want, 1e-5) {
+			t.Errorf("irradiance toward %v = %v, want %v", dir, got, want)
+		}
+		if got, want := loaded.SpecularAt(dir, 0.7), p.SpecularAt(dir, 0.7); !colorNear(got, want, 1e-5) {
+			t.Errorf("specular toward %v = %v, want %v", dir, got, want)
+		}
+	}
+	if _, err := LoadPrefilteredEnvironment(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for missing maps")
+	}
+}
+
+func TestDecodePFMOrientation(t *testing.T) {
+	// PFM rows run bottom to top: the first row in the file is the bottom one
+	film := NewFilm(2, 2)
+	film.AddSamples(0, 0, Color{X: 1}, 1)
+	film.AddSamples(1, 1, Color{Z: 3}, 1)
+	var buf bytes.Buffer
+	if err := film.WritePFM(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img := NewImageLoader()
+	if err := img.DecodePFM(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if img.Width() != 2 || img.Height() != 2 {
+		t.Fatalf("size %dx%d", img.Width(), img.Height())
+	}
+	if got := img.data[0]; got != (Color{X: 1}) {
+		t.Errorf("top-left = %v", got)
+	}
+	if got := img.data[3]; got != (Color{Z: 3}) {
+		t.Errorf("bottom-right = %v", got)
+	}
+}
+
+func colorNear(a, b Color, tolerance float64) bool {
+	return math.Abs(a.X-b.X) <= tolerance*math.Max(1, math.Abs(b.X)) &&
+		math.Abs(a.Y-b.Y) <= tolerance*math.Max(1, math.Abs(b.Y)) &&
+		math.Abs(a.Z-b.Z) <= tolerance*math.Max(1, math.Abs(b.Z))
+}
@@ -0,0 +1,258 @@
+package rt
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+)
+
+// =============================================================================
+// STREAMED (PAGED) HDRI ENVIRONMENTS
+// =============================================================================
+
+// HDRIStreamConfig controls paging of large environment maps. A streamed map
+// is decoded once into fixed-size tiles in a scratch file; lookups read the
+// tiles they touch through an LRU cache, so memory stays bounded however
+// large the map. Importance sampling, power estimates and preview passes
+// use an in-memory proxy at most ProxyWidth pixels wide.
+type HDRIStreamConfig struct {
+	Enabled    bool
+	TileSize   int    // Tile edge in pixels
+	CacheMB    int    // Memory for decoded tiles
+	ProxyWidth int    // Maps no wider than this are loaded whole
+	TileDir    string // Directory for the scratch tile file ("" = system temp)
+}
+
+// DefaultHDRIStreamConfig returns streaming off, with 256-pixel tiles, a
+// 256 MB cache and a 2K proxy once enabled
+func DefaultHDRIStreamConfig() HDRIStreamConfig {
+	return HDRIStreamConfig{
+		TileSize:   256,
+		CacheMB:    256,
+		ProxyWidth: 2048,
+	}
+}
+
+var hdriStreaming = DefaultHDRIStreamConfig()
+
+// SetHDRIStreaming makes HDRI environments loaded afterwards stream from
+// tiles when they are wider than config.ProxyWidth, e.g. 16K maps for final
+// frames that would otherwise take gigabytes
+func SetHDRIStreaming(config HDRIStreamConfig) {
+	hdriStreaming = config
+}
+
+// streamedImage is a full-resolution image read tile by tile from disk
+type streamedImage struct {
+	width, height  int
+	tileSize       int
+	tilesX, tilesY int
+	file           *os.File
+	cache          *tileCache
+}
+
+// tileBytes is the on-disk size of one tile: RGB float32 per pixel
+func (s *streamedImage) tileBytes() int {
+	return s.tileSize * s.tileSize * 12
+}
+
+// loadTile reads tile index from the scratch file
+func (s *streamedImage) loadTile(index int) []Color {
+	buf := make([]byte, s.tileBytes())
+	tile := make([]Color, s.tileSize*s.tileSize)
+	if _, err := s.file.ReadAt(buf, int64(index)*int64(len(buf))); err != nil {
+		return tile
+	}
+	for i := range tile {
+		tile[i] = Color{
+			X: float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[i*12:]))),
+			Y: float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[i*12+4:]))),
+			Z: float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[i*12+8:]))),
+		}
+	}
+	return tile
+}
+
+// texel returns the pixel at (x, y), clamped to the image
+func (s *streamedImage) texel(x, y int) Color {
+	x = clamp(x, 0, s.width)
+	y = clamp(y, 0, s.height)
+	tx, ty := x/s.tileSize, y/s.tileSize
+	tile := s.cache.get(ty*s.tilesX + tx)
+	return tile[(y-ty*s.tileSize)*s.tileSize+(x-tx*s.tileSize)]
+}
+
+// nearest matches ImageLoader.PixelDataUV
+func (s *streamedImage) nearest(u, v float64) Color {
+	return s.texel(int(u*float64(s.width)), int(v*float64(s.height)))
+}
+
+// bilinear matches ImageLoader.PixelDataBilinear: u wraps, v clamps
+func (s *streamedImage) bilinear(u, v float64) Color {
+	texel := func(x, y int) Color {
+		return s.texel(((x%s.width)+s.width)%s.width, y)
+	}
+	return bilinearTexels(texel, u*float64(s.width)-0.5, v*float64(s.height)-0.5)
+}
+
+// tileCache keeps the most recently used tiles in memory
+type tileCache struct {
+	mu       sync.Mutex
+	capacity int
+	tiles    map[int]*list.Element
+	order    *list.List // Of *cachedTile, most recent first
+	load     func(index int) []Color
+}
+
+type cachedTile struct {
+	index int
+	data  []Color
+}
+
+func newTileCache(capacity int, load func(index int) []Color) *tileCache {
+	return &tileCache{
+		capacity: max(1, capacity),
+		tiles:    make(map[int]*list.Element),
+		order:    list.New(),
+		load:     load,
+	}
+}
+
+// get returns a tile, reading it without holding the lock on a miss so
+// workers hitting other tiles don't wait on the disk
+func (c *tileCache) get(index int) []Color {
+	c.mu.Lock()
+	if e, ok := c.tiles[index]; ok {
+		c.order.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*cachedTile).data
+	}
+	c.mu.Unlock()
+
+	data := c.load(index)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.tiles[index]; ok {
+		// Another worker loaded it meanwhile
+		return e.Value.(*cachedTile).data
+	}
+	c.tiles[index] = c.order.PushFront(&cachedTile{index: index, data: data})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.tiles, oldest.Value.(*cachedTile).index)
+	}
+	return data
+}
+
+// loadStreamedHDRI decodes a Radiance HDR file scanline by scanline into a
+// tile file and a box-filtered proxy, never holding the full map in memory.
+// Maps that fit in the proxy are loaded whole instead.
+func loadStreamedHDRI(path string, config HDRIStreamConfig) (*HDRIEnvironment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+
+	width, height, err := (&ImageLoader{}).parseHDRHeader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HDR header: %w", err)
+	}
+	proxyWidth := max(64, config.ProxyWidth)
+	if width <= proxyWidth {
+		return nil, nil
+	}
+
+	tileSize := max(16, config.TileSize)
+	stream := &streamedImage{
+		width:    width,
+		height:   height,
+		tileSize: tileSize,
+		tilesX:   (width + tileSize - 1) / tileSize,
+		tilesY:   (height + tileSize - 1) / tileSize,
+	}
+	stream.file, err = os.CreateTemp(config.TileDir, "hdri-tiles-*.bin")
+	if err != nil {
+		return nil, err
+	}
+	// The open handle keeps the data; on systems that can't unlink open
+	// files the scratch file stays in the temp directory
+	_ = os.Remove(stream.file.Name())
+
+	factor := (width + proxyWidth - 1) / proxyWidth
+	proxyW, proxyH := (width+factor-1)/factor, (height+factor-1)/factor
+	proxySum := make([]Color, proxyW*proxyH)
+	proxyCount := make([]int, proxyW*proxyH)
+
+	row := make([]Color, width)
+	band := make([]float32, width*tileSize*3)
+	tileBuf := make([]byte, stream.tileBytes())
+	for y := 0; y < height; y++ {
+		if err := readHDRScanline(reader, row); err != nil {
+			stream.file.Close()
+			return nil, fmt.Errorf("failed to read HDR scanline %d: %w", y, err)
+		}
+		bandRow := y % tileSize
+		for x, c := range row {
+			i := (bandRow*width + x) * 3
+			band[i], band[i+1], band[i+2] = float32(c.X), float32(c.Y), float32(c.Z)
+			p := (y/factor)*proxyW + x/factor
+			proxySum[p] = proxySum[p].Add(c)
+			proxyCount[p]++
+		}
+		if bandRow == tileSize-1 || y == height-1 {
+			if err := stream.writeBand(y/tileSize, bandRow+1, band, tileBuf); err != nil {
+				stream.file.Close()
+				return nil, err
+			}
+		}
+	}
+
+	for i, n := range proxyCount {
+		proxySum[i] = proxySum[i].Scale(1 / float64(max(n, 1)))
+	}
+	proxy := &ImageLoader{
+		data:        proxySum,
+		imageWidth:  proxyW,
+		imageHeight: proxyH,
+		IsHDR:       true,
+	}
+
+	tileMemory := tileSize * tileSize * 24
+	stream.cache = newTileCache(config.CacheMB<<20/tileMemory, stream.loadTile)
+	fmt.Printf("HDRI: Streaming %dx%d as %d tiles of %dpx (cache %d MB), proxy %dx%d\n",
+		width, height, stream.tilesX*stream.tilesY, tileSize, config.CacheMB, proxyW, proxyH)
+
+	env := newEnvironmentFromImage(proxy)
+	env.stream = stream
+	return env, nil
+}
+
+// writeBand stores one row of tiles: rows scanlines of band, zero padded to
+// full tiles
+func (s *streamedImage) writeBand(tileRow, rows int, band []float32, buf []byte) error {
+	for tx := 0; tx < s.tilesX; tx++ {
+		clear(buf)
+		for y := 0; y < rows; y++ {
+			for x := 0; x < s.tileSize && tx*s.tileSize+x < s.width; x++ {
+				src := (y*s.width + tx*s.tileSize + x) * 3
+				dst := (y*s.tileSize + x) * 12
+				for c := 0; c < 3; c++ {
+					binary.LittleEndian.PutUint32(buf[dst+c*4:], math.Float32bits(band[src+c]))
+				}
+			}
+		}
+		offset := int64(tileRow*s.tilesX+tx) * int64(len(buf))
+		if _, err := s.file.WriteAt(buf, offset); err != nil {
+			return fmt.Errorf("writing HDRI tile: %w", err)
+		}
+	}
+	return nil
+}
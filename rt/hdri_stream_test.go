@@ -0,0 +1,74 @@
+package rt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStreamedHDRIMatchesInMemory(t *testing.T) {
+	const width, height = 200, 100
+	payload := make([]byte, 0, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			payload = append(payload, byte(x), byte(y), byte(x+y), 129)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "big.hdr")
+	if err := os.WriteFile(path, hdrFile("-Y 100 +X 200", payload), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultHDRIStreamConfig()
+	config.Enabled = true
+	config.TileSize = 16
+	config.CacheMB = 1
+	config.ProxyWidth = 64
+	config.TileDir = t.TempDir()
+	streamed, err := loadStreamedHDRI(path, config)
+	if err != nil || streamed == nil {
+		t.Fatalf("loadStreamedHDRI = %v, %v", streamed, err)
+	}
+	if streamed.width > 64 {
+		t.Errorf("proxy width = %d, want at most 64", streamed.width)
+	}
+	whole := loadHDRIEnvironment(path)
+
+	SeedRandom(3)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	for i := 0; i < 200; i++ {
+		dir := RandomUnitVector()
+		if got, want := streamed.Sample(dir), whole.Sample(dir); got.Sub(want).Len() > 1e-6 {
+			t.Fatalf("Sample(%v) = %v, want %v", dir, got, want)
+		}
+		if got, want := streamed.SampleNearest(dir), whole.SampleNearest(dir); got != want {
+			t.Fatalf("SampleNearest(%v) = %v, want %v", dir, got, want)
+		}
+	}
+
+	// The proxy keeps the map's power for light selection
+	if got, want := streamed.Power(1), whole.Power(1); got < 0.95*want || got > 1.05*want {
+		t.Errorf("power = %v, want about %v", got, want)
+	}
+}
+
+func TestTileCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	loads := 0
+	cache := newTileCache(2, func(index int) []Color {
+		loads++
+		return []Color{{X: float64(index)}}
+	})
+	cache.get(1)
+	cache.get(2)
+	cache.get(1) // 2 is now the least recent
+	cache.get(3)
+	if _, ok := cache.tiles[2]; ok {
+		t.Error("tile 2 should have been evicted")
+	}
+	if cache.get(1)[0].X != 1 || loads != 3 {
+		t.Errorf("tile 1 reloaded (loads = %d)", loads)
+	}
+	if cache.order.Len() != 2 {
+		t.Errorf("cache holds %d tiles, want 2", cache.order.Len())
+	}
+}
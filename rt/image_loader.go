@@ -2,6 +2,7 @@ package rt
 
 import (
 	"bufio"
+	"encoding/binary"
 	"fmt"
 	"image"
 	_ "image/jpeg"
@@ -38,6 +39,10 @@ func NewImageLoaderFromFile(filename string) *ImageLoader {
 		fmt.Fprintf(os.Stderr, "ERROR: Could not resolve image file path '%s'.\n", filename)
 		return img
 	}
+	if asyncAssets.enabled.Load() {
+		loadImageAsync(img, path)
+		return img
+	}
 	img.Load(path)
 	return img
 }
@@ -120,6 +125,16 @@ func clamp(x, low, high int) int {
 }
 
 func FindAsset(filename string, assetType string) (string, error) {
+	for _, path := range assetSearchPaths(filename, assetType) {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("asset file '%s' not found in any search paths", filename)
+}
+
+// assetSearchPaths lists where FindAsset looks for filename, in order
+func assetSearchPaths(filename string, assetType string) []string {
 	searchPaths := []string{
 		filename,
 		filepath.Join(assetType, filename),
@@ -136,12 +151,7 @@ func FindAsset(filename string, assetType string) (string, error) {
 			filepath.Join("..", "assets", "hdri", filename),
 		)
 	}
-	for _, path := range searchPaths {
-		if _, err := os.Stat(path); err == nil {
-			return path, nil
-		}
-	}
-	return "", fmt.Errorf("asset file '%s' not found in any search paths", filename)
+	return searchPaths
 }
 
 // =============================================================================
@@ -161,7 +171,15 @@ func NewImageLoaderFromHDR(filename string) *ImageLoader {
 	return img
 }
 
-// LoadHDR loads a Radiance HDR (.hdr) file
+// Limits that keep corrupt or hostile HDR files from exhausting memory
+const (
+	MaxHDRDimension   = 32768        // Max width or height in pixels
+	MaxHDRPixels      = 16384 * 8192 // Max total pixels (a 16K lat-long map)
+	maxHDRHeaderLines = 1024         // Max header lines before the resolution
+	hdrPreallocPixels = 1024 * 1024  // Pixels reserved up front; the rest grows as scanlines arrive
+)
+
+// LoadHDR loads a Radiance HDR (.hdr) or, by extension, PFM (.pfm) file
 func (img *ImageLoader) LoadHDR(filename string) bool {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -170,34 +188,119 @@ func (img *ImageLoader) LoadHDR(filename string) bool {
 	}
 	defer file.Close()
 
-	reader := bufio.NewReader(file)
+	decode := img.DecodeHDR
+	if strings.EqualFold(filepath.Ext(filename), ".pfm") {
+		decode = img.DecodePFM
+	}
+	if err := decode(file); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not load HDR file '%s': %v\n", filename, err)
+		return false
+	}
+	return true
+}
+
+// DecodeHDR reads a Radiance HDR image from r. On error the loader is left
+// empty, so lookups return the missing-texture color instead of partial data.
+func (img *ImageLoader) DecodeHDR(r io.Reader) error {
+	img.data = nil
+	reader := bufio.NewReader(r)
 
 	// Parse header
 	width, height, err := img.parseHDRHeader(reader)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "ERROR: Invalid HDR header in '%s': %v\n", filename, err)
-		return false
+		return fmt.Errorf("invalid HDR header: %w", err)
+	}
+
+	// Memory grows with the scanlines actually present, so a header that
+	// claims a huge image can't allocate gigabytes before the data runs out
+	totalPixels := width * height
+	data := make([]Color, 0, min(totalPixels, hdrPreallocPixels))
+	row := make([]Color, width)
+
+	// Read scanlines
+	for y := 0; y < height; y++ {
+		if err := readHDRScanline(reader, row); err != nil {
+			return fmt.Errorf("failed to read HDR scanline %d: %w", y, err)
+		}
+		data = append(data, row...)
 	}
 
 	img.imageWidth = width
 	img.imageHeight = height
 	img.bytesPerScanline = width * 4
 	img.IsHDR = true
+	img.data = data
 
-	// Allocate pixel data
-	totalPixels := width * height
-	img.data = make([]Color, totalPixels)
+	return nil
+}
 
-	// Read scanlines
+// DecodePFM reads a color (PF) or grayscale (Pf) Portable Float Map from r,
+// such as the renderer's -hdr-output. On error the loader is left empty.
+func (img *ImageLoader) DecodePFM(r io.Reader) error {
+	img.data = nil
+	reader := bufio.NewReader(r)
+
+	var magic string
+	var width, height int
+	var scale float64
+	if _, err := fmt.Fscan(reader, &magic, &width, &height, &scale); err != nil {
+		return fmt.Errorf("invalid PFM header: %w", err)
+	}
+	channels := 3
+	switch magic {
+	case "PF":
+	case "Pf":
+		channels = 1
+	default:
+		return fmt.Errorf("not a PFM file (magic %q)", magic)
+	}
+	if width <= 0 || height <= 0 || width > MaxHDRDimension || height > MaxHDRDimension || width*height > MaxHDRPixels {
+		return fmt.Errorf("invalid PFM size %dx%d", width, height)
+	}
+	if scale == 0 || math.IsNaN(scale) {
+		return fmt.Errorf("invalid PFM scale %g", scale)
+	}
+	// A single whitespace character separates the header from the data
+	if _, err := reader.ReadByte(); err != nil {
+		return fmt.Errorf("truncated PFM header: %w", err)
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	if scale < 0 {
+		order = binary.LittleEndian
+	}
+	data := make([]Color, 0, min(width*height, hdrPreallocPixels))
+	row := make([]byte, width*channels*4)
+	channel := func(x, c int) float64 {
+		return float64(math.Float32frombits(order.Uint32(row[(x*channels+c)*4:])))
+	}
 	for y := 0; y < height; y++ {
-		err := img.readHDRScanline(reader, y)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "ERROR: Failed to read HDR scanline %d: %v\n", y, err)
-			return false
+		if _, err := io.ReadFull(reader, row); err != nil {
+			return fmt.Errorf("failed to read PFM scanline %d: %w", y, err)
+		}
+		for x := 0; x < width; x++ {
+			if channels == 1 {
+				v := channel(x, 0)
+				data = append(data, Color{X: v, Y: v, Z: v})
+			} else {
+				data = append(data, Color{X: channel(x, 0), Y: channel(x, 1), Z: channel(x, 2)})
+			}
 		}
 	}
 
-	return true
+	// PFM scanlines are stored bottom-to-top
+	for top, bottom := 0, height-1; top < bottom; top, bottom = top+1, bottom-1 {
+		for x := 0; x < width; x++ {
+			data[top*width+x], data[bottom*width+x] = data[bottom*width+x], data[top*width+x]
+		}
+	}
+
+	img.imageWidth = width
+	img.imageHeight = height
+	img.bytesPerScanline = width * channels * 4
+	img.IsHDR = true
+	img.data = data
+	return nil
 }
 
 // parseHDRHeader parses the Radiance HDR file header
@@ -213,7 +316,11 @@ func (img *ImageLoader) parseHDRHeader(reader *bufio.Reader) (width, height int,
 	}
 
 	// Read header lines until we find an empty line
-	for {
+	for lines := 0; ; lines++ {
+		if lines >= maxHDRHeaderLines {
+			return 0, 0, fmt.Errorf("header exceeds %d lines", maxHDRHeaderLines)
+		}
+
 		line, err = reader.ReadString('\n')
 		if err != nil {
 			return 0, 0, fmt.Errorf("unexpected end of header: %v", err)
@@ -226,8 +333,12 @@ func (img *ImageLoader) parseHDRHeader(reader *bufio.Reader) (width, height int,
 			break
 		}
 
-		// We can ignore FORMAT, EXPOSURE, etc. for basic loading
-		// Just continue reading until empty line
+		// Only RGBE pixels are supported
+		if format, ok := strings.CutPrefix(line, "FORMAT="); ok && format != "32-bit_rle_rgbe" {
+			return 0, 0, fmt.Errorf("unsupported pixel format: %s", format)
+		}
+
+		// EXPOSURE, GAMMA, etc. are ignored for basic loading
 	}
 
 	// Read resolution line: -Y height +X width (most common format)
@@ -267,49 +378,57 @@ func (img *ImageLoader) parseHDRHeader(reader *bufio.Reader) (width, height int,
 		return 0, 0, fmt.Errorf("unsupported resolution format: %s", line)
 	}
 
+	if width <= 0 || height <= 0 || width > MaxHDRDimension || height > MaxHDRDimension {
+		return 0, 0, fmt.Errorf("resolution %dx%d outside 1..%d", width, height, MaxHDRDimension)
+	}
+	if width*height > MaxHDRPixels {
+		return 0, 0, fmt.Errorf("resolution %dx%d exceeds %d pixels", width, height, MaxHDRPixels)
+	}
+
 	return width, height, nil
 }
 
-// readHDRScanline reads a single scanline from the HDR file
-func (img *ImageLoader) readHDRScanline(reader *bufio.Reader, y int) error {
-	width := img.imageWidth
+// readHDRScanline reads a single scanline into row
+func readHDRScanline(reader *bufio.Reader, row []Color) error {
+	width := len(row)
 
 	// Read first 4 bytes to determine encoding
-	header := make([]byte, 4)
-	_, err := io.ReadFull(reader, header)
+	var header [4]byte
+	_, err := io.ReadFull(reader, header[:])
 	if err != nil {
 		return fmt.Errorf("failed to read scanline header: %v", err)
 	}
 
-	// Check for new RLE format: starts with 2, 2, then 16-bit width
-	if header[0] == 2 && header[1] == 2 {
-		// New RLE format
+	// New RLE format: starts with 2, 2, then 16-bit width (only used for
+	// widths 8..32767; other widths are always stored flat)
+	if header[0] == 2 && header[1] == 2 && header[2]&0x80 == 0 && width >= 8 && width < 32768 {
 		scanlineWidth := int(header[2])<<8 | int(header[3])
 		if scanlineWidth != width {
 			return fmt.Errorf("scanline width mismatch: expected %d, got %d", width, scanlineWidth)
 		}
-		return img.readRLEScanline(reader, y, width)
+		return readRLEScanline(reader, row)
 	}
 
 	// Old format or uncompressed - first 4 bytes are first pixel
-	// Convert first pixel
-	img.rgbeToColor(header, y, 0)
+	row[0] = rgbeToColor(header)
 
 	// Read remaining pixels as raw RGBE
+	var pixel [4]byte
 	for x := 1; x < width; x++ {
-		pixel := make([]byte, 4)
-		_, err := io.ReadFull(reader, pixel)
+		_, err := io.ReadFull(reader, pixel[:])
 		if err != nil {
 			return fmt.Errorf("failed to read pixel: %v", err)
 		}
-		img.rgbeToColor(pixel, y, x)
+		row[x] = rgbeToColor(pixel)
 	}
 
 	return nil
 }
 
 // readRLEScanline reads an RLE-compressed scanline (new format)
-func (img *ImageLoader) readRLEScanline(reader *bufio.Reader, y, width int) error {
+func readRLEScanline(reader *bufio.Reader, row []Color) error {
+	width := len(row)
+
 	// Each component (R, G, B, E) is encoded separately
 	scanline := make([][]byte, 4)
 	for i := range scanline {
@@ -325,49 +444,49 @@ func (img *ImageLoader) readRLEScanline(reader *bufio.Reader, y, width int) erro
 				return fmt.Errorf("failed to read RLE code: %v", err)
 			}
 
+			// Zero-length runs would never advance x; overlong runs would
+			// spill into the next component
+			count := int(code)
+			if code > 128 {
+				count -= 128
+			}
+			if count == 0 || x+count > width {
+				return fmt.Errorf("invalid RLE run of %d at x=%d (width %d)", count, x, width)
+			}
+
 			if code > 128 {
 				// RLE run: next byte repeated (code - 128) times
-				count := int(code) - 128
 				value, err := reader.ReadByte()
 				if err != nil {
 					return fmt.Errorf("failed to read RLE value: %v", err)
 				}
-				for i := 0; i < count && x < width; i++ {
+				for i := 0; i < count; i++ {
 					scanline[component][x] = value
 					x++
 				}
 			} else {
 				// Raw run: read 'code' literal bytes
-				count := int(code)
-				for i := 0; i < count && x < width; i++ {
-					value, err := reader.ReadByte()
-					if err != nil {
-						return fmt.Errorf("failed to read raw value: %v", err)
-					}
-					scanline[component][x] = value
-					x++
+				if _, err := io.ReadFull(reader, scanline[component][x:x+count]); err != nil {
+					return fmt.Errorf("failed to read raw value: %v", err)
 				}
+				x += count
 			}
 		}
 	}
 
 	// Convert RGBE to Color for each pixel
 	for x := 0; x < width; x++ {
-		rgbe := []byte{scanline[0][x], scanline[1][x], scanline[2][x], scanline[3][x]}
-		img.rgbeToColor(rgbe, y, x)
+		row[x] = rgbeToColor([4]byte{scanline[0][x], scanline[1][x], scanline[2][x], scanline[3][x]})
 	}
 
 	return nil
 }
 
 // rgbeToColor converts RGBE bytes to a linear HDR Color
-func (img *ImageLoader) rgbeToColor(rgbe []byte, y, x int) {
-	idx := y*img.imageWidth + x
-
+func rgbeToColor(rgbe [4]byte) Color {
 	if rgbe[3] == 0 {
 		// Zero exponent means black
-		img.data[idx] = Color{X: 0, Y: 0, Z: 0}
-		return
+		return Color{X: 0, Y: 0, Z: 0}
 	}
 
 	// RGBE to float conversion
@@ -375,13 +494,46 @@ func (img *ImageLoader) rgbeToColor(rgbe []byte, y, x int) {
 	exponent := int(rgbe[3]) - 128 - 8
 	scale := math.Ldexp(1.0, exponent)
 
-	img.data[idx] = Color{
+	return Color{
 		X: (float64(rgbe[0]) + 0.5) * scale,
 		Y: (float64(rgbe[1]) + 0.5) * scale,
 		Z: (float64(rgbe[2]) + 0.5) * scale,
 	}
 }
 
+// Downsample returns a copy reduced by an integer factor with a box filter.
+// Edge blocks average only the pixels that exist.
+func (img *ImageLoader) Downsample(factor int) *ImageLoader {
+	if img.data == nil || factor <= 1 {
+		return img
+	}
+
+	width := (img.imageWidth + factor - 1) / factor
+	height := (img.imageHeight + factor - 1) / factor
+	out := &ImageLoader{
+		data:        make([]Color, width*height),
+		imageWidth:  width,
+		imageHeight: height,
+		IsHDR:       img.IsHDR,
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sum := Color{X: 0, Y: 0, Z: 0}
+			count := 0
+			for sy := y * factor; sy < min((y+1)*factor, img.imageHeight); sy++ {
+				for sx := x * factor; sx < min((x+1)*factor, img.imageWidth); sx++ {
+					sum = sum.Add(img.data[sy*img.imageWidth+sx])
+					count++
+				}
+			}
+			out.data[y*width+x] = sum.Scale(1.0 / float64(count))
+		}
+	}
+
+	return out
+}
+
 // PixelDataUV returns pixel data with float UV coordinates (for bilinear filtering)
 func (img *ImageLoader) PixelDataUV(u, v float64) Color {
 	if img.data == nil {
@@ -3,7 +3,9 @@ package rt
 // ImageTexture uses an image as a texture
 // C++: class image_texture : public texture
 type ImageTexture struct {
-	image *ImageLoader
+	image  *ImageLoader
+	filter TextureFilter
+	tiles  map[int]*ImageLoader // UDIM tiles by number; replaces image when set
 }
 
 // NewImageTexture creates a texture from an image file
@@ -24,8 +26,13 @@ func NewImageTextureFromImage(image *ImageLoader) *ImageTexture {
 // Value returns the color at the given texture coordinates
 // C++: color value(double u, double v, const point3& p) const override
 func (tex *ImageTexture) Value(u, v float64, p Point3) Color {
+	image := tex.image
+	if tex.tiles != nil {
+		image, u, v = tex.udimTile(u, v)
+	}
+
 	// If we have no texture data, return solid cyan as a debugging aid
-	if tex.image.Height() <= 0 {
+	if image == nil || image.Height() <= 0 {
 		return Color{X: 0, Y: 1, Z: 1}
 	}
 
@@ -33,11 +40,24 @@ func (tex *ImageTexture) Value(u, v float64, p Point3) Color {
 	u = clampFloat(u, 0.0, 1.0)
 	v = 1.0 - clampFloat(v, 0.0, 1.0) // Flip V to image coordinates
 
+	// Filtered lookups work on continuous pixel coordinates (texel centers
+	// at half-integers)
+	px := u*float64(image.Width()) - 0.5
+	py := v*float64(image.Height()) - 0.5
+	switch tex.filter {
+	case TextureFilterBilinear:
+		return image.PixelDataBilinear(u, v)
+	case TextureFilterSpherical:
+		return sphericalBilinear(image, px, py)
+	case TextureFilterCube:
+		return cubeBilinear(image, px, py)
+	}
+
 	// Convert to integer pixel coordinates
-	i := int(u * float64(tex.image.Width()))
-	j := int(v * float64(tex.image.Height()))
+	i := int(u * float64(image.Width()))
+	j := int(v * float64(image.Height()))
 
-	return tex.image.PixelData(i, j)
+	return image.PixelData(i, j)
 }
 
 // clampFloat clamps a float value to [min, max]
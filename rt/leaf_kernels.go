@@ -0,0 +1,180 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// BATCHED LEAF INTERSECTION KERNELS
+// =============================================================================
+
+// Mesh BVH leaves hold only triangles, and sphere fields only spheres. For
+// such leaves the primitives are copied into structure-of-arrays form at
+// build time and tested in one tight loop: no interface dispatch per
+// primitive, contiguous float64 loads, and the HitRecord is filled once for
+// the closest hit instead of for every closer candidate. The loops are
+// written branch-light so they can be swapped for AVX2 assembly later
+// without changing callers. Build with -tags rtscalar to fall back to
+// per-primitive Hit calls (e.g. to A/B the kernels).
+
+// leafKernel intersects a ray with all primitives of a leaf at once
+type leafKernel interface {
+	hit(r Ray, rayT Interval, rec *HitRecord) bool
+}
+
+// newLeafKernel returns a batch kernel for a homogeneous leaf, or nil when
+// kernels are disabled or the leaf mixes primitive types
+func newLeafKernel(objects []Hittable) leafKernel {
+	if !leafKernelsEnabled || len(objects) < 2 {
+		return nil
+	}
+	switch objects[0].(type) {
+	case *Triangle:
+		return newTriangleBatch(objects)
+	case *Sphere:
+		return newSphereBatch(objects)
+	}
+	return nil
+}
+
+// triangleBatch stores vertex 0 and both edges of each triangle by component.
+// All triangles of a batch share their TriangleOptions.
+type triangleBatch struct {
+	v0x, v0y, v0z []float64
+	e1x, e1y, e1z []float64
+	e2x, e2y, e2z []float64
+	triangles     []*Triangle
+	epsilon       float64
+	cull          bool
+}
+
+func newTriangleBatch(objects []Hittable) leafKernel {
+	n := len(objects)
+	b := &triangleBatch{
+		v0x: make([]float64, n), v0y: make([]float64, n), v0z: make([]float64, n),
+		e1x: make([]float64, n), e1y: make([]float64, n), e1z: make([]float64, n),
+		e2x: make([]float64, n), e2y: make([]float64, n), e2z: make([]float64, n),
+		triangles: make([]*Triangle, n),
+	}
+	for i, obj := range objects {
+		tri, ok := obj.(*Triangle)
+		if !ok || tri.options() != objects[0].(*Triangle).options() {
+			return nil
+		}
+		edge1, edge2 := tri.v1.Sub(tri.v0), tri.v2.Sub(tri.v0)
+		b.v0x[i], b.v0y[i], b.v0z[i] = tri.v0.X, tri.v0.Y, tri.v0.Z
+		b.e1x[i], b.e1y[i], b.e1z[i] = edge1.X, edge1.Y, edge1.Z
+		b.e2x[i], b.e2y[i], b.e2z[i] = edge2.X, edge2.Y, edge2.Z
+		b.triangles[i] = tri
+	}
+	b.epsilon, b.cull = b.triangles[0].epsilon, b.triangles[0].cull
+	return b
+}
+
+// hit runs Möller-Trumbore over the batch, matching Triangle.Hit exactly
+func (b *triangleBatch) hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	o, d := r.Origin(), r.Direction()
+	closest := rayT.Max
+	best := -1
+	var bestU, bestV float64
+
+	for i := range b.triangles {
+		// h = d x e2
+		hx := d.Y*b.e2z[i] - d.Z*b.e2y[i]
+		hy := d.Z*b.e2x[i] - d.X*b.e2z[i]
+		hz := d.X*b.e2y[i] - d.Y*b.e2x[i]
+		a := b.e1x[i]*hx + b.e1y[i]*hy + b.e1z[i]*hz
+		if a < b.epsilon && (b.cull || a > -b.epsilon) {
+			continue
+		}
+
+		f := 1.0 / a
+		sx, sy, sz := o.X-b.v0x[i], o.Y-b.v0y[i], o.Z-b.v0z[i]
+		u := f * (sx*hx + sy*hy + sz*hz)
+		if u < 0.0 || u > 1.0 {
+			continue
+		}
+
+		// q = s x e1
+		qx := sy*b.e1z[i] - sz*b.e1y[i]
+		qy := sz*b.e1x[i] - sx*b.e1z[i]
+		qz := sx*b.e1y[i] - sy*b.e1x[i]
+		v := f * (d.X*qx + d.Y*qy + d.Z*qz)
+		if v < 0.0 || u+v > 1.0 {
+			continue
+		}
+
+		t := f * (b.e2x[i]*qx + b.e2y[i]*qy + b.e2z[i]*qz)
+		if t < rayT.Min || t > closest {
+			continue
+		}
+		closest, best, bestU, bestV = t, i, u, v
+	}
+
+	if best < 0 {
+		return false
+	}
+	b.triangles[best].setHitRecord(r, closest, bestU, bestV, rec)
+	return true
+}
+
+// sphereBatch stores the centers and squared radii of static spheres
+type sphereBatch struct {
+	cx, cy, cz, r2 []float64
+	spheres        []*Sphere
+}
+
+func newSphereBatch(objects []Hittable) leafKernel {
+	n := len(objects)
+	b := &sphereBatch{
+		cx: make([]float64, n), cy: make([]float64, n), cz: make([]float64, n),
+		r2:      make([]float64, n),
+		spheres: make([]*Sphere, n),
+	}
+	for i, obj := range objects {
+		s, ok := obj.(*Sphere)
+		// Moving spheres need their center at the ray time
+		if !ok || s.Center.Direction() != (Vec3{}) {
+			return nil
+		}
+		c := s.Center.Origin()
+		b.cx[i], b.cy[i], b.cz[i] = c.X, c.Y, c.Z
+		b.r2[i] = s.Radius * s.Radius
+		b.spheres[i] = s
+	}
+	return b
+}
+
+// hit solves the ray-sphere quadratic over the batch, matching Sphere.Hit
+func (b *sphereBatch) hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	o, d := r.Origin(), r.Direction()
+	a := d.Len2()
+	closest := rayT.Max
+	best := -1
+
+	for i := range b.spheres {
+		ocx, ocy, ocz := b.cx[i]-o.X, b.cy[i]-o.Y, b.cz[i]-o.Z
+		h := d.X*ocx + d.Y*ocy + d.Z*ocz
+		c := ocx*ocx + ocy*ocy + ocz*ocz - b.r2[i]
+
+		discriminant := h*h - a*c
+		if discriminant < 0 {
+			continue
+		}
+
+		sqrtd := math.Sqrt(discriminant)
+		root := (h - sqrtd) / a
+		if root <= rayT.Min || root >= closest {
+			root = (h + sqrtd) / a
+			if root <= rayT.Min || root >= closest {
+				continue
+			}
+		}
+		closest, best = root, i
+	}
+
+	if best < 0 {
+		return false
+	}
+	s := b.spheres[best]
+	s.setHitRecord(r, closest, s.Center.Origin(), rec)
+	return true
+}
@@ -0,0 +1,7 @@
+//go:build !rtscalar
+
+package rt
+
+// leafKernelsEnabled selects the batched leaf kernels (build with -tags
+// rtscalar to test primitives one Hit call at a time)
+const leafKernelsEnabled = true
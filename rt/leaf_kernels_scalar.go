@@ -0,0 +1,7 @@
+//go:build rtscalar
+
+package rt
+
+// leafKernelsEnabled is off in rtscalar builds: leaves call each
+// primitive's Hit in turn
+const leafKernelsEnabled = false
@@ -0,0 +1,105 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+// randomTriangleSoup returns n small random triangles in [-5, 5]^3
+func randomTriangleSoup(n int) []Hittable {
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	objects := make([]Hittable, n)
+	for i := range objects {
+		v0 := RandomVec3Range(-5, 5)
+		objects[i] = NewTriangle(v0, v0.Add(RandomVec3Range(-1, 1)), v0.Add(RandomVec3Range(-1, 1)), mat)
+	}
+	return objects
+}
+
+func TestLeafKernelsMatchScalarHits(t *testing.T) {
+	if !leafKernelsEnabled {
+		t.Skip("batched leaf kernels are disabled in rtscalar builds")
+	}
+	SeedRandom(7)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	spheres := make([]Hittable, 6)
+	for i := range spheres {
+		spheres[i] = NewSphere(RandomVec3Range(-3, 3), RandomDoubleRange(0.3, 1.5), mat)
+	}
+
+	for name, objects := range map[string][]Hittable{
+		"triangles": randomTriangleSoup(8),
+		"spheres":   spheres,
+	} {
+		kernel := newLeafKernel(objects)
+		if kernel == nil {
+			t.Fatalf("%s: no batch kernel for a homogeneous leaf", name)
+		}
+		scalar := &BVHLeaf{objects: objects}
+
+		hits := 0
+		for i := range 2000 {
+			origin := RandomVec3Range(-8, 8)
+			r := NewRay(origin, RandomVec3Range(-3, 3).Sub(origin), 0)
+			rayT := NewInterval(0.001, math.Inf(1))
+
+			var want, got HitRecord
+			wantHit := scalar.Hit(r, rayT, &want)
+			gotHit := kernel.hit(r, rayT, &got)
+			if wantHit != gotHit {
+				t.Fatalf("%s ray %d: kernel hit=%v, scalar hit=%v", name, i, gotHit, wantHit)
+			}
+			if !wantHit {
+				continue
+			}
+			hits++
+			if got.T != want.T || got.Normal != want.Normal || got.U != want.U || got.V != want.V || got.FrontFace != want.FrontFace {
+				t.Fatalf("%s ray %d: kernel record %+v, scalar record %+v", name, i, got, want)
+			}
+		}
+		if hits == 0 {
+			t.Fatalf("%s: no test ray hit the leaf", name)
+		}
+	}
+}
+
+func TestLeafKernelSkipsMixedLeaves(t *testing.T) {
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	mixed := []Hittable{
+		NewSphere(Point3{}, 1, mat),
+		NewTriangle(Point3{}, Point3{X: 1}, Point3{Y: 1}, mat),
+	}
+	moving := []Hittable{
+		NewSphere(Point3{}, 1, mat),
+		NewMovingSphere(Point3{}, Point3{X: 1}, 1, mat),
+	}
+	if newLeafKernel(mixed) != nil || newLeafKernel(moving) != nil {
+		t.Error("expected no batch kernel for mixed or moving primitives")
+	}
+}
+
+// BenchmarkMeshTraversal traces rays through a triangle soup BVH. Compare
+// with -tags rtscalar to measure the batched leaf kernels.
+func BenchmarkMeshTraversal(b *testing.B) {
+	SeedRandom(1)
+	defer activeSeed.Store(nil)
+
+	opts := DefaultBVHOptions()
+	opts.LeafMaxSize = 8
+	objects := randomTriangleSoup(50000)
+	bvh := NewBVHNodeWithOptions(objects, 0, len(objects), opts)
+
+	rays := make([]Ray, 1024)
+	for i := range rays {
+		origin := RandomVec3Range(-15, 15)
+		rays[i] = NewRay(origin, RandomVec3Range(-5, 5).Sub(origin).Unit(), 0)
+	}
+
+	rec := &HitRecord{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bvh.Hit(rays[i%len(rays)], NewInterval(0.001, math.Inf(1)), rec)
+	}
+}
@@ -0,0 +1,265 @@
+package rt
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"strings"
+)
+
+// =============================================================================
+// LIGHT CONTRIBUTION AOVS AND EFFICIENCY REPORT
+// =============================================================================
+
+// Every camera sample can be split by where its light came from: one slot
+// per entry of Camera.Lights, then the background (HDRI, sky or color), the
+// ambient fill and emissive surfaces that aren't registered lights. The
+// slots add up to the beauty render, so each becomes an AOV and its share
+// of the image shows which lights earn the samples NEE spends on them.
+
+const (
+	lightSlotBackground = iota // Offsets after the Camera.Lights slots
+	lightSlotAmbient
+	lightSlotEmitters
+	lightSlotExtra // Number of slots after the lights
+)
+
+// dimLightShare is the share of the image below which a light that takes
+// NEE samples is reported as a candidate for removal
+const dimLightShare = 0.01
+
+// lightTally holds one sample's radiance per slot
+type lightTally []Color
+
+func newLightTally(numLights int) lightTally {
+	return make(lightTally, numLights+lightSlotExtra)
+}
+
+// reset clears the tally for the next sample
+func (t lightTally) reset() {
+	clear(t)
+}
+
+// slot returns the slot of light from source (light is a Camera.Lights
+// index, or -1)
+func (t lightTally) slot(source LightSource, light int) int {
+	lights := len(t) - lightSlotExtra
+	switch {
+	case source == LightSourceBackground:
+		return lights + lightSlotBackground
+	case source == LightSourceAmbient:
+		return lights + lightSlotAmbient
+	case light >= 0 && light < lights:
+		return light
+	}
+	return lights + lightSlotEmitters
+}
+
+// tally adds radiance reaching the current vertex from source to the
+// sample's tally, weighted by the path throughput
+func (p pathState) tally(source LightSource, light int, radiance Color) {
+	if p.lights == nil {
+		return
+	}
+	slot := p.lights.slot(source, light)
+	p.lights[slot] = p.lights[slot].Add(p.throughput.Mult(radiance))
+}
+
+// LightContribution is one light's share of a finished render
+type LightContribution struct {
+	Name        string  // e.g. "light 0 (Quad)" or "background"
+	Light       int     // Camera.Lights index, -1 for the other slots
+	Share       float64 // Fraction of the image's total luminance
+	SampleShare float64 // Fraction of NEE light samples spent on it
+	Clipped     int     // Over-exposed pixels it is the largest contributor to
+}
+
+// lightAOVs accumulates the final render split by light
+type lightAOVs struct {
+	films []*Film // One per tally slot
+}
+
+// SetLightAOVs accumulates a film per light alongside the render. When the
+// render finishes each light's AOV is saved as image_<light>.png and the
+// light contribution report is printed.
+func (r *BucketRenderer) SetLightAOVs(enabled bool) *BucketRenderer {
+	r.lightAOVs = nil
+	if enabled {
+		aovs := &lightAOVs{}
+		for range newLightTally(len(r.camera.Lights)) {
+			aovs.films = append(aovs.films, NewFilm(r.camera.ImageWidth, r.camera.ImageHeight))
+		}
+		r.lightAOVs = aovs
+	}
+	return r
+}
+
+// lightTally returns a tally for a bucket of an accumulating pass, nil when
+// light AOVs are off or the pass is display-only
+func (r *BucketRenderer) lightTally(accumulate bool) lightTally {
+	if r.lightAOVs == nil || !accumulate {
+		return nil
+	}
+	return newLightTally(len(r.camera.Lights))
+}
+
+// addSamples adds a pixel's per-slot sums of count samples
+func (a *lightAOVs) addSamples(x, y int, sums lightTally, count int) {
+	for i, film := range a.films {
+		film.AddSamples(x, y, sums[i], count)
+	}
+}
+
+// slotName returns the report name of a slot
+func (r *BucketRenderer) slotName(slot int) string {
+	lights := len(r.camera.Lights)
+	switch slot - lights {
+	case lightSlotBackground:
+		return "background"
+	case lightSlotAmbient:
+		return "ambient"
+	case lightSlotEmitters:
+		return "other emitters"
+	}
+	return fmt.Sprintf("light %d (%s)", slot, primitiveName(r.camera.Lights[slot]))
+}
+
+// slotFile returns the AOV filename of a slot
+func (r *BucketRenderer) slotFile(slot int) string {
+	name := r.slotName(slot)
+	if slot < len(r.camera.Lights) {
+		name = fmt.Sprintf("light%d", slot)
+	}
+	return "image_" + strings.ReplaceAll(name, " ", "_") + ".png"
+}
+
+// LightContributions returns each light's share of the accumulated render.
+// Lights are always listed; the background, ambient and other emitters only
+// when they contribute. Nil unless light AOVs are enabled.
+func (r *BucketRenderer) LightContributions() []LightContribution {
+	if r.lightAOVs == nil {
+		return nil
+	}
+	films := r.lightAOVs.films
+	lights := len(r.camera.Lights)
+	totals := make([]float64, len(films))
+	clipped := make([]int, len(films))
+	total := 0.0
+	for y := 0; y < r.camera.ImageHeight; y++ {
+		for x := 0; x < r.camera.ImageWidth; x++ {
+			pixel, brightest, brightestLum := 0.0, 0, 0.0
+			for i, film := range films {
+				lum := Luminance(film.Resolve(x, y))
+				totals[i] += lum
+				pixel += lum
+				if lum > brightestLum {
+					brightest, brightestLum = i, lum
+				}
+			}
+			total += pixel
+			if pixel*r.exposure > 1 {
+				clipped[brightest]++
+			}
+		}
+	}
+
+	var contributions []LightContribution
+	for i := range films {
+		if i >= lights && totals[i] == 0 {
+			continue
+		}
+		c := LightContribution{Name: r.slotName(i), Light: -1, Clipped: clipped[i]}
+		if total > 0 {
+			c.Share = totals[i] / total
+		}
+		sampler := r.camera.lightSampler
+		if i < lights {
+			c.Light = i
+			if sampler != nil {
+				c.SampleShare = sampler.probs[i]
+			}
+		} else if i == lights+lightSlotBackground && sampler != nil && sampler.envIndex >= 0 {
+			c.SampleShare = sampler.probs[sampler.envIndex]
+		}
+		contributions = append(contributions, c)
+	}
+	return contributions
+}
+
+// saveLightAOVs writes each contributing slot's AOV and prints the report
+func (r *BucketRenderer) saveLightAOVs() {
+	if r.lightAOVs == nil {
+		return
+	}
+	meta := NewRenderMetadata(r.camera, r.sceneName, r.GetRenderDuration())
+	for i, film := range r.lightAOVs.films {
+		if i >= len(r.camera.Lights) && film.isBlack() {
+			continue
+		}
+		filename := r.slotFile(i)
+		if err := r.saveFilmPNG(film, filename, meta); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			continue
+		}
+		fmt.Printf("✓ Light AOV saved to %s\n", filename)
+	}
+	printLightContributions(r.LightContributions())
+}
+
+// saveFilmPNG writes a film with the render's exposure as a PNG
+func (r *BucketRenderer) saveFilmPNG(film *Film, filename string, meta RenderMetadata) error {
+	img := image.NewRGBA(image.Rect(0, 0, film.Width(), film.Height()))
+	for y := 0; y < film.Height(); y++ {
+		for x := 0; x < film.Width(); x++ {
+			img.SetRGBA(x, y, LinearToRGBA(film.Resolve(x, y).Scale(r.exposure)))
+		}
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating light AOV file: %w", err)
+	}
+	defer file.Close()
+	if err := EncodePNGWithMetadata(file, img, meta); err != nil {
+		return fmt.Errorf("error encoding PNG: %w", err)
+	}
+	return file.Close()
+}
+
+// isBlack reports whether no pixel received any radiance
+func (f *Film) isBlack() bool {
+	for _, c := range f.sum {
+		if c != (Color{}) {
+			return false
+		}
+	}
+	return true
+}
+
+// printLightContributions prints the light efficiency report: each light's
+// share of the image against its share of the light samples, dim lights
+// worth removing and the lights behind clipped pixels
+func printLightContributions(contributions []LightContribution) {
+	if len(contributions) == 0 {
+		return
+	}
+	fmt.Println("\n=== Light Contributions ===")
+	fmt.Printf("%-28s %8s %12s %10s\n", "Light", "Image", "NEE samples", "Clipped px")
+	clipped, brightest := 0, contributions[0]
+	for _, c := range contributions {
+		fmt.Printf("%-28s %7.1f%% %11.1f%% %10d\n", c.Name, 100*c.Share, 100*c.SampleShare, c.Clipped)
+		clipped += c.Clipped
+		if c.Clipped > brightest.Clipped {
+			brightest = c
+		}
+	}
+	for _, c := range contributions {
+		if c.Light >= 0 && c.Share < dimLightShare {
+			fmt.Printf("⚠ %s adds %.1f%% of the image for %.1f%% of the light samples; consider removing it\n",
+				c.Name, 100*c.Share, 100*c.SampleShare)
+		}
+	}
+	if clipped > 0 {
+		fmt.Printf("⚠ %d pixels are over-exposed, %d of them mostly lit by %s\n",
+			clipped, brightest.Clipped, brightest.Name)
+	}
+}
@@ -0,0 +1,61 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLightTallyAddsUpToSample(t *testing.T) {
+	SeedRandom(5)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	world, camera := lightPathScene()
+	camera.Ambient = DefaultAmbientConfig()
+	camera.Ambient.Enabled = true
+
+	tally := newLightTally(len(camera.Lights))
+	for i := range 500 {
+		ray := camera.GetRay(i%camera.ImageWidth, 0)
+		radiance := camera.rayColorCached(ray, camera.MaxDepth, world, nil, nil, tally)
+		sum := Color{}
+		for _, c := range tally {
+			sum = sum.Add(c)
+		}
+		if sum.Sub(radiance).Len() > 1e-9*(1+radiance.Len()) {
+			t.Fatalf("sample %d: tally adds up to %v, radiance is %v", i, sum, radiance)
+		}
+	}
+}
+
+func TestLightContributions(t *testing.T) {
+	SeedRandom(9)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	world, camera := lightPathScene()
+	// A third light far below the floor costs samples but lights nothing
+	hidden := NewQuad(Point3{Y: -10}, Vec3{X: 1}, Vec3{Z: 1}, NewDiffuseLightColor(Color{X: 6, Y: 6, Z: 6}))
+	world.Add(hidden)
+	camera.AddLight(hidden)
+	camera.Initialize()
+
+	r := NewBucketRenderer(camera, NewBVHNodeFromList(world), 8, 2).SetLightAOVs(true)
+	defer r.Close()
+	for _, bucket := range r.buckets {
+		r.renderBucketWithQuality(bucket, 8, camera.MaxDepth, true, nil, nil)
+	}
+
+	total := 0.0
+	byLight := map[int]LightContribution{}
+	for _, c := range r.LightContributions() {
+		total += c.Share
+		byLight[c.Light] = c
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("shares add up to %g, want 1", total)
+	}
+	if c := byLight[2]; c.Share != 0 || c.SampleShare == 0 {
+		t.Errorf("hidden light: %.1f%% of the image for %.1f%% of the samples, want none for some",
+			100*c.Share, 100*c.SampleShare)
+	}
+	if byLight[0].Share == 0 || byLight[1].Share == 0 {
+		t.Error("a visible light contributes nothing")
+	}
+}
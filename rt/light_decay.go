@@ -0,0 +1,102 @@
+package rt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// LIGHT DECAY
+// =============================================================================
+
+// DecayMode is how a light's illumination falls off with distance
+type DecayMode int
+
+const (
+	DecayInverseSquare DecayMode = iota // Physical: irradiance falls off with 1/d²
+	DecayLinear                         // Falls off with 1/d, for art direction
+	DecayNone                           // Same at any distance, for art direction
+)
+
+var decayModeNames = []string{"inverse-square", "linear", "none"}
+
+func (m DecayMode) String() string {
+	if int(m) < 0 || int(m) >= len(decayModeNames) {
+		return "unknown"
+	}
+	return decayModeNames[m]
+}
+
+// ParseDecayMode converts a mode name (e.g. "linear") to a DecayMode
+func ParseDecayMode(name string) (DecayMode, error) {
+	for i, n := range decayModeNames {
+		if strings.EqualFold(name, n) {
+			return DecayMode(i), nil
+		}
+	}
+	return DecayInverseSquare, fmt.Errorf("unknown decay mode: %s (use %s)",
+		name, strings.Join(decayModeNames, ", "))
+}
+
+// LightDecay is the distance falloff of a light, like the decay setting of
+// lights in DCC tools. Every mode gives the physical result at Distance, so
+// switching modes keeps the light's brightness there. Decay applies to the
+// light a surface receives, through both light sampling and BSDF rays that
+// hit the light, so MIS stays consistent; camera rays still see area lights
+// at their own radiance. The zero value is physical.
+type LightDecay struct {
+	Mode     DecayMode
+	Distance float64 // Distance where every mode matches the physical falloff (0 = 1 scene unit)
+}
+
+// scale returns the factor that turns the physical falloff of light that
+// traveled distance into the decay's
+func (d LightDecay) scale(distance float64) float64 {
+	ref := d.Distance
+	if ref <= 0 {
+		ref = 1
+	}
+	switch d.Mode {
+	case DecayLinear:
+		return distance / ref
+	case DecayNone:
+		return distance * distance / (ref * ref)
+	}
+	return 1
+}
+
+// SetDecay sets the light's distance falloff
+func (s *SpotLight) SetDecay(decay LightDecay) *SpotLight {
+	s.Decay = decay
+	return s
+}
+
+// SetDecay sets the distance falloff of the light this material emits
+func (dl *DiffuseLight) SetDecay(decay LightDecay) *DiffuseLight {
+	dl.Decay = decay
+	return dl
+}
+
+// emitterDecay returns the decay of an emissive material (physical for
+// anything but a DiffuseLight)
+func emitterDecay(mat Material) LightDecay {
+	if light, ok := mat.(*DiffuseLight); ok {
+		return light.Decay
+	}
+	return LightDecay{}
+}
+
+// SetLightDecay gives every registered light (see AddLight) the same decay
+func (c *Camera) SetLightDecay(decay LightDecay) *Camera {
+	for _, light := range c.Lights {
+		switch l := light.(type) {
+		case *SpotLight:
+			l.SetDecay(decay)
+		case *Quad:
+			if emitter, ok := l.mat.(*DiffuseLight); ok {
+				emitter.SetDecay(decay)
+			}
+		}
+	}
+	return c
+}
@@ -0,0 +1,77 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLightDecayScale(t *testing.T) {
+	cases := []struct {
+		decay    LightDecay
+		distance float64
+		want     float64
+	}{
+		{LightDecay{}, 5, 1},
+		{LightDecay{Mode: DecayLinear}, 5, 5},
+		{LightDecay{Mode: DecayNone}, 5, 25},
+		{LightDecay{Mode: DecayNone, Distance: 5}, 5, 1},
+		{LightDecay{Mode: DecayLinear, Distance: 2}, 1, 0.5},
+	}
+	for _, tc := range cases {
+		if got := tc.decay.scale(tc.distance); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%v at %g: scale %g, want %g", tc.decay, tc.distance, got, tc.want)
+		}
+	}
+	for _, mode := range []DecayMode{DecayInverseSquare, DecayLinear, DecayNone} {
+		if got, err := ParseDecayMode(mode.String()); err != nil || got != mode {
+			t.Errorf("ParseDecayMode(%q) = %v, %v", mode, got, err)
+		}
+	}
+}
+
+func TestPointLightWithoutDecay(t *testing.T) {
+	camera := NewCameraBuilder().SetResolution(8, 1).Build()
+	white := Color{X: 1, Y: 1, Z: 1}
+	lambert := NewLambertian(white)
+	lit := func(height float64) Color {
+		light := NewPointLight(Point3{Y: height}, white).SetDecay(LightDecay{Mode: DecayNone})
+		return camera.sampleSpotLight(Point3{}, Vec3{Y: 1}, Vec3{Y: -1}, NewHittableList(), light, 1, white, lambert, nil)
+	}
+	if near, far := lit(1), lit(4); math.Abs(near.X-far.X) > 1e-12 {
+		t.Errorf("a light without decay gives %v at 1 and %v at 4", near, far)
+	}
+}
+
+// With decay, light sampling and BSDF rays that hit the light must agree, or
+// MIS would blend two different answers
+func TestLightDecayMatchesBSDFSampling(t *testing.T) {
+	SeedRandom(11)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	emitter := NewDiffuseLightColor(Color{X: 4, Y: 4, Z: 4}).SetDecay(LightDecay{Mode: DecayLinear, Distance: 0.5})
+	light := NewQuad(Point3{X: -0.5, Y: 1, Z: -0.5}, Vec3{X: 1}, Vec3{Z: 1}, emitter)
+	world := NewHittableList()
+	world.Add(NewQuad(Point3{X: -4, Z: -4}, Vec3{Z: 8}, Vec3{X: 8}, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})))
+	world.Add(light)
+
+	estimate := func(camera *Camera) float64 {
+		const samples = 40000
+		sum := 0.0
+		for range samples {
+			sum += camera.RayColor(NewRay(Point3{Y: 0.5}, Vec3{Y: -1}, 0), 2, world).X
+		}
+		return sum / samples
+	}
+	bsdfOnly := NewCameraBuilder().SetResolution(8, 1).SetQuality(1, 2).Build()
+	withNEE := NewCameraBuilder().SetResolution(8, 1).SetQuality(1, 2).AddLight(light).Build()
+
+	bsdf, nee := estimate(bsdfOnly), estimate(withNEE)
+	if math.Abs(bsdf-nee) > 0.06*nee {
+		t.Errorf("BSDF sampling gives %.4f, light sampling with MIS %.4f", bsdf, nee)
+	}
+
+	emitter.SetDecay(LightDecay{})
+	if physical := estimate(withNEE); nee < 1.5*physical {
+		t.Errorf("linear decay from 0.5 gives %.4f, physical %.4f; want about twice", nee, physical)
+	}
+}
@@ -0,0 +1,195 @@
+package rt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// LIGHT PATH FILTERS (LPE-STYLE AOVS)
+// =============================================================================
+
+// PathEvent classifies how a path scattered at a surface
+type PathEvent int
+
+const (
+	PathEventDiffuse  PathEvent = iota // Materials that take NEE (Lambertian, volumes, ...)
+	PathEventGlossy                    // Rough metals and other non-NEE reflections
+	PathEventSpecular                  // Mirrors and glass
+)
+
+// LightSource is what a contribution's light came from
+type LightSource int
+
+const (
+	LightSourceEmitter    LightSource = iota // Emissive surface or spot light
+	LightSourceBackground                    // HDRI, sky gradient or background color
+	LightSourceAmbient                       // Ambient fill (see AmbientConfig)
+)
+
+// LightPathContribution describes one radiance contribution of a camera
+// path, in the spirit of a light path expression: the scatter events from
+// the camera to the light and the light itself
+type LightPathContribution struct {
+	Bounces    int       // Scatter events before the light (0 = seen directly)
+	FirstEvent PathEvent // Event at the first surface (meaningless when Bounces is 0)
+	Source     LightSource
+	Light      int // Index into Camera.Lights, -1 for the background and ambient
+}
+
+// LightPathFilter selects the contributions kept in the render, so the image
+// becomes an AOV such as diffuse indirect light or a single light's share.
+// Sampling is unchanged; rejected contributions are dropped.
+type LightPathFilter func(LightPathContribution) bool
+
+var lightPathFilterNames = []string{
+	"beauty", "emission", "background", "direct", "indirect",
+	"diffuse", "diffuse-direct", "diffuse-indirect", "specular",
+}
+
+// lightPathFilters holds the named filters. emission + background + diffuse
+// + specular add up to the beauty render.
+var lightPathFilters = map[string]LightPathFilter{
+	"beauty": nil,
+	"emission": func(p LightPathContribution) bool {
+		return p.Bounces == 0 && p.Source == LightSourceEmitter
+	},
+	"background": func(p LightPathContribution) bool {
+		return p.Bounces == 0 && p.Source == LightSourceBackground
+	},
+	"direct": func(p LightPathContribution) bool {
+		return p.Bounces == 1
+	},
+	"indirect": func(p LightPathContribution) bool {
+		return p.Bounces >= 2
+	},
+	"diffuse": func(p LightPathContribution) bool {
+		return p.Bounces > 0 && p.FirstEvent == PathEventDiffuse
+	},
+	"diffuse-direct": func(p LightPathContribution) bool {
+		return p.Bounces == 1 && p.FirstEvent == PathEventDiffuse
+	},
+	"diffuse-indirect": func(p LightPathContribution) bool {
+		return p.Bounces >= 2 && p.FirstEvent == PathEventDiffuse
+	},
+	"specular": func(p LightPathContribution) bool {
+		return p.Bounces > 0 && p.FirstEvent != PathEventDiffuse
+	},
+}
+
+// ParseLightPathFilter converts a filter name (e.g. "diffuse-indirect") to a
+// LightPathFilter. "light:N" keeps only light from Camera.Lights[N], seen
+// directly or reflected. "beauty" returns nil, i.e. no filtering.
+func ParseLightPathFilter(name string) (LightPathFilter, error) {
+	if index, ok := strings.CutPrefix(strings.ToLower(name), "light:"); ok {
+		light, err := strconv.Atoi(index)
+		if err != nil || light < 0 {
+			return nil, fmt.Errorf("invalid light path filter %s: light index must be a non-negative integer", name)
+		}
+		return func(p LightPathContribution) bool { return p.Light == light }, nil
+	}
+	for _, n := range lightPathFilterNames {
+		if strings.EqualFold(name, n) {
+			return lightPathFilters[n], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown light path filter: %s (use %s, light:N)",
+		name, strings.Join(lightPathFilterNames, ", "))
+}
+
+// SetLightPathFilter renders only the contributions accepted by filter (nil
+// renders everything)
+func (c *Camera) SetLightPathFilter(filter LightPathFilter) *Camera {
+	c.LightPath = filter
+	return c
+}
+
+// pathState is the part of a camera path a LightPathFilter looks at, plus
+// the bucket's environment visibility cache for the camera-ray hit and the
+// per-light tally of the sample
+type pathState struct {
+	bounces    int
+	first      PathEvent
+	envCache   *envVisibilityCache // Only set before the first scatter
+	nee        *neeBucket          // Adaptive light samples; only set before the first scatter
+	lights     lightTally          // Radiance per light (nil = not tallied)
+	throughput Color               // Product of the attenuations so far (only kept while tallying)
+	internal   int                 // Consecutive free internal bounces (see SetMaxInternalBounces)
+	reflection prefilterReflection // Prefiltered lookup for rays escaping a rough metal
+}
+
+// scatter returns the state after scattering with event and attenuation.
+// Later vertices are spread over the scene, so they don't share the bucket's
+// visibility cache.
+func (p pathState) scatter(event PathEvent, attenuation Color) pathState {
+	if p.bounces == 0 {
+		p.first = event
+	}
+	p.bounces++
+	p.envCache = nil
+	p.nee = nil
+	if p.lights != nil {
+		p.throughput = p.throughput.Mult(attenuation)
+	}
+	return p
+}
+
+// pathEvent classifies the scatter event of a material
+func pathEvent(mat Material) PathEvent {
+	info, ok := mat.(MaterialInfo)
+	if !ok {
+		return PathEventDiffuse
+	}
+	props := info.Properties()
+	switch {
+	case props.isPureSpecular:
+		return PathEventSpecular
+	case props.CanUseNEE:
+		return PathEventDiffuse
+	}
+	return PathEventGlossy
+}
+
+// pathWeight returns 1 when the light path filter keeps a contribution
+// reaching source (light index, or -1) after path, 0 otherwise
+func (c *Camera) pathWeight(path pathState, source LightSource, light int) float64 {
+	if c.LightPath == nil {
+		return 1
+	}
+	contribution := LightPathContribution{
+		Bounces:    path.bounces,
+		FirstEvent: path.first,
+		Source:     source,
+		Light:      light,
+	}
+	if c.LightPath(contribution) {
+		return 1
+	}
+	return 0
+}
+
+// emitterLight returns the Camera.Lights index of the light with emissive
+// material mat, -1 if it isn't a registered light. Lights sharing a material
+// can't be told apart and report the first of them.
+func (c *Camera) emitterLight(mat Material) int {
+	emitter, ok := mat.(*DiffuseLight)
+	if !ok {
+		return -1
+	}
+	if light, ok := c.emitterLights[emitter]; ok {
+		return light
+	}
+	return -1
+}
+
+// buildEmitterLights indexes the registered area lights by material
+func (c *Camera) buildEmitterLights() {
+	c.emitterLights = make(map[*DiffuseLight]int, len(c.Lights))
+	for i, light := range c.Lights {
+		emitter := lightEmitter(light)
+		if _, seen := c.emitterLights[emitter]; emitter != nil && !seen {
+			c.emitterLights[emitter] = i
+		}
+	}
+}
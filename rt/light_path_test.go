@@ -0,0 +1,131 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+// lightPathScene has two area lights, a diffuse floor, a rough metal and a
+// glass sphere under a sky background
+func lightPathScene() (*HittableList, *Camera) {
+	world := NewHittableList()
+	world.Add(NewQuad(Point3{X: -5, Y: 0, Z: -5}, Vec3{X: 10}, Vec3{Z: 10}, NewLambertian(Color{X: 0.7, Y: 0.7, Z: 0.7})))
+	world.Add(NewSphere(Point3{X: -1, Y: 1, Z: 0}, 1, NewMetal(Color{X: 0.9, Y: 0.8, Z: 0.6}, 0.3)))
+	world.Add(NewSphere(Point3{X: 1.2, Y: 0.7, Z: 0.5}, 0.7, NewDielectric(1.5)))
+
+	warm := NewQuad(Point3{X: -3, Y: 4, Z: -1}, Vec3{X: 1}, Vec3{Z: 1}, NewDiffuseLightColor(Color{X: 8, Y: 6, Z: 4}))
+	cool := NewQuad(Point3{X: 2, Y: 4, Z: -1}, Vec3{X: 1}, Vec3{Z: 1}, NewDiffuseLightColor(Color{X: 3, Y: 5, Z: 9}))
+	world.Add(warm)
+	world.Add(cool)
+
+	camera := NewCameraBuilder().SetResolution(12, 1).SetQuality(4, 6).
+		SetPosition(Point3{Y: 2, Z: 6}, Point3{Y: 1}, Vec3{Y: 1}).
+		SetBackground(Color{X: 0.3, Y: 0.4, Z: 0.5}).Build()
+	camera.AddLight(warm).AddLight(cool)
+	camera.Initialize()
+	return world, camera
+}
+
+// renderLightPath traces a fixed random sequence so renders with different
+// filters sample the same paths
+func renderLightPath(world Hittable, camera *Camera, filter LightPathFilter) []Color {
+	camera.SetLightPathFilter(filter)
+	SeedRandom(3)
+
+	var pixels []Color
+	for j := 0; j < camera.ImageHeight; j++ {
+		for i := 0; i < camera.ImageWidth; i++ {
+			sum := Color{}
+			for s := 0; s < camera.SamplesPerPixel; s++ {
+				sum = sum.Add(camera.RayColor(camera.GetRay(i, j), camera.MaxDepth, world))
+			}
+			pixels = append(pixels, sum)
+		}
+	}
+	return pixels
+}
+
+func mustParseLightPath(t *testing.T, name string) LightPathFilter {
+	t.Helper()
+	filter, err := ParseLightPathFilter(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filter
+}
+
+// checkPartition verifies that the filtered renders add up to the beauty
+func checkPartition(t *testing.T, world Hittable, camera *Camera, names ...string) {
+	t.Helper()
+	var filters []LightPathFilter
+	for _, name := range names {
+		filters = append(filters, mustParseLightPath(t, name))
+	}
+	checkFilterPartition(t, world, camera, names, filters...)
+}
+
+func checkFilterPartition(t *testing.T, world Hittable, camera *Camera, names []string, filters ...LightPathFilter) {
+	t.Helper()
+	beauty := renderLightPath(world, camera, nil)
+	total := make([]Color, len(beauty))
+	for _, filter := range filters {
+		for i, c := range renderLightPath(world, camera, filter) {
+			total[i] = total[i].Add(c)
+		}
+	}
+	for i := range beauty {
+		if diff := total[i].Sub(beauty[i]); math.Abs(diff.X)+math.Abs(diff.Y)+math.Abs(diff.Z) > 1e-9*(1+beauty[i].Len()) {
+			t.Fatalf("%v: pixel %d sums to %v, beauty is %v", names, i, total[i], beauty[i])
+		}
+	}
+}
+
+func TestLightPathFiltersPartitionBeauty(t *testing.T) {
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	world, camera := lightPathScene()
+
+	checkPartition(t, world, camera, "emission", "background", "diffuse", "specular")
+	checkPartition(t, world, camera, "emission", "background", "direct", "indirect")
+	checkPartition(t, world, camera, "emission", "background", "diffuse-direct", "diffuse-indirect", "specular")
+
+	// Per-light AOVs plus the background however it is reached
+	anyBackground := func(p LightPathContribution) bool { return p.Source == LightSourceBackground }
+	checkFilterPartition(t, world, camera, []string{"light:0", "light:1", "any background"},
+		mustParseLightPath(t, "light:0"), mustParseLightPath(t, "light:1"), anyBackground)
+}
+
+func TestLightPathFilterSeparatesLights(t *testing.T) {
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	world, camera := lightPathScene()
+
+	warm, cool := Color{}, Color{}
+	for _, c := range renderLightPath(world, camera, mustParseLightPath(t, "light:0")) {
+		warm = warm.Add(c)
+	}
+	for _, c := range renderLightPath(world, camera, mustParseLightPath(t, "light:1")) {
+		cool = cool.Add(c)
+	}
+	if warm.X <= warm.Z || cool.Z <= cool.X {
+		t.Errorf("light:0 = %v should be warm and light:1 = %v cool", warm, cool)
+	}
+}
+
+func TestParseLightPathFilter(t *testing.T) {
+	if filter, err := ParseLightPathFilter("Beauty"); err != nil || filter != nil {
+		t.Errorf("beauty should parse to no filter, got err %v", err)
+	}
+	filter, err := ParseLightPathFilter("diffuse-indirect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filter(LightPathContribution{Bounces: 3, FirstEvent: PathEventDiffuse}) ||
+		filter(LightPathContribution{Bounces: 1, FirstEvent: PathEventDiffuse}) ||
+		filter(LightPathContribution{Bounces: 3, FirstEvent: PathEventGlossy}) {
+		t.Error("diffuse-indirect should keep only diffuse paths with two or more bounces")
+	}
+	for _, bad := range []string{"glossy-ish", "light:", "light:-1", "light:x"} {
+		if _, err := ParseLightPathFilter(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// LIGHT SELECTION
+// =============================================================================
+
+// lightSampler picks one light per NEE event with probability proportional to
+// its emitted power. Area lights and the HDRI environment share a single
+// distribution so dim fill lights don't take samples away from the key light.
+//
+// Entries [0, len(lights)) map to Camera.Lights; when an importance-sampled
+// environment is present it occupies the last entry.
+type lightSampler struct {
+	lights   []*Quad      // Parallel to Camera.Lights (nil for unsupported shapes)
+	spots    []*SpotLight // Parallel to Camera.Lights (nil for area lights)
+	env      *HDRIEnvironment
+	probs    []float64 // Selection probability per entry
+	cdf      []float64 // Cumulative selection probability (len(probs)+1)
+	envIndex int       // Index of the environment entry, -1 if none
+}
+
+func newLightSampler(lights []Hittable, env *HDRIEnvironment, sceneRadius float64) *lightSampler {
+	ls := &lightSampler{envIndex: -1}
+
+	var powers []float64
+	for _, light := range lights {
+		var quad *Quad
+		var spot *SpotLight
+		power := 0.0
+		switch l := light.(type) {
+		case *Quad:
+			quad, power = l, l.Power()
+		case *SpotLight:
+			spot, power = l, l.Power()
+		}
+		ls.lights = append(ls.lights, quad)
+		ls.spots = append(ls.spots, spot)
+		powers = append(powers, power)
+	}
+
+	if env != nil && env.IsValid() && env.useImportanceSampling {
+		ls.env = env
+		ls.envIndex = len(powers)
+		powers = append(powers, env.Power(sceneRadius))
+	}
+
+	total := 0.0
+	for _, p := range powers {
+		total += p
+	}
+
+	ls.probs = make([]float64, len(powers))
+	ls.cdf = make([]float64, len(powers)+1)
+	for i, p := range powers {
+		if total > 0 {
+			ls.probs[i] = p / total
+		} else {
+			// No measurable power (e.g. black emitters) - fall back to uniform
+			ls.probs[i] = 1.0 / float64(len(powers))
+		}
+		ls.cdf[i+1] = ls.cdf[i] + ls.probs[i]
+	}
+
+	return ls
+}
+
+// Len returns the number of selectable lights
+func (ls *lightSampler) Len() int {
+	if ls == nil {
+		return 0
+	}
+	return len(ls.probs)
+}
+
+// Sample selects a light entry for the uniform number xi and returns its
+// index together with the probability of having selected it
+func (ls *lightSampler) Sample(xi float64) (int, float64) {
+	n := len(ls.probs)
+	low, high := 0, n-1
+	for low < high {
+		mid := (low + high) / 2
+		if ls.cdf[mid+1] <= xi {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	// Skip zero-probability entries that share a CDF value with their neighbour
+	for low < n-1 && ls.probs[low] == 0 {
+		low++
+	}
+
+	return low, ls.probs[low]
+}
+
+// EnvironmentPDF returns the combined selection and direction density for the
+// environment along dir, or 0 if the environment isn't sampled by NEE
+func (ls *lightSampler) EnvironmentPDF(dir Vec3) float64 {
+	if ls == nil || ls.envIndex < 0 {
+		return 0
+	}
+	return ls.probs[ls.envIndex] * ls.env.PDF(dir)
+}
+
+// AreaLightPDF returns the combined selection and solid-angle density with
+// which NEE would generate the direction dir from origin to the light hit at
+// rec, or 0 if rec isn't on a registered area light. Lights behind the hit
+// one don't count: NEE's shadow rays to them are blocked by it.
+func (ls *lightSampler) AreaLightPDF(origin Point3, dir Vec3, rec *HitRecord) float64 {
+	if ls == nil {
+		return 0
+	}
+
+	for i, quad := range ls.lights {
+		if quad == nil || quad.mat != rec.Mat {
+			continue
+		}
+		// Lights sharing a material are told apart by where the ray meets them
+		lightRec := &HitRecord{}
+		if !quad.Hit(NewRay(origin, dir, 0), NewInterval(0.001, math.Inf(1)), lightRec) ||
+			math.Abs(lightRec.T-rec.T) > 1e-6*math.Max(1, rec.T) {
+			continue
+		}
+		return ls.probs[i] * quad.PdfValue(origin, dir)
+	}
+	return 0
+}
+
+// Power returns the emitted power of the quad used for light selection
+func (q *Quad) Power() float64 {
+	center := q.Q.Add(q.u.Scale(0.5)).Add(q.v.Scale(0.5))
+	return math.Pi * Luminance(q.mat.Emitted(0.5, 0.5, center)) * q.Area()
+}
@@ -0,0 +1,30 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAreaLightPDFOnlyCountsTheHitLight(t *testing.T) {
+	// Two lights facing the origin, one behind the other along +Y
+	near := NewQuad(Point3{X: -0.5, Y: 1, Z: -0.5}, Vec3{X: 1}, Vec3{Z: 1}, NewDiffuseLightColor(Color{X: 4, Y: 4, Z: 4}))
+	far := NewQuad(Point3{X: -1, Y: 3, Z: -1}, Vec3{X: 2}, Vec3{Z: 2}, NewDiffuseLightColor(Color{X: 4, Y: 4, Z: 4}))
+	ls := newLightSampler([]Hittable{near, far}, nil, 10)
+
+	origin, dir := Point3{}, Vec3{Y: 1}
+	rec := &HitRecord{}
+	if !near.Hit(NewRay(origin, dir, 0), NewInterval(0.001, math.Inf(1)), rec) {
+		t.Fatal("ray misses the near light")
+	}
+	want := ls.probs[0] * near.PdfValue(origin, dir)
+	if got := ls.AreaLightPDF(origin, dir, rec); math.Abs(got-want) > 1e-12 {
+		t.Errorf("pdf = %v, want %v for the near light alone", got, want)
+	}
+
+	// An emitter NEE doesn't know about can't have been light sampled
+	unregistered := NewQuad(Point3{X: -0.5, Y: 1, Z: -0.5}, Vec3{X: 1}, Vec3{Z: 1}, NewDiffuseLightColor(Color{X: 1, Y: 1, Z: 1}))
+	unregistered.Hit(NewRay(origin, dir, 0), NewInterval(0.001, math.Inf(1)), rec)
+	if got := ls.AreaLightPDF(origin, dir, rec); got != 0 {
+		t.Errorf("pdf of an unregistered emitter = %v, want 0", got)
+	}
+}
@@ -0,0 +1,116 @@
+package rt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Fuzz targets for the asset parsers. The seed corpus runs with `go test`;
+// explore further with e.g.
+//
+//	go test ./rt -run '^$' -fuzz FuzzDecodeHDR -fuzztime 60s
+
+// hdrFile builds a minimal Radiance HDR file with the given resolution line
+// and pixel payload
+func hdrFile(resolution string, payload []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n")
+	buf.WriteString(resolution + "\n")
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+func FuzzDecodeHDR(f *testing.F) {
+	// Flat 2x1 image
+	f.Add(hdrFile("-Y 1 +X 2", []byte{128, 64, 32, 129, 0, 0, 0, 0}))
+
+	// RLE 8x1 image: one run per component
+	f.Add(hdrFile("-Y 1 +X 8", []byte{2, 2, 0, 8, 136, 10, 136, 20, 136, 30, 136, 129}))
+
+	// Zero-length runs used to spin forever
+	f.Add(hdrFile("-Y 1 +X 8", []byte{2, 2, 0, 8, 0, 0, 0, 0}))
+	f.Add(hdrFile("-Y 1 +X 8", []byte{2, 2, 0, 8, 128, 5}))
+
+	// Runs longer than the scanline, truncated data, absurd sizes
+	f.Add(hdrFile("-Y 1 +X 8", []byte{2, 2, 0, 8, 255, 1}))
+	f.Add(hdrFile("-Y 4 +X 4", []byte{1, 2, 3}))
+	f.Add(hdrFile("-Y 1000000 +X 1000000", nil))
+	f.Add(hdrFile("-Y -1 +X 4", nil))
+	f.Add([]byte("#?RADIANCE\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		img := NewImageLoader()
+		if err := img.DecodeHDR(bytes.NewReader(data)); err != nil {
+			if img.Width() != 0 || img.Height() != 0 {
+				t.Fatalf("failed decode left a %dx%d image", img.Width(), img.Height())
+			}
+			return
+		}
+
+		if len(img.data) != img.Width()*img.Height() {
+			t.Fatalf("decoded %d pixels for %dx%d", len(img.data), img.Width(), img.Height())
+		}
+		if img.Width() > MaxHDRDimension || img.Height() > MaxHDRDimension {
+			t.Fatalf("decoded %dx%d beyond limits", img.Width(), img.Height())
+		}
+	})
+}
+
+func FuzzParseOBJ(f *testing.F) {
+	f.Add("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n")
+	f.Add("v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1/1/1 2/2/2 3/3/3 4/4/4\n")
+	f.Add("v 0 0 0\nv 1 0 0\nv 0 1 0\nf -3 -2 -1\n")
+	f.Add("v 0 0 0\nf 0 1 2\n")
+	f.Add("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 99\n")
+	f.Add("v NaN 0 0\n")
+	f.Add("v 1e400 0 0\n")
+	f.Add("f " + strings.Repeat("1 ", MaxOBJFaceVertices+1) + "\n")
+
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+
+	f.Fuzz(func(t *testing.T, data string) {
+		triangles, numVertices, err := ParseOBJ(strings.NewReader(data), mat)
+		if err != nil {
+			return
+		}
+
+		if numVertices > MaxOBJVertices || len(triangles) > MaxOBJTriangles {
+			t.Fatalf("parsed %d vertices, %d triangles beyond limits", numVertices, len(triangles))
+		}
+
+		// Whatever parses must be safe to build a BVH from
+		NewBVHNode(triangles, 0, len(triangles))
+	})
+}
+
+func FuzzParsePLY(f *testing.F) {
+	header := "ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n"
+	f.Add(header + "element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n3 0 1 2\n")
+	f.Add("ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n" +
+		"property uchar red\nproperty uchar green\nproperty uchar blue\n" +
+		"element face 1\nproperty list uchar int vertex_indices\nend_header\n" +
+		"0 0 0 255 0 0\n1 0 0 0 255 0\n0 1 0 0 0 255\n3 0 1 2\n")
+	f.Add(header + "element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n3 0 1 9\n")
+	f.Add(header + "element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n255 0 1 2\n")
+	f.Add("ply\nformat binary_little_endian 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nend_header\n\x00\x00\xc0\x7f\x00\x00\x00\x00\x00\x00\x00\x00")
+	f.Add(header + "element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n3 0 1 nan\n")
+	f.Add(header + "element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\nnan 0 1 2\n")
+	f.Add("ply\nformat ascii 1.0\nelement vertex 1000000000\nend_header\n")
+	f.Add("ply\nformat ascii 1.0\nend_head")
+
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+
+	f.Fuzz(func(t *testing.T, data string) {
+		triangles, numVertices, err := ParsePLY(strings.NewReader(data), mat)
+		if err != nil {
+			return
+		}
+
+		if numVertices > MaxOBJVertices || len(triangles) > MaxOBJTriangles {
+			t.Fatalf("parsed %d vertices, %d triangles beyond limits", numVertices, len(triangles))
+		}
+
+		NewBVHNode(triangles, 0, len(triangles))
+	})
+}
@@ -55,30 +55,24 @@ func (l *Lambertian) Properties() MaterialProperties {
 }
 
 func (l *Lambertian) Scatter(rIn Ray, rec *HitRecord, attenuation *Color, scattered *Ray) bool {
-	scatterDirection := rec.Normal.Add(RandomUnitVector())
-
-	if scatterDirection.NearZero() {
-		scatterDirection = rec.Normal
-	}
-
-	*scattered = NewRay(rec.P, scatterDirection, rIn.Time())
+	*scattered = NewRay(rec.P, sampleCosineHemisphere(rIn.sampler, rec.Normal), rIn.Time())
 	*attenuation = l.tex.Value(rec.U, rec.V, rec.P)
 
 	return true
 }
 
 func (l *Lambertian) PDF(wi, wo, normal Vec3) float64 {
-	cosTheta := Dot(normal, wo)
-	if cosTheta < 0 {
-		return 0
-	}
-	return cosTheta / math.Pi
+	return CosineHemispherePDF(Dot(normal, wo))
 }
 
 func (l *Lambertian) Emitted(u, v float64, p Point3) Color {
 	return Color{X: 0, Y: 0, Z: 0}
 }
 
+func (l *Lambertian) SurfaceAlbedo(u, v float64, p Point3) Color {
+	return l.tex.Value(u, v, p)
+}
+
 // =============================================================================
 // METAL (REFLECTIVE)
 // =============================================================================
@@ -112,12 +106,17 @@ func (m *Metal) Properties() MaterialProperties {
 
 func (m *Metal) Scatter(rIn Ray, rec *HitRecord, attenuation *Color, scattered *Ray) bool {
 	reflected := Reflect(rIn.Direction(), rec.Normal)
-	reflected = reflected.Unit().Add(RandomUnitVector().Scale(m.Fuzz))
+	reflected = reflected.Unit().Add(sampleUniformSphere(rIn.sampler).Scale(m.Fuzz))
 	*scattered = NewRay(rec.P, reflected, rIn.Time())
 	*attenuation = m.Albedo
 	return Dot(scattered.Direction(), rec.Normal) > 0
 }
 
+// withRoughnessOffset returns a copy with fuzz shifted by offset (clamped to [0, 1])
+func (m *Metal) withRoughnessOffset(offset float64) Material {
+	return &Metal{Albedo: m.Albedo, Fuzz: clampFloat(m.Fuzz+offset, 0, 1)}
+}
+
 func (m *Metal) PDF(wi, wo, normal Vec3) float64 {
 	if m.Fuzz == 0 {
 		return 0 // Perfect specular reflection is a delta distribution
@@ -139,12 +138,22 @@ func (m *Metal) Emitted(u, v float64, p Point3) Color {
 	return Color{X: 0, Y: 0, Z: 0}
 }
 
+func (m *Metal) SurfaceAlbedo(u, v float64, p Point3) Color {
+	return m.Albedo
+}
+
 // =============================================================================
 // DIELECTRIC (GLASS/REFRACTIVE)
 // =============================================================================
 
 type Dielectric struct {
 	RefractionIndex float64
+	// Per-channel IOR (R, G, B) for dispersion; zero disables it (see
+	// SetDispersion)
+	DispersionIOR Color
+	// Bounces inside the material that don't use up the camera's MaxDepth
+	// (see SetMaxInternalBounces)
+	MaxInternalBounces int
 }
 
 func NewDielectric(refractionIndex float64) *Dielectric {
@@ -164,11 +173,16 @@ func (d *Dielectric) Properties() MaterialProperties {
 func (d *Dielectric) Scatter(rIn Ray, rec *HitRecord, attenuation *Color, scattered *Ray) bool {
 	*attenuation = Color{X: 1.0, Y: 1.0, Z: 1.0}
 
+	refractionIndex, channel := d.RefractionIndex, rIn.channel
+	if d.DispersionIOR != (Color{}) {
+		refractionIndex, channel = d.dispersedIOR(channel, attenuation, rIn.sampler)
+	}
+
 	var ri float64
 	if rec.FrontFace {
-		ri = 1.0 / d.RefractionIndex
+		ri = 1.0 / refractionIndex
 	} else {
-		ri = d.RefractionIndex
+		ri = refractionIndex
 	}
 	unitDirection := rIn.Direction().Unit()
 	cosTheta := math.Min(Dot(unitDirection.Neg(), rec.Normal), 1.0)
@@ -177,12 +191,12 @@ func (d *Dielectric) Scatter(rIn Ray, rec *HitRecord, attenuation *Color, scatte
 
 	var direction Vec3
 
-	if cannotRefract || reflectance(cosTheta, ri) > RandomDouble() {
+	if cannotRefract || reflectance(cosTheta, ri) > rIn.sampler.Float64() {
 		direction = Reflect(unitDirection, rec.Normal)
 	} else {
 		direction = Refract(unitDirection, rec.Normal, ri)
 	}
-	*scattered = NewRay(rec.P, direction, rIn.Time())
+	*scattered = NewRay(rec.P, direction, rIn.Time()).withChannel(channel)
 
 	return true
 }
@@ -195,12 +209,17 @@ func (d *Dielectric) Emitted(u, v float64, p Point3) Color {
 	return Color{X: 0, Y: 0, Z: 0}
 }
 
+func (d *Dielectric) SurfaceAlbedo(u, v float64, p Point3) Color {
+	return Color{X: 1, Y: 1, Z: 1}
+}
+
 // =============================================================================
 // DIFFUSE LIGHT (EMISSIVE)
 // =============================================================================
 
 type DiffuseLight struct {
-	tex Texture
+	tex   Texture
+	Decay LightDecay // Distance falloff of the light it casts (zero = physical)
 }
 
 func NewDiffuseLight(tex Texture) *DiffuseLight {
@@ -215,6 +234,12 @@ func NewDiffuseLightColor(emit Color) *DiffuseLight {
 	}
 }
 
+// ScaleIntensity multiplies the emitted radiance by scale. Lights sampled by
+// NEE pick up the change at the next camera Initialize.
+func (dl *DiffuseLight) ScaleIntensity(scale float64) {
+	dl.tex = NewScaledTexture(dl.tex, scale)
+}
+
 func (dl *DiffuseLight) Properties() MaterialProperties {
 	return MaterialProperties{
 		isPureSpecular: false,
@@ -264,19 +289,23 @@ func (i *Isotropic) Properties() MaterialProperties {
 
 // Scatter scatters the ray in a random direction (uniform sphere)
 func (i *Isotropic) Scatter(rIn Ray, rec *HitRecord, attenuation *Color, scattered *Ray) bool {
-	*scattered = NewRay(rec.P, RandomUnitVector(), rIn.Time())
+	*scattered = NewRay(rec.P, sampleUniformSphere(rIn.sampler), rIn.Time())
 	*attenuation = i.tex.Value(rec.U, rec.V, rec.P)
 	return true
 }
 
 func (i *Isotropic) PDF(wi, wo, normal Vec3) float64 {
-	return 1.0 / (4.0 * math.Pi) // Uniform sphere PDF
+	return UniformSpherePDF()
 }
 
 func (i *Isotropic) Emitted(u, v float64, p Point3) Color {
 	return Color{X: 0, Y: 0, Z: 0}
 }
 
+func (i *Isotropic) SurfaceAlbedo(u, v float64, p Point3) Color {
+	return i.tex.Value(u, v, p)
+}
+
 // =============================================================================
 // UTILITY FUNCTIONS
 // =============================================================================
@@ -0,0 +1,113 @@
+package rt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// MATERIAL PREVIEW (SHADER BALL)
+// =============================================================================
+
+// MaterialPreviewScene builds the standard look-development setup around mat:
+// a checkered floor and backdrop, the test sphere on a pedestal, 18% grey and
+// chrome reference balls as insets, and soft key/fill area lights over a dim
+// uniform environment
+func MaterialPreviewScene(mat Material) (*HittableList, *Camera) {
+	world := NewHittableList()
+
+	// =============================================================================
+	// BACKDROP
+	// =============================================================================
+	floorMat := NewLambertianTexture(NewCheckerTextureFromColors(0.5,
+		Color{X: 0.2, Y: 0.2, Z: 0.2},
+		Color{X: 0.45, Y: 0.45, Z: 0.45}))
+	backdropMat := NewLambertian(Color{X: 0.35, Y: 0.35, Z: 0.35})
+	world.Add(NewPlane(Point3{X: 0, Y: 0, Z: 0}, Vec3{X: 0, Y: 1, Z: 0}, floorMat))
+	world.Add(NewQuad(Point3{X: -8, Y: 0, Z: -4}, Vec3{X: 16, Y: 0, Z: 0}, Vec3{X: 0, Y: 8, Z: 0}, backdropMat))
+
+	// =============================================================================
+	// TEST SPHERE AND REFERENCE INSETS
+	// =============================================================================
+	pedestalMat := NewLambertian(Color{X: 0.1, Y: 0.1, Z: 0.1})
+	world.Add(Box(Point3{X: -0.6, Y: 0, Z: -0.6}, Point3{X: 0.6, Y: 0.3, Z: 0.6}, pedestalMat))
+	world.Add(NewSphere(Point3{X: 0, Y: 1.3, Z: 0}, 1.0, mat))
+
+	greyBall := NewLambertian(Color{X: 0.18, Y: 0.18, Z: 0.18})
+	chromeBall := NewMetal(Color{X: 0.95, Y: 0.95, Z: 0.95}, 0)
+	world.Add(NewSphere(Point3{X: -1.9, Y: 0.35, Z: 0.9}, 0.35, greyBall))
+	world.Add(NewSphere(Point3{X: 1.9, Y: 0.35, Z: 0.9}, 0.35, chromeBall))
+
+	// =============================================================================
+	// SOFT LIGHTING
+	// =============================================================================
+	key := NewQuad(Point3{X: -5, Y: 3, Z: 1}, Vec3{X: 0, Y: 3, Z: 0}, Vec3{X: 2.2, Y: 0, Z: 2.2},
+		NewDiffuseLightColor(Color{X: 12, Y: 11.4, Z: 10.4}))
+	fill := NewQuad(Point3{X: 5, Y: 1.5, Z: 4}, Vec3{X: 0, Y: 2.5, Z: 0}, Vec3{X: 0, Y: 0, Z: -2.5},
+		NewDiffuseLightColor(Color{X: 2.4, Y: 2.6, Z: 3.0}))
+	world.Add(key)
+	world.Add(fill)
+
+	camera := NewCameraBuilder().
+		SetResolution(600, 4.0/3.0).
+		SetQuality(128, 20).
+		SetPosition(
+			Point3{X: 0, Y: 1.8, Z: 6.5},
+			Point3{X: 0, Y: 1.0, Z: 0},
+			Vec3{X: 0, Y: 1, Z: 0},
+		).
+		SetLens(35, 0, 6.5).
+		SetUniformEnvironment(Color{X: 0.15, Y: 0.15, Z: 0.17}).
+		AddLight(key).
+		AddLight(fill).
+		Build()
+
+	return world, camera
+}
+
+// previewMaterial is a named entry of the material library
+type previewMaterial struct {
+	name  string
+	build func() Material
+}
+
+// previewMaterials are the named materials for -preview-material, followed
+// by those added with RegisterMaterial
+var previewMaterials = []previewMaterial{
+	{"lambertian", func() Material { return NewLambertian(Color{X: 0.75, Y: 0.3, Z: 0.2}) }},
+	{"metal", func() Material { return NewMetal(Color{X: 0.9, Y: 0.9, Z: 0.9}, 0) }},
+	{"brushed-metal", func() Material { return NewMetal(Color{X: 0.8, Y: 0.8, Z: 0.85}, 0.3) }},
+	{"gold", func() Material { return NewMetal(Color{X: 1.0, Y: 0.78, Z: 0.34}, 0.1) }},
+	{"glass", func() Material { return NewDielectric(1.5) }},
+	{"flint-glass", func() Material { return NewDielectric(1.62).SetDispersion(36) }},
+	{"plaster", func() Material {
+		return WithDetail(NewLambertian(Color{X: 0.8, Y: 0.78, Z: 0.72}), DefaultDetailConfig())
+	}},
+	{"checker", func() Material {
+		return NewLambertianTexture(NewCheckerTextureFromColors(0.2,
+			Color{X: 0.1, Y: 0.1, Z: 0.1}, Color{X: 0.9, Y: 0.9, Z: 0.9}))
+	}},
+	{"marble", func() Material { return NewLambertianTexture(NewNoiseTexture(4)) }},
+	{"emissive", func() Material { return NewDiffuseLightColor(Color{X: 2, Y: 1.6, Z: 1.0}) }},
+}
+
+// PreviewMaterialNames lists the names accepted by PreviewMaterial
+func PreviewMaterialNames() []string {
+	names := make([]string, len(previewMaterials))
+	for i, m := range previewMaterials {
+		names[i] = m.name
+	}
+	return names
+}
+
+// PreviewMaterial returns a new instance of a named built-in or registered
+// material
+func PreviewMaterial(name string) (Material, error) {
+	for _, m := range previewMaterials {
+		if strings.EqualFold(name, m.name) {
+			return m.build(), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown material: %s (use %s)",
+		name, strings.Join(PreviewMaterialNames(), ", "))
+}
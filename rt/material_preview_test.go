@@ -0,0 +1,23 @@
+package rt
+
+import "testing"
+
+func TestPreviewMaterials(t *testing.T) {
+	for _, name := range PreviewMaterialNames() {
+		mat, err := PreviewMaterial(name)
+		if err != nil || mat == nil {
+			t.Fatalf("PreviewMaterial(%q) = %v, %v", name, mat, err)
+		}
+		world, camera := MaterialPreviewScene(mat)
+		if len(world.Objects) == 0 || len(camera.Lights) == 0 {
+			t.Fatalf("%s: preview scene has %d objects and %d lights", name, len(world.Objects), len(camera.Lights))
+		}
+	}
+
+	if _, err := PreviewMaterial("GLASS"); err != nil {
+		t.Errorf("names should be case-insensitive: %v", err)
+	}
+	if _, err := PreviewMaterial("unobtainium"); err == nil {
+		t.Error("unknown material should fail")
+	}
+}
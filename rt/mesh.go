@@ -0,0 +1,67 @@
+package rt
+
+// =============================================================================
+// SHARED MESH DATA (OBJ / PLY)
+// =============================================================================
+
+// MaterialFactory creates a material around a texture. Mesh loaders use it to
+// give every vertex-colored triangle its own VertexColorTexture.
+type MaterialFactory func(tex Texture) Material
+
+// meshData is the triangulated result of parsing a mesh file
+type meshData struct {
+	vertices []Point3
+	colors   []Color  // Linear vertex colors parallel to vertices, nil if the file has none
+	faces    [][3]int // Vertex indices per triangle
+}
+
+// setColor stores a display-referred vertex color, converting it to linear
+// with the renderer's gamma of 2. Vertices read before the first color stay white.
+func (m *meshData) setColor(index int, c Color) {
+	if m.colors == nil {
+		m.colors = make([]Color, 0, cap(m.vertices))
+	}
+	for len(m.colors) <= index {
+		m.colors = append(m.colors, Color{X: 1, Y: 1, Z: 1})
+	}
+	m.colors[index] = Color{
+		X: GammaToLinear(clampFloat(c.X, 0, 1)),
+		Y: GammaToLinear(clampFloat(c.Y, 0, 1)),
+		Z: GammaToLinear(clampFloat(c.Z, 0, 1)),
+	}
+}
+
+// color returns the linear color of a vertex (white when uncolored)
+func (m *meshData) color(index int) Color {
+	if index < len(m.colors) {
+		return m.colors[index]
+	}
+	return Color{X: 1, Y: 1, Z: 1}
+}
+
+// addPolygon fan-triangulates a polygon given by vertex indices
+func (m *meshData) addPolygon(indices []int) {
+	for i := 1; i < len(indices)-1; i++ {
+		m.faces = append(m.faces, [3]int{indices[0], indices[i], indices[i+1]})
+	}
+}
+
+// triangles builds the mesh triangles. With newMaterial set and vertex colors
+// present, each triangle gets newMaterial(VertexColorTexture); otherwise all
+// triangles share material (or newMaterial applied to white).
+func (m *meshData) triangles(material Material, newMaterial MaterialFactory) []Hittable {
+	if newMaterial != nil && m.colors == nil {
+		material = newMaterial(NewSolidColorRGB(1, 1, 1))
+		newMaterial = nil
+	}
+
+	triangles := make([]Hittable, 0, len(m.faces))
+	for _, f := range m.faces {
+		mat := material
+		if newMaterial != nil {
+			mat = newMaterial(NewVertexColorTexture(m.color(f[0]), m.color(f[1]), m.color(f[2])))
+		}
+		triangles = append(triangles, NewTriangle(m.vertices[f[0]], m.vertices[f[1]], m.vertices[f[2]], mat))
+	}
+	return triangles
+}
@@ -0,0 +1,147 @@
+package rt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// MESH CACHE
+// =============================================================================
+
+// Loading the same mesh file again (e.g. from several scenes or cameras)
+// reuses the parsed mesh, and with the same material also the built BVH.
+// Entries are keyed by absolute path, size and modification time, so an
+// edited file is parsed again. BVHs are read-only after construction and
+// safe to share.
+
+// meshFileKey identifies one version of a mesh file on disk
+type meshFileKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+type meshBVHKey struct {
+	file     meshFileKey
+	material string
+}
+
+var meshCache = struct {
+	sync.Mutex
+	enabled bool
+	meshes  map[meshFileKey]*meshData
+	bvhs    map[meshBVHKey]Hittable
+}{
+	enabled: true,
+	meshes:  make(map[meshFileKey]*meshData),
+	bvhs:    make(map[meshBVHKey]Hittable),
+}
+
+// SetMeshCacheEnabled turns mesh reuse across loads on or off (on by default).
+// Disabling it also drops all cached meshes.
+func SetMeshCacheEnabled(enabled bool) {
+	meshCache.Lock()
+	meshCache.enabled = enabled
+	meshCache.Unlock()
+	ClearMeshCache()
+}
+
+// ClearMeshCache releases all cached meshes and BVHs
+func ClearMeshCache() {
+	meshCache.Lock()
+	defer meshCache.Unlock()
+	meshCache.meshes = make(map[meshFileKey]*meshData)
+	meshCache.bvhs = make(map[meshBVHKey]Hittable)
+}
+
+// materialKey identifies a material for the BVH cache: pointer materials by
+// identity, value materials by type and contents
+func materialKey(mat Material) string {
+	if mat == nil {
+		return "<nil>"
+	}
+	if v := reflect.ValueOf(mat); v.Kind() == reflect.Pointer {
+		return fmt.Sprintf("%T@%x", mat, v.Pointer())
+	}
+	return fmt.Sprintf("%T%#v", mat, mat)
+}
+
+// meshParser reads a mesh file format into triangulated mesh data
+type meshParser func(r io.Reader) (*meshData, error)
+
+// loadMesh returns the BVH of a mesh file, parsing and building only what
+// the cache doesn't already hold. kind names the format in log messages.
+func loadMesh(kind, filename string, parse meshParser, material Material, newMaterial MaterialFactory) (Hittable, error) {
+	key, err := meshFileKeyFor(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s file: %w", kind, err)
+	}
+	bvhKey := meshBVHKey{file: key, material: materialKey(material)}
+
+	meshCache.Lock()
+	enabled := meshCache.enabled
+	mesh := meshCache.meshes[key]
+	cachedBVH := meshCache.bvhs[bvhKey]
+	meshCache.Unlock()
+
+	// Per-triangle factory materials can't be compared, so only the parse is shared
+	if enabled && newMaterial == nil && cachedBVH != nil {
+		fmt.Printf("Reusing cached %s BVH: %s\n", kind, filename)
+		return cachedBVH, nil
+	}
+
+	if mesh == nil {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s file: %w", kind, err)
+		}
+		mesh, err = parse(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	} else {
+		fmt.Printf("Reusing cached %s mesh: %s\n", kind, filename)
+	}
+
+	triangles := mesh.triangles(material, newMaterial)
+
+	fmt.Printf("Loaded %s: %d vertices, %d triangles\n", kind, len(mesh.vertices), len(triangles))
+	if mesh.colors != nil {
+		fmt.Printf("  with vertex colors\n")
+	}
+
+	// Build BVH for the mesh
+	fmt.Printf("Building BVH for mesh...\n")
+	meshBVH := newMeshBVH(triangles)
+	fmt.Printf("BVH built successfully\n")
+
+	if enabled {
+		meshCache.Lock()
+		meshCache.meshes[key] = mesh
+		if newMaterial == nil {
+			meshCache.bvhs[bvhKey] = meshBVH
+		}
+		meshCache.Unlock()
+	}
+
+	return meshBVH, nil
+}
+
+func meshFileKeyFor(filename string) (meshFileKey, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return meshFileKey{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return meshFileKey{}, err
+	}
+	return meshFileKey{path: path, size: info.Size(), modTime: info.ModTime()}, nil
+}
@@ -0,0 +1,38 @@
+package rt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMeshCacheReusesBVH(t *testing.T) {
+	ClearMeshCache()
+	defer ClearMeshCache()
+
+	path := filepath.Join(t.TempDir(), "tri.obj")
+	if err := os.WriteFile(path, []byte("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	first, err := LoadOBJ(path, mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := LoadOBJ(path, mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("second load with the same material should reuse the BVH")
+	}
+
+	other, err := LoadOBJ(path, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first {
+		t.Error("a different material must get its own BVH")
+	}
+}
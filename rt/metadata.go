@@ -0,0 +1,227 @@
+package rt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// RENDER METADATA
+// =============================================================================
+
+// RenderMetadata describes how an image was produced. It is embedded into
+// saved images so renders can be compared later without guessing settings.
+type RenderMetadata struct {
+	Scene      string
+	Width      int
+	Height     int
+	SPP        int
+	MaxDepth   int
+	Seed       int64
+	Seeded     bool // False when the render used a nondeterministic seed
+	RenderTime time.Duration
+	LookFrom   Point3
+	LookAt     Point3
+	Vup        Vec3
+	Vfov       float64
+	Defocus    float64 // Defocus angle in degrees
+	FocusDist  float64
+	Version    string // Git revision of the renderer
+	Created    time.Time
+}
+
+// MetadataEntry is a single key/value pair, kept ordered for stable output
+type MetadataEntry struct {
+	Key   string
+	Value string
+}
+
+// NewRenderMetadata collects the metadata for a render of camera
+func NewRenderMetadata(camera *Camera, scene string, renderTime time.Duration) RenderMetadata {
+	meta := RenderMetadata{
+		Scene:      scene,
+		Width:      camera.ImageWidth,
+		Height:     camera.ImageHeight,
+		SPP:        camera.SamplesPerPixel,
+		MaxDepth:   camera.MaxDepth,
+		RenderTime: renderTime,
+		LookFrom:   camera.LookFrom,
+		LookAt:     camera.LookAt,
+		Vup:        camera.Vup,
+		Vfov:       camera.Vfov,
+		Defocus:    camera.DefocusAngle,
+		FocusDist:  camera.FocusDist,
+		Version:    GitVersion(),
+		Created:    time.Now(),
+	}
+	meta.Seed, meta.Seeded = RandomSeed()
+	return meta
+}
+
+// Entries returns the metadata as ordered key/value pairs
+func (m RenderMetadata) Entries() []MetadataEntry {
+	seed := "none (nondeterministic)"
+	if m.Seeded {
+		seed = fmt.Sprintf("%d", m.Seed)
+	}
+	scene := m.Scene
+	if scene == "" {
+		scene = "unknown"
+	}
+
+	return []MetadataEntry{
+		{"Software", "go-raytracing " + m.Version},
+		{"Scene", scene},
+		{"Resolution", fmt.Sprintf("%dx%d", m.Width, m.Height)},
+		{"Samples Per Pixel", fmt.Sprintf("%d", m.SPP)},
+		{"Max Depth", fmt.Sprintf("%d", m.MaxDepth)},
+		{"Seed", seed},
+		{"Render Time", FormatDuration(m.RenderTime)},
+		{"Camera LookFrom", m.LookFrom.String()},
+		{"Camera LookAt", m.LookAt.String()},
+		{"Camera Vup", m.Vup.String()},
+		{"Camera Vfov", fmt.Sprintf("%g", m.Vfov)},
+		{"Camera Defocus Angle", fmt.Sprintf("%g", m.Defocus)},
+		{"Camera Focus Distance", fmt.Sprintf("%g", m.FocusDist)},
+		{"Creation Time", m.Created.Format(time.RFC3339)},
+	}
+}
+
+var (
+	gitVersionOnce sync.Once
+	gitVersion     string
+)
+
+// GitVersion returns the git revision the renderer was built from. Binaries
+// built with `go build` carry it in their build info; `go run` does not, so
+// fall back to asking git directly.
+func GitVersion() string {
+	gitVersionOnce.Do(func() {
+		gitVersion = "unknown"
+
+		if info, ok := debug.ReadBuildInfo(); ok {
+			revision, modified := "", false
+			for _, setting := range info.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					revision = setting.Value
+				case "vcs.modified":
+					modified = setting.Value == "true"
+				}
+			}
+			if revision != "" {
+				gitVersion = revision[:min(len(revision), 12)]
+				if modified {
+					gitVersion += "-dirty"
+				}
+				return
+			}
+		}
+
+		out, err := exec.Command("git", "describe", "--always", "--dirty", "--abbrev=12").Output()
+		if err == nil {
+			gitVersion = strings.TrimSpace(string(out))
+		}
+	})
+	return gitVersion
+}
+
+// =============================================================================
+// PNG tEXt CHUNKS
+// =============================================================================
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// EncodePNGWithMetadata writes img as a PNG with the metadata stored in tEXt
+// chunks directly after the header
+func EncodePNGWithMetadata(w io.Writer, img image.Image, meta RenderMetadata) error {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		return err
+	}
+	data := encoded.Bytes()
+
+	// Signature (8 bytes) + IHDR (4 length + 4 type + 13 data + 4 CRC)
+	headerEnd := len(pngSignature) + 25
+	if _, err := w.Write(data[:headerEnd]); err != nil {
+		return err
+	}
+
+	for _, entry := range meta.Entries() {
+		chunk := append([]byte(entry.Key), 0)
+		chunk = append(chunk, entry.Value...)
+		if err := writePNGChunk(w, "tEXt", chunk); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(data[headerEnd:])
+	return err
+}
+
+func writePNGChunk(w io.Writer, chunkType string, data []byte) error {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], chunkType)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+
+	for _, part := range [][]byte{header[:], data, footer[:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadPNGMetadata returns the tEXt entries stored in a PNG stream
+func ReadPNGMetadata(r io.Reader) ([]MetadataEntry, error) {
+	reader := bufio.NewReader(r)
+
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(reader, signature); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(signature, pngSignature) {
+		return nil, fmt.Errorf("not a PNG file")
+	}
+
+	var entries []MetadataEntry
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			return nil, err
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		chunkType := string(header[4:])
+
+		data := make([]byte, length+4) // Chunk data + CRC
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+
+		switch chunkType {
+		case "tEXt":
+			key, value, _ := bytes.Cut(data[:length], []byte{0})
+			entries = append(entries, MetadataEntry{Key: string(key), Value: string(value)})
+		case "IEND":
+			return entries, nil
+		}
+	}
+}
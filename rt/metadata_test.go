@@ -0,0 +1,66 @@
+package rt
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPNGMetadataRoundTrip(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 2, color.RGBA{R: 200, G: 10, B: 30, A: 255})
+	meta := RenderMetadata{
+		Scene:      "cornell",
+		Width:      4,
+		Height:     3,
+		SPP:        16,
+		MaxDepth:   8,
+		Seed:       42,
+		Seeded:     true,
+		RenderTime: 1500 * time.Millisecond,
+		LookFrom:   Point3{X: 278, Y: 278, Z: -800},
+		LookAt:     Point3{X: 278, Y: 278},
+		Vup:        Vec3{Y: 1},
+		Vfov:       40,
+		Version:    "abc123",
+		Created:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	if err := EncodePNGWithMetadata(&buf, img, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	// png.Decode checks the CRC of every chunk, the inserted ones included
+	decoded, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("decoding the PNG: %v", err)
+	}
+	r, g, b, _ := decoded.At(1, 2).RGBA()
+	if decoded.Bounds() != img.Bounds() || r>>8 != 200 || g>>8 != 10 || b>>8 != 30 {
+		t.Errorf("decoded image differs: %v, pixel %v", decoded.Bounds(), decoded.At(1, 2))
+	}
+
+	entries, err := ReadPNGMetadata(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := meta.Entries(); !slices.Equal(entries, want) {
+		t.Errorf("metadata = %v, want %v", entries, want)
+	}
+
+	// A corrupted text chunk must fail the CRC check
+	corrupt := bytes.Clone(buf.Bytes())
+	i := bytes.Index(corrupt, []byte("cornell"))
+	corrupt[i] = 'C'
+	if _, err := png.Decode(bytes.NewReader(corrupt)); err == nil {
+		t.Error("expected a checksum error for a corrupted tEXt chunk")
+	}
+	if _, err := ReadPNGMetadata(bytes.NewReader([]byte("not a png"))); err == nil {
+		t.Error("expected an error for a non-PNG stream")
+	}
+}
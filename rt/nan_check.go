@@ -0,0 +1,81 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// =============================================================================
+// NaN / Inf SAMPLE DETECTION
+// =============================================================================
+
+// NaNCheckConfig controls detection of invalid radiance samples. Without it a
+// single NaN or Inf poisons the pixel's accumulated value for the whole render.
+type NaNCheckConfig struct {
+	Enabled    bool
+	DebugColor Color // Replaces invalid samples so they stand out in the image
+	MaxReports int   // Samples logged in detail; later ones are only counted
+}
+
+// DefaultNaNCheckConfig returns a disabled check that paints magenta
+func DefaultNaNCheckConfig() NaNCheckConfig {
+	return NaNCheckConfig{
+		Enabled:    false,
+		DebugColor: Color{X: 1, Y: 0, Z: 1},
+		MaxReports: 20,
+	}
+}
+
+// nanChecker validates samples for a renderer; safe for concurrent use
+type nanChecker struct {
+	config NaNCheckConfig
+	count  atomic.Int64
+}
+
+// newNaNChecker returns nil when the check is disabled
+func newNaNChecker(config NaNCheckConfig) *nanChecker {
+	if !config.Enabled {
+		return nil
+	}
+	return &nanChecker{config: config}
+}
+
+// isFiniteColor reports whether all components are neither NaN nor Inf
+func isFiniteColor(c Color) bool {
+	return isFinite(c.X) && isFinite(c.Y) && isFinite(c.Z)
+}
+
+// check returns the sample unchanged when it is finite. Otherwise it logs the
+// pixel, sample and camera ray (plus what the ray hits first) for the first
+// MaxReports occurrences and returns the debug color.
+func (n *nanChecker) check(sample Color, world Hittable, ray Ray, x, y, index int) Color {
+	if n == nil || isFiniteColor(sample) {
+		return sample
+	}
+
+	if count := n.count.Add(1); count <= int64(n.config.MaxReports) {
+		firstHit := "miss (environment)"
+		rec := &HitRecord{}
+		if world.Hit(ray, NewInterval(0.001, math.Inf(1)), rec) {
+			firstHit = fmt.Sprintf("%T at %v (normal %v, t=%.4g)", rec.Mat, rec.P, rec.Normal, rec.T)
+		}
+		fmt.Printf("⚠ Invalid radiance %v at pixel (%d, %d) sample %d: ray %v -> %v, first hit %s\n",
+			sample, x, y, index, ray.Origin(), ray.Direction(), firstHit)
+		if count == int64(n.config.MaxReports) {
+			fmt.Printf("⚠ Further invalid samples are only counted\n")
+		}
+	}
+
+	return n.config.DebugColor
+}
+
+// report prints the total number of replaced samples, if any
+func (n *nanChecker) report() {
+	if n == nil {
+		return
+	}
+	if count := n.count.Load(); count > 0 {
+		fmt.Printf("⚠ %d NaN/Inf samples replaced with the debug color\n", count)
+	}
+}
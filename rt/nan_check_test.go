@@ -0,0 +1,43 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+// nanMaterial emits NaN, standing in for an integrator bug
+type nanMaterial struct{}
+
+func (nanMaterial) Scatter(rIn Ray, rec *HitRecord, attenuation *Color, scattered *Ray) bool {
+	return false
+}
+
+func (nanMaterial) Emitted(u, v float64, p Point3) Color {
+	return Color{X: math.NaN(), Y: 0, Z: math.Inf(1)}
+}
+
+func TestNaNCheck(t *testing.T) {
+	world, cam := sphereWith(nanMaterial{})()
+	cam.SamplesPerPixel = 2
+
+	config := DefaultNaNCheckConfig()
+	config.Enabled = true
+	config.MaxReports = 1
+	r := NewProgressiveRenderer(cam, world).SetNaNCheck(config)
+
+	row := goldenSize / 2
+	r.renderScanline(row)
+
+	if r.nanCheck.count.Load() == 0 {
+		t.Error("no invalid samples were detected")
+	}
+	center := r.film.Resolve(goldenSize/2, row)
+	if center != config.DebugColor {
+		t.Errorf("center pixel = %v, want debug color %v", center, config.DebugColor)
+	}
+	for x := 0; x < goldenSize; x++ {
+		if !isFiniteColor(r.film.Resolve(x, row)) {
+			t.Fatalf("pixel (%d, %d) still holds an invalid value", x, row)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// NEAR CLIP
+// =============================================================================
+
+// SetNearClip makes camera rays ignore geometry closer than distance (scene
+// units) to the lens, measured along the view direction, so the camera can
+// sit outside a wall and look through it into the room. Only camera rays are
+// clipped: the clipped geometry still casts shadows, shows in reflections
+// and bounces light. 0 turns clipping off.
+func (c *Camera) SetNearClip(distance float64) *Camera {
+	c.NearClip = math.Max(0, distance)
+	return c
+}
+
+// rayInterval returns the hit interval of r: from the near clip plane for
+// camera rays, from the ray offset for everything else
+func (c *Camera) rayInterval(r Ray) Interval {
+	tMin := c.rayEpsilon()
+	if c.NearClip > 0 && r.kind == rayCamera {
+		// t is in units of the direction's length, so scale the plane
+		// distance by how far each unit of t advances along the view axis
+		if along := Dot(r.Direction(), c.w.Neg()); along > 0 {
+			tMin = math.Max(tMin, c.NearClip/along)
+		}
+	}
+	return NewInterval(tMin, math.Inf(1))
+}
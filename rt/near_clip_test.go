@@ -0,0 +1,34 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNearClipSeesThroughWall(t *testing.T) {
+	world := NewHittableList()
+	wall := NewQuad(Point3{X: -2, Y: -2, Z: -1}, Vec3{X: 4}, Vec3{Y: 4}, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))
+	ball := NewSphere(Point3{Z: -3}, 0.5, NewLambertian(Color{X: 0.8, Y: 0.1, Z: 0.1}))
+	world.Add(wall)
+	world.Add(ball)
+	camera := NewCameraBuilder().SetResolution(16, 1).SetQuality(1, 4).Build()
+	camera.Initialize()
+
+	if aov := camera.TracePixelAOV(world, 8, 8); aov.Object != wall {
+		t.Fatalf("without a near clip the camera should see the wall, got %v", aov.Object)
+	}
+	camera.SetNearClip(1.5)
+	aov := camera.TracePixelAOV(world, 8, 8)
+	if aov.Object != ball {
+		t.Fatalf("with a 1.5 near clip the camera should see the ball, got %v", aov.Object)
+	}
+
+	// Rays that leave the ball towards the camera still hit the wall
+	back := NewRay(aov.Position, Vec3{Z: 1}, 0)
+	if !world.Hit(back, camera.rayInterval(back), &HitRecord{}) {
+		t.Error("secondary rays should not be clipped")
+	}
+	if got := camera.rayInterval(camera.centerRay(8, 8)).Min; math.Abs(got*Dot(camera.centerRay(8, 8).Direction(), Vec3{Z: -1})-1.5) > 1e-9 {
+		t.Errorf("camera ray starts %g along the view axis, want 1.5", got)
+	}
+}
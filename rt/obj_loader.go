@@ -3,26 +3,56 @@ package rt
 import (
 	"bufio"
 	"fmt"
-	"os"
+	"io"
+	"math"
 	"strconv"
 	"strings"
 )
 
+// Limits that keep corrupt or hostile OBJ and PLY files from exhausting memory
+const (
+	MaxOBJVertices      = 50_000_000 // Max vertex positions per file
+	MaxOBJTriangles     = 50_000_000 // Max triangles after fan triangulation
+	MaxOBJFaceVertices  = 256        // Max vertices in a single polygon
+	maxOBJLineBytes     = 1 << 20    // Max bytes per line
+	maxPLYHeaderLines   = 1024       // Max PLY header lines before end_header
+	plyPreallocVertices = 1 << 20    // PLY vertices reserved up front; the rest grows as they are read
+)
+
 // LoadOBJ loads a Wavefront OBJ file and returns a BVH of the triangles
 // RUST PORT NOTE: Consider using the 'obj' crate or 'tobj' for parsing
 // Returns a pre-built BVH (not a flat list) for optimal performance
 // with large meshes (hundreds of thousands of triangles)
 func LoadOBJ(filename string, material Material) (Hittable, error) {
-	file, err := os.Open(filename)
+	return loadOBJ(filename, material, nil)
+}
+
+// LoadOBJWithVertexColors loads an OBJ file whose vertices carry colors
+// ("v x y z r g b") and shades each triangle with newMaterial applied to a
+// VertexColorTexture. Vertices without colors are treated as white.
+func LoadOBJWithVertexColors(filename string, newMaterial MaterialFactory) (Hittable, error) {
+	return loadOBJ(filename, nil, newMaterial)
+}
+
+func loadOBJ(filename string, material Material, newMaterial MaterialFactory) (Hittable, error) {
+	return loadMesh("OBJ", filename, parseOBJMesh, material, newMaterial)
+}
+
+// ParseOBJ reads vertex positions and faces from an OBJ stream and returns the
+// triangulated faces together with the number of vertices read
+func ParseOBJ(r io.Reader, material Material) ([]Hittable, int, error) {
+	mesh, err := parseOBJMesh(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open OBJ file: %w", err)
+		return nil, 0, err
 	}
-	defer file.Close()
+	return mesh.triangles(material, nil), len(mesh.vertices), nil
+}
 
-	var vertices []Point3
-	var triangles []Hittable
+func parseOBJMesh(r io.Reader) (*meshData, error) {
+	mesh := &meshData{}
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxOBJLineBytes)
 	lineNum := 0
 
 	for scanner.Scan() {
@@ -45,24 +75,41 @@ func LoadOBJ(filename string, material Material) (Hittable, error) {
 			if len(parts) < 4 {
 				return nil, fmt.Errorf("invalid vertex at line %d", lineNum)
 			}
-			x, err1 := strconv.ParseFloat(parts[1], 64)
-			y, err2 := strconv.ParseFloat(parts[2], 64)
-			z, err3 := strconv.ParseFloat(parts[3], 64)
-			if err1 != nil || err2 != nil || err3 != nil {
-				return nil, fmt.Errorf("invalid vertex coordinates at line %d", lineNum)
+			if len(mesh.vertices) >= MaxOBJVertices {
+				return nil, fmt.Errorf("more than %d vertices at line %d", MaxOBJVertices, lineNum)
+			}
+			p, err := parseOBJFloats(parts[1:4])
+			if err != nil {
+				return nil, fmt.Errorf("invalid vertex coordinates at line %d: %w", lineNum, err)
+			}
+			mesh.vertices = append(mesh.vertices, Point3{X: p[0], Y: p[1], Z: p[2]})
+
+			// Vertex color extension: v x y z r g b (display-referred, 0..1)
+			if len(parts) >= 7 {
+				c, err := parseOBJFloats(parts[4:7])
+				if err != nil {
+					return nil, fmt.Errorf("invalid vertex color at line %d: %w", lineNum, err)
+				}
+				mesh.setColor(len(mesh.vertices)-1, Color{X: c[0], Y: c[1], Z: c[2]})
 			}
-			vertices = append(vertices, Point3{X: x, Y: y, Z: z})
 
 		case "f":
 			// Face - only process triangles
 			if len(parts) < 4 {
 				continue
 			}
+			if len(parts)-1 > MaxOBJFaceVertices {
+				return nil, fmt.Errorf("face with %d vertices at line %d (max %d)",
+					len(parts)-1, lineNum, MaxOBJFaceVertices)
+			}
+			if len(mesh.faces)+len(parts)-3 > MaxOBJTriangles {
+				return nil, fmt.Errorf("more than %d triangles at line %d", MaxOBJTriangles, lineNum)
+			}
 
 			// Parse vertex indices (handle f v1 v2 v3 or f v1/vt1/vn1 v2/vt2/vn2 v3/vt3/vn3)
 			indices := make([]int, 0, len(parts)-1)
 			for i := 1; i < len(parts); i++ {
-				indexStr := strings.Split(parts[i], "/")[0] // Get vertex index (ignore texture/normal)
+				indexStr, _, _ := strings.Cut(parts[i], "/") // Get vertex index (ignore texture/normal)
 				idx, err := strconv.Atoi(indexStr)
 				if err != nil {
 					return nil, fmt.Errorf("invalid face index at line %d", lineNum)
@@ -70,46 +117,45 @@ func LoadOBJ(filename string, material Material) (Hittable, error) {
 				// OBJ indices are 1-based
 				if idx < 0 {
 					// Negative indices count from the end
-					idx = len(vertices) + idx + 1
+					idx = len(mesh.vertices) + idx + 1
+				}
+				// Zero and out-of-range indices both end up outside [0, len)
+				if idx < 1 || idx > len(mesh.vertices) {
+					return nil, fmt.Errorf("vertex index out of bounds at line %d", lineNum)
 				}
 				indices = append(indices, idx-1) // Convert to 0-based
 			}
 
 			// Triangulate if needed (for quads or n-gons)
-			for i := 1; i < len(indices)-1; i++ {
-				idx0 := indices[0]
-				idx1 := indices[i]
-				idx2 := indices[i+1]
-
-				// Validate indices
-				if idx0 < 0 || idx0 >= len(vertices) ||
-					idx1 < 0 || idx1 >= len(vertices) ||
-					idx2 < 0 || idx2 >= len(vertices) {
-					return nil, fmt.Errorf("vertex index out of bounds at line %d", lineNum)
-				}
-
-				v0 := vertices[idx0]
-				v1 := vertices[idx1]
-				v2 := vertices[idx2]
-
-				triangle := NewTriangle(v0, v1, v2, material)
-				triangles = append(triangles, triangle)
-			}
+			mesh.addPolygon(indices)
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading OBJ file: %w", err)
+		return nil, fmt.Errorf("error reading OBJ file at line %d: %w", lineNum+1, err)
 	}
 
-	fmt.Printf("Loaded OBJ: %d vertices, %d triangles\n", len(vertices), len(triangles))
+	return mesh, nil
+}
 
-	// Build BVH for the mesh
-	fmt.Printf("Building BVH for mesh...\n")
-	meshBVH := NewBVHNode(triangles, 0, len(triangles))
-	fmt.Printf("BVH built successfully\n")
+// parseOBJFloats parses finite floats; NaN/Inf positions would poison BVH bounds
+func parseOBJFloats(fields []string) ([]float64, error) {
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		if !isFinite(v) {
+			return nil, fmt.Errorf("non-finite value %s", field)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
 
-	return meshBVH, nil
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
 }
 
 // LoadOBJWithTransform loads an OBJ file and applies a transform
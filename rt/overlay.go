@@ -0,0 +1,153 @@
+package rt
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// =============================================================================
+// VIEWER STATS OVERLAY
+// =============================================================================
+
+// OverlayCorner selects which corner of the window the stats overlay uses
+type OverlayCorner int
+
+const (
+	OverlayBottomLeft OverlayCorner = iota
+	OverlayBottomRight
+	OverlayTopLeft
+	OverlayTopRight
+)
+
+var overlayCornerNames = []string{"bottom-left", "bottom-right", "top-left", "top-right"}
+
+func (c OverlayCorner) String() string {
+	if int(c) < 0 || int(c) >= len(overlayCornerNames) {
+		return "unknown"
+	}
+	return overlayCornerNames[c]
+}
+
+// ParseOverlayCorner converts a corner name (e.g. "top-right") to an OverlayCorner
+func ParseOverlayCorner(name string) (OverlayCorner, error) {
+	for i, n := range overlayCornerNames {
+		if strings.EqualFold(name, n) {
+			return OverlayCorner(i), nil
+		}
+	}
+	return OverlayBottomLeft, fmt.Errorf("unknown overlay corner: %s (use %s)",
+		name, strings.Join(overlayCornerNames, ", "))
+}
+
+// OverlayConfig controls the on-screen stats overlay of the interactive viewer.
+// The overlay is only drawn to the window, never into the saved image.
+type OverlayConfig struct {
+	Enabled      bool            // Draw the overlay at all
+	Corner       OverlayCorner   // Window corner to anchor the overlay to
+	Opacity      float64         // Background opacity [0, 1]
+	ShowRayStats bool            // Add rays/sec and samples/sec lines
+	ShowDOF      bool            // Tint the image by depth of field and show lens limits
+	DOFCoC       float64         // Acceptable blur circle for the DOF preview, in pixels
+	BucketStats  BucketStatsMode // Per-bucket heatmap (bucket renderer only)
+	ToggleKey    ebiten.Key      // Shows/hides the overlay
+	CornerKey    ebiten.Key      // Cycles through the corners
+	DOFKey       ebiten.Key      // Shows/hides the DOF preview
+	BucketKey    ebiten.Key      // Cycles through the bucket heatmap modes
+	CompareKey   ebiten.Key      // Cycles through the A/B comparison modes
+	KeepKey      ebiten.Key      // Keeps the current frame as the A/B reference
+}
+
+// DefaultOverlayConfig returns the overlay settings used by the renderers
+func DefaultOverlayConfig() OverlayConfig {
+	return OverlayConfig{
+		Enabled:      true,
+		Corner:       OverlayBottomLeft,
+		Opacity:      0.6,
+		ShowRayStats: false,
+		ShowDOF:      false,
+		DOFCoC:       1.0,
+		ToggleKey:    ebiten.KeyO,
+		CornerKey:    ebiten.KeyC,
+		DOFKey:       ebiten.KeyF,
+		BucketKey:    ebiten.KeyB,
+		CompareKey:   ebiten.KeyA,
+		KeepKey:      ebiten.KeyK,
+	}
+}
+
+const (
+	overlayCharWidth  = 6  // ebitenutil debug font glyph width
+	overlayLineHeight = 16 // ebitenutil debug font glyph height
+	overlayPadding    = 6
+)
+
+// handleInput applies the overlay hotkeys; call once per Update
+func (o *OverlayConfig) handleInput() {
+	if inpututil.IsKeyJustPressed(o.ToggleKey) {
+		o.Enabled = !o.Enabled
+	}
+	if inpututil.IsKeyJustPressed(o.CornerKey) {
+		o.Corner = (o.Corner + 1) % OverlayCorner(len(overlayCornerNames))
+	}
+	if inpututil.IsKeyJustPressed(o.DOFKey) {
+		o.ShowDOF = !o.ShowDOF
+	}
+	if inpututil.IsKeyJustPressed(o.BucketKey) {
+		o.BucketStats = (o.BucketStats + 1) % BucketStatsMode(len(bucketStatsModeNames))
+	}
+}
+
+// draw renders the given lines in a translucent box in the configured corner
+func (o *OverlayConfig) draw(screen *ebiten.Image, lines []string) {
+	if !o.Enabled || len(lines) == 0 {
+		return
+	}
+
+	maxChars := 0
+	for _, line := range lines {
+		maxChars = max(maxChars, len(line))
+	}
+
+	boxW := maxChars*overlayCharWidth + 2*overlayPadding
+	boxH := len(lines)*overlayLineHeight + 2*overlayPadding
+
+	bounds := screen.Bounds()
+	x, y := 0, 0
+	switch o.Corner {
+	case OverlayBottomRight:
+		x, y = bounds.Dx()-boxW, bounds.Dy()-boxH
+	case OverlayTopLeft:
+		x, y = 0, 0
+	case OverlayTopRight:
+		x, y = bounds.Dx()-boxW, 0
+	default:
+		x, y = 0, bounds.Dy()-boxH
+	}
+
+	alpha := uint8(255 * clampFloat(o.Opacity, 0, 1))
+	bg := color.RGBA{R: 0, G: 0, B: 0, A: alpha}
+	vector.FillRect(screen, float32(x), float32(y), float32(boxW), float32(boxH), bg, false)
+
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, x+overlayPadding, y+overlayPadding+i*overlayLineHeight)
+	}
+}
+
+// rayStatsLines formats throughput lines from GlobalRenderStats
+func rayStatsLines(elapsedSeconds float64) []string {
+	if elapsedSeconds <= 0 {
+		return nil
+	}
+	rays := float64(GlobalRenderStats.RayCount.Load())
+	samples := float64(GlobalRenderStats.SamplesComputed.Load())
+	return []string{
+		fmt.Sprintf("Rays/s: %.2f M | Samples/s: %.2f M",
+			rays/elapsedSeconds/1_000_000, samples/elapsedSeconds/1_000_000),
+	}
+}
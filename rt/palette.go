@@ -0,0 +1,329 @@
+package rt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// =============================================================================
+// COLOR PALETTES
+// =============================================================================
+
+// Palette maps color names to linear colors. Palette files hold sRGB
+// colors; they are converted to linear once, when loaded.
+type Palette map[string]Color
+
+// builtinColors are the named colors of the built-in scenes, so a loaded
+// palette can restyle them (e.g. a "cornell-red" entry recolors the Cornell
+// box's left wall)
+var builtinColors = Palette{
+	"white":         {X: 1, Y: 1, Z: 1},
+	"black":         {},
+	"cornell-white": {X: 0.73, Y: 0.73, Z: 0.73},
+	"cornell-red":   {X: 0.65, Y: 0.05, Z: 0.05},
+	"cornell-green": {X: 0.12, Y: 0.45, Z: 0.15},
+}
+
+// activePalette holds the built-in colors and those added with UsePalette
+var activePalette = maps.Clone(builtinColors)
+
+// UsePalette adds p's colors to the colors NamedColor and scene overrides
+// look up, replacing colors with the same name. Like the plugin registries
+// it is not synchronized and must be called before scenes are built.
+func UsePalette(p Palette) {
+	for name, c := range p {
+		activePalette[strings.ToLower(name)] = c
+	}
+}
+
+// LookupColor returns a named color of the active palette, or the color of
+// an sRGB hex code such as "#d62828"
+func LookupColor(name string) (Color, error) {
+	return activePalette.Lookup(name)
+}
+
+// NamedColor is LookupColor for scene code; it panics on unknown names
+func NamedColor(name string) Color {
+	c, err := LookupColor(name)
+	if err != nil {
+		panic("rt: " + err.Error())
+	}
+	return c
+}
+
+// PaletteColorNames lists the colors of the active palette in alphabetical
+// order
+func PaletteColorNames() []string {
+	names := make([]string, 0, len(activePalette))
+	for name := range activePalette {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns a named color, case-insensitively, or the color of an sRGB
+// hex code
+func (p Palette) Lookup(name string) (Color, error) {
+	if strings.HasPrefix(name, "#") {
+		return ParseHexColor(name)
+	}
+	if c, ok := p[name]; ok {
+		return c, nil
+	}
+	for key, c := range p {
+		if strings.EqualFold(key, name) {
+			return c, nil
+		}
+	}
+	return Color{}, fmt.Errorf("unknown color %q", name)
+}
+
+// =============================================================================
+// SRGB CONVERSION
+// =============================================================================
+
+// SRGBToLinear decodes one sRGB channel in [0, 1] with the exact sRGB curve
+// (image textures use the gamma 2 approximation of LinearToGamma instead)
+func SRGBToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// ColorFromSRGB converts sRGB channels in [0, 1] to a linear color
+func ColorFromSRGB(r, g, b float64) Color {
+	return Color{X: SRGBToLinear(r), Y: SRGBToLinear(g), Z: SRGBToLinear(b)}
+}
+
+// ParseHexColor converts an sRGB hex code, "#rrggbb" or "#rgb", to a linear
+// color
+func ParseHexColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid hex color %q (want #rrggbb or #rgb)", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return ColorFromSRGB(float64(v>>16)/255, float64(v>>8&0xff)/255, float64(v&0xff)/255), nil
+}
+
+// =============================================================================
+// PALETTE FILES
+// =============================================================================
+
+// LoadPalette reads a JSON (.json) or Adobe Swatch Exchange (.ase) palette
+func LoadPalette(filename string) (Palette, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var p Palette
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".json":
+		p, err = ParsePaletteJSON(file)
+	case ".ase":
+		p, err = ParseASE(file)
+	default:
+		return nil, fmt.Errorf("%s: unknown palette format %q (want .json or .ase)", filename, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return p, nil
+}
+
+// ParsePaletteJSON decodes a JSON object of named colors. A color is an sRGB
+// hex code, an sRGB [r, g, b] array in [0, 1], or {"linear": [r, g, b]} for
+// values that are already linear (such as emission above 1):
+//
+//	{
+//	  "brand-red": "#d62828",
+//	  "sand": [0.91, 0.84, 0.7],
+//	  "neon": {"linear": [4, 0.5, 2]}
+//	}
+func ParsePaletteJSON(r io.Reader) (Palette, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	p := make(Palette, len(raw))
+	for name, value := range raw {
+		c, err := parsePaletteColor(value)
+		if err != nil {
+			return nil, fmt.Errorf("color %q: %w", name, err)
+		}
+		p[strings.ToLower(name)] = c
+	}
+	return p, nil
+}
+
+func parsePaletteColor(value json.RawMessage) (Color, error) {
+	var hex string
+	if json.Unmarshal(value, &hex) == nil {
+		return ParseHexColor(hex)
+	}
+	var srgb [3]float64
+	if json.Unmarshal(value, &srgb) == nil {
+		for _, v := range srgb {
+			if v < 0 || v > 1 {
+				return Color{}, fmt.Errorf("sRGB channels must be in [0, 1], got %v (use a hex code for 8-bit values)", srgb)
+			}
+		}
+		return ColorFromSRGB(srgb[0], srgb[1], srgb[2]), nil
+	}
+	var linear struct {
+		Linear *[3]float64 `json:"linear"`
+	}
+	if err := json.Unmarshal(value, &linear); err != nil || linear.Linear == nil {
+		return Color{}, fmt.Errorf("want a hex code, an [r, g, b] array or {\"linear\": [r, g, b]}, got %s", value)
+	}
+	return Color{X: linear.Linear[0], Y: linear.Linear[1], Z: linear.Linear[2]}, nil
+}
+
+// ASE block types
+const (
+	aseColorEntry = 0x0001
+	aseGroupStart = 0xc001
+	aseGroupEnd   = 0xc002
+)
+
+// ParseASE decodes an Adobe Swatch Exchange file. RGB, gray and CMYK
+// swatches are read as sRGB (CMYK through the naive conversion, without an
+// ICC profile); LAB swatches are rejected. Groups are flattened.
+func ParseASE(r io.Reader) (Palette, error) {
+	var header struct {
+		Signature [4]byte
+		Major     uint16
+		Minor     uint16
+		Blocks    uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("reading ASE header: %w", err)
+	}
+	if string(header.Signature[:]) != "ASEF" {
+		return nil, fmt.Errorf("not an ASE file")
+	}
+
+	p := Palette{}
+	for i := uint32(0); i < header.Blocks; i++ {
+		var block struct {
+			Type   uint16
+			Length uint32
+		}
+		if err := binary.Read(r, binary.BigEndian, &block); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if block.Length > 1<<16 {
+			return nil, fmt.Errorf("block %d: length %d too large", i, block.Length)
+		}
+		data := make([]byte, block.Length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		switch block.Type {
+		case aseColorEntry:
+			name, c, err := parseASEColor(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+			p[strings.ToLower(name)] = c
+		case aseGroupStart, aseGroupEnd:
+		default:
+			return nil, fmt.Errorf("block %d: unknown type %#04x", i, block.Type)
+		}
+	}
+	return p, nil
+}
+
+// parseASEColor decodes a color entry: a UTF-16 name, a color model and its
+// float channels
+func parseASEColor(r io.Reader) (string, Color, error) {
+	var nameLength uint16
+	if err := binary.Read(r, binary.BigEndian, &nameLength); err != nil {
+		return "", Color{}, err
+	}
+	units := make([]uint16, nameLength)
+	if err := binary.Read(r, binary.BigEndian, units); err != nil {
+		return "", Color{}, err
+	}
+	// The name length counts the terminating zero
+	if n := len(units); n > 0 && units[n-1] == 0 {
+		units = units[:n-1]
+	}
+	name := string(utf16.Decode(units))
+
+	var model [4]byte
+	if err := binary.Read(r, binary.BigEndian, &model); err != nil {
+		return name, Color{}, err
+	}
+	channels := map[string]int{"RGB ": 3, "Gray": 1, "CMYK": 4}[string(model[:])]
+	if channels == 0 {
+		return name, Color{}, fmt.Errorf("color %q: unsupported color model %q", name, string(model[:]))
+	}
+	v := make([]float32, channels)
+	if err := binary.Read(r, binary.BigEndian, v); err != nil {
+		return name, Color{}, err
+	}
+	switch channels {
+	case 1:
+		return name, ColorFromSRGB(float64(v[0]), float64(v[0]), float64(v[0])), nil
+	case 4:
+		k := 1 - float64(v[3])
+		return name, ColorFromSRGB((1-float64(v[0]))*k, (1-float64(v[1]))*k, (1-float64(v[2]))*k), nil
+	}
+	return name, ColorFromSRGB(float64(v[0]), float64(v[1]), float64(v[2])), nil
+}
+
+// =============================================================================
+// COLORS IN JSON FILES
+// =============================================================================
+
+// ColorRef is a color in a JSON file such as a scene override sidecar: a
+// linear [r, g, b] array, a palette color name, or an sRGB hex code
+type ColorRef struct {
+	linear *Color
+	name   string
+}
+
+// UnmarshalJSON accepts an [r, g, b] array or a string
+func (c *ColorRef) UnmarshalJSON(data []byte) error {
+	var rgb [3]float64
+	if err := json.Unmarshal(data, &rgb); err == nil {
+		c.linear, c.name = &Color{X: rgb[0], Y: rgb[1], Z: rgb[2]}, ""
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("color must be an [r, g, b] array or a palette name, got %s", data)
+	}
+	c.linear, c.name = nil, name
+	return nil
+}
+
+// Resolve returns the linear color, looking names up in the active palette
+func (c ColorRef) Resolve() (Color, error) {
+	if c.linear != nil {
+		return *c.linear, nil
+	}
+	return LookupColor(c.name)
+}
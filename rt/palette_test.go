@@ -0,0 +1,153 @@
+package rt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"maps"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// withPalette restores the active palette when the test ends
+func withPalette(t *testing.T) {
+	saved := maps.Clone(activePalette)
+	t.Cleanup(func() { activePalette = saved })
+}
+
+func TestParseHexColor(t *testing.T) {
+	for hex, want := range map[string]Color{
+		"#ffffff": {X: 1, Y: 1, Z: 1},
+		"#000":    {},
+		"ff0000":  {X: 1},
+		"#808080": {X: 0.2158605, Y: 0.2158605, Z: 0.2158605},
+	} {
+		got, err := ParseHexColor(hex)
+		if err != nil || !colorNear(got, want, 1e-6) {
+			t.Errorf("ParseHexColor(%q) = %v, %v; want %v", hex, got, err, want)
+		}
+	}
+	for _, bad := range []string{"#ff00", "#gg0000", ""} {
+		if _, err := ParseHexColor(bad); err == nil {
+			t.Errorf("ParseHexColor(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestParsePaletteJSON(t *testing.T) {
+	p, err := ParsePaletteJSON(strings.NewReader(`{
+		"Brand-Red": "#ff0000",
+		"gray": [0.5, 0.5, 0.5],
+		"neon": {"linear": [4, 0.5, 2]}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]Color{
+		"brand-red": {X: 1},
+		"GRAY":      ColorFromSRGB(0.5, 0.5, 0.5),
+		"neon":      {X: 4, Y: 0.5, Z: 2},
+	} {
+		if got, err := p.Lookup(name); err != nil || got != want {
+			t.Errorf("%s = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	for name, body := range map[string]string{
+		"8-bit array": `{"red": [255, 0, 0]}`,
+		"bad value":   `{"red": true}`,
+		"bad hex":     `{"red": "#red"}`,
+	} {
+		if _, err := ParsePaletteJSON(strings.NewReader(body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// aseFile encodes RGB swatches, the first inside a group
+func aseFile(names []string, rgb [][3]float32) []byte {
+	var buf bytes.Buffer
+	write := func(v any) { binary.Write(&buf, binary.BigEndian, v) }
+	buf.WriteString("ASEF")
+	write([]uint16{1, 0})
+	write(uint32(len(names) + 2))
+	group := append(utf16.Encode([]rune("Brand")), 0)
+	write(uint16(aseGroupStart))
+	write(uint32(2 + 2*len(group)))
+	write(uint16(len(group)))
+	write(group)
+	for i, name := range names {
+		units := append(utf16.Encode([]rune(name)), 0)
+		write(uint16(aseColorEntry))
+		write(uint32(2 + 2*len(units) + 4 + 12 + 2))
+		write(uint16(len(units)))
+		write(units)
+		buf.WriteString("RGB ")
+		write(rgb[i])
+		write(uint16(0))
+		if i == 0 {
+			write(uint16(aseGroupEnd))
+			write(uint32(0))
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestParseASE(t *testing.T) {
+	data := aseFile([]string{"Brand Red", "Sky"}, [][3]float32{{1, 0, 0}, {0.5, 0.75, 1}})
+	p, err := ParseASE(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := p.Lookup("brand red"); got != (Color{X: 1}) {
+		t.Errorf("brand red = %v", got)
+	}
+	if got, want := p["sky"], ColorFromSRGB(0.5, 0.75, 1); !colorNear(got, want, 1e-6) {
+		t.Errorf("sky = %v, want %v", got, want)
+	}
+	if _, err := ParseASE(bytes.NewReader(data[:len(data)-5])); err == nil {
+		t.Error("expected an error for a truncated file")
+	}
+}
+
+func TestNamedColorsInScenesAndOverrides(t *testing.T) {
+	withPalette(t)
+	UsePalette(Palette{"Cornell-Red": {X: 0, Y: 0, Z: 1}, "brand-blue": {X: 0.1, Y: 0.2, Z: 0.3}})
+
+	if got := NamedColor("cornell-red"); got != (Color{Z: 1}) {
+		t.Errorf("cornell-red = %v after UsePalette", got)
+	}
+	if _, err := LookupColor("missing"); err == nil {
+		t.Error("expected an error for an unknown color")
+	}
+
+	_, camera := CornellBoxScene()
+	overrides, err := ParseSceneOverrides(strings.NewReader(`{"camera": {"background": "brand-blue"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := overrides.Apply(camera); err != nil {
+		t.Fatal(err)
+	}
+	if camera.Background != (Color{X: 0.1, Y: 0.2, Z: 0.3}) {
+		t.Errorf("background = %v", camera.Background)
+	}
+	// Arrays stay linear, as before
+	overrides, _ = ParseSceneOverrides(strings.NewReader(`{"camera": {"background": [0.5, 0.5, 0.5]}}`))
+	if err := overrides.Apply(camera); err != nil || camera.Background != (Color{X: 0.5, Y: 0.5, Z: 0.5}) {
+		t.Errorf("background = %v, %v", camera.Background, err)
+	}
+	if _, err := ParseSceneOverrides(strings.NewReader(`{"camera": {"background": "no-such-color"}}`)); err == nil {
+		t.Error("expected an error for an unknown background color")
+	}
+}
+
+func TestSRGBToLinear(t *testing.T) {
+	// The curve is continuous at its linear segment's end
+	if a, b := SRGBToLinear(0.04045), SRGBToLinear(0.04045+1e-9); math.Abs(a-b) > 1e-6 {
+		t.Errorf("discontinuity: %v vs %v", a, b)
+	}
+	if got := SRGBToLinear(1); math.Abs(got-1) > 1e-12 {
+		t.Errorf("SRGBToLinear(1) = %v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package rt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// PASS SCHEDULE
+// =============================================================================
+
+// RenderPass is one pass of a BucketRenderer's pass schedule
+type RenderPass struct {
+	Samples  int  // Samples per pixel traced in the pass
+	MaxDepth int  // Bounce limit (0 = the camera's MaxDepth)
+	Preview  bool // Display only: the samples are not added to the film
+}
+
+// SetPassSchedule replaces the default passes (a 1 SPP depth-3 preview, then
+// SPP/4 and the rest of SPP at full depth) with passes, rendered in order.
+// The camera's SPP becomes the samples of the accumulating passes. Passes
+// that trace at less than full depth should be Preview passes, as mixing
+// depths in the film biases it. A time budget (SetTimeBudget) takes
+// precedence over the schedule. An invalid schedule keeps the default.
+func (r *BucketRenderer) SetPassSchedule(passes []RenderPass) *BucketRenderer {
+	if err := validatePassSchedule(passes); err != nil {
+		fmt.Printf("Warning: %v; keeping the default passes\n", err)
+		return r
+	}
+	r.schedule = append([]RenderPass(nil), passes...)
+	r.camera.SamplesPerPixel = 0
+	for _, pass := range passes {
+		if !pass.Preview {
+			r.camera.SamplesPerPixel += pass.Samples
+		}
+	}
+	return r
+}
+
+// ParsePassSchedule parses a comma-separated list of passes, each SPP or
+// SPP:DEPTH, with a "p" suffix for preview passes (e.g. "1:3p,16,48")
+func ParsePassSchedule(list string) ([]RenderPass, error) {
+	var passes []RenderPass
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		var pass RenderPass
+		if trimmed, ok := strings.CutSuffix(field, "p"); ok {
+			field, pass.Preview = trimmed, true
+		}
+		samples, depth, hasDepth := strings.Cut(field, ":")
+		var err error
+		if pass.Samples, err = strconv.Atoi(samples); err != nil {
+			return nil, fmt.Errorf("invalid pass %q: use SPP or SPP:DEPTH, with a p suffix for preview passes", field)
+		}
+		if hasDepth {
+			if pass.MaxDepth, err = strconv.Atoi(depth); err != nil {
+				return nil, fmt.Errorf("invalid pass depth %q", depth)
+			}
+		}
+		passes = append(passes, pass)
+	}
+	if err := validatePassSchedule(passes); err != nil {
+		return nil, err
+	}
+	return passes, nil
+}
+
+// validatePassSchedule checks that passes trace something and leave samples
+// in the film
+func validatePassSchedule(passes []RenderPass) error {
+	accumulated := 0
+	for i, pass := range passes {
+		if pass.Samples <= 0 || pass.MaxDepth < 0 {
+			return fmt.Errorf("pass %d needs positive samples and a non-negative depth", i+1)
+		}
+		if !pass.Preview {
+			accumulated += pass.Samples
+		}
+	}
+	if accumulated == 0 {
+		return fmt.Errorf("pass schedule has no pass that adds samples to the film")
+	}
+	return nil
+}
+
+// passSchedule returns the passes to render: the custom schedule, or the
+// default ladder for the camera's SPP. With a preview HDRI or visibility
+// cache the default medium pass is display-only, so the final pass traces
+// every sample itself.
+func (r *BucketRenderer) passSchedule() []RenderPass {
+	if r.schedule != nil {
+		return r.schedule
+	}
+	spp := r.camera.SamplesPerPixel
+	medium := max(1, spp/4)
+	if r.previewEnv != nil || r.envCaches != nil {
+		return []RenderPass{
+			{Samples: 1, MaxDepth: 3, Preview: true},
+			{Samples: medium, Preview: true},
+			{Samples: spp},
+		}
+	}
+	passes := []RenderPass{
+		// Preview pass: 1 SPP, reduced depth (display only, biased)
+		{Samples: 1, MaxDepth: 3, Preview: true},
+		// Medium pass: 25% of target SPP
+		{Samples: medium},
+	}
+	// Final pass: remaining samples up to full SPP (none left at SPP 1)
+	if spp > medium {
+		passes = append(passes, RenderPass{Samples: spp - medium})
+	}
+	return passes
+}
+
+// passCount returns the number of passes in the schedule
+func (r *BucketRenderer) passCount() int {
+	return len(r.passSchedule())
+}
+
+// passName returns the overlay name of a pass
+func (r *BucketRenderer) passName(pass int) string {
+	switch {
+	case r.budget != nil && pass > 0:
+		return "ACCUMULATING"
+	case pass >= r.passCount():
+		return "RENDERING"
+	case pass == r.passCount()-1:
+		return "FINAL"
+	case pass == 0 && r.passSchedule()[0].Preview:
+		return "PREVIEW"
+	}
+	return "REFINING"
+}
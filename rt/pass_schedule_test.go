@@ -0,0 +1,76 @@
+package rt
+
+import "testing"
+
+func TestParsePassSchedule(t *testing.T) {
+	passes, err := ParsePassSchedule("1:3p, 4p,16,8:6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RenderPass{
+		{Samples: 1, MaxDepth: 3, Preview: true},
+		{Samples: 4, Preview: true},
+		{Samples: 16},
+		{Samples: 8, MaxDepth: 6},
+	}
+	if len(passes) != len(want) {
+		t.Fatalf("got %d passes, want %d", len(passes), len(want))
+	}
+	for i := range want {
+		if passes[i] != want[i] {
+			t.Errorf("pass %d = %+v, want %+v", i+1, passes[i], want[i])
+		}
+	}
+	for _, bad := range []string{"", "1p", "0,4", "x", "4:y", "2:-1"} {
+		if _, err := ParsePassSchedule(bad); err == nil {
+			t.Errorf("ParsePassSchedule(%q) should fail", bad)
+		}
+	}
+}
+
+func TestPassScheduleAccumulatesOnlyFilmPasses(t *testing.T) {
+	SeedRandom(5)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	t.Chdir(t.TempDir())
+
+	world, camera := renderImageScene()
+	r := NewBucketRenderer(camera, world, 8, 2).SetPassSchedule([]RenderPass{
+		{Samples: 1, MaxDepth: 2, Preview: true},
+		{Samples: 2},
+		{Samples: 3, Preview: true},
+		{Samples: 5},
+	})
+	if camera.SamplesPerPixel != 7 {
+		t.Errorf("camera SPP = %d, want the 7 accumulated samples", camera.SamplesPerPixel)
+	}
+	if _, err := r.RenderToImage(); err != nil {
+		t.Fatal(err)
+	}
+	if r.currentPass != 4 {
+		t.Errorf("rendered %d passes, want 4", r.currentPass)
+	}
+	if n := r.film.SampleCount(3, 3); n != 7 {
+		t.Errorf("film has %d samples per pixel, want 7", n)
+	}
+
+	// An invalid schedule keeps the default passes
+	world, camera = renderImageScene()
+	r = NewBucketRenderer(camera, world, 8, 2).SetPassSchedule([]RenderPass{{Samples: 4, Preview: true}})
+	if r.schedule != nil || r.passCount() != 3 {
+		t.Error("a schedule without film passes should be rejected")
+	}
+
+	// At SPP 1 the default ladder has no empty final pass
+	world, camera = renderImageScene()
+	camera.SamplesPerPixel = 1
+	r = NewBucketRenderer(camera, world, 8, 2)
+	if err := validatePassSchedule(r.passSchedule()); err != nil || r.passCount() != 2 {
+		t.Errorf("default schedule at SPP 1 = %+v (%v), want preview and one film pass", r.passSchedule(), err)
+	}
+	if _, err := r.RenderToImage(); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.film.SampleCount(3, 3); n != 1 {
+		t.Errorf("film has %d samples per pixel at SPP 1, want 1", n)
+	}
+}
@@ -0,0 +1,90 @@
+package rt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// PLUGIN REGISTRATION
+// =============================================================================
+
+// External Go modules add primitives, materials and scenes without forking
+// the package: they implement Hittable or Material and register from an
+// init function, so importing the module for its side effects is enough:
+//
+//	import _ "example.com/myplugin"
+//
+// Registration is not synchronized and must happen before rendering starts.
+//
+// Any Material already works in renders. To show up in the debug AOVs and
+// pixel hooks it can implement AlbedoProvider; MaterialInfo and PDFEvaluator
+// let it take part in NEE and MIS. External primitives implement
+// PrimitiveInfo, and hittables that wrap others implement HittableContainer,
+// so scene statistics and light scaling can see inside them.
+
+// PrimitiveInfo is implemented by external primitives to report their name
+// in scene statistics and their material to statistics, light overrides and
+// the light path filters
+type PrimitiveInfo interface {
+	PrimitiveName() string
+	PrimitiveMaterial() Material
+}
+
+// HittableContainer is implemented by external hittables that group or wrap
+// other hittables, so scene walkers descend into them
+type HittableContainer interface {
+	Children() []Hittable
+}
+
+// registeredScenes holds the scenes added with RegisterScene
+var registeredScenes = map[string]func() (*HittableList, *Camera){}
+
+// RegisterScene makes a scene available by name, e.g. to -scene. Names are
+// case-insensitive; registering a name twice or a nil builder panics.
+func RegisterScene(name string, build func() (*HittableList, *Camera)) {
+	key := strings.ToLower(name)
+	if build == nil {
+		panic("rt: RegisterScene builder is nil for " + name)
+	}
+	if _, dup := registeredScenes[key]; dup {
+		panic("rt: RegisterScene called twice for " + name)
+	}
+	registeredScenes[key] = build
+}
+
+// RegisteredScene builds a scene added with RegisterScene
+func RegisteredScene(name string) (*HittableList, *Camera, error) {
+	build, ok := registeredScenes[strings.ToLower(name)]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown scene: %s", name)
+	}
+	world, camera := build()
+	return world, camera, nil
+}
+
+// RegisteredSceneNames lists the registered scenes in alphabetical order
+func RegisteredSceneNames() []string {
+	names := make([]string, 0, len(registeredScenes))
+	for name := range registeredScenes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterMaterial adds a named material to the material library, so
+// PreviewMaterial (and -preview-material) can build it. Registering a name
+// that is already in the library or a nil builder panics.
+func RegisterMaterial(name string, build func() Material) {
+	if build == nil {
+		panic("rt: RegisterMaterial builder is nil for " + name)
+	}
+	for _, m := range previewMaterials {
+		if strings.EqualFold(name, m.name) {
+			panic("rt: RegisterMaterial called twice for " + name)
+		}
+	}
+	previewMaterials = append(previewMaterials, previewMaterial{name: name, build: build})
+}
@@ -0,0 +1,66 @@
+package rt
+
+import "testing"
+
+// pluginBall is an external primitive: a sphere behind its own type
+type pluginBall struct {
+	*Sphere
+}
+
+func (b pluginBall) PrimitiveName() string       { return "PluginBall" }
+func (b pluginBall) PrimitiveMaterial() Material { return b.Mat }
+
+// pluginGroup is an external container
+type pluginGroup struct {
+	*HittableList
+}
+
+func (g pluginGroup) Children() []Hittable { return g.Objects }
+
+func TestPluginPrimitivesInSceneStats(t *testing.T) {
+	light := pluginBall{NewSphere(Point3{Y: 2}, 0.5, NewDiffuseLightColor(Color{X: 4, Y: 4, Z: 4}))}
+	group := pluginGroup{NewHittableList()}
+	group.Add(light)
+	group.Add(NewSphere(Point3{}, 1, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})))
+
+	stats := CollectSceneStats(group, nil)
+	if stats.Primitives["PluginBall"] != 1 || stats.Primitives["Sphere"] != 1 {
+		t.Errorf("primitives = %v, want one PluginBall and one Sphere inside the group", stats.Primitives)
+	}
+	if stats.EmissiveCount != 1 {
+		t.Errorf("EmissiveCount = %d, want the plugin light counted", stats.EmissiveCount)
+	}
+	if lightEmitter(light) == nil {
+		t.Error("lightEmitter doesn't see the plugin primitive's material")
+	}
+}
+
+func TestRegisterMaterialAndScene(t *testing.T) {
+	builtin := previewMaterials
+	t.Cleanup(func() {
+		previewMaterials = builtin
+		delete(registeredScenes, "plugin-test")
+	})
+
+	RegisterMaterial("plugin-red", func() Material { return NewLambertian(Color{X: 1}) })
+	if _, err := PreviewMaterial("Plugin-Red"); err != nil {
+		t.Errorf("registered material not found: %v", err)
+	}
+
+	RegisterScene("Plugin-Test", func() (*HittableList, *Camera) {
+		return NewHittableList(), NewCameraBuilder().Build()
+	})
+	if world, camera, err := RegisteredScene("plugin-test"); err != nil || world == nil || camera == nil {
+		t.Errorf("registered scene not built: %v", err)
+	}
+	if _, _, err := RegisteredScene("missing"); err == nil {
+		t.Error("unknown scene didn't return an error")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a built-in material name didn't panic")
+		}
+	}()
+	RegisterMaterial("glass", func() Material { return NewDielectric(1.33) })
+}
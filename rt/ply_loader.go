@@ -0,0 +1,372 @@
+package rt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// PLY (STANFORD POLYGON) LOADER
+// =============================================================================
+
+// LoadPLY loads an ASCII or binary PLY file and returns a BVH of the triangles.
+// PLY files share the OBJ limits (MaxOBJVertices, MaxOBJTriangles, MaxOBJFaceVertices).
+// RUST PORT NOTE: Consider the 'ply-rs' crate
+func LoadPLY(filename string, material Material) (Hittable, error) {
+	return loadPLY(filename, material, nil)
+}
+
+// LoadPLYWithVertexColors loads a PLY file with per-vertex red/green/blue
+// properties and shades each triangle with newMaterial applied to a
+// VertexColorTexture, e.g. for scanned models with baked colors
+func LoadPLYWithVertexColors(filename string, newMaterial MaterialFactory) (Hittable, error) {
+	return loadPLY(filename, nil, newMaterial)
+}
+
+func loadPLY(filename string, material Material, newMaterial MaterialFactory) (Hittable, error) {
+	return loadMesh("PLY", filename, parsePLYMesh, material, newMaterial)
+}
+
+// ParsePLY reads a PLY stream and returns the triangulated faces together
+// with the number of vertices read
+func ParsePLY(r io.Reader, material Material) ([]Hittable, int, error) {
+	mesh, err := parsePLYMesh(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mesh.triangles(material, nil), len(mesh.vertices), nil
+}
+
+type plyProperty struct {
+	name      string
+	valueType string // Scalar type, or list item type
+	countType string // List count type ("" for scalar properties)
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// plyValueReader reads a single typed value from the body
+type plyValueReader func(valueType string) (float64, error)
+
+func parsePLYMesh(r io.Reader) (*meshData, error) {
+	reader := bufio.NewReader(r)
+
+	format, elements, err := parsePLYHeader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PLY header: %w", err)
+	}
+
+	var read plyValueReader
+	switch format {
+	case "ascii":
+		scanner := bufio.NewScanner(reader)
+		scanner.Split(bufio.ScanWords)
+		read = func(string) (float64, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return 0, err
+				}
+				return 0, io.ErrUnexpectedEOF
+			}
+			return strconv.ParseFloat(scanner.Text(), 64)
+		}
+	case "binary_little_endian":
+		read = binaryPLYReader(reader, binary.LittleEndian)
+	case "binary_big_endian":
+		read = binaryPLYReader(reader, binary.BigEndian)
+	default:
+		return nil, fmt.Errorf("unsupported PLY format: %s", format)
+	}
+
+	mesh := &meshData{}
+	for _, element := range elements {
+		switch element.name {
+		case "vertex":
+			err = readPLYVertices(mesh, element, read)
+		case "face":
+			err = readPLYFaces(mesh, element, read)
+		default:
+			err = skipPLYElement(element, read)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s element: %w", element.name, err)
+		}
+	}
+
+	return mesh, nil
+}
+
+func parsePLYHeader(reader *bufio.Reader) (string, []plyElement, error) {
+	readLine := func() (string, error) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	magic, err := readLine()
+	if err != nil || magic != "ply" {
+		return "", nil, fmt.Errorf("missing 'ply' signature")
+	}
+
+	format := ""
+	var elements []plyElement
+	for lines := 0; ; lines++ {
+		if lines >= maxPLYHeaderLines {
+			return "", nil, fmt.Errorf("header exceeds %d lines", maxPLYHeaderLines)
+		}
+		line, err := readLine()
+		if err != nil {
+			return "", nil, fmt.Errorf("unexpected end of header: %v", err)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return "", nil, fmt.Errorf("invalid format line: %s", line)
+			}
+			format = fields[1]
+		case "element":
+			if len(fields) != 3 {
+				return "", nil, fmt.Errorf("invalid element line: %s", line)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return "", nil, fmt.Errorf("invalid element count: %s", line)
+			}
+			if count > MaxOBJVertices || count > MaxOBJTriangles {
+				return "", nil, fmt.Errorf("element %s has %d entries (limit %d)", fields[1], count, MaxOBJVertices)
+			}
+			elements = append(elements, plyElement{name: fields[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return "", nil, fmt.Errorf("property before any element")
+			}
+			element := &elements[len(elements)-1]
+			var prop plyProperty
+			if len(fields) == 5 && fields[1] == "list" {
+				prop = plyProperty{countType: fields[2], valueType: fields[3], name: fields[4]}
+			} else if len(fields) == 3 {
+				prop = plyProperty{valueType: fields[1], name: fields[2]}
+			} else {
+				return "", nil, fmt.Errorf("invalid property line: %s", line)
+			}
+			if plyTypeSize(prop.valueType) == 0 || (prop.countType != "" && plyTypeSize(prop.countType) == 0) {
+				return "", nil, fmt.Errorf("unknown property type: %s", line)
+			}
+			element.properties = append(element.properties, prop)
+		case "end_header":
+			if format == "" {
+				return "", nil, fmt.Errorf("missing format line")
+			}
+			return format, elements, nil
+		}
+		// comment, obj_info and unknown lines are ignored
+	}
+}
+
+// plyTypeSize returns the byte size of a PLY scalar type, 0 if unknown
+func plyTypeSize(valueType string) int {
+	switch valueType {
+	case "char", "int8", "uchar", "uint8":
+		return 1
+	case "short", "int16", "ushort", "uint16":
+		return 2
+	case "int", "int32", "uint", "uint32", "float", "float32":
+		return 4
+	case "double", "float64":
+		return 8
+	}
+	return 0
+}
+
+func binaryPLYReader(reader *bufio.Reader, order binary.ByteOrder) plyValueReader {
+	var buf [8]byte
+	return func(valueType string) (float64, error) {
+		size := plyTypeSize(valueType)
+		if _, err := io.ReadFull(reader, buf[:size]); err != nil {
+			return 0, err
+		}
+		b := buf[:size]
+		switch valueType {
+		case "char", "int8":
+			return float64(int8(b[0])), nil
+		case "uchar", "uint8":
+			return float64(b[0]), nil
+		case "short", "int16":
+			return float64(int16(order.Uint16(b))), nil
+		case "ushort", "uint16":
+			return float64(order.Uint16(b)), nil
+		case "int", "int32":
+			return float64(int32(order.Uint32(b))), nil
+		case "uint", "uint32":
+			return float64(order.Uint32(b)), nil
+		case "float", "float32":
+			return float64(math.Float32frombits(order.Uint32(b))), nil
+		default:
+			return math.Float64frombits(order.Uint64(b)), nil
+		}
+	}
+}
+
+// plyColorScale maps a color property to [0, 1]: integer channels are 0..255
+func plyColorScale(valueType string) float64 {
+	switch valueType {
+	case "float", "float32", "double", "float64":
+		return 1
+	case "ushort", "uint16":
+		return 1.0 / 65535
+	}
+	return 1.0 / 255
+}
+
+func readPLYVertices(mesh *meshData, element plyElement, read plyValueReader) error {
+	hasColor := false
+	for _, prop := range element.properties {
+		switch prop.name {
+		case "red", "diffuse_red":
+			hasColor = true
+		}
+	}
+
+	mesh.vertices = make([]Point3, 0, min(element.count, plyPreallocVertices))
+	for i := 0; i < element.count; i++ {
+		var p Point3
+		c := Color{X: 1, Y: 1, Z: 1}
+		for _, prop := range element.properties {
+			if prop.countType != "" {
+				if err := skipPLYList(prop, read); err != nil {
+					return err
+				}
+				continue
+			}
+
+			v, err := read(prop.valueType)
+			if err != nil {
+				return fmt.Errorf("vertex %d: %w", i, err)
+			}
+			switch prop.name {
+			case "x":
+				p.X = v
+			case "y":
+				p.Y = v
+			case "z":
+				p.Z = v
+			case "red", "diffuse_red":
+				c.X = v * plyColorScale(prop.valueType)
+			case "green", "diffuse_green":
+				c.Y = v * plyColorScale(prop.valueType)
+			case "blue", "diffuse_blue":
+				c.Z = v * plyColorScale(prop.valueType)
+			}
+		}
+
+		if !isFinite(p.X) || !isFinite(p.Y) || !isFinite(p.Z) {
+			return fmt.Errorf("vertex %d has non-finite coordinates", i)
+		}
+		mesh.vertices = append(mesh.vertices, p)
+		if hasColor {
+			mesh.setColor(i, c)
+		}
+	}
+	return nil
+}
+
+func readPLYFaces(mesh *meshData, element plyElement, read plyValueReader) error {
+	for i := 0; i < element.count; i++ {
+		for _, prop := range element.properties {
+			if prop.countType == "" || (prop.name != "vertex_indices" && prop.name != "vertex_index") {
+				if prop.countType != "" {
+					if err := skipPLYList(prop, read); err != nil {
+						return err
+					}
+				} else if _, err := read(prop.valueType); err != nil {
+					return err
+				}
+				continue
+			}
+
+			count, err := read(prop.countType)
+			if err != nil {
+				return fmt.Errorf("face %d: %w", i, err)
+			}
+			n, ok := plyInt(count, MaxOBJFaceVertices+1)
+			if !ok {
+				return fmt.Errorf("face %d has %g vertices (max %d)", i, count, MaxOBJFaceVertices)
+			}
+
+			indices := make([]int, n)
+			for j := range indices {
+				v, err := read(prop.valueType)
+				if err != nil {
+					return fmt.Errorf("face %d: %w", i, err)
+				}
+				if indices[j], ok = plyInt(v, len(mesh.vertices)); !ok {
+					return fmt.Errorf("face %d: vertex index %g out of bounds", i, v)
+				}
+			}
+
+			if len(mesh.faces)+len(indices)-2 > MaxOBJTriangles {
+				return fmt.Errorf("more than %d triangles", MaxOBJTriangles)
+			}
+			mesh.addPolygon(indices)
+		}
+	}
+	return nil
+}
+
+// plyInt converts a list count or vertex index read as a float to an int,
+// rejecting NaN, fractions and values outside [0, limit)
+func plyInt(v float64, limit int) (int, bool) {
+	if !(v >= 0 && v < float64(limit)) || v != math.Trunc(v) {
+		return 0, false
+	}
+	return int(v), true
+}
+
+func skipPLYList(prop plyProperty, read plyValueReader) error {
+	count, err := read(prop.countType)
+	if err != nil {
+		return err
+	}
+	n, ok := plyInt(count, MaxOBJFaceVertices+1)
+	if !ok {
+		return fmt.Errorf("list %s has %g entries", prop.name, count)
+	}
+	for j := 0; j < n; j++ {
+		if _, err := read(prop.valueType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func skipPLYElement(element plyElement, read plyValueReader) error {
+	for i := 0; i < element.count; i++ {
+		for _, prop := range element.properties {
+			if prop.countType != "" {
+				if err := skipPLYList(prop, read); err != nil {
+					return err
+				}
+			} else if _, err := read(prop.valueType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -85,9 +85,13 @@ func (q *Quad) isInterior(a, b float64, rec *HitRecord) bool {
 
 // SamplePoint returns a random point on the quad surface
 func (q *Quad) SamplePoint() Point3 {
+	return q.samplePoint(nil)
+}
+
+func (q *Quad) samplePoint(s *Sampler) Point3 {
 	// Random barycentric coordinates [0,1] x [0,1]
-	alpha := RandomDouble()
-	beta := RandomDouble()
+	alpha := s.Float64()
+	beta := s.Float64()
 	return q.Q.Add(q.u.Scale(alpha)).Add(q.v.Scale(beta))
 }
 
@@ -0,0 +1,123 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// =============================================================================
+// QUATERNIONS
+// =============================================================================
+
+// Quaternion is a rotation W + Xi + Yj + Zk. Rotations built by the
+// constructors below are unit length.
+type Quaternion struct {
+	W, X, Y, Z float64
+}
+
+// IdentityQuaternion returns the rotation that leaves vectors unchanged
+func IdentityQuaternion() Quaternion {
+	return Quaternion{W: 1}
+}
+
+// NewQuaternionAxisAngle returns a rotation of degrees around axis
+// (right-handed, matching Rx/Ry/Rz)
+func NewQuaternionAxisAngle(axis Vec3, degrees float64) Quaternion {
+	if axis.Len2() == 0 {
+		return IdentityQuaternion()
+	}
+	half := DegreesToRadians(degrees) / 2
+	a := axis.Unit().Scale(math.Sin(half))
+	return Quaternion{W: math.Cos(half), X: a.X, Y: a.Y, Z: a.Z}
+}
+
+// NewQuaternionFromEuler converts Euler angles in degrees, applied one axis
+// at a time in the given order, e.g. RotationXYZ rotates about X first
+func NewQuaternionFromEuler(degrees Vec3, order RotationOrder) Quaternion {
+	axes := map[byte]Quaternion{
+		'x': NewQuaternionAxisAngle(Vec3{X: 1}, degrees.X),
+		'y': NewQuaternionAxisAngle(Vec3{Y: 1}, degrees.Y),
+		'z': NewQuaternionAxisAngle(Vec3{Z: 1}, degrees.Z),
+	}
+	name := order.String()
+	if name == "unknown" {
+		name = RotationXYZ.String()
+	}
+	q := IdentityQuaternion()
+	for _, axis := range []byte(name) {
+		// Later rotations multiply on the left
+		q = axes[axis].Mul(q)
+	}
+	return q
+}
+
+// Mul composes two rotations: q.Mul(r) applies r first, then q
+func (q Quaternion) Mul(r Quaternion) Quaternion {
+	return Quaternion{
+		W: q.W*r.W - q.X*r.X - q.Y*r.Y - q.Z*r.Z,
+		X: q.W*r.X + q.X*r.W + q.Y*r.Z - q.Z*r.Y,
+		Y: q.W*r.Y - q.X*r.Z + q.Y*r.W + q.Z*r.X,
+		Z: q.W*r.Z + q.X*r.Y - q.Y*r.X + q.Z*r.W,
+	}
+}
+
+// Conjugate returns the inverse rotation of a unit quaternion
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// Normalized returns q scaled to unit length (identity for a zero quaternion)
+func (q Quaternion) Normalized() Quaternion {
+	n := math.Sqrt(q.W*q.W + q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+	if n == 0 {
+		return IdentityQuaternion()
+	}
+	return Quaternion{W: q.W / n, X: q.X / n, Y: q.Y / n, Z: q.Z / n}
+}
+
+// Rotate applies the rotation to a vector
+func (q Quaternion) Rotate(v Vec3) Vec3 {
+	// v' = v + 2w(u x v) + 2u x (u x v) with u the vector part
+	u := Vec3{X: q.X, Y: q.Y, Z: q.Z}
+	t := Cross(u, v).Scale(2)
+	return v.Add(t.Scale(q.W)).Add(Cross(u, t))
+}
+
+// =============================================================================
+// EULER ROTATION ORDER
+// =============================================================================
+
+// RotationOrder is the sequence in which Euler angles are applied. The
+// first letter is applied first (RotationXYZ = X, then Y, then Z about the
+// fixed world axes), matching Maya's rotateOrder and Blender's Euler modes.
+type RotationOrder int
+
+const (
+	RotationXYZ RotationOrder = iota // Default, same as the Rx -> Ry -> Rz chain
+	RotationXZY
+	RotationYXZ
+	RotationYZX
+	RotationZXY
+	RotationZYX
+)
+
+var rotationOrderNames = []string{"xyz", "xzy", "yxz", "yzx", "zxy", "zyx"}
+
+func (o RotationOrder) String() string {
+	if int(o) < 0 || int(o) >= len(rotationOrderNames) {
+		return "unknown"
+	}
+	return rotationOrderNames[o]
+}
+
+// ParseRotationOrder converts an order name (xyz, zyx, ...) to a RotationOrder
+func ParseRotationOrder(name string) (RotationOrder, error) {
+	for i, n := range rotationOrderNames {
+		if strings.EqualFold(name, n) {
+			return RotationOrder(i), nil
+		}
+	}
+	return RotationXYZ, fmt.Errorf("unknown rotation order: %s (use %s)",
+		name, strings.Join(rotationOrderNames, ", "))
+}
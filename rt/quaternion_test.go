@@ -0,0 +1,59 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuaternionAxisAngleRotatesVector(t *testing.T) {
+	q := NewQuaternionAxisAngle(Vec3{Z: 1}, 90)
+	got := q.Rotate(Vec3{X: 1})
+	if got.Sub(Vec3{Y: 1}).Len() > 1e-12 {
+		t.Errorf("90 degrees about Z rotated +X to %v, want +Y", got)
+	}
+}
+
+func TestQuaternionEulerMatchesRotationChain(t *testing.T) {
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	box := Box(Point3{X: 0.5, Y: -0.2, Z: -0.1}, Point3{X: 1.5, Y: 0.3, Z: 0.4}, mat)
+	angles := Vec3{X: 30, Y: 45, Z: 60}
+
+	euler := NewTransform().SetRotation(angles).Apply(box)
+	quat := NewTransform().SetQuaternion(NewQuaternionFromEuler(angles, RotationXYZ)).Apply(box)
+
+	hits := 0
+	for i := range 200 {
+		origin := Point3{X: 4 * math.Cos(float64(i)), Y: 4 * math.Sin(float64(i)*0.7), Z: 4}
+		r := NewRay(origin, Point3{X: 0.3, Y: 0.5, Z: 0.2}.Sub(origin), 0)
+		var a, b HitRecord
+		hitA := euler.Hit(r, NewInterval(0.001, math.Inf(1)), &a)
+		hitB := quat.Hit(r, NewInterval(0.001, math.Inf(1)), &b)
+		if hitA != hitB {
+			t.Fatalf("ray %d: Euler chain hit=%v, quaternion hit=%v", i, hitA, hitB)
+		}
+		if !hitA {
+			continue
+		}
+		hits++
+		if math.Abs(a.T-b.T) > 1e-9 || Dot(a.Normal, b.Normal) < 1-1e-9 {
+			t.Fatalf("ray %d: Euler chain t=%g n=%v, quaternion t=%g n=%v", i, a.T, a.Normal, b.T, b.Normal)
+		}
+	}
+	if hits == 0 {
+		t.Fatal("no test ray hit the box")
+	}
+}
+
+func TestRotationOrderChangesResult(t *testing.T) {
+	angles := Vec3{X: 90, Y: 90}
+	xyz := NewQuaternionFromEuler(angles, RotationXYZ).Rotate(Vec3{Z: 1})
+	yxz := NewQuaternionFromEuler(angles, RotationYXZ).Rotate(Vec3{Z: 1})
+	// X first: +Z -> -Y, then Y leaves it. Y first: +Z -> +X, then X leaves it.
+	if xyz.Sub(Vec3{Y: -1}).Len() > 1e-12 || yxz.Sub(Vec3{X: 1}).Len() > 1e-12 {
+		t.Errorf("xyz rotated +Z to %v (want -Y), yxz to %v (want +X)", xyz, yxz)
+	}
+
+	if order, err := ParseRotationOrder("ZXY"); err != nil || order != RotationZXY {
+		t.Errorf("ParseRotationOrder(ZXY) = %v, %v", order, err)
+	}
+}
@@ -1,15 +1,31 @@
 package rt
 
 type Ray struct {
-	orig Point3
-	dir  Vec3
-	tm   float64
+	orig    Point3
+	dir     Vec3
+	tm      float64
+	channel int      // Color channel a dispersed path carries (0 = all, 1-3 = R, G, B)
+	kind    rayKind  // What traced the ray, for per-object visibility
+	sampler *Sampler // Random stream of the pixel sample that traced the ray (nil = global)
 }
 
 func NewRay(origin Point3, direction Vec3, time float64) Ray {
 	return Ray{orig: origin, dir: direction, tm: time}
 }
 
+// reframed returns the ray with a new origin and direction, e.g. in an
+// object's local space, keeping its time, channel, kind and sampler
+func (r Ray) reframed(origin Point3, direction Vec3) Ray {
+	r.orig, r.dir = origin, direction
+	return r
+}
+
+// withSampler returns the ray drawing its random numbers from s
+func (r Ray) withSampler(s *Sampler) Ray {
+	r.sampler = s
+	return r
+}
+
 func (r Ray) Origin() Point3 {
 	return r.orig
 }
@@ -0,0 +1,73 @@
+package rt
+
+import (
+	"os"
+	"testing"
+)
+
+func renderImageScene() (*HittableList, *Camera) {
+	world := NewHittableList()
+	world.Add(NewSphere(Point3{Z: -1}, 0.5, NewLambertian(Color{X: 0.7, Y: 0.3, Z: 0.3})))
+	camera := NewCameraBuilder().SetResolution(16, 2).SetQuality(4, 4).
+		SetBackground(Color{X: 0.5, Y: 0.7, Z: 1}).Build()
+	return world, camera
+}
+
+func TestRenderToImageWritesNoFiles(t *testing.T) {
+	SeedRandom(3)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+	t.Chdir(t.TempDir())
+
+	world, camera := renderImageScene()
+	single, err := camera.RenderToImage(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	world, camera = renderImageScene()
+	bucket, err := NewBucketRenderer(camera, world, 8, 2).RenderToImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	world, camera = renderImageScene()
+	progressive, err := NewProgressiveRenderer(camera, world).RenderToImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, img := range []struct {
+		name string
+		w, h int
+	}{
+		{"camera", single.Bounds().Dx(), single.Bounds().Dy()},
+		{"bucket", bucket.Bounds().Dx(), bucket.Bounds().Dy()},
+		{"progressive", progressive.Bounds().Dx(), progressive.Bounds().Dy()},
+	} {
+		if img.w != 16 || img.h != 8 {
+			t.Errorf("%s image is %dx%d, want 16x8", img.name, img.w, img.h)
+		}
+	}
+	if center := bucket.RGBAAt(8, 4); center.R <= center.B {
+		t.Errorf("bucket render center = %v, want the red sphere", center)
+	}
+
+	entries, _ := os.ReadDir(".")
+	for _, e := range entries {
+		t.Errorf("render wrote %s", e.Name())
+	}
+}
+
+func TestRenderToImageRejectsSecondRun(t *testing.T) {
+	world, camera := renderImageScene()
+	r := NewBucketRenderer(camera, world, 8, 2)
+	if _, err := r.RenderToImage(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.RenderToImage(); err == nil {
+		t.Error("a finished renderer should not render again")
+	}
+	if _, err := camera.RenderToImage(nil); err == nil {
+		t.Error("rendering a nil world should fail")
+	}
+}
@@ -3,51 +3,114 @@ package rt
 import (
 	"fmt"
 	"image"
-	"image/color"
-	"image/png"
 	"os"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/text/v2"
-	"golang.org/x/image/font/basicfont"
 )
 
 type ProgressiveRenderer struct {
-	framebuffer *image.RGBA
-	camera      *Camera
-	world       Hittable
-	currentRow  int
-	completed   bool
-	renderStart time.Time
-	renderEnd   time.Time
+	framebuffer   *image.RGBA
+	film          *Film // Linear radiance for HDR output
+	camera        *Camera
+	world         Hittable
+	currentRow    int
+	completed     bool
+	renderStart   time.Time
+	renderEnd     time.Time
+	overlay       OverlayConfig
+	dof           *dofPreview // Created when the DOF preview is first shown
+	sceneName     string      // Recorded in saved image metadata
+	hdrOutput     string      // Optional path for the raw linear render (.pfm)
+	pixelHook     PixelHook   // Optional per-pixel post-process before display
+	nanCheck      *nanChecker // Replaces and logs NaN/Inf samples (nil = off)
+	fireflyFilter FireflyFilterConfig
 }
 
 func NewProgressiveRenderer(camera *Camera, world Hittable) *ProgressiveRenderer {
 	framebuffer := image.NewRGBA(image.Rect(0, 0, camera.ImageWidth, camera.ImageHeight))
 	return &ProgressiveRenderer{
 		framebuffer: framebuffer,
+		film:        NewFilm(camera.ImageWidth, camera.ImageHeight),
 		camera:      camera,
 		world:       world,
 		currentRow:  0,
 		completed:   false,
 		renderStart: time.Now(), // Start timing when renderer is created
+		overlay:     DefaultOverlayConfig(),
 	}
 }
+
+// SetOverlay configures the viewer stats overlay
+func (r *ProgressiveRenderer) SetOverlay(overlay OverlayConfig) *ProgressiveRenderer {
+	r.overlay = overlay
+	return r
+}
+
+// SetHDROutput additionally saves the untonemapped linear render as a PFM
+// file at path when the render completes (empty disables it)
+func (r *ProgressiveRenderer) SetHDROutput(path string) *ProgressiveRenderer {
+	r.hdrOutput = path
+	return r
+}
+
+// SetPixelHook installs a callback that post-processes every pixel's HDR
+// value (with its AOV data) before it is written to the framebuffer
+func (r *ProgressiveRenderer) SetPixelHook(hook PixelHook) *ProgressiveRenderer {
+	r.pixelHook = hook
+	return r
+}
+
+// SetFireflyFilter enables outlier clamping of the finished image before it
+// is tonemapped for display and the PNG
+func (r *ProgressiveRenderer) SetFireflyFilter(config FireflyFilterConfig) *ProgressiveRenderer {
+	r.fireflyFilter = config
+	return r
+}
+
+// applyFireflyFilter redraws the framebuffer from the filtered film
+func (r *ProgressiveRenderer) applyFireflyFilter() {
+	pixels := filteredFilm(r.film, r.fireflyFilter)
+	writeFramebuffer(r.framebuffer, pixels, r.film, 1, ToneMapConfig{}, r.pixelHook, r.camera, r.world)
+}
+
+// SetNaNCheck enables detection of NaN/Inf samples, which are then replaced
+// with config.DebugColor and logged instead of corrupting the film
+func (r *ProgressiveRenderer) SetNaNCheck(config NaNCheckConfig) *ProgressiveRenderer {
+	r.nanCheck = newNaNChecker(config)
+	return r
+}
+
+// SetSceneName sets the scene name recorded in the saved image metadata
+func (r *ProgressiveRenderer) SetSceneName(name string) *ProgressiveRenderer {
+	r.sceneName = name
+	return r
+}
+
 func (r *ProgressiveRenderer) Update() error {
+	r.overlay.handleInput()
+	if r.dof != nil && r.overlay.Enabled && r.overlay.ShowDOF {
+		r.dof.handleInput()
+	}
+
 	if r.currentRow < r.camera.ImageHeight {
 		r.renderScanline(r.currentRow)
 		r.currentRow++
 		if r.currentRow >= r.camera.ImageHeight && !r.completed {
 			r.completed = true
 			r.renderEnd = time.Now()
-			r.drawStatsToFramebuffer()
+			if r.fireflyFilter.Enabled {
+				r.applyFireflyFilter()
+			}
 			_ = r.SaveImage("image.png")
+			if r.hdrOutput != "" {
+				_ = r.SaveHDR(r.hdrOutput)
+			}
 
 			// Print render stats with actual render time
 			renderDuration := r.renderEnd.Sub(r.renderStart)
 			PrintRenderStats(renderDuration, r.camera.ImageWidth, r.camera.ImageHeight)
+			r.nanCheck.report()
 		}
 	}
 	return nil
@@ -56,7 +119,7 @@ func (r *ProgressiveRenderer) Update() error {
 func (r *ProgressiveRenderer) Draw(screen *ebiten.Image) {
 	screen.WritePixels(r.framebuffer.Pix)
 
-	// Draw render settings in lower left corner
+	// Draw the stats overlay (window only, never saved)
 	r.drawRenderSettings(screen)
 }
 
@@ -75,21 +138,6 @@ func (r *ProgressiveRenderer) drawRenderSettings(screen *ebiten.Image) {
 		elapsed = time.Since(r.renderStart)
 	}
 
-	// Draw black bar at bottom (30 pixels tall)
-	barHeight := 30
-	barY := r.camera.ImageHeight - barHeight
-	bgColor := color.RGBA{R: 0, G: 0, B: 0, A: 255}
-
-	for py := barY; py < r.camera.ImageHeight; py++ {
-		for px := 0; px < r.camera.ImageWidth; px++ {
-			r.framebuffer.Set(px, py, bgColor)
-		}
-	}
-
-	// Display stats in a single line across the bar
-	textY := barY + 10 // Center text vertically in the 30px bar
-	spacing := 15
-
 	var status string
 	if r.completed {
 		status = "COMPLETED"
@@ -97,56 +145,29 @@ func (r *ProgressiveRenderer) drawRenderSettings(screen *ebiten.Image) {
 		status = fmt.Sprintf("Scanline: %d/%d", r.currentRow, r.camera.ImageHeight)
 	}
 
-	statsText := fmt.Sprintf("%dx%d | SPP:%d | Depth:%d | %.1f%% | %s | %s",
-		r.camera.ImageWidth,
-		r.camera.ImageHeight,
-		r.camera.SamplesPerPixel,
-		r.camera.MaxDepth,
-		progress,
-		FormatDuration(elapsed),
-		status,
-	)
-
-	ebitenutil.DebugPrintAt(screen, statsText, spacing, textY)
-}
-
-// drawStatsToFramebuffer draws the render statistics directly onto the framebuffer for saving
-func (r *ProgressiveRenderer) drawStatsToFramebuffer() {
-	elapsed := r.renderEnd.Sub(r.renderStart)
-
-	// Draw black bar at bottom (30 pixels tall)
-	barHeight := 30
-	barY := r.camera.ImageHeight - barHeight
-	bgColor := color.RGBA{R: 0, G: 0, B: 0, A: 255}
-
-	for py := barY; py < r.camera.ImageHeight; py++ {
-		for px := 0; px < r.camera.ImageWidth; px++ {
-			r.framebuffer.Set(px, py, bgColor)
+	lines := []string{
+		fmt.Sprintf("%dx%d | SPP:%d | Depth:%d | %.1f%% | %s | %s",
+			r.camera.ImageWidth,
+			r.camera.ImageHeight,
+			r.camera.SamplesPerPixel,
+			r.camera.MaxDepth,
+			progress,
+			FormatDuration(elapsed),
+			status,
+		),
+	}
+	if r.overlay.ShowRayStats {
+		lines = append(lines, rayStatsLines(elapsed.Seconds())...)
+	}
+	if r.overlay.Enabled && r.overlay.ShowDOF {
+		if r.dof == nil {
+			r.dof = newDOFPreview(r.camera, r.world, r.overlay.DOFCoC)
 		}
+		r.dof.draw(screen)
+		lines = append(lines, r.dof.lines()...)
 	}
 
-	// Prepare stats text
-	textColor := color.RGBA{R: 255, G: 255, B: 255, A: 255}
-	face := text.NewGoXFace(basicfont.Face7x13)
-
-	statsText := fmt.Sprintf("%dx%d | SPP:%d | Depth:%d | 100.0%% | %s",
-		r.camera.ImageWidth,
-		r.camera.ImageHeight,
-		r.camera.SamplesPerPixel,
-		r.camera.MaxDepth,
-		FormatDuration(elapsed),
-	)
-
-	// Create temporary ebiten.Image from framebuffer
-	tempImg := ebiten.NewImageFromImage(r.framebuffer)
-
-	opts := &text.DrawOptions{}
-	opts.GeoM.Translate(15, float64(barY+10)) // Position text in the bar (13px font height needs room)
-	opts.ColorScale.ScaleWithColor(textColor)
-	text.Draw(tempImg, statsText, face, opts)
-
-	// Copy the result back to framebuffer
-	tempImg.ReadPixels(r.framebuffer.Pix)
+	r.overlay.draw(screen, lines)
 }
 
 func (r *ProgressiveRenderer) Layout(w, h int) (int, int) {
@@ -157,22 +178,34 @@ func (r *ProgressiveRenderer) renderScanline(j int) {
 		pixelColor := Color{X: 0, Y: 0, Z: 0}
 
 		for sample := 0; sample < r.camera.SamplesPerPixel; sample++ {
-			ray := r.camera.GetRay(i, j)
-			pixelColor = pixelColor.Add(r.camera.RayColor(ray, r.camera.MaxDepth, r.world))
+			ray := r.camera.getRay(i, j, pixelSampler(i, j, 0, sample))
+			sampleColor := r.nanCheck.check(r.camera.RayColor(ray, r.camera.MaxDepth, r.world), r.world, ray, i, j, sample)
+			pixelColor = pixelColor.Add(sampleColor)
 		}
 
-		scale := 1.0 / float64(r.camera.SamplesPerPixel)
-		pixelColor = pixelColor.Scale(scale)
+		r.film.AddSamples(i, j, pixelColor, r.camera.SamplesPerPixel)
+		pixelColor = r.film.Resolve(i, j)
+		pixelColor = applyPixelHook(r.pixelHook, r.camera, r.world, i, j, r.film.SampleCount(i, j), pixelColor)
+
+		r.framebuffer.Set(i, j, LinearToRGBA(pixelColor))
+	}
+}
 
-		// Clamp using Interval
-		intensity := NewInterval(0.0, 0.999)
-		r.framebuffer.Set(i, j, color.RGBA{
-			R: uint8(256 * intensity.Clamp(LinearToGamma(pixelColor.X))),
-			G: uint8(256 * intensity.Clamp(LinearToGamma(pixelColor.Y))),
-			B: uint8(256 * intensity.Clamp(LinearToGamma(pixelColor.Z))),
-			A: 255,
-		})
+// RenderToImage renders the remaining scanlines on the calling goroutine and
+// returns a copy of the finished image, without a window or writing files
+func (r *ProgressiveRenderer) RenderToImage() (*image.RGBA, error) {
+	if r.completed {
+		return nil, fmt.Errorf("render: already completed")
+	}
+	for ; r.currentRow < r.camera.ImageHeight; r.currentRow++ {
+		r.renderScanline(r.currentRow)
+	}
+	r.completed = true
+	r.renderEnd = time.Now()
+	if r.fireflyFilter.Enabled {
+		r.applyFireflyFilter()
 	}
+	return cloneRGBA(r.framebuffer, nil), nil
 }
 
 func (r *ProgressiveRenderer) SaveImage(filename string) error {
@@ -187,7 +220,8 @@ func (r *ProgressiveRenderer) SaveImage(filename string) error {
 		}
 	}(file)
 
-	if err := png.Encode(file, r.framebuffer); err != nil {
+	meta := NewRenderMetadata(r.camera, r.sceneName, r.renderEnd.Sub(r.renderStart))
+	if err := EncodePNGWithMetadata(file, r.framebuffer, meta); err != nil {
 		return fmt.Errorf("error encoding PNG: %w", err)
 	}
 
@@ -206,3 +240,13 @@ func (r *ProgressiveRenderer) GetRenderDuration() time.Duration {
 	}
 	return time.Since(r.renderStart)
 }
+
+// SaveHDR writes the raw linear render to a PFM file
+func (r *ProgressiveRenderer) SaveHDR(filename string) error {
+	if err := r.film.SavePFM(filename); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ HDR image saved to %s\n", filename)
+	return nil
+}
@@ -0,0 +1,57 @@
+package rt
+
+// =============================================================================
+// PER-SAMPLE RANDOM STREAMS
+// =============================================================================
+
+// Sampler is the random number stream of one pixel sample. Rays carry the
+// sampler of the sample that traced them, and every random decision along
+// the path (lens, time, scattering, light selection, volume distances) draws
+// from it. Streams are derived from (seed, x, y, pass, sample) alone, so
+// seeded renders come out identical whatever the number of workers or the
+// order buckets are scheduled in.
+//
+// A nil *Sampler draws from the global generator, so code without a pixel
+// sample (scene construction, tests, previews) keeps using RandomDouble.
+type Sampler struct {
+	state uint64
+}
+
+// NewSampler returns the stream of a pixel sample. pass separates samples of
+// the same index in successive progressive passes.
+func NewSampler(seed int64, x, y, pass, sample int) *Sampler {
+	h := splitMix64(uint64(seed))
+	for _, v := range [...]int{x, y, pass, sample} {
+		h = splitMix64(h ^ uint64(v))
+	}
+	return &Sampler{state: h}
+}
+
+// pixelSampler returns the stream of a pixel sample when a seed is set, and
+// nil (the global generator) otherwise
+func pixelSampler(x, y, pass, sample int) *Sampler {
+	seed, ok := RandomSeed()
+	if !ok {
+		return nil
+	}
+	return NewSampler(seed, x, y, pass, sample)
+}
+
+// Float64 returns a uniform number in [0, 1)
+func (s *Sampler) Float64() float64 {
+	if s == nil {
+		return RandomDouble()
+	}
+	s.state += 0x9e3779b97f4a7c15
+	return float64(splitMix64(s.state)>>11) / (1 << 53)
+}
+
+// splitMix64 is the SplitMix64 output function: a bijective hash whose
+// outputs for consecutive inputs are statistically independent
+func splitMix64(z uint64) uint64 {
+	z ^= z >> 30
+	z *= 0xbf58476d1ce4e5b9
+	z ^= z >> 27
+	z *= 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
@@ -0,0 +1,53 @@
+package rt
+
+import "testing"
+
+func TestSeededBucketRenderIndependentOfWorkers(t *testing.T) {
+	SeedRandom(42)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	world := NewHittableList()
+	world.Add(NewSphere(Point3{Y: -100.5, Z: -1}, 100, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})))
+	world.Add(NewSphere(Point3{X: -0.6, Z: -1}, 0.4, NewDielectric(1.5)))
+	world.Add(NewSphere(Point3{X: 0.6, Z: -1}, 0.4, NewMetal(Color{X: 0.8, Y: 0.6, Z: 0.2}, 0.3)))
+	world.Add(NewVolumeFromColor(NewSphere(Point3{Z: -2}, 0.5, nil), 0.8, Color{X: 0.9, Y: 0.9, Z: 0.9}))
+	light := NewQuad(Point3{X: -0.5, Y: 1.5, Z: -1.5}, Vec3{X: 1}, Vec3{Z: 1}, NewDiffuseLightColor(Color{X: 4, Y: 4, Z: 4}))
+	world.Add(light)
+
+	render := func(bucketSize, workers int) *Film {
+		camera := NewCameraBuilder().SetResolution(24, 1.5).SetQuality(8, 6).
+			SetLens(60, 2, 1).SetBackground(Color{X: 0.2, Y: 0.3, Z: 0.5}).Build()
+		camera.AddLight(light)
+		camera.Initialize()
+		r := NewBucketRenderer(camera, world, bucketSize, workers)
+		defer r.Close()
+		for pass := 0; pass < 3; pass++ {
+			r.currentPass = pass
+			r.renderPass()
+		}
+		return r.film
+	}
+
+	serial := render(4, 1)
+	parallel := render(8, 4)
+	for y := 0; y < serial.Height(); y++ {
+		for x := 0; x < serial.Width(); x++ {
+			if a, b := serial.Resolve(x, y), parallel.Resolve(x, y); a != b {
+				t.Fatalf("pixel (%d, %d) = %v with 1 worker, %v with 4", x, y, a, b)
+			}
+		}
+	}
+}
+
+func TestSamplerStreamsDiffer(t *testing.T) {
+	a, b := NewSampler(1, 3, 4, 0, 0), NewSampler(1, 3, 4, 0, 1)
+	if a.Float64() == b.Float64() {
+		t.Error("neighbouring samples should draw different numbers")
+	}
+	c, d := NewSampler(7, 1, 1, 1, 1), NewSampler(7, 1, 1, 1, 1)
+	for i := 0; i < 10; i++ {
+		if c.Float64() != d.Float64() {
+			t.Fatal("equal keys should give equal streams")
+		}
+	}
+}
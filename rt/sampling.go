@@ -0,0 +1,208 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// SAMPLING UTILITIES
+// =============================================================================
+
+// Direction and point sampling routines paired with their PDFs. Each Sample*
+// function draws from exactly the density its *PDF counterpart returns, so
+// materials and lights can combine them with MIS without re-deriving the math.
+// Solid-angle PDFs are per steradian; area PDFs are per unit area.
+
+// ONB is an orthonormal basis around a unit normal (W)
+type ONB struct {
+	U, V, W Vec3
+}
+
+// NewONB builds a basis with W = n (must be unit length), using the branchless
+// construction of Duff et al. 2017
+func NewONB(n Vec3) ONB {
+	sign := math.Copysign(1, n.Z)
+	a := -1 / (sign + n.Z)
+	b := n.X * n.Y * a
+	return ONB{
+		U: Vec3{X: 1 + sign*n.X*n.X*a, Y: sign * b, Z: -sign * n.X},
+		V: Vec3{X: b, Y: sign + n.Y*n.Y*a, Z: -n.Y},
+		W: n,
+	}
+}
+
+// NewONBFromTangent builds a basis with W = n and U along the part of tangent
+// perpendicular to n, e.g. a mesh tangent or anisotropy direction. Falls back
+// to NewONB when the tangent is (nearly) parallel to n.
+func NewONBFromTangent(n, tangent Vec3) ONB {
+	n = n.Unit()
+	u := tangent.Sub(n.Scale(Dot(tangent, n)))
+	if u.Len2() < 1e-12 {
+		return NewONB(n)
+	}
+	u = u.Unit()
+	return ONB{U: u, V: Cross(n, u), W: n}
+}
+
+// NewONBWithUp builds a view-style basis with W = w and V along the part of
+// up perpendicular to w (so U = V × W points right when W points backwards).
+// Falls back to NewONB when up is parallel to w, e.g. a camera looking
+// straight down with a +Y up vector.
+func NewONBWithUp(w, up Vec3) ONB {
+	w = w.Unit()
+	u := Cross(up, w)
+	if u.Len2() < 1e-12 {
+		return NewONB(w)
+	}
+	u = u.Unit()
+	return ONB{U: u, V: Cross(w, u), W: w}
+}
+
+// Local maps a vector from basis coordinates (z along W) to world space
+func (b ONB) Local(a Vec3) Vec3 {
+	return b.U.Scale(a.X).Add(b.V.Scale(a.Y)).Add(b.W.Scale(a.Z))
+}
+
+// ToLocal maps a world-space vector into basis coordinates (z along W)
+func (b ONB) ToLocal(a Vec3) Vec3 {
+	return Vec3{X: Dot(a, b.U), Y: Dot(a, b.V), Z: Dot(a, b.W)}
+}
+
+// SampleUniformSphere returns a uniformly distributed unit vector
+func SampleUniformSphere() Vec3 {
+	return sampleUniformSphere(nil)
+}
+
+func sampleUniformSphere(s *Sampler) Vec3 {
+	z := 1 - 2*s.Float64()
+	r := math.Sqrt(math.Max(0, 1-z*z))
+	phi := 2 * math.Pi * s.Float64()
+	return Vec3{X: r * math.Cos(phi), Y: r * math.Sin(phi), Z: z}
+}
+
+// UniformSpherePDF is the density of SampleUniformSphere
+func UniformSpherePDF() float64 {
+	return 1 / (4 * math.Pi)
+}
+
+// SampleUniformHemisphere returns a uniformly distributed unit vector on the
+// side of normal
+func SampleUniformHemisphere(normal Vec3) Vec3 {
+	z := RandomDouble()
+	r := math.Sqrt(math.Max(0, 1-z*z))
+	phi := 2 * math.Pi * RandomDouble()
+	return NewONB(normal.Unit()).Local(Vec3{X: r * math.Cos(phi), Y: r * math.Sin(phi), Z: z})
+}
+
+// UniformHemispherePDF is the density of SampleUniformHemisphere
+func UniformHemispherePDF() float64 {
+	return 1 / (2 * math.Pi)
+}
+
+// SampleCosineHemisphere returns a cosine-weighted unit vector around normal
+// (Malley's method: uniform disk projected up)
+func SampleCosineHemisphere(normal Vec3) Vec3 {
+	return sampleCosineHemisphere(nil, normal)
+}
+
+func sampleCosineHemisphere(s *Sampler, normal Vec3) Vec3 {
+	d := sampleConcentricDisk(s)
+	z := math.Sqrt(math.Max(0, 1-d.X*d.X-d.Y*d.Y))
+	return NewONB(normal.Unit()).Local(Vec3{X: d.X, Y: d.Y, Z: z})
+}
+
+// CosineHemispherePDF is the density of SampleCosineHemisphere for a direction
+// at cosTheta to the normal
+func CosineHemispherePDF(cosTheta float64) float64 {
+	if cosTheta <= 0 {
+		return 0
+	}
+	return cosTheta / math.Pi
+}
+
+// SampleUniformCone returns a unit vector uniformly distributed within the
+// cone around axis whose half-angle has cosine cosThetaMax, e.g. towards a
+// spherical light
+func SampleUniformCone(axis Vec3, cosThetaMax float64) Vec3 {
+	cosTheta := 1 - RandomDouble()*(1-cosThetaMax)
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * RandomDouble()
+	return NewONB(axis.Unit()).Local(Vec3{X: sinTheta * math.Cos(phi), Y: sinTheta * math.Sin(phi), Z: cosTheta})
+}
+
+// UniformConePDF is the density of SampleUniformCone
+func UniformConePDF(cosThetaMax float64) float64 {
+	if cosThetaMax >= 1 {
+		return 0 // Degenerate cone: a delta direction
+	}
+	return 1 / (2 * math.Pi * (1 - cosThetaMax))
+}
+
+// GGXD is the GGX (Trowbridge-Reitz) normal distribution for a microfacet
+// normal at cosThetaH to the surface normal, with roughness alpha
+func GGXD(cosThetaH, alpha float64) float64 {
+	if cosThetaH <= 0 {
+		return 0
+	}
+	a2 := alpha * alpha
+	d := cosThetaH*cosThetaH*(a2-1) + 1
+	return a2 / (math.Pi * d * d)
+}
+
+// SampleGGXHalfVector samples a microfacet normal proportional to D(h)·cos(θh)
+func SampleGGXHalfVector(normal Vec3, alpha float64) Vec3 {
+	xi := RandomDouble()
+	cos2Theta := (1 - xi) / (1 + (alpha*alpha-1)*xi)
+	cosTheta := math.Sqrt(cos2Theta)
+	sinTheta := math.Sqrt(math.Max(0, 1-cos2Theta))
+	phi := 2 * math.Pi * RandomDouble()
+	return NewONB(normal.Unit()).Local(Vec3{X: sinTheta * math.Cos(phi), Y: sinTheta * math.Sin(phi), Z: cosTheta})
+}
+
+// GGXHalfVectorPDF is the density of SampleGGXHalfVector (per solid angle of h).
+// For the reflected direction divide by 4·|wo·h|.
+func GGXHalfVectorPDF(cosThetaH, alpha float64) float64 {
+	return GGXD(cosThetaH, alpha) * cosThetaH
+}
+
+// SampleConcentricDisk maps two uniform numbers to the unit disk (Shirley-Chiu),
+// preserving stratification; Z is 0
+func SampleConcentricDisk() Vec3 {
+	return sampleConcentricDisk(nil)
+}
+
+func sampleConcentricDisk(s *Sampler) Vec3 {
+	a := 2*s.Float64() - 1
+	b := 2*s.Float64() - 1
+	if a == 0 && b == 0 {
+		return Vec3{X: 0, Y: 0, Z: 0}
+	}
+
+	var r, theta float64
+	if math.Abs(a) > math.Abs(b) {
+		r, theta = a, (math.Pi/4)*(b/a)
+	} else {
+		r, theta = b, math.Pi/2-(math.Pi/4)*(a/b)
+	}
+	return Vec3{X: r * math.Cos(theta), Y: r * math.Sin(theta), Z: 0}
+}
+
+// SampleTriangleBarycentric returns uniformly distributed barycentric
+// coordinates (b1, b2); the first vertex weight is 1 - b1 - b2
+func SampleTriangleBarycentric() (b1, b2 float64) {
+	su := math.Sqrt(RandomDouble())
+	return 1 - su, RandomDouble() * su
+}
+
+// SampleTriangle returns a uniformly distributed point on the triangle
+func SampleTriangle(v0, v1, v2 Point3) Point3 {
+	b1, b2 := SampleTriangleBarycentric()
+	return v0.Scale(1 - b1 - b2).Add(v1.Scale(b1)).Add(v2.Scale(b2))
+}
+
+// TrianglePDF is the area density of SampleTriangle
+func TrianglePDF(v0, v1, v2 Point3) float64 {
+	area := 0.5 * Cross(v1.Sub(v0), v2.Sub(v0)).Len()
+	if area == 0 {
+		return 0
+	}
+	return 1 / area
+}
@@ -0,0 +1,151 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+// integrateOverPDF estimates the measure of a sampler's domain as E[1/pdf];
+// it matches the domain's solid angle only if the PDF matches the sampler
+func integrateOverPDF(n int, sample func() (Vec3, float64)) float64 {
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		_, pdf := sample()
+		if pdf > 0 {
+			sum += 1 / pdf
+		}
+	}
+	return sum / float64(n)
+}
+
+func TestSamplingPDFsMatchSamplers(t *testing.T) {
+	SeedRandom(7)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	normal := Vec3{X: 0.3, Y: -0.8, Z: 0.52}.Unit()
+	const cosMax = 0.8
+	const alpha = 0.4
+	const n = 200000
+
+	cases := []struct {
+		name   string
+		sample func() (Vec3, float64)
+		want   float64
+	}{
+		{"uniform-sphere", func() (Vec3, float64) {
+			return SampleUniformSphere(), UniformSpherePDF()
+		}, 4 * math.Pi},
+		{"uniform-hemisphere", func() (Vec3, float64) {
+			d := SampleUniformHemisphere(normal)
+			return d, UniformHemispherePDF()
+		}, 2 * math.Pi},
+		{"cosine-hemisphere", func() (Vec3, float64) {
+			d := SampleCosineHemisphere(normal)
+			return d, CosineHemispherePDF(Dot(d, normal))
+		}, 2 * math.Pi},
+		{"cone", func() (Vec3, float64) {
+			d := SampleUniformCone(normal, cosMax)
+			return d, UniformConePDF(cosMax)
+		}, 2 * math.Pi * (1 - cosMax)},
+		{"ggx-half-vector", func() (Vec3, float64) {
+			h := SampleGGXHalfVector(normal, alpha)
+			return h, GGXHalfVectorPDF(Dot(h, normal), alpha)
+		}, 2 * math.Pi},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := integrateOverPDF(n, tc.sample)
+			if math.Abs(got-tc.want)/tc.want > 0.03 {
+				t.Errorf("E[1/pdf] = %.4f, want %.4f", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSampledDirectionsStayInDomain(t *testing.T) {
+	normal := Vec3{X: -0.2, Y: 0.1, Z: -0.97}.Unit()
+	for i := 0; i < 10000; i++ {
+		for name, d := range map[string]Vec3{
+			"sphere":     SampleUniformSphere(),
+			"hemisphere": SampleUniformHemisphere(normal),
+			"cosine":     SampleCosineHemisphere(normal),
+			"ggx":        SampleGGXHalfVector(normal, 0.3),
+		} {
+			if math.Abs(d.Len()-1) > 1e-9 {
+				t.Fatalf("%s: length %v", name, d.Len())
+			}
+			if name != "sphere" && Dot(d, normal) < -1e-9 {
+				t.Fatalf("%s: direction %v below the surface", name, d)
+			}
+		}
+		if d := SampleUniformCone(normal, 0.9); Dot(d, normal) < 0.9-1e-9 {
+			t.Fatalf("cone: direction %v outside the cone", d)
+		}
+		if p := SampleConcentricDisk(); p.Len2() > 1+1e-9 {
+			t.Fatalf("disk: point %v outside the unit disk", p)
+		}
+		if b1, b2 := SampleTriangleBarycentric(); b1 < 0 || b2 < 0 || b1+b2 > 1+1e-9 {
+			t.Fatalf("triangle: barycentrics (%v, %v) outside the triangle", b1, b2)
+		}
+	}
+}
+
+func TestTrianglePDF(t *testing.T) {
+	got := TrianglePDF(Point3{}, Point3{X: 2}, Point3{Y: 2})
+	if math.Abs(got-0.5) > 1e-12 {
+		t.Errorf("TrianglePDF = %v, want 0.5 (area 2)", got)
+	}
+}
+
+func TestONBIsOrthonormal(t *testing.T) {
+	normals := []Vec3{
+		{X: 0, Y: 0, Z: 1}, {X: 0, Y: 0, Z: -1}, {X: 0, Y: 1, Z: 0},
+		{X: 1e-9, Y: 0, Z: -1}, Vec3{X: 0.3, Y: -0.4, Z: 0.87}.Unit(),
+	}
+	check := func(name string, b ONB) {
+		t.Helper()
+		for _, axis := range []Vec3{b.U, b.V, b.W} {
+			if math.Abs(axis.Len()-1) > 1e-9 {
+				t.Errorf("%s: axis %v is not unit length", name, axis)
+			}
+		}
+		if math.Abs(Dot(b.U, b.V))+math.Abs(Dot(b.V, b.W))+math.Abs(Dot(b.W, b.U)) > 1e-9 {
+			t.Errorf("%s: axes %v are not orthogonal", name, b)
+		}
+		if Dot(Cross(b.U, b.V), b.W) < 0 {
+			t.Errorf("%s: basis %v is left-handed", name, b)
+		}
+		v := Vec3{X: 0.2, Y: -0.7, Z: 0.5}
+		if back := b.ToLocal(b.Local(v)); back.Sub(v).Len() > 1e-9 {
+			t.Errorf("%s: ToLocal(Local(v)) = %v, want %v", name, back, v)
+		}
+	}
+
+	for _, n := range normals {
+		check("NewONB", NewONB(n))
+		// Tangent and up hints parallel to the normal must fall back cleanly
+		check("NewONBFromTangent", NewONBFromTangent(n, n))
+		check("NewONBWithUp", NewONBWithUp(n, n.Scale(2)))
+		check("NewONBWithUp", NewONBWithUp(n, Vec3{X: 0, Y: 1, Z: 0}))
+	}
+
+	if b := NewONBFromTangent(Vec3{Z: 1}, Vec3{X: 1, Z: 1}); b.U.Sub(Vec3{X: 1}).Len() > 1e-9 {
+		t.Errorf("U = %v, want the tangent projected onto the surface (1, 0, 0)", b.U)
+	}
+}
+
+func TestCameraLookingStraightDown(t *testing.T) {
+	camera := NewCameraBuilder().
+		SetResolution(16, 1).
+		SetPosition(Point3{Y: 5}, Point3{}, Vec3{Y: 1}).
+		Build()
+	center := camera.centerRay(8, 8).Direction().Unit()
+	corner := camera.centerRay(0, 0).Direction().Unit()
+	if !isFiniteColor(center) || center.Y >= 0 {
+		t.Errorf("center ray direction = %v, want a finite downward ray", center)
+	}
+	if Dot(center, corner) > 0.999 {
+		t.Errorf("corner ray %v matches the center ray %v: the image plane collapsed", corner, center)
+	}
+}
@@ -0,0 +1,159 @@
+package rt
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// =============================================================================
+// WORKER SCALING BENCHMARK
+// =============================================================================
+
+// ScalingConfig controls RunScalingBenchmark
+type ScalingConfig struct {
+	MaxWorkers int // Highest worker count; counts double from 1 up to it
+	Samples    int // Samples per pixel (0 = the scene's)
+	Width      int // Image width (0 = the scene's)
+	BucketSize int // Bucket size for every run (0 = AutoBucketSize for MaxWorkers)
+}
+
+// DefaultScalingConfig returns runs up to one worker per CPU at 16 samples
+// per pixel
+func DefaultScalingConfig() ScalingConfig {
+	return ScalingConfig{
+		MaxWorkers: runtime.NumCPU(),
+		Samples:    16,
+	}
+}
+
+// ScalingResult is one run of the scaling benchmark
+type ScalingResult struct {
+	Workers    int
+	Wall       time.Duration // Render time, without scene build and BVH construction
+	Samples    int64         // GlobalRenderStats.SamplesComputed of the run
+	Rays       int64         // GlobalRenderStats.RayCount of the run
+	Busy       float64       // Fraction of worker time spent rendering buckets (see PassWorkerStats.Efficiency)
+	Speedup    float64       // Samples per second relative to the first run
+	Efficiency float64       // Speedup / Workers (1 = perfect scaling)
+}
+
+// SamplesPerSecond is the run's sample throughput
+func (r ScalingResult) SamplesPerSecond() float64 {
+	if r.Wall <= 0 {
+		return 0
+	}
+	return float64(r.Samples) / r.Wall.Seconds()
+}
+
+// ScalingWorkerCounts returns 1, 2, 4, ... up to maxWorkers, which is
+// always included
+func ScalingWorkerCounts(maxWorkers int) []int {
+	var counts []int
+	for n := 1; n < maxWorkers; n *= 2 {
+		counts = append(counts, n)
+	}
+	return append(counts, max(1, maxWorkers))
+}
+
+// RunScalingBenchmark builds the scene once and renders it once per worker
+// count of ScalingWorkerCounts, with the same world, BVH and buckets every
+// run (both are read-only while rendering, so scenes laid out with
+// RandomDouble don't change between runs), and reports each run's
+// throughput against the single-worker run.
+//
+// Efficiency well below 1 while Busy stays near 1 means workers are slowed
+// down inside their buckets by shared state (the framebuffer lock, the
+// GlobalRenderStats atomics, memory bandwidth); low Busy means they sit idle
+// waiting for the last buckets of a pass instead.
+func RunScalingBenchmark(build func() (*HittableList, *Camera, error), config ScalingConfig) ([]ScalingResult, error) {
+	if config.MaxWorkers < 1 {
+		return nil, fmt.Errorf("scaling: max workers must be positive, got %d", config.MaxWorkers)
+	}
+	if config.MaxWorkers > runtime.GOMAXPROCS(0) {
+		fmt.Printf("Warning: %d workers on %d CPUs; runs above %d workers can't scale\n",
+			config.MaxWorkers, runtime.GOMAXPROCS(0), runtime.GOMAXPROCS(0))
+	}
+
+	world, camera, err := build()
+	if err != nil {
+		return nil, fmt.Errorf("scaling: %w", err)
+	}
+	if config.Samples > 0 {
+		camera.SamplesPerPixel = config.Samples
+	}
+	if config.Width > 0 {
+		camera.ImageWidth = config.Width
+	}
+	camera.Initialize()
+	bvh := NewBVHNodeFromList(world)
+	bucketSize := config.BucketSize
+	if bucketSize <= 0 {
+		bucketSize = AutoBucketSize(camera.ImageWidth, camera.ImageHeight, config.MaxWorkers)
+	}
+
+	var results []ScalingResult
+	for _, workers := range ScalingWorkerCounts(config.MaxWorkers) {
+		result, err := runScalingPass(camera, bvh, bucketSize, workers)
+		if err != nil {
+			return results, fmt.Errorf("scaling: %d workers: %w", workers, err)
+		}
+		if len(results) > 0 && results[0].SamplesPerSecond() > 0 {
+			result.Speedup = result.SamplesPerSecond() / results[0].SamplesPerSecond()
+		} else {
+			result.Speedup = 1
+		}
+		result.Efficiency = result.Speedup / float64(workers)
+		fmt.Printf("Scaling: %d workers: %s, %.2f M samples/s, %.2fx\n",
+			workers, FormatDuration(result.Wall), result.SamplesPerSecond()/1e6, result.Speedup)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runScalingPass renders the scene with the given workers
+func runScalingPass(camera *Camera, bvh Hittable, bucketSize, workers int) (ScalingResult, error) {
+	renderer := NewBucketRenderer(camera, bvh, bucketSize, workers)
+
+	ResetRenderStats()
+	start := time.Now()
+	if _, err := renderer.RenderToImage(); err != nil {
+		return ScalingResult{}, err
+	}
+	wall := time.Since(start)
+
+	var busy, capacity float64
+	for _, pass := range renderer.WorkerStats() {
+		busy += pass.Efficiency() * float64(pass.Wall) * float64(len(pass.Workers))
+		capacity += float64(pass.Wall) * float64(len(pass.Workers))
+	}
+	result := ScalingResult{
+		Workers: workers,
+		Wall:    wall,
+		Samples: GlobalRenderStats.SamplesComputed.Load(),
+		Rays:    GlobalRenderStats.RayCount.Load(),
+	}
+	if capacity > 0 {
+		result.Busy = busy / capacity
+	}
+	return result, nil
+}
+
+// PrintScalingReport prints a table of the scaling benchmark runs
+func PrintScalingReport(results []ScalingResult) {
+	fmt.Println("========================================")
+	fmt.Println("WORKER SCALING")
+	fmt.Println("========================================")
+	fmt.Printf("%-8s %10s %12s %10s %8s %6s %6s\n", "Workers", "Time", "MSamples/s", "MRays/s", "Speedup", "Eff", "Busy")
+	for _, r := range results {
+		raysPerSecond := 0.0
+		if r.Wall > 0 {
+			raysPerSecond = float64(r.Rays) / r.Wall.Seconds()
+		}
+		fmt.Printf("%-8d %10s %12.3f %10.3f %7.2fx %5.0f%% %5.0f%%\n",
+			r.Workers, FormatDuration(r.Wall), r.SamplesPerSecond()/1e6, raysPerSecond/1e6,
+			r.Speedup, 100*r.Efficiency, 100*r.Busy)
+	}
+	fmt.Println("Eff = speedup / workers; Busy = worker time spent in buckets.")
+	fmt.Println("Low Eff with high Busy points at contention, low Busy at load imbalance.")
+}
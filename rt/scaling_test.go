@@ -0,0 +1,56 @@
+package rt
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScalingWorkerCounts(t *testing.T) {
+	for max, want := range map[int][]int{
+		1: {1},
+		4: {1, 2, 4},
+		6: {1, 2, 4, 6},
+		0: {1},
+	} {
+		if got := ScalingWorkerCounts(max); !slices.Equal(got, want) {
+			t.Errorf("ScalingWorkerCounts(%d) = %v, want %v", max, got, want)
+		}
+	}
+}
+
+func TestRunScalingBenchmark(t *testing.T) {
+	builds := 0
+	build := func() (*HittableList, *Camera, error) {
+		builds++
+		world, camera := renderImageScene()
+		return world, camera, nil
+	}
+	config := ScalingConfig{MaxWorkers: 2, Samples: 2}
+	results, err := RunScalingBenchmark(build, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Workers != 1 || results[1].Workers != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	if builds != 1 {
+		t.Errorf("scene built %d times, want once for all runs", builds)
+	}
+	first := results[0]
+	if first.Speedup != 1 || first.Efficiency != 1 {
+		t.Errorf("single-worker run: speedup %v, efficiency %v; want 1", first.Speedup, first.Efficiency)
+	}
+	// Every run renders the same image, so it counts the same samples
+	for _, r := range results {
+		if r.Samples != results[0].Samples || r.Samples == 0 || r.Rays == 0 || r.Wall <= 0 {
+			t.Errorf("%d workers: %d samples, %d rays in %v", r.Workers, r.Samples, r.Rays, r.Wall)
+		}
+		if r.Busy <= 0 || r.Busy > 1.01 {
+			t.Errorf("%d workers: busy %v", r.Workers, r.Busy)
+		}
+	}
+
+	if _, err := RunScalingBenchmark(build, ScalingConfig{}); err == nil {
+		t.Error("expected an error for zero workers")
+	}
+}
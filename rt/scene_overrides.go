@@ -0,0 +1,279 @@
+package rt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// =============================================================================
+// SCENE OVERRIDE SIDECARS
+// =============================================================================
+
+// SceneOverrides tweaks a built-in scene without code changes. It is read
+// from a JSON sidecar such as cornell.overrides.json; every field is optional
+// and only the fields present are applied. Example:
+//
+//	{
+//	  "camera": {"width": 400, "vfov": 35, "look_from": [278, 278, -700], "background": "brand-blue"},
+//	  "quality": {"samples": 64, "max_depth": 8},
+//	  "light_intensity": 1.5,
+//	  "lights": [2.0]
+//	}
+type SceneOverrides struct {
+	Camera         *CameraOverrides  `json:"camera"`
+	Quality        *QualityOverrides `json:"quality"`
+	LightIntensity *float64          `json:"light_intensity"` // Multiplies every registered light
+	Lights         []float64         `json:"lights"`          // Per-light multipliers in AddLight order
+}
+
+// CameraOverrides replaces camera placement and lens settings
+type CameraOverrides struct {
+	Width        *int        `json:"width"`
+	AspectRatio  *float64    `json:"aspect_ratio"`
+	Vfov         *float64    `json:"vfov"`
+	LookFrom     *[3]float64 `json:"look_from"`
+	LookAt       *[3]float64 `json:"look_at"`
+	Vup          *[3]float64 `json:"vup"`
+	DefocusAngle *float64    `json:"defocus_angle"`
+	FocusDist    *float64    `json:"focus_dist"`
+	Background   *ColorRef   `json:"background"`   // Linear [r, g, b], palette name or "#rrggbb"
+	FocalLength  *float64    `json:"focal_length"` // mm; replaces vfov
+	Sensor       *string     `json:"sensor"`       // Film back name or "WxH" in mm
+	FStop        *float64    `json:"f_stop"`       // With focal_length; replaces defocus_angle
+	NearClip     *float64    `json:"near_clip"`    // Camera rays skip geometry closer than this
+}
+
+// QualityOverrides replaces the sampling settings
+type QualityOverrides struct {
+	Samples  *int `json:"samples"`
+	MaxDepth *int `json:"max_depth"`
+}
+
+// SceneOverridesPath returns the sidecar file name for a scene,
+// e.g. "cornell" -> "cornell.overrides.json"
+func SceneOverridesPath(scene string) string {
+	return strings.ToLower(scene) + ".overrides.json"
+}
+
+// ParseSceneOverrides decodes a sidecar. Unknown keys are rejected so typos
+// don't silently do nothing.
+func ParseSceneOverrides(r io.Reader) (*SceneOverrides, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	overrides := &SceneOverrides{}
+	if err := decoder.Decode(overrides); err != nil {
+		return nil, err
+	}
+	return overrides, overrides.validate()
+}
+
+// LoadSceneOverrides reads a sidecar file. A missing file returns nil
+// overrides and no error.
+func LoadSceneOverrides(filename string) (*SceneOverrides, error) {
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	overrides, err := ParseSceneOverrides(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return overrides, nil
+}
+
+func (o *SceneOverrides) validate() error {
+	if c := o.Camera; c != nil {
+		if c.Width != nil && *c.Width <= 0 {
+			return fmt.Errorf("camera.width must be positive, got %d", *c.Width)
+		}
+		if c.AspectRatio != nil && *c.AspectRatio <= 0 {
+			return fmt.Errorf("camera.aspect_ratio must be positive, got %g", *c.AspectRatio)
+		}
+		if c.Vfov != nil && (*c.Vfov <= 0 || *c.Vfov >= 180) {
+			return fmt.Errorf("camera.vfov must be in (0, 180), got %g", *c.Vfov)
+		}
+		if c.FocusDist != nil && *c.FocusDist <= 0 {
+			return fmt.Errorf("camera.focus_dist must be positive, got %g", *c.FocusDist)
+		}
+		if c.FocalLength != nil && *c.FocalLength <= 0 {
+			return fmt.Errorf("camera.focal_length must be positive, got %g", *c.FocalLength)
+		}
+		if c.Sensor != nil {
+			if _, err := ParseFilmBack(*c.Sensor); err != nil {
+				return fmt.Errorf("camera.sensor: %w", err)
+			}
+		}
+		if c.FStop != nil && *c.FStop <= 0 {
+			return fmt.Errorf("camera.f_stop must be positive, got %g", *c.FStop)
+		}
+		if c.NearClip != nil && *c.NearClip < 0 {
+			return fmt.Errorf("camera.near_clip must not be negative, got %g", *c.NearClip)
+		}
+		if c.Background != nil {
+			if _, err := c.Background.Resolve(); err != nil {
+				return fmt.Errorf("camera.background: %w", err)
+			}
+		}
+	}
+	if q := o.Quality; q != nil {
+		if q.Samples != nil && *q.Samples <= 0 {
+			return fmt.Errorf("quality.samples must be positive, got %d", *q.Samples)
+		}
+		if q.MaxDepth != nil && *q.MaxDepth <= 0 {
+			return fmt.Errorf("quality.max_depth must be positive, got %d", *q.MaxDepth)
+		}
+	}
+	if o.LightIntensity != nil && *o.LightIntensity < 0 {
+		return fmt.Errorf("light_intensity must not be negative, got %g", *o.LightIntensity)
+	}
+	for i, scale := range o.Lights {
+		if scale < 0 {
+			return fmt.Errorf("lights[%d] must not be negative, got %g", i, scale)
+		}
+	}
+	return nil
+}
+
+// Apply updates the camera and its registered lights, then re-initializes
+// the camera. Area lights are scaled through their emissive material, so
+// lights sharing a material must get the same multiplier.
+func (o *SceneOverrides) Apply(camera *Camera) error {
+	if len(o.Lights) > len(camera.Lights) {
+		return fmt.Errorf("overrides list %d lights but the scene has %d", len(o.Lights), len(camera.Lights))
+	}
+	// Palette names resolve against the palette in use now
+	background := camera.Background
+	if c := o.Camera; c != nil && c.Background != nil {
+		var err error
+		if background, err = c.Background.Resolve(); err != nil {
+			return fmt.Errorf("camera.background: %w", err)
+		}
+	}
+
+	if c := o.Camera; c != nil {
+		setIf(&camera.ImageWidth, c.Width)
+		setIf(&camera.AspectRatio, c.AspectRatio)
+		setIf(&camera.Vfov, c.Vfov)
+		setVec3If(&camera.LookFrom, c.LookFrom)
+		setVec3If(&camera.LookAt, c.LookAt)
+		setVec3If(&camera.Vup, c.Vup)
+		setIf(&camera.DefocusAngle, c.DefocusAngle)
+		setIf(&camera.FocusDist, c.FocusDist)
+		camera.Background = background
+		setIf(&camera.FocalLength, c.FocalLength)
+		if c.Sensor != nil {
+			camera.FilmBack, _ = ParseFilmBack(*c.Sensor)
+		}
+		if c.FStop != nil {
+			camera.SetFStop(*c.FStop)
+		}
+		setIf(&camera.NearClip, c.NearClip)
+	}
+	if q := o.Quality; q != nil {
+		setIf(&camera.SamplesPerPixel, q.Samples)
+		setIf(&camera.MaxDepth, q.MaxDepth)
+	}
+
+	// Resolve the multiplier of every light first so a shared material is
+	// scaled once and conflicting multipliers are caught before any change
+	spots := make(map[*SpotLight]float64)
+	emitters := make(map[*DiffuseLight]float64)
+	var order []*DiffuseLight
+	for i, light := range camera.Lights {
+		scale := 1.0
+		if o.LightIntensity != nil {
+			scale *= *o.LightIntensity
+		}
+		if i < len(o.Lights) {
+			scale *= o.Lights[i]
+		}
+
+		if spot, ok := light.(*SpotLight); ok {
+			spots[spot] = scale
+			continue
+		}
+		emitter := lightEmitter(light)
+		if emitter == nil {
+			if scale != 1 {
+				return fmt.Errorf("light %d: can't scale a %s light", i, primitiveName(light))
+			}
+			continue
+		}
+		if previous, seen := emitters[emitter]; seen {
+			if previous != scale {
+				return fmt.Errorf("light %d shares its material with another light but has a different multiplier (%g vs %g)", i, scale, previous)
+			}
+			continue
+		}
+		emitters[emitter] = scale
+		order = append(order, emitter)
+	}
+
+	for spot, scale := range spots {
+		spot.Intensity = spot.Intensity.Scale(scale)
+	}
+	for _, emitter := range order {
+		if scale := emitters[emitter]; scale != 1 {
+			emitter.ScaleIntensity(scale)
+		}
+	}
+
+	camera.Initialize()
+	return nil
+}
+
+func setIf[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+func setVec3If(field *Vec3, value *[3]float64) {
+	if value != nil {
+		*field = Vec3{X: value[0], Y: value[1], Z: value[2]}
+	}
+}
+
+// lightEmitter returns the emissive material of an area light, nil if it
+// has none
+func lightEmitter(light Hittable) *DiffuseLight {
+	var mat Material
+	switch l := light.(type) {
+	case *Quad:
+		mat = l.mat
+	case *Sphere:
+		mat = l.Mat
+	case *Triangle:
+		mat = l.mat
+	case *Circle:
+		mat = l.mat
+	case PrimitiveInfo:
+		mat = l.PrimitiveMaterial()
+	}
+	emitter, _ := mat.(*DiffuseLight)
+	return emitter
+}
+
+// ApplySceneOverridesFile applies the sidecar for a scene if one exists and
+// reports whether it did
+func ApplySceneOverridesFile(scene string, camera *Camera) (bool, error) {
+	path := SceneOverridesPath(scene)
+	overrides, err := LoadSceneOverrides(path)
+	if err != nil || overrides == nil {
+		return false, err
+	}
+	if err := overrides.Apply(camera); err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	fmt.Printf("Applied scene overrides from %s\n", path)
+	return true, nil
+}
@@ -0,0 +1,65 @@
+package rt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSceneOverridesApplyToBuiltinScene(t *testing.T) {
+	_, camera := CornellBoxScene()
+	light := camera.Lights[0].(*Quad)
+	before := light.mat.Emitted(0, 0, Point3{})
+
+	overrides, err := ParseSceneOverrides(strings.NewReader(`{
+		"camera": {"width": 120, "vfov": 30, "look_from": [278, 278, -600]},
+		"quality": {"samples": 8},
+		"light_intensity": 2,
+		"lights": [1.5]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := overrides.Apply(camera); err != nil {
+		t.Fatal(err)
+	}
+
+	if camera.ImageWidth != 120 || camera.ImageHeight != 120 || camera.Vfov != 30 || camera.SamplesPerPixel != 8 {
+		t.Errorf("camera = %dx%d vfov %g spp %d, want 120x120 vfov 30 spp 8",
+			camera.ImageWidth, camera.ImageHeight, camera.Vfov, camera.SamplesPerPixel)
+	}
+	if camera.LookFrom != (Point3{X: 278, Y: 278, Z: -600}) || camera.MaxDepth != 5 {
+		t.Errorf("look_from = %v, max depth = %d; want the override and the scene's own depth", camera.LookFrom, camera.MaxDepth)
+	}
+	if got, want := light.mat.Emitted(0, 0, Point3{}), before.Scale(3); got.Sub(want).Len() > 1e-9 {
+		t.Errorf("light emission = %v, want %v", got, want)
+	}
+}
+
+func TestSceneOverridesRejectBadFiles(t *testing.T) {
+	for name, body := range map[string]string{
+		"typo":     `{"camera": {"widht": 100}}`,
+		"negative": `{"quality": {"samples": -1}}`,
+	} {
+		if _, err := ParseSceneOverrides(strings.NewReader(body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	_, camera := CornellBoxScene()
+	overrides, _ := ParseSceneOverrides(strings.NewReader(`{"lights": [1, 2]}`))
+	if err := overrides.Apply(camera); err == nil {
+		t.Error("expected an error for more light multipliers than lights")
+	}
+
+	// A missing sidecar is not an error
+	if o, err := LoadSceneOverrides(filepath.Join(t.TempDir(), "none.overrides.json")); o != nil || err != nil {
+		t.Errorf("missing sidecar = %v, %v; want nil, nil", o, err)
+	}
+	path := filepath.Join(t.TempDir(), "bad.overrides.json")
+	os.WriteFile(path, []byte(`{`), 0o644)
+	if _, err := LoadSceneOverrides(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("malformed sidecar error = %v, want one naming the file", err)
+	}
+}
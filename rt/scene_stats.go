@@ -0,0 +1,241 @@
+package rt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// =============================================================================
+// SCENE STATISTICS
+// =============================================================================
+
+// SceneStats summarizes a scene before rendering to help estimate its cost.
+// Instanced geometry (the same BVH under several transforms) is counted once
+// per instance, since each instance is traced separately.
+type SceneStats struct {
+	Primitives     map[string]int // Leaf primitive counts by type name
+	Triangles      int
+	Lights         int   // Lights sampled directly (Camera.Lights)
+	EmissiveCount  int   // Primitives with an emissive material
+	TextureBytes   int64 // Memory held by distinct image textures
+	BVHDepth       int   // Longest chain of BVH nodes, including nested mesh BVHs
+	BVHNodes       int   // Distinct BVH nodes
+	EnvWidth       int
+	EnvHeight      int
+	EnvBytes       int64
+	distinctImages map[*ImageLoader]bool
+	subtrees       map[*BVHNode]sceneSubtree
+	quantized      map[*QuantizedBVH]sceneSubtree
+}
+
+// sceneSubtree is the memoized summary of a shared BVH subtree
+type sceneSubtree struct {
+	primitives map[string]int
+	emissive   int
+	depth      int
+}
+
+// CollectSceneStats walks the world graph and the camera's lights and environment
+func CollectSceneStats(world Hittable, camera *Camera) SceneStats {
+	stats := SceneStats{
+		distinctImages: make(map[*ImageLoader]bool),
+		subtrees:       make(map[*BVHNode]sceneSubtree),
+		quantized:      make(map[*QuantizedBVH]sceneSubtree),
+	}
+
+	summary := stats.walk(world)
+	stats.Primitives = summary.primitives
+	stats.Triangles = summary.primitives["Triangle"]
+	stats.EmissiveCount = summary.emissive
+	stats.BVHDepth = summary.depth
+	stats.BVHNodes = len(stats.subtrees)
+	for q := range stats.quantized {
+		interior, leaves := q.Nodes()
+		stats.BVHNodes += interior + leaves
+	}
+
+	if camera != nil {
+		stats.Lights = len(camera.Lights)
+		if env := camera.Environment; env != nil && env.IsValid() {
+			stats.EnvWidth, stats.EnvHeight = env.width, env.height
+			stats.EnvBytes = imageBytes(env.image)
+		}
+	}
+	return stats
+}
+
+func (s *SceneStats) walk(object Hittable) sceneSubtree {
+	switch obj := object.(type) {
+	case *BVHNode:
+		if cached, ok := s.subtrees[obj]; ok {
+			return cached
+		}
+		var summary sceneSubtree
+		if obj.left == obj.right {
+			// Leaf wrapper: both children point at the same BVHLeaf
+			summary = s.walk(obj.left)
+			summary.depth++
+		} else {
+			left, right := s.walk(obj.left), s.walk(obj.right)
+			summary = mergeSubtrees(left, right)
+			summary.depth = 1 + max(left.depth, right.depth)
+		}
+		s.subtrees[obj] = summary
+		return summary
+	case *QuantizedBVH:
+		if cached, ok := s.quantized[obj]; ok {
+			return cached
+		}
+		summary := sceneSubtree{primitives: map[string]int{}}
+		leafDepth := 0
+		for _, leaf := range obj.leaves {
+			child := s.walk(leaf)
+			leafDepth = max(leafDepth, child.depth)
+			summary = mergeSubtrees(summary, child)
+		}
+		summary.depth = obj.depth() + leafDepth
+		s.quantized[obj] = summary
+		return summary
+	case *BVHLeaf:
+		return s.walkAll(obj.objects)
+	case *HittableList:
+		return s.walkAll(obj.Objects)
+	case *MaterialOverride:
+		s.addMaterial(obj.Mat)
+		return s.walk(obj.Obj)
+	case *Translate:
+		return s.walk(obj.Obj)
+	case *Rotate:
+		return s.walk(obj.Obj)
+	case *RotateX:
+		return s.walk(obj.Obj)
+	case *RotateY:
+		return s.walk(obj.Obj)
+	case *RotateZ:
+		return s.walk(obj.Obj)
+	case *Scale:
+		return s.walk(obj.Obj)
+	case *Volume:
+		s.addMaterial(obj.phaseFunction)
+		summary := s.walk(obj.boundary)
+		summary.primitives = map[string]int{"Volume": 1}
+		return summary
+	case HittableContainer:
+		return s.walkAll(obj.Children())
+	}
+
+	var mat Material
+	switch obj := object.(type) {
+	case *Sphere:
+		mat = obj.Mat
+	case *Quad:
+		mat = obj.mat
+	case *Triangle:
+		mat = obj.mat
+	case *Plane:
+		mat = obj.Mat
+	case *Circle:
+		mat = obj.mat
+	case PrimitiveInfo:
+		mat = obj.PrimitiveMaterial()
+	}
+	s.addMaterial(mat)
+
+	summary := sceneSubtree{primitives: map[string]int{primitiveName(object): 1}}
+	if info, ok := mat.(MaterialInfo); ok && info.Properties().isEmissive {
+		summary.emissive = 1
+	}
+	return summary
+}
+
+func (s *SceneStats) walkAll(objects []Hittable) sceneSubtree {
+	summary := sceneSubtree{primitives: map[string]int{}}
+	for _, obj := range objects {
+		child := s.walk(obj)
+		depth := max(summary.depth, child.depth)
+		summary = mergeSubtrees(summary, child)
+		summary.depth = depth
+	}
+	return summary
+}
+
+func mergeSubtrees(a, b sceneSubtree) sceneSubtree {
+	merged := sceneSubtree{primitives: make(map[string]int, len(a.primitives)+len(b.primitives)), emissive: a.emissive + b.emissive}
+	for name, n := range a.primitives {
+		merged.primitives[name] += n
+	}
+	for name, n := range b.primitives {
+		merged.primitives[name] += n
+	}
+	return merged
+}
+
+// addMaterial records the image textures a material references
+func (s *SceneStats) addMaterial(mat Material) {
+	switch m := mat.(type) {
+	case *Lambertian:
+		s.addTexture(m.tex)
+	case *DiffuseLight:
+		s.addTexture(m.tex)
+	case *Isotropic:
+		s.addTexture(m.tex)
+	case *DetailMaterial:
+		s.addMaterial(m.Mat)
+	}
+}
+
+func (s *SceneStats) addTexture(tex Texture) {
+	switch t := tex.(type) {
+	case *ImageTexture:
+		for _, img := range append(t.udimImages(), t.image) {
+			if img != nil && !s.distinctImages[img] {
+				s.distinctImages[img] = true
+				s.TextureBytes += imageBytes(img)
+			}
+		}
+	case *CheckerTexture:
+		s.addTexture(t.even)
+		s.addTexture(t.odd)
+	}
+}
+
+func imageBytes(img *ImageLoader) int64 {
+	if img == nil {
+		return 0
+	}
+	return int64(len(img.data)) * int64(unsafe.Sizeof(Color{}))
+}
+
+func primitiveName(object Hittable) string {
+	if info, ok := object.(PrimitiveInfo); ok {
+		return info.PrimitiveName()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", object), "*rt.")
+}
+
+// Print writes the summary in the PrintRenderSettings layout
+func (s SceneStats) Print() {
+	names := make([]string, 0, len(s.Primitives))
+	total := 0
+	for name, n := range s.Primitives {
+		names = append(names, name)
+		total += n
+	}
+	sort.Strings(names)
+
+	fmt.Printf("Primitives:         %d\n", total)
+	for _, name := range names {
+		fmt.Printf("  %-18s%d\n", name+":", s.Primitives[name])
+	}
+	fmt.Printf("Triangles:          %d\n", s.Triangles)
+	fmt.Printf("Lights:             %d sampled, %d emissive primitives\n", s.Lights, s.EmissiveCount)
+	fmt.Printf("Texture Memory:     %s (%d images)\n", formatBytes(uint64(s.TextureBytes)), len(s.distinctImages))
+	fmt.Printf("BVH Depth:          %d (%d nodes)\n", s.BVHDepth, s.BVHNodes)
+	if s.EnvWidth > 0 {
+		fmt.Printf("Environment:        %dx%d HDRI (%s)\n", s.EnvWidth, s.EnvHeight, formatBytes(uint64(s.EnvBytes)))
+	} else {
+		fmt.Printf("Environment:        none\n")
+	}
+}
@@ -0,0 +1,28 @@
+package rt
+
+import "testing"
+
+func TestCollectSceneStatsCountsInstances(t *testing.T) {
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	triangles := []Hittable{
+		NewTriangle(Point3{X: 0}, Point3{X: 1}, Point3{Y: 1}, mat),
+		NewTriangle(Point3{X: 1}, Point3{X: 1, Y: 1}, Point3{Y: 1}, mat),
+	}
+	mesh := NewBVHNode(triangles, 0, len(triangles))
+
+	world := NewHittableList()
+	world.Add(mesh)
+	world.Add(NewTranslate(mesh, Vec3{X: 3}))
+	world.Add(NewSphere(Point3{Z: -2}, 0.5, NewDiffuseLightColor(Color{X: 4, Y: 4, Z: 4})))
+
+	stats := CollectSceneStats(NewBVHNodeFromList(world), nil)
+	if stats.Triangles != 4 {
+		t.Errorf("Triangles = %d, want 4 (2 per instance)", stats.Triangles)
+	}
+	if stats.Primitives["Sphere"] != 1 || stats.EmissiveCount != 1 {
+		t.Errorf("Spheres = %d, emissive = %d, want 1 and 1", stats.Primitives["Sphere"], stats.EmissiveCount)
+	}
+	if stats.BVHDepth < 2 {
+		t.Errorf("BVHDepth = %d, want nested mesh BVH counted", stats.BVHDepth)
+	}
+}
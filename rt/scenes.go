@@ -213,7 +213,7 @@ func EarthScene() (*HittableList, *Camera) {
 	// =============================================================================
 	// MATERIALS
 	// =============================================================================
-	earthTexture := NewImageTexture("earthmap.jpg")
+	earthTexture := NewImageTexture("earthmap.jpg").SetFilter(TextureFilterSpherical)
 	earthSurface := NewLambertianTexture(earthTexture)
 
 	// =============================================================================
@@ -463,9 +463,9 @@ func HDRITestScene() (*HittableList, *Camera) {
 func CornellBoxScene() (*HittableList, *Camera) {
 	world := NewHittableList()
 
-	whiteMat := NewLambertian(Color{X: 0.73, Y: 0.73, Z: 0.73})
-	redMat := NewLambertian(Color{X: 0.65, Y: 0.05, Z: 0.05})
-	greenMat := NewLambertian(Color{X: 0.12, Y: 0.45, Z: 0.15})
+	whiteMat := NewLambertian(NamedColor("cornell-white"))
+	redMat := NewLambertian(NamedColor("cornell-red"))
+	greenMat := NewLambertian(NamedColor("cornell-green"))
 	lightMat := NewDiffuseLight(NewSolidColor(Color{X: 3, Y: 3, Z: 3}))
 
 	areaLight := NewQuad(
@@ -543,7 +543,7 @@ func CornellBoxScene() (*HittableList, *Camera) {
 		Point3{X: 555, Y: 555, Z: 555},
 		whiteMat, // Material doesn't matter for the boundary, it's just for the volume
 	)
-	world.Add(NewVolumeFromColor(fogBoundary, 0.001, Color{X: 1, Y: 1, Z: 1}))
+	world.Add(NewVolumeFromColor(fogBoundary, NewSceneUnits(UnitsMillimeters).Density(1), Color{X: 1, Y: 1, Z: 1}))
 
 	camera := NewCameraBuilder().
 		SetResolution(600, 1.0).
@@ -556,6 +556,7 @@ func CornellBoxScene() (*HittableList, *Camera) {
 		SetLens(40, 0, 10).
 		SetBackground(Color{0, 0, 0}).
 		AddLight(areaLight).
+		SetUnits(NewSceneUnits(UnitsMillimeters)).
 		Build()
 
 	return world, camera
@@ -609,9 +610,9 @@ func CornellBoxGlossy() (*HittableList, *Camera) {
 	// =============================================================================
 	// MATERIALS
 	// =============================================================================
-	whiteMat := NewLambertian(Color{X: 0.73, Y: 0.73, Z: 0.73})
-	redMat := NewLambertian(Color{X: 0.65, Y: 0.05, Z: 0.05})
-	greenMat := NewLambertian(Color{X: 0.12, Y: 0.45, Z: 0.15})
+	whiteMat := NewLambertian(NamedColor("cornell-white"))
+	redMat := NewLambertian(NamedColor("cornell-red"))
+	greenMat := NewLambertian(NamedColor("cornell-green"))
 
 	// Glossy metals with different roughness
 	goldShiny := NewMetal(Color{X: 1.0, Y: 0.84, Z: 0.0}, 0.05)     // Polished gold
@@ -705,6 +706,7 @@ func CornellBoxGlossy() (*HittableList, *Camera) {
 		SetLens(40, 0, 10).
 		SetBackground(BackgroundBlack).
 		AddLight(areaLight).
+		SetUnits(NewSceneUnits(UnitsMillimeters)).
 		Build()
 
 	return world, camera
@@ -714,9 +716,9 @@ func CornellBoxGlossy() (*HittableList, *Camera) {
 func CornellBoxLucy() (*HittableList, *Camera) {
 	world := NewHittableList()
 
-	whiteMat := NewLambertian(Color{X: 0.73, Y: 0.73, Z: 0.73})
-	redMat := NewLambertian(Color{X: 0.65, Y: 0.05, Z: 0.05})
-	greenMat := NewLambertian(Color{X: 0.12, Y: 0.45, Z: 0.15})
+	whiteMat := NewLambertian(NamedColor("cornell-white"))
+	redMat := NewLambertian(NamedColor("cornell-red"))
+	greenMat := NewLambertian(NamedColor("cornell-green"))
 	lightMat := NewDiffuseLight(NewSolidColor(Color{X: 15, Y: 15, Z: 15}))
 
 	areaLight := NewQuad(
@@ -772,29 +774,35 @@ func CornellBoxLucy() (*HittableList, *Camera) {
 		panic(err)
 	}
 
+	// Per-instance materials (the shared mesh keeps lucyMat)
+	gold := NewMetal(Color{X: 0.83, Y: 0.69, Z: 0.22}, 0.15)
+	glass := NewDielectric(1.5)
+
 	// Create 10 angel instances in a grid pattern using transform wrappers
 	positions := []struct {
 		pos Vec3
 		rot float64
+		mat Material // nil keeps the mesh material
 	}{
-		{Vec3{X: 150, Y: 0, Z: 150}, 45},
-		{Vec3{X: 400, Y: 0, Z: 150}, 315},
-		{Vec3{X: 150, Y: 0, Z: 400}, 135},
-		{Vec3{X: 400, Y: 0, Z: 400}, 225},
-		{Vec3{X: 278, Y: 0, Z: 278}, 0},
-		{Vec3{X: 100, Y: 0, Z: 278}, 90},
-		{Vec3{X: 450, Y: 0, Z: 278}, 270},
-		{Vec3{X: 278, Y: 0, Z: 100}, 180},
-		{Vec3{X: 278, Y: 0, Z: 450}, 0},
-		{Vec3{X: 200, Y: 0, Z: 350}, 60},
+		{Vec3{X: 150, Y: 0, Z: 150}, 45, NewLambertian(Color{X: 0.8, Y: 0.3, Z: 0.3})},
+		{Vec3{X: 400, Y: 0, Z: 150}, 315, NewLambertian(Color{X: 0.3, Y: 0.8, Z: 0.3})},
+		{Vec3{X: 150, Y: 0, Z: 400}, 135, NewLambertian(Color{X: 0.3, Y: 0.3, Z: 0.8})},
+		{Vec3{X: 400, Y: 0, Z: 400}, 225, NewLambertian(Color{X: 0.8, Y: 0.8, Z: 0.3})},
+		{Vec3{X: 278, Y: 0, Z: 278}, 0, gold},
+		{Vec3{X: 100, Y: 0, Z: 278}, 90, NewLambertian(Color{X: 0.8, Y: 0.3, Z: 0.8})},
+		{Vec3{X: 450, Y: 0, Z: 278}, 270, NewLambertian(Color{X: 0.3, Y: 0.8, Z: 0.8})},
+		{Vec3{X: 278, Y: 0, Z: 100}, 180, glass},
+		{Vec3{X: 278, Y: 0, Z: 450}, 0, nil},
+		{Vec3{X: 200, Y: 0, Z: 350}, 60, NewMetal(Color{X: 0.9, Y: 0.9, Z: 0.9}, 0.05)},
 	}
 
-	// Reuse the same mesh with different transforms (10x faster loading)
+	// Reuse the same mesh with different transforms and materials (10x faster loading)
 	for _, inst := range positions {
 		lucyInstance := NewTransform().
 			SetScale(Vec3{X: scale, Y: scale, Z: scale}).
 			SetRotationY(inst.rot).
 			SetPosition(inst.pos).
+			SetMaterial(inst.mat).
 			Apply(lucyMesh)
 
 		world.Add(lucyInstance)
@@ -811,6 +819,7 @@ func CornellBoxLucy() (*HittableList, *Camera) {
 		SetLens(40, 0, 10).
 		SetBackground(Color{0, 0, 0}).
 		AddLight(areaLight).
+		SetUnits(NewSceneUnits(UnitsMillimeters)).
 		Build()
 
 	return world, camera
@@ -823,9 +832,9 @@ func CornellSmoke() (*HittableList, *Camera) {
 	// =============================================================================
 	// MATERIALS
 	// =============================================================================
-	whiteMat := NewLambertian(Color{X: 0.73, Y: 0.73, Z: 0.73})
-	redMat := NewLambertian(Color{X: 0.65, Y: 0.05, Z: 0.05})
-	greenMat := NewLambertian(Color{X: 0.12, Y: 0.45, Z: 0.15})
+	whiteMat := NewLambertian(NamedColor("cornell-white"))
+	redMat := NewLambertian(NamedColor("cornell-red"))
+	greenMat := NewLambertian(NamedColor("cornell-green"))
 	lightMat := NewDiffuseLight(NewSolidColor(Color{X: 3, Y: 3, Z: 3}))
 
 	// =============================================================================
@@ -919,7 +928,181 @@ func CornellSmoke() (*HittableList, *Camera) {
 		SetLens(40, 0, 10).
 		SetBackground(Color{X: 0, Y: 0, Z: 0}).
 		AddLight(areaLight).
+		SetUnits(NewSceneUnits(UnitsMillimeters)).
+		Build()
+
+	return world, camera
+}
+
+// ==================================================================================
+// Atmosphere (Sunset) Scene
+// ==================================================================================
+
+// SunsetScene shows spheres on a ground plane under a low sun, lit only by
+// the ray-marched atmosphere
+func SunsetScene() (*HittableList, *Camera) {
+	world := NewHittableList()
+
+	groundMat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	world.Add(NewPlane(Point3{X: 0, Y: 0, Z: 0}, Vec3{X: 0, Y: 1, Z: 0}, groundMat))
+
+	world.Add(NewSphere(Point3{X: -2.2, Y: 1, Z: 0}, 1.0, NewLambertian(Color{X: 0.8, Y: 0.8, Z: 0.8})))
+	world.Add(NewSphere(Point3{X: 0, Y: 1, Z: 0}, 1.0, NewDielectric(1.5)))
+	world.Add(NewSphere(Point3{X: 2.2, Y: 1, Z: 0}, 1.0, NewMetal(Color{X: 0.9, Y: 0.9, Z: 0.9}, 0.05)))
+
+	sky := DefaultAtmosphereConfig()
+	sky.SunElevation = 4
+	sky.SunAzimuth = -60
+
+	camera := NewCameraBuilder().
+		SetResolution(800, 16.0/9.0).
+		SetQuality(100, 20).
+		SetPosition(
+			Point3{X: 0, Y: 1.5, Z: 8},
+			Point3{X: 0, Y: 1.5, Z: 0},
+			Vec3{X: 0, Y: 1, Z: 0},
+		).
+		SetLens(50, 0, 8).
+		SetAtmosphere(sky).
+		Build()
+
+	return world, camera
+}
+
+// ==================================================================================
+// Spot Light Gobo Scene
+// ==================================================================================
+
+// GoboScene lights a small set with a spot light projecting window blinds and
+// a point light masked by a noise gobo that fakes dappled foliage
+func GoboScene() (*HittableList, *Camera) {
+	world := NewHittableList()
+
+	white := NewLambertian(Color{X: 0.75, Y: 0.75, Z: 0.75})
+	world.Add(NewPlane(Point3{X: 0, Y: 0, Z: 0}, Vec3{X: 0, Y: 1, Z: 0}, white))
+	world.Add(NewPlane(Point3{X: 0, Y: 0, Z: -3}, Vec3{X: 0, Y: 0, Z: 1}, white))
+	world.Add(NewSphere(Point3{X: -1.2, Y: 0.8, Z: -0.5}, 0.8, NewLambertian(Color{X: 0.7, Y: 0.3, Z: 0.2})))
+	world.Add(NewSphere(Point3{X: 1.2, Y: 0.6, Z: 0.3}, 0.6, NewLambertian(Color{X: 0.2, Y: 0.4, Z: 0.7})))
+
+	blinds := NewStripeTexture(12, 0.6,
+		NewSolidColor(Color{X: 1, Y: 1, Z: 1}),
+		NewSolidColor(Color{X: 0, Y: 0, Z: 0}))
+	window := NewSpotLight(
+		Point3{X: -4, Y: 5, Z: 4}, Point3{X: 0.5, Y: 0.5, Z: -2},
+		Color{X: 60, Y: 55, Z: 45}, 14, 18,
+	).SetGobo(blinds)
+
+	leaves := NewPointLight(Point3{X: 3, Y: 4, Z: 2}, Color{X: 4, Y: 6, Z: 4}).
+		SetGobo(NewNoiseTexture(12))
+
+	camera := NewCameraBuilder().
+		SetResolution(600, 16.0/9.0).
+		SetQuality(100, 10).
+		SetPosition(
+			Point3{X: 0, Y: 2, Z: 7},
+			Point3{X: 0, Y: 0.8, Z: 0},
+			Vec3{X: 0, Y: 1, Z: 0},
+		).
+		SetLens(40, 0, 7).
+		SetBackground(Color{X: 0.02, Y: 0.02, Z: 0.03}).
+		AddLight(window).
+		AddLight(leaves).
 		Build()
 
 	return world, camera
 }
+
+// ==================================================================================
+// Gem Scene
+// ==================================================================================
+
+// GemScene shows a diamond, a cubic zirconia and a moissanite standing on
+// their culets under small overhead lights, which bring out the fire
+func GemScene() (*HittableList, *Camera) {
+	world := NewHittableList()
+
+	floor := NewLambertian(Color{X: 0.05, Y: 0.05, Z: 0.06})
+	world.Add(NewPlane(Point3{X: 0, Y: 0, Z: 0}, Vec3{X: 0, Y: 1, Z: 0}, floor))
+
+	// A stone of girdle radius r stands with its girdle this far above its culet
+	standing := (gemPavilion + gemGirdle/2) * 2
+	world.Add(Gem(Point3{X: 0, Y: standing, Z: 0}, 1, 16, NewGem(GemDiamond)))
+	world.Add(Gem(Point3{X: -2.2, Y: 0.6 * standing, Z: 0.6}, 0.6, 16, NewGem(GemCubicZirconia)))
+	world.Add(Gem(Point3{X: 2.2, Y: 0.6 * standing, Z: 0.6}, 0.6, 16, NewGem(GemMoissanite)))
+
+	camera := NewCameraBuilder().
+		SetResolution(800, 16.0/9.0).
+		SetQuality(200, 12).
+		SetPosition(
+			Point3{X: 0, Y: 4, Z: 6},
+			Point3{X: 0, Y: 0.6, Z: 0},
+			Vec3{X: 0, Y: 1, Z: 0},
+		).
+		SetLens(40, 0, 7).
+		SetBackground(Color{X: 0.01, Y: 0.01, Z: 0.01}).
+		Build()
+
+	lightMat := NewDiffuseLightColor(Color{X: 40, Y: 40, Z: 40})
+	for _, corner := range []Point3{{X: -3, Y: 6, Z: -1}, {X: 2, Y: 7, Z: 2}, {X: 0.5, Y: 5, Z: -3}} {
+		light := NewQuad(corner, Vec3{X: 0.5, Y: 0, Z: 0}, Vec3{X: 0, Y: 0, Z: 0.5}, lightMat)
+		world.Add(light)
+		camera.AddLight(light)
+	}
+
+	return world, camera
+}
+
+// ==================================================================================
+// Fur Ball Scene
+// ==================================================================================
+
+// FurBallScene shows a sphere covered in light-brown hair strands, drooping under
+// gravity, with a key light behind and above it for the rim highlights
+func FurBallScene() (*HittableList, *Camera) {
+	world := NewHittableList()
+
+	floor := NewLambertian(Color{X: 0.4, Y: 0.4, Z: 0.4})
+	world.Add(NewPlane(Point3{X: 0, Y: 0, Z: 0}, Vec3{X: 0, Y: 1, Z: 0}, floor))
+
+	center, radius := Point3{X: 0, Y: 1.2, Z: 0}, 0.8
+	hair := NewHair(0.8, 0.3)
+	world.Add(NewSphere(center, radius, NewLambertian(Color{X: 0.2, Y: 0.12, Z: 0.07})))
+
+	const strands, segments, length = 5000, 4, 0.35
+	for range strands {
+		root := RandomUnitVector()
+		if root.Y < -0.6 {
+			continue // Hidden against the floor
+		}
+		points := make([]Point3, segments+1)
+		p, dir := center.Add(root.Scale(radius*0.98)), root
+		for i := range points {
+			points[i] = p
+			dir = dir.Add(Vec3{Y: -0.35}).Unit()
+			p = p.Add(dir.Scale(length / segments))
+		}
+		world.Add(HairStrand(points, 0.006, 5, hair))
+	}
+
+	camera := NewCameraBuilder().
+		SetResolution(800, 1.0).
+		SetQuality(128, 32).
+		SetPosition(
+			Point3{X: 0, Y: 1.6, Z: 4.5},
+			Point3{X: 0, Y: 1.1, Z: 0},
+			Vec3{X: 0, Y: 1, Z: 0},
+		).
+		SetLens(35, 0, 4.5).
+		SetBackground(Color{X: 0.02, Y: 0.02, Z: 0.03}).
+		Build()
+
+	for _, light := range []*Quad{
+		NewQuad(Point3{X: -1, Y: 4, Z: -3}, Vec3{X: 2, Y: 0, Z: 0}, Vec3{X: 0, Y: 0, Z: 1}, NewDiffuseLightColor(Color{X: 15, Y: 15, Z: 15})),
+		NewQuad(Point3{X: 2, Y: 2, Z: 2}, Vec3{X: 0, Y: 1.5, Z: 0}, Vec3{X: -1, Y: 0, Z: 1}, NewDiffuseLightColor(Color{X: 4, Y: 4, Z: 4})),
+	} {
+		world.Add(light)
+		camera.AddLight(light)
+	}
+
+	return world, camera
+}
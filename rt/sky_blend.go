@@ -0,0 +1,178 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// =============================================================================
+// HDRI AND PHYSICAL SKY BLENDING
+// =============================================================================
+
+// SkyBlendMode selects which parts of a blended environment come from the
+// HDRI and which from the physical atmosphere
+type SkyBlendMode int
+
+const (
+	SkyBlendHDRISky SkyBlendMode = iota // Sky from the HDRI, sun from the atmosphere model
+	SkyBlendHDRISun                     // Sun from the HDRI, sky from the atmosphere model
+)
+
+var skyBlendModeNames = []string{"hdri-sky", "hdri-sun"}
+
+func (m SkyBlendMode) String() string {
+	if int(m) < 0 || int(m) >= len(skyBlendModeNames) {
+		return "unknown"
+	}
+	return skyBlendModeNames[m]
+}
+
+// ParseSkyBlendMode converts a mode name (e.g. "hdri-sky") to a SkyBlendMode
+func ParseSkyBlendMode(name string) (SkyBlendMode, error) {
+	for i, n := range skyBlendModeNames {
+		if strings.EqualFold(name, n) {
+			return SkyBlendMode(i), nil
+		}
+	}
+	return SkyBlendHDRISky, fmt.Errorf("unknown sky blend mode: %s (use %s)",
+		name, strings.Join(skyBlendModeNames, ", "))
+}
+
+// SkyBlendConfig controls how an HDRI and the physical atmosphere are
+// combined. The HDRI's sun is the part of the map brighter than SunThreshold:
+// in hdri-sky mode it is clamped away and the analytic sun, reddened by the
+// atmosphere, takes its place, so an overcast HDRI gets a crisp sun; in
+// hdri-sun mode only that part is kept over the atmosphere's sky.
+type SkyBlendConfig struct {
+	Mode         SkyBlendMode
+	Atmosphere   AtmosphereConfig // Analytic sun and sky
+	SkyIntensity float64          // Scale of the sky, whichever source it comes from
+	SunIntensity float64          // Scale of the sun, whichever source it comes from
+	SunThreshold float64          // HDRI luminance above which texels are sun (0 = 20x the map's mean)
+	AlignSun     bool             // Put the analytic sun where the HDRI's brightest texel is
+}
+
+// DefaultSkyBlendConfig returns an HDRI sky with an analytic sun aligned to
+// the HDRI's, both at their own intensity
+func DefaultSkyBlendConfig() SkyBlendConfig {
+	return SkyBlendConfig{
+		Mode:         SkyBlendHDRISky,
+		Atmosphere:   DefaultAtmosphereConfig(),
+		SkyIntensity: 1,
+		SunIntensity: 1,
+		AlignSun:     true,
+	}
+}
+
+// NewSkyBlendEnvironment bakes hdri and the atmosphere into one environment
+// map at the HDRI's resolution (the proxy's, for streamed maps), or the
+// atmosphere's Width if that is larger. The result keeps the HDRI's rotation
+// and importance sampling setting.
+func NewSkyBlendEnvironment(hdri *HDRIEnvironment, config SkyBlendConfig) *HDRIEnvironment {
+	if hdri == nil || !hdri.IsValid() || hdri.image.imageWidth <= 1 {
+		fmt.Println("Warning: sky blending needs a loaded HDRI; keeping the environment")
+		return hdri
+	}
+	src := hdri.image
+
+	threshold := config.SunThreshold
+	if threshold <= 0 {
+		threshold = 20 * meanLuminance(src.data)
+	}
+	atmo := config.Atmosphere
+	if config.AlignSun {
+		if dir, ok := brightestDirection(src, threshold); ok {
+			atmo.SunElevation = math.Asin(clampFloat(dir.Y, -1, 1)) * 180 / math.Pi
+			atmo.SunAzimuth = math.Atan2(dir.Z, dir.X) * 180 / math.Pi
+		}
+	}
+	sky := newAtmosphere(atmo)
+
+	var radiance func(u, v float64, dir Vec3) Color
+	switch config.Mode {
+	case SkyBlendHDRISun:
+		skyWidth := max(atmo.Width, 16)
+		skyMap := bakeEquirect(skyWidth, skyWidth/2, func(_, _ float64, dir Vec3) Color {
+			s, _ := sky.radianceParts(dir)
+			return s
+		})
+		radiance = func(u, v float64, dir Vec3) Color {
+			_, sun := splitSun(src.PixelDataBilinear(u, v), threshold)
+			return skyMap.PixelDataBilinear(u, v).Scale(config.SkyIntensity).Add(sun.Scale(config.SunIntensity))
+		}
+	default:
+		// The sun sits where the disk is, so one march towards it colors it
+		sunR, sunM, visible := sky.sunOpticalDepth(sky.origin)
+		sunColor := sky.transmittance(sunR, sunM).Scale(atmo.SunIntensity)
+		radiance = func(u, v float64, dir Vec3) Color {
+			hdriSky, _ := splitSun(src.PixelDataBilinear(u, v), threshold)
+			out := hdriSky.Scale(config.SkyIntensity)
+			if visible {
+				out = out.Add(sunColor.Scale(sky.sunDisk(Dot(dir, sky.sunDir)) * config.SunIntensity))
+			}
+			return out
+		}
+	}
+
+	fmt.Printf("Sky blend: %s, sun at elevation %.1f°, azimuth %.1f° (threshold %.3g)\n",
+		config.Mode, atmo.SunElevation, atmo.SunAzimuth, threshold)
+	// Small maps are upsampled so the analytic sun disk spans several texels
+	width := max(src.imageWidth, atmo.Width)
+	env := newEnvironmentFromImage(bakeEquirect(width, width/2, radiance))
+	env.rotation = hdri.rotation
+	// A small analytic sun needs lookups that match its sampling PDF exactly
+	env.nearest = config.Mode == SkyBlendHDRISky
+	if !hdri.useImportanceSampling {
+		env.DisableImportanceSampling()
+	}
+	return env
+}
+
+// splitSun splits an HDRI texel into the part up to threshold luminance (sky)
+// and the excess above it (sun), keeping its color in both
+func splitSun(texel Color, threshold float64) (sky, sun Color) {
+	lum := Luminance(texel)
+	if lum <= threshold || lum <= 0 {
+		return texel, Color{}
+	}
+	sky = texel.Scale(threshold / lum)
+	return sky, texel.Sub(sky)
+}
+
+// meanLuminance returns the average luminance of texels
+func meanLuminance(texels []Color) float64 {
+	if len(texels) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, c := range texels {
+		sum += Luminance(c)
+	}
+	return sum / float64(len(texels))
+}
+
+// brightestDirection returns the unrotated map direction of the brightest
+// texel, if it is brighter than threshold
+func brightestDirection(img *ImageLoader, threshold float64) (Vec3, bool) {
+	best, bestLum := -1, threshold
+	for i, c := range img.data {
+		if lum := Luminance(c); lum > bestLum {
+			best, bestLum = i, lum
+		}
+	}
+	if best < 0 {
+		return Vec3{}, false
+	}
+	layout := &HDRIEnvironment{width: img.imageWidth, height: img.imageHeight}
+	u := (float64(best%img.imageWidth) + 0.5) / float64(img.imageWidth)
+	v := (float64(best/img.imageWidth) + 0.5) / float64(img.imageHeight)
+	return layout.UVToDirection(u, v), true
+}
+
+// SetSkyBlend replaces the camera's HDRI with its blend with the physical
+// atmosphere (see NewSkyBlendEnvironment)
+func (c *Camera) SetSkyBlend(config SkyBlendConfig) *Camera {
+	c.Environment = NewSkyBlendEnvironment(c.Environment, config)
+	return c
+}
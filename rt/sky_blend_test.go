@@ -0,0 +1,68 @@
+package rt
+
+import "testing"
+
+func TestSplitSun(t *testing.T) {
+	sky, sun := splitSun(Color{X: 10, Y: 10, Z: 10}, 4)
+	if Luminance(sky) > 4+1e-9 || sky.Add(sun) != (Color{X: 10, Y: 10, Z: 10}) {
+		t.Errorf("split of a bright texel = %v + %v", sky, sun)
+	}
+	if sky, sun := splitSun(Color{X: 1, Y: 2, Z: 3}, 4); sun != (Color{}) || sky != (Color{X: 1, Y: 2, Z: 3}) {
+		t.Errorf("texel below the threshold split into %v + %v", sky, sun)
+	}
+}
+
+func TestParseSkyBlendMode(t *testing.T) {
+	for _, mode := range []SkyBlendMode{SkyBlendHDRISky, SkyBlendHDRISun} {
+		if got, err := ParseSkyBlendMode(mode.String()); err != nil || got != mode {
+			t.Errorf("ParseSkyBlendMode(%q) = %v, %v", mode, got, err)
+		}
+	}
+	if _, err := ParseSkyBlendMode("sky"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+// testSunHDRI is a dim gray map with one bright texel, the HDRI's sun
+func testSunHDRI() (*HDRIEnvironment, Vec3) {
+	const width, height = 64, 32
+	image := &ImageLoader{
+		data:        make([]Color, width*height),
+		imageWidth:  width,
+		imageHeight: height,
+		IsHDR:       true,
+	}
+	for i := range image.data {
+		image.data[i] = Color{X: 0.5, Y: 0.5, Z: 0.5}
+	}
+	image.data[8*width+20] = Color{X: 5000, Y: 5000, Z: 5000}
+	sunDir, _ := brightestDirection(image, 1)
+	return newEnvironmentFromImage(image), sunDir
+}
+
+func TestSkyBlendEnvironment(t *testing.T) {
+	config := DefaultSkyBlendConfig()
+	config.Atmosphere.Width = 256
+	config.Atmosphere.ViewSamples = 4
+	config.Atmosphere.LightSamples = 2
+
+	hdri, sunDir := testSunHDRI()
+	blended := NewSkyBlendEnvironment(hdri, config)
+	if Luminance(blended.image.PixelDataBilinear(0.9, 0.9)) > 1 {
+		t.Error("hdri-sky mode should keep the HDRI's dim sky")
+	}
+	if dir, ok := brightestDirection(blended.image, 0); !ok || Dot(dir, sunDir) < 0.995 {
+		t.Errorf("analytic sun at %v, want the HDRI's sun at %v", dir, sunDir)
+	}
+	// 2.5° from the sun: outside the analytic disk, inside the HDRI's sun texel
+	const nearU, nearV = 20.5 / 64, 8.05 / 32
+	if near := Luminance(blended.image.PixelDataBilinear(nearU, nearV)); near > 60 {
+		t.Errorf("hdri-sky mode kept the HDRI's sun (%.0f next to the disk)", near)
+	}
+
+	config.Mode = SkyBlendHDRISun
+	blended = NewSkyBlendEnvironment(hdri, config)
+	if near := Luminance(blended.image.PixelDataBilinear(nearU, nearV)); near < 1000 {
+		t.Errorf("hdri-sun mode lost the HDRI's sun (%.0f)", near)
+	}
+}
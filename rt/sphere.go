@@ -4,10 +4,11 @@ import "math"
 
 // Sphere represents a sphere object that can be hit by rays
 type Sphere struct {
-	Center Ray
-	Radius float64
-	Mat    Material
-	bbox   AABB
+	Center  Ray
+	Radius  float64
+	Mat     Material
+	Mapping SphereMapping // UV parameterization (lat-long by default)
+	bbox    AABB
 }
 
 // NewSphere creates a new sphere with the given center and radius and material
@@ -84,11 +85,16 @@ func (s *Sphere) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 		}
 	}
 
+	s.setHitRecord(r, root, sphereCenter, rec)
+	return true
+}
+
+// setHitRecord fills rec for a hit at root on the sphere centered at center
+func (s *Sphere) setHitRecord(r Ray, root float64, center Point3, rec *HitRecord) {
 	rec.T = root
 	rec.P = r.At(rec.T)
-	outwardNormal := rec.P.Sub(sphereCenter).Div(s.Radius)
+	outwardNormal := rec.P.Sub(center).Div(s.Radius)
 	rec.SetFaceNormal(r, outwardNormal)
-	rec.U, rec.V = getSphereUV(outwardNormal)
+	rec.U, rec.V = sphereUV(s.Mapping, outwardNormal)
 	rec.Mat = s.Mat
-	return true
 }
@@ -0,0 +1,197 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// =============================================================================
+// SPHERE UV MAPPINGS AND SEAM-AWARE TEXTURE FILTERING
+// =============================================================================
+
+// SphereMapping selects how a sphere's surface is parameterized into UVs
+type SphereMapping int
+
+const (
+	SphereMappingLatLong   SphereMapping = iota // Equirectangular: v linear in latitude (default)
+	SphereMappingEqualArea                      // Lambert cylindrical: v linear in height, equal texel area
+	SphereMappingCube                           // Cube map in a 3x2 atlas, no poles
+)
+
+var sphereMappingNames = []string{"lat-long", "equal-area", "cube"}
+
+func (m SphereMapping) String() string {
+	if int(m) < 0 || int(m) >= len(sphereMappingNames) {
+		return "unknown"
+	}
+	return sphereMappingNames[m]
+}
+
+// ParseSphereMapping converts a mapping name (e.g. "equal-area") to a
+// SphereMapping
+func ParseSphereMapping(name string) (SphereMapping, error) {
+	for i, n := range sphereMappingNames {
+		if strings.EqualFold(name, n) {
+			return SphereMapping(i), nil
+		}
+	}
+	return SphereMappingLatLong, fmt.Errorf("unknown sphere mapping: %s (use %s)",
+		name, strings.Join(sphereMappingNames, ", "))
+}
+
+// TextureFilter returns the seam-aware filter for images laid out in this
+// mapping
+func (m SphereMapping) TextureFilter() TextureFilter {
+	if m == SphereMappingCube {
+		return TextureFilterCube
+	}
+	return TextureFilterSpherical
+}
+
+// SetMapping changes how the sphere's UVs are computed. The texture image
+// must be laid out to match.
+func (s *Sphere) SetMapping(mapping SphereMapping) *Sphere {
+	s.Mapping = mapping
+	return s
+}
+
+// sphereUV returns the UVs of unit vector p under mapping
+func sphereUV(mapping SphereMapping, p Point3) (u, v float64) {
+	switch mapping {
+	case SphereMappingEqualArea:
+		u, _ = getSphereUV(p)
+		return u, clampFloat((p.Y+1)/2, 0, 1)
+	case SphereMappingCube:
+		return cubeMapUV(p)
+	}
+	return getSphereUV(p)
+}
+
+// Cube atlas faces: +X, -X, +Y in the bottom row of UV space, -Y, +Z, -Z in
+// the top row, each a third of the width and half the height
+const (
+	cubeAtlasCols = 3
+	cubeAtlasRows = 2
+)
+
+// cubeMapUV projects p onto the cube face of its largest component
+func cubeMapUV(p Point3) (u, v float64) {
+	ax, ay, az := math.Abs(p.X), math.Abs(p.Y), math.Abs(p.Z)
+	var face int
+	var s, t, major float64
+	switch {
+	case ax >= ay && ax >= az:
+		major = ax
+		if p.X > 0 {
+			face, s, t = 0, -p.Z, p.Y
+		} else {
+			face, s, t = 1, p.Z, p.Y
+		}
+	case ay >= az:
+		major = ay
+		if p.Y > 0 {
+			face, s, t = 2, p.X, -p.Z
+		} else {
+			face, s, t = 3, p.X, p.Z
+		}
+	default:
+		major = az
+		if p.Z > 0 {
+			face, s, t = 4, p.X, p.Y
+		} else {
+			face, s, t = 5, -p.X, p.Y
+		}
+	}
+	if major == 0 {
+		return 0.5, 0.5
+	}
+	fu := clampFloat((s/major+1)/2, 0, 1)
+	fv := clampFloat((t/major+1)/2, 0, 1)
+	col, row := face%cubeAtlasCols, face/cubeAtlasCols
+	return (float64(col) + fu) / cubeAtlasCols, (float64(row) + fv) / cubeAtlasRows
+}
+
+// TextureFilter selects how an ImageTexture is sampled
+type TextureFilter int
+
+const (
+	TextureFilterNearest   TextureFilter = iota // Nearest texel, UVs clamped (default)
+	TextureFilterBilinear                       // Bilinear, u wraps and v clamps
+	TextureFilterSpherical                      // Bilinear across the u seam and over the poles (lat-long and equal-area)
+	TextureFilterCube                           // Bilinear within each face of a cube atlas, never across faces
+)
+
+var textureFilterNames = []string{"nearest", "bilinear", "spherical", "cube"}
+
+func (f TextureFilter) String() string {
+	if int(f) < 0 || int(f) >= len(textureFilterNames) {
+		return "unknown"
+	}
+	return textureFilterNames[f]
+}
+
+// ParseTextureFilter converts a filter name (e.g. "spherical") to a
+// TextureFilter
+func ParseTextureFilter(name string) (TextureFilter, error) {
+	for i, n := range textureFilterNames {
+		if strings.EqualFold(name, n) {
+			return TextureFilter(i), nil
+		}
+	}
+	return TextureFilterNearest, fmt.Errorf("unknown texture filter: %s (use %s)",
+		name, strings.Join(textureFilterNames, ", "))
+}
+
+// SetFilter changes how the texture is sampled, e.g. to the seam-aware filter
+// of the sphere mapping it is used with
+func (tex *ImageTexture) SetFilter(filter TextureFilter) *ImageTexture {
+	tex.filter = filter
+	return tex
+}
+
+// sphericalBilinear filters an equirectangular-topology image at continuous
+// pixel coordinates: columns wrap around the u seam, and rows past a pole
+// continue down the other side of the globe, half a turn around
+func sphericalBilinear(img *ImageLoader, px, py float64) Color {
+	w, h := img.imageWidth, img.imageHeight
+	texel := func(x, y int) Color {
+		if y < 0 {
+			y, x = -1-y, x+w/2
+		} else if y >= h {
+			y, x = 2*h-1-y, x+w/2
+		}
+		y = clamp(y, 0, h)
+		x = ((x % w) + w) % w
+		return img.data[y*w+x]
+	}
+	return bilinearTexels(texel, px, py)
+}
+
+// cubeBilinear filters a cube atlas at continuous pixel coordinates, with
+// the footprint clamped to the face containing (px, py)
+func cubeBilinear(img *ImageLoader, px, py float64) Color {
+	w, h := img.imageWidth, img.imageHeight
+	faceW, faceH := float64(w)/cubeAtlasCols, float64(h)/cubeAtlasRows
+	col := clamp(int((px+0.5)/faceW), 0, cubeAtlasCols)
+	row := clamp(int((py+0.5)/faceH), 0, cubeAtlasRows)
+	x0, x1 := int(math.Ceil(float64(col)*faceW)), int(math.Ceil(float64(col+1)*faceW))-1
+	y0, y1 := int(math.Ceil(float64(row)*faceH)), int(math.Ceil(float64(row+1)*faceH))-1
+	px = clampFloat(px, float64(x0), float64(max(x0, x1)))
+	py = clampFloat(py, float64(y0), float64(max(y0, y1)))
+	texel := func(x, y int) Color {
+		x = min(max(x, x0), max(x0, x1))
+		y = min(max(y, y0), max(y0, y1))
+		return img.data[y*w+x]
+	}
+	return bilinearTexels(texel, px, py)
+}
+
+// bilinearTexels interpolates the four texels around (px, py)
+func bilinearTexels(texel func(x, y int) Color, px, py float64) Color {
+	x0, y0 := int(math.Floor(px)), int(math.Floor(py))
+	fx, fy := px-float64(x0), py-float64(y0)
+	c0 := texel(x0, y0).Scale(1 - fx).Add(texel(x0+1, y0).Scale(fx))
+	c1 := texel(x0, y0+1).Scale(1 - fx).Add(texel(x0+1, y0+1).Scale(fx))
+	return c0.Scale(1 - fy).Add(c1.Scale(fy))
+}
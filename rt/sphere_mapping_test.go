@@ -0,0 +1,89 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+// testImage builds an image from a per-texel color function
+func testImage(w, h int, texel func(x, y int) Color) *ImageLoader {
+	img := &ImageLoader{data: make([]Color, w*h), imageWidth: w, imageHeight: h}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.data[y*w+x] = texel(x, y)
+		}
+	}
+	return img
+}
+
+func TestSphericalFilterIsSeamless(t *testing.T) {
+	// Columns alternate black and white; the u seam sits between the last
+	// (white) and first (black) column
+	img := testImage(16, 8, func(x, y int) Color { return Color{X: float64(x % 2)} })
+	tex := NewImageTextureFromImage(img).SetFilter(TextureFilterSpherical)
+
+	left := tex.Value(0.0001, 0.5, Point3{})
+	right := tex.Value(0.9999, 0.5, Point3{})
+	if math.Abs(left.X-right.X) > 0.01 || math.Abs(left.X-0.5) > 0.01 {
+		t.Errorf("either side of the seam = %.3f and %.3f, want both 0.5", left.X, right.X)
+	}
+
+	nearest := NewImageTextureFromImage(img)
+	if a, b := nearest.Value(0.0001, 0.5, Point3{}), nearest.Value(0.9999, 0.5, Point3{}); a.X == b.X {
+		t.Fatal("test image has no seam under nearest filtering")
+	}
+}
+
+func TestSphericalFilterCrossesPoles(t *testing.T) {
+	// The top row is red on the left half and green on the right half, so
+	// filtering over the north pole blends in the opposite side
+	img := testImage(16, 8, func(x, y int) Color {
+		if x < 8 {
+			return Color{X: 1}
+		}
+		return Color{Y: 1}
+	})
+	tex := NewImageTextureFromImage(img).SetFilter(TextureFilterSpherical)
+	c := tex.Value(0.25, 1, Point3{})
+	if math.Abs(c.X-0.5) > 1e-9 || math.Abs(c.Y-0.5) > 1e-9 {
+		t.Errorf("at the pole = %v, want an even blend of both sides", c)
+	}
+}
+
+func TestSphereMappings(t *testing.T) {
+	// Equal-area: v is linear in height
+	for _, y := range []float64{-1, -0.5, 0, 0.5, 1} {
+		p := Point3{X: math.Sqrt(1 - y*y), Y: y}
+		if _, v := sphereUV(SphereMappingEqualArea, p); math.Abs(v-(y+1)/2) > 1e-12 {
+			t.Errorf("equal-area v at y=%g = %g, want %g", y, v, (y+1)/2)
+		}
+	}
+
+	// Cube: each axis lands in the center of its own atlas cell
+	seen := map[[2]int]bool{}
+	for _, p := range []Point3{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}, {Z: 1}, {Z: -1}} {
+		u, v := sphereUV(SphereMappingCube, p)
+		cell := [2]int{int(u * cubeAtlasCols), int(v * cubeAtlasRows)}
+		cu := (float64(cell[0]) + 0.5) / cubeAtlasCols
+		cv := (float64(cell[1]) + 0.5) / cubeAtlasRows
+		if seen[cell] || math.Abs(u-cu) > 1e-12 || math.Abs(v-cv) > 1e-12 {
+			t.Errorf("%v maps to (%g, %g), want the center of an unused cell", p, u, v)
+		}
+		seen[cell] = true
+	}
+}
+
+func TestCubeFilterStaysInFace(t *testing.T) {
+	// Every face a different flat color
+	img := testImage(12, 8, func(x, y int) Color { return Color{X: float64(x / 4), Y: float64(y / 4)} })
+	tex := NewImageTextureFromImage(img).SetFilter(TextureFilterCube)
+
+	for _, p := range []Point3{{X: 1, Y: 0.999, Z: 0.999}, {X: -0.999, Y: -1, Z: 0.5}, {X: 0.999, Y: 0.2, Z: -1}} {
+		u, v := sphereUV(SphereMappingCube, p.Unit())
+		got := tex.Value(u, v, Point3{})
+		want := NewImageTextureFromImage(img).Value(u, v, Point3{})
+		if got != want {
+			t.Errorf("%v at a face edge = %v, want the face color %v", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// POINT / SPOT LIGHTS WITH GOBO PROJECTION
+// =============================================================================
+
+// SpotLight is an invisible point emitter with an optional cone and gobo (a
+// texture projected through the light, e.g. window blinds or foliage). It is
+// a delta light: register it with Camera.AddLight only, not in the world. It
+// reaches surfaces through NEE, so it lights materials with CanUseNEE.
+type SpotLight struct {
+	Position   Point3
+	Intensity  Color      // Radiant intensity along the axis (radiance × area at 1 unit)
+	InnerAngle float64    // Full-intensity half-angle in degrees
+	OuterAngle float64    // Cutoff half-angle in degrees; >= 180 makes an omni point light
+	Gobo       Texture    // Optional projected pattern, nil = unmasked
+	Decay      LightDecay // Distance falloff (zero = physical)
+
+	basis      ONB
+	cosInner   float64
+	cosOuter   float64
+	tanOuter   float64
+	bbox       AABB
+	isDirected bool
+}
+
+// NewPointLight creates an omnidirectional light. A gobo wraps around it in
+// latitude/longitude (u around the Y axis, v from bottom to top).
+func NewPointLight(position Point3, intensity Color) *SpotLight {
+	return newSpotLight(position, Vec3{X: 0, Y: -1, Z: 0}, intensity, 180, 180)
+}
+
+// NewSpotLight creates a cone light aimed at target with a smooth falloff
+// between the inner and outer half-angles (degrees)
+func NewSpotLight(position, target Point3, intensity Color, innerAngle, outerAngle float64) *SpotLight {
+	return newSpotLight(position, target.Sub(position), intensity, innerAngle, outerAngle)
+}
+
+func newSpotLight(position Point3, direction Vec3, intensity Color, innerAngle, outerAngle float64) *SpotLight {
+	outerAngle = clampFloat(outerAngle, 0.1, 180)
+	innerAngle = clampFloat(innerAngle, 0, outerAngle)
+
+	s := &SpotLight{
+		Position:   position,
+		Intensity:  intensity,
+		InnerAngle: innerAngle,
+		OuterAngle: outerAngle,
+		basis:      NewONB(direction.Unit()),
+		cosInner:   math.Cos(innerAngle * math.Pi / 180),
+		cosOuter:   math.Cos(outerAngle * math.Pi / 180),
+		isDirected: outerAngle < 90,
+	}
+	if s.isDirected {
+		s.tanOuter = math.Tan(outerAngle * math.Pi / 180)
+	}
+	s.bbox = NewAABBFromPoints(position, position)
+	s.bbox.padToMinimums()
+	return s
+}
+
+// SetGobo projects a texture through the light. For spot lights (outer angle
+// below 90°) the texture's unit square spans the cone's outer angle; texture
+// lookups get p on the gobo plane one unit in front of the light.
+func (s *SpotLight) SetGobo(tex Texture) *SpotLight {
+	s.Gobo = tex
+	return s
+}
+
+// Hit never reports an intersection: the light has no surface
+func (s *SpotLight) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	return false
+}
+
+func (s *SpotLight) BoundingBox() AABB {
+	return s.bbox
+}
+
+// Radiant returns the intensity emitted towards dir (unit, pointing away from the light)
+func (s *SpotLight) Radiant(dir Vec3) Color {
+	local := s.basis.ToLocal(dir)
+	if local.Z < s.cosOuter {
+		return Color{X: 0, Y: 0, Z: 0}
+	}
+
+	intensity := s.Intensity
+	if local.Z < s.cosInner {
+		intensity = intensity.Scale(smoothstep(s.cosOuter, s.cosInner, local.Z))
+	}
+
+	if s.Gobo != nil {
+		intensity = intensity.Mult(s.goboValue(dir, local))
+	}
+	return intensity
+}
+
+// goboValue looks up the gobo for a world direction and the same direction in
+// light space (Z = axis)
+func (s *SpotLight) goboValue(dir, local Vec3) Color {
+	if s.isDirected {
+		// Perspective projection onto the gobo plane at unit distance
+		x, y := local.X/local.Z, local.Y/local.Z
+		u := 0.5 + 0.5*x/s.tanOuter
+		v := 0.5 + 0.5*y/s.tanOuter
+		return s.Gobo.Value(u, v, Point3{X: x, Y: y, Z: 1})
+	}
+
+	// Latitude/longitude around world Y for omni lights
+	u := 0.5 + math.Atan2(dir.Z, dir.X)/(2*math.Pi)
+	v := 0.5 + math.Asin(clampFloat(dir.Y, -1, 1))/math.Pi
+	return s.Gobo.Value(u, v, dir)
+}
+
+// Power returns the emitted power used for light selection, ignoring the gobo
+func (s *SpotLight) Power() float64 {
+	// Solid angle of the cone, counting the falloff band at half weight
+	cosMid := 0.5 * (s.cosInner + s.cosOuter)
+	return Luminance(s.Intensity) * 2 * math.Pi * (1 - cosMid)
+}
+
+// sampleSpotLight adds the direct contribution of a delta light. There is no
+// MIS weight: BRDF sampling can never hit a point, so NEE is the only strategy.
+func (c *Camera) sampleSpotLight(
+	hitPoint Point3, hitNormal Vec3, rayDirection Vec3,
+	world Hittable, light *SpotLight, selectPDF float64,
+	attenuation Color, pdfEval PDFEvaluator, sampler *Sampler,
+) Color {
+	toLight := light.Position.Sub(hitPoint)
+	distance := toLight.Len()
+	if distance < 1e-6 {
+		return Color{X: 0, Y: 0, Z: 0}
+	}
+	lightDir := toLight.Scale(1 / distance)
+
+	if Dot(hitNormal, lightDir) <= 0 {
+		return Color{X: 0, Y: 0, Z: 0}
+	}
+
+	emitted := light.Radiant(lightDir.Neg())
+	if emitted.X == 0 && emitted.Y == 0 && emitted.Z == 0 {
+		return Color{X: 0, Y: 0, Z: 0}
+	}
+
+	shadowRay := NewRay(hitPoint, lightDir, 0).withKind(rayShadow).withSampler(sampler)
+	epsilon := c.rayEpsilon()
+	if world.Hit(shadowRay, NewInterval(epsilon, distance-epsilon), &HitRecord{}) {
+		return Color{X: 0, Y: 0, Z: 0}
+	}
+
+	// f*cos = attenuation * pdfBRDF; irradiance falls off with 1/d² unless
+	// the light's decay says otherwise
+	pdfBRDF := pdfEval.PDF(rayDirection.Neg().Unit(), lightDir, hitNormal)
+	contribution := emitted.Mult(attenuation).Scale(pdfBRDF * light.Decay.scale(distance) / (distance * distance * selectPDF))
+
+	// Clamp to prevent fireflies
+	maxComponent := 20.0
+	contribution.X = math.Min(contribution.X, maxComponent)
+	contribution.Y = math.Min(contribution.Y, maxComponent)
+	contribution.Z = math.Min(contribution.Z, maxComponent)
+
+	return contribution
+}
@@ -0,0 +1,57 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpotLightConeAndGobo(t *testing.T) {
+	white := Color{X: 1, Y: 1, Z: 1}
+	spot := NewSpotLight(Point3{Y: 5}, Point3{}, white, 10, 20)
+
+	down := Vec3{Y: -1}
+	if got := spot.Radiant(down); got != white {
+		t.Errorf("on-axis intensity = %v, want %v", got, white)
+	}
+	if got := spot.Radiant(Vec3{X: 1, Y: -1}.Unit()); got != (Color{}) {
+		t.Errorf("45° off-axis intensity = %v, want black outside the 20° cone", got)
+	}
+	falloff := spot.Radiant(Vec3{X: math.Tan(15 * math.Pi / 180), Y: -1}.Unit())
+	if !(falloff.X > 0 && falloff.X < 1) {
+		t.Errorf("15° off-axis intensity = %v, want partial falloff", falloff)
+	}
+
+	// One band: the lower half of the gobo (v < 0.5) blocks, the upper half passes
+	spot.SetGobo(NewStripeTexture(1, 0.5, NewSolidColor(Color{}), NewSolidColor(white)))
+	offset := spot.basis.V.Scale(math.Tan(5 * math.Pi / 180))
+	if got := spot.Radiant(down.Add(offset).Unit()); got != white {
+		t.Errorf("upper gobo half = %v, want %v", got, white)
+	}
+	if got := spot.Radiant(down.Sub(offset).Unit()); got != (Color{}) {
+		t.Errorf("lower gobo half = %v, want black", got)
+	}
+}
+
+func TestPointLightIrradiance(t *testing.T) {
+	// A point light of intensity I at height h over a white Lambertian floor:
+	// outgoing radiance directly below is I / (π h²)
+	const intensity, height = 2.0, 2.0
+	world := NewHittableList()
+	world.Add(NewPlane(Point3{}, Vec3{Y: 1}, NewLambertian(Color{X: 1, Y: 1, Z: 1})))
+	light := NewPointLight(Point3{Y: height}, Color{X: intensity, Y: intensity, Z: intensity})
+
+	camera := NewCameraBuilder().AddLight(light)
+	camera.Initialize()
+
+	rec := &HitRecord{}
+	r := NewRay(Point3{Y: 1}, Vec3{Y: -1}, 0)
+	if !world.Hit(r, NewInterval(0.001, math.Inf(1)), rec) {
+		t.Fatal("probe ray missed the floor")
+	}
+	mat := rec.Mat.(*Lambertian)
+	got := camera.sampleSpotLight(rec.P, rec.Normal, r.Direction(), world, light, 1, Color{X: 1, Y: 1, Z: 1}, mat, nil)
+	want := intensity / (math.Pi * height * height)
+	if math.Abs(got.X-want) > 1e-9 {
+		t.Errorf("radiance = %v, want %v", got.X, want)
+	}
+}
@@ -0,0 +1,214 @@
+package rt
+
+import "math"
+
+// =============================================================================
+// STUDIO GROUND PLANE AND CYCLORAMA
+// =============================================================================
+
+const (
+	groundPlaneScale = 10.0 // Ground size as a multiple of the scene's extent
+	cycloramaScale   = 4.0  // Cyclorama width and height as a multiple of the scene's extent
+)
+
+// AutoGroundPlane returns a square floor just under world, centered on its
+// footprint and large enough to reach the horizon in most shots. Unbounded
+// objects such as planes are ignored when fitting.
+func AutoGroundPlane(world Hittable, mat Material) Hittable {
+	box := finiteBounds(world)
+	extent := sceneExtent(box)
+	size := groundPlaneScale * extent
+	center := box.Centroid()
+	// Slightly below the lowest point so flat model bases don't z-fight
+	y := box.Y.Min - 1e-4*extent
+	return NewQuad(
+		Point3{X: center.X - size/2, Y: y, Z: center.Z - size/2},
+		Vec3{Z: size}, Vec3{X: size},
+		mat,
+	)
+}
+
+// Cyclorama returns a seamless studio sweep: a floor that bends up into a
+// back wall through a quarter cylinder of curveRadius, so there is no
+// visible horizon. The wall stands on the x axis facing +Z, width wide and
+// height tall; the floor runs height units towards +Z.
+func Cyclorama(width, height, curveRadius float64, mat Material) Hittable {
+	return newCyclorama(Point3{}, Vec3{X: 1}, Vec3{Z: 1}, width, height, height, curveRadius, mat)
+}
+
+// AutoCyclorama places a cyclorama behind world as seen from camera, with
+// its floor under the scene and its wall turned to face the camera
+func AutoCyclorama(world Hittable, camera *Camera, mat Material) Hittable {
+	box := finiteBounds(world)
+	extent := sceneExtent(box)
+	center := box.Centroid()
+
+	// Horizontal direction from the scene towards the camera
+	toward := Vec3{Z: 1}
+	if camera != nil {
+		if d := camera.LookFrom.Sub(center); d.X != 0 || d.Z != 0 {
+			toward = Vec3{X: d.X, Z: d.Z}.Unit()
+		}
+	}
+	right := Cross(Vec3{Y: 1}, toward).Unit()
+
+	// The wall stands one footprint radius behind the scene's back edge and
+	// the curve takes up that gap
+	footprint := math.Hypot(box.X.Size(), box.Z.Size()) / 2
+	radius := max(footprint, 1e-3*extent)
+	origin := Point3{X: center.X, Y: box.Y.Min - 1e-4*extent, Z: center.Z}.Sub(toward.Scale(footprint + radius))
+	size := cycloramaScale * extent
+	return newCyclorama(origin, right, toward, size, size, size, radius, mat)
+}
+
+// newCyclorama builds a sweep whose wall/floor corner runs through origin
+// along right, with the floor extending depth units along toward
+func newCyclorama(origin Point3, right, toward Vec3, width, height, depth, radius float64, mat Material) Hittable {
+	up := Vec3{Y: 1}
+	radius = clampFloat(radius, 0, min(height, depth))
+	corner := origin.Sub(right.Scale(width / 2))
+
+	sweep := NewHittableList()
+	if depth > radius {
+		sweep.Add(NewQuad(corner.Add(toward.Scale(radius)), toward.Scale(depth-radius), right.Scale(width), mat))
+	}
+	if height > radius {
+		sweep.Add(NewQuad(corner.Add(up.Scale(radius)), right.Scale(width), up.Scale(height-radius), mat))
+	}
+	if radius > 0 {
+		sweep.Add(newSweepCurve(origin.Add(up.Scale(radius)).Add(toward.Scale(radius)), right, up, toward.Neg(), radius, width/2, mat))
+	}
+	return sweep
+}
+
+// sweepCurve is the quarter cylinder joining a cyclorama's floor and wall:
+// the arc from center-up*radius (floor) to center+back*radius (wall),
+// extruded halfWidth either way along axis
+type sweepCurve struct {
+	center         Point3
+	axis, up, back Vec3 // Orthonormal frame
+	radius         float64
+	halfWidth      float64
+	mat            Material
+	bbox           AABB
+}
+
+func newSweepCurve(center Point3, axis, up, back Vec3, radius, halfWidth float64, mat Material) *sweepCurve {
+	floor := center.Sub(up.Scale(radius))
+	wall := center.Add(back.Scale(radius))
+	side := axis.Scale(halfWidth)
+	box := NewAABBFromPoints(floor.Sub(side), floor.Add(side))
+	box = NewAABBFromBoxes(box, NewAABBFromPoints(wall.Sub(side), wall.Add(side)))
+	// The arc bulges past its chord towards the corner
+	corner := center.Sub(up.Scale(radius)).Add(back.Scale(radius))
+	box = NewAABBFromBoxes(box, NewAABBFromPoints(corner.Sub(side), corner.Add(side)))
+	return &sweepCurve{
+		center: center, axis: axis, up: up, back: back,
+		radius: radius, halfWidth: halfWidth, mat: mat, bbox: box,
+	}
+}
+
+func (s *sweepCurve) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	// Work in the curve's frame; the cylinder is y^2 + z^2 = radius^2
+	o := r.Origin().Sub(s.center)
+	d := r.Direction()
+	oy, oz := Dot(o, s.up), Dot(o, s.back)
+	dy, dz := Dot(d, s.up), Dot(d, s.back)
+
+	a := dy*dy + dz*dz
+	if a < 1e-12 {
+		return false
+	}
+	halfB := oy*dy + oz*dz
+	c := oy*oy + oz*oz - s.radius*s.radius
+	discriminant := halfB*halfB - a*c
+	if discriminant < 0 {
+		return false
+	}
+	sqrtD := math.Sqrt(discriminant)
+
+	for _, t := range [2]float64{(-halfB - sqrtD) / a, (-halfB + sqrtD) / a} {
+		if !rayT.Surrounds(t) {
+			continue
+		}
+		y, z := oy+t*dy, oz+t*dz
+		x := Dot(o, s.axis) + t*Dot(d, s.axis)
+		if y > 0 || z < 0 || math.Abs(x) > s.halfWidth {
+			continue
+		}
+		rec.T = t
+		rec.P = r.At(t)
+		rec.SetFaceNormal(r, s.up.Scale(y/s.radius).Add(s.back.Scale(z/s.radius)))
+		rec.Mat = s.mat
+		rec.U = (x + s.halfWidth) / (2 * s.halfWidth)
+		rec.V = math.Atan2(z, -y) / (math.Pi / 2)
+		return true
+	}
+	return false
+}
+
+func (s *sweepCurve) BoundingBox() AABB {
+	return s.bbox
+}
+
+func (s *sweepCurve) PrimitiveName() string       { return "Cyclorama" }
+func (s *sweepCurve) PrimitiveMaterial() Material { return s.mat }
+
+// finiteBounds returns the bounds of world's bounded objects, or a unit box
+// on the origin if there are none
+func finiteBounds(world Hittable) AABB {
+	box, ok := boundedBox(world)
+	if !ok {
+		return NewAABBFromPoints(Point3{X: -0.5, Z: -0.5}, Point3{X: 0.5, Y: 1, Z: 0.5})
+	}
+	return box
+}
+
+// boundedBox merges the finite bounding boxes below object
+func boundedBox(object Hittable) (AABB, bool) {
+	box := object.BoundingBox()
+	if isBoundedBox(box) {
+		return box, true
+	}
+	var children []Hittable
+	switch obj := object.(type) {
+	case *HittableList:
+		children = obj.Objects
+	case *BVHLeaf:
+		children = obj.objects
+	case *BVHNode:
+		if obj.left == nil {
+			return box, false
+		}
+		children = []Hittable{obj.left, obj.right}
+	default:
+		return box, false
+	}
+
+	merged, found := NewAABB(), false
+	for _, child := range children {
+		if b, ok := boundedBox(child); ok {
+			merged, found = NewAABBFromBoxes(merged, b), true
+		}
+	}
+	return merged, found
+}
+
+func isBoundedBox(box AABB) bool {
+	for axis := 0; axis < 3; axis++ {
+		i := box.AxisInterval(axis)
+		if math.IsInf(i.Min, 0) || math.IsInf(i.Max, 0) || i.Min > i.Max {
+			return false
+		}
+	}
+	return true
+}
+
+// sceneExtent is the larger of a box's horizontal diagonal and height
+func sceneExtent(box AABB) float64 {
+	extent := max(math.Hypot(box.X.Size(), box.Z.Size()), box.Y.Size())
+	if extent <= 0 {
+		return 1
+	}
+	return extent
+}
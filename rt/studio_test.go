@@ -0,0 +1,77 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAutoGroundPlaneFitsUnderScene(t *testing.T) {
+	world := NewHittableList()
+	world.Add(NewSphere(Point3{X: 3, Y: 2, Z: -1}, 1, NewLambertian(Color{X: 0.5})))
+	world.Add(NewPlane(Point3{Y: -50}, Vec3{Y: 1}, NewLambertian(Color{X: 0.5}))) // Ignored
+	ground := AutoGroundPlane(world, NewLambertian(Color{X: 0.8, Y: 0.8, Z: 0.8}))
+
+	rec := &HitRecord{}
+	if !ground.Hit(NewRay(Point3{X: 3, Y: 5, Z: -1}, Vec3{Y: -1}, 0), NewInterval(0.001, math.Inf(1)), rec) {
+		t.Fatal("ray straight down through the sphere misses the ground")
+	}
+	if math.Abs(rec.P.Y-1) > 1e-3 {
+		t.Errorf("ground at y = %g, want just under the sphere at 1", rec.P.Y)
+	}
+	if rec.Normal.Y <= 0 {
+		t.Errorf("ground normal %v seen from above, want up", rec.Normal)
+	}
+	// Far out towards the horizon
+	if !ground.Hit(NewRay(Point3{X: 3 + 10, Y: 5, Z: -1}, Vec3{Y: -1}, 0), NewInterval(0.001, math.Inf(1)), rec) {
+		t.Error("ground doesn't reach several scene sizes out")
+	}
+}
+
+func TestCycloramaIsSeamless(t *testing.T) {
+	const radius = 2.0
+	sweep := Cyclorama(20, 10, radius, NewLambertian(Color{X: 0.8, Y: 0.8, Z: 0.8}))
+	rayT := NewInterval(0.001, math.Inf(1))
+
+	// Walk the profile from the floor up to the wall, looking from the curve
+	// center: every ray hits at the radius or beyond, with no gaps
+	center := Point3{Y: radius, Z: radius}
+	for i := 0; i <= 32; i++ {
+		angle := float64(i) / 32 * math.Pi / 2
+		dir := Vec3{Y: -math.Cos(angle), Z: -math.Sin(angle)}
+		rec := &HitRecord{}
+		if !sweep.Hit(NewRay(center, dir, 0), rayT, rec) {
+			t.Fatalf("gap in the sweep at %.1f degrees", angle*180/math.Pi)
+		}
+		if math.Abs(rec.T-radius) > 1e-6 {
+			t.Errorf("at %.1f degrees hit at distance %g, want the curve radius %g", angle*180/math.Pi, rec.T, radius)
+		}
+		// The normal faces the center and turns smoothly from up to forward
+		if want := dir.Neg(); Dot(rec.Normal, want) < 1-1e-6 {
+			t.Errorf("at %.1f degrees normal %v, want %v", angle*180/math.Pi, rec.Normal, want)
+		}
+	}
+}
+
+func TestAutoCycloramaFacesCamera(t *testing.T) {
+	world := NewHittableList()
+	world.Add(NewSphere(Point3{Y: 1}, 1, NewLambertian(Color{X: 0.5})))
+	camera := NewCameraBuilder().SetPosition(Point3{X: 10, Y: 1, Z: 0}, Point3{Y: 1}, Vec3{Y: 1}).Build()
+	sweep := AutoCyclorama(world, camera, NewLambertian(Color{X: 0.8, Y: 0.8, Z: 0.8}))
+
+	// Looking past the sphere from the camera hits the wall behind it
+	rec := &HitRecord{}
+	if !sweep.Hit(NewRay(Point3{X: 10, Y: 3, Z: 0}, Vec3{X: -1}, 0), NewInterval(0.001, math.Inf(1)), rec) {
+		t.Fatal("no backdrop behind the scene")
+	}
+	if rec.P.X > -1 {
+		t.Errorf("backdrop at x = %g, want behind the sphere (x < -1)", rec.P.X)
+	}
+	if rec.Normal.X <= 0 {
+		t.Errorf("backdrop normal %v, want facing the camera", rec.Normal)
+	}
+
+	// And the floor is under the sphere
+	if !sweep.Hit(NewRay(Point3{Y: 5}, Vec3{Y: -1}, 0), NewInterval(0.001, math.Inf(1)), rec) || math.Abs(rec.P.Y) > 1e-3 {
+		t.Errorf("floor under the sphere at %v, want y = 0", rec.P)
+	}
+}
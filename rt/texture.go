@@ -76,6 +76,62 @@ func (c *CheckerTexture) Value(u, v float64, p Point3) Color {
 	return c.odd.Value(u, v, p)
 }
 
+// VertexColorTexture interpolates the colors of a triangle's three vertices.
+// It relies on triangles reporting barycentric coordinates in (u, v).
+type VertexColorTexture struct {
+	c0, c1, c2 Color
+}
+
+func NewVertexColorTexture(c0, c1, c2 Color) *VertexColorTexture {
+	return &VertexColorTexture{c0: c0, c1: c1, c2: c2}
+}
+
+func (t *VertexColorTexture) Value(u, v float64, p Point3) Color {
+	w := 1 - u - v
+	return t.c0.Scale(w).Add(t.c1.Scale(u)).Add(t.c2.Scale(v))
+}
+
+// StripeTexture alternates two textures in bands along v, e.g. window blinds
+// when used as a spot light gobo. Duty is the fraction of each band using on.
+type StripeTexture struct {
+	count int
+	duty  float64
+	on    Texture
+	off   Texture
+}
+
+func NewStripeTexture(count int, duty float64, on, off Texture) *StripeTexture {
+	return &StripeTexture{
+		count: max(count, 1),
+		duty:  clampFloat(duty, 0, 1),
+		on:    on,
+		off:   off,
+	}
+}
+
+func (t *StripeTexture) Value(u, v float64, p Point3) Color {
+	band := v * float64(t.count)
+	if band-math.Floor(band) < t.duty {
+		return t.on.Value(u, v, p)
+	}
+	return t.off.Value(u, v, p)
+}
+
+// ScaledTexture multiplies another texture by a constant, e.g. to brighten
+// an emission texture
+type ScaledTexture struct {
+	tex   Texture
+	scale float64
+}
+
+func NewScaledTexture(tex Texture, scale float64) *ScaledTexture {
+	return &ScaledTexture{tex: tex, scale: scale}
+}
+
+func (t *ScaledTexture) Value(u, v float64, p Point3) Color {
+	return t.tex.Value(u, v, p).Scale(t.scale)
+}
+
 // TODO add option for turbulence
 // TODO add different noise types and turbulences
 func (tex *NoiseTexture) Value(u, v float64, p Point3) Color {
@@ -0,0 +1,147 @@
+package rt
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// =============================================================================
+// TIGHT BOUNDS FOR ROTATED OBJECTS
+// =============================================================================
+
+// Rotating an object's AABB and boxing the result inflates it, up to sqrt(3)
+// per axis for a diagonal mesh, and nested rotations compound the error. With
+// tight bounds enabled (the default) the rotation wrappers instead re-derive
+// their box from the transformed geometry: triangle and quad corners,
+// sphere centers plus radius, and the box corners of anything else.
+
+var tightBounds atomic.Bool
+
+func init() {
+	tightBounds.Store(true)
+}
+
+// SetTightTransformBounds turns vertex-derived bounds for rotated objects on
+// or off (on by default). Off restores the cheaper rotated-box bounds.
+// Affects wrappers created afterwards.
+func SetTightTransformBounds(enabled bool) {
+	tightBounds.Store(enabled)
+}
+
+// boundsMap maps object-space points to the space being bounded. rigid is
+// false once a scale is involved, since spheres then stop being spheres.
+type boundsMap struct {
+	apply func(Vec3) Vec3
+	rigid bool
+}
+
+// then returns the map that applies f before m
+func (m boundsMap) then(f func(Vec3) Vec3, rigid bool) boundsMap {
+	return boundsMap{
+		apply: func(p Vec3) Vec3 { return m.apply(f(p)) },
+		rigid: m.rigid && rigid,
+	}
+}
+
+// transformedBounds returns the box around obj after the rotation rotate
+func transformedBounds(obj Hittable, rotate func(Vec3) Vec3) AABB {
+	m := boundsMap{apply: rotate, rigid: true}
+	if !tightBounds.Load() {
+		return cornerBounds(obj.BoundingBox(), m)
+	}
+	return mappedBounds(obj, m)
+}
+
+// mappedBounds bounds obj under m, walking wrappers and containers down to
+// primitives whose exact extent is known
+func mappedBounds(object Hittable, m boundsMap) AABB {
+	switch obj := object.(type) {
+	case *BVHNode:
+		if obj.left == obj.right {
+			return mappedBounds(obj.left, m)
+		}
+		return NewAABBFromBoxes(mappedBounds(obj.left, m), mappedBounds(obj.right, m))
+	case *QuantizedBVH:
+		box := NewAABB()
+		for _, leaf := range obj.leaves {
+			box = NewAABBFromBoxes(box, mappedListBounds(leaf.objects, m))
+		}
+		return box
+	case *BVHLeaf:
+		return mappedListBounds(obj.objects, m)
+	case *HittableList:
+		return mappedListBounds(obj.Objects, m)
+	case *MaterialOverride:
+		return mappedBounds(obj.Obj, m)
+	case *Translate:
+		return mappedBounds(obj.Obj, m.then(func(p Vec3) Vec3 { return p.Add(obj.Offset) }, true))
+	case *Scale:
+		return mappedBounds(obj.Obj, m.then(func(p Vec3) Vec3 { return p.Mult(obj.Factor) }, false))
+	case *Rotate:
+		return mappedBounds(obj.Obj, m.then(obj.Rotation.Rotate, true))
+	case *RotateX:
+		return mappedBounds(obj.Obj, m.then(obj.rotate, true))
+	case *RotateY:
+		return mappedBounds(obj.Obj, m.then(obj.rotate, true))
+	case *RotateZ:
+		return mappedBounds(obj.Obj, m.then(obj.rotate, true))
+	case *Triangle:
+		return pointBounds(m, obj.v0, obj.v1, obj.v2)
+	case *DeformingTriangle:
+		return pointBounds(m, obj.open[0], obj.open[1], obj.open[2], obj.close[0], obj.close[1], obj.close[2])
+	case *Quad:
+		return pointBounds(m, obj.Q, obj.Q.Add(obj.u), obj.Q.Add(obj.v), obj.Q.Add(obj.u).Add(obj.v))
+	case *Sphere:
+		if m.rigid {
+			// Rigid maps keep the sphere round: bound the moved centers
+			r := Vec3{X: obj.Radius, Y: obj.Radius, Z: obj.Radius}
+			c0, c1 := m.apply(obj.Center.At(0)), m.apply(obj.Center.At(1))
+			return NewAABBFromBoxes(
+				NewAABBFromPoints(c0.Sub(r), c0.Add(r)),
+				NewAABBFromPoints(c1.Sub(r), c1.Add(r)),
+			)
+		}
+	}
+	return cornerBounds(object.BoundingBox(), m)
+}
+
+func mappedListBounds(objects []Hittable, m boundsMap) AABB {
+	box := NewAABB()
+	for _, obj := range objects {
+		box = NewAABBFromBoxes(box, mappedBounds(obj, m))
+	}
+	return box
+}
+
+// cornerBounds bounds the eight mapped corners of a box
+func cornerBounds(bbox AABB, m boundsMap) AABB {
+	corners := make([]Point3, 0, 8)
+	for i := range 2 {
+		for j := range 2 {
+			for k := range 2 {
+				corners = append(corners, Point3{
+					X: float64(i)*bbox.X.Max + float64(1-i)*bbox.X.Min,
+					Y: float64(j)*bbox.Y.Max + float64(1-j)*bbox.Y.Min,
+					Z: float64(k)*bbox.Z.Max + float64(1-k)*bbox.Z.Min,
+				})
+			}
+		}
+	}
+	return pointBounds(m, corners...)
+}
+
+// pointBounds returns the box around the mapped points
+func pointBounds(m boundsMap, points ...Point3) AABB {
+	min := Point3{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+	max := Point3{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+	for _, p := range points {
+		p = m.apply(p)
+		min.X = math.Min(min.X, p.X)
+		max.X = math.Max(max.X, p.X)
+		min.Y = math.Min(min.Y, p.Y)
+		max.Y = math.Max(max.Y, p.Y)
+		min.Z = math.Min(min.Z, p.Z)
+		max.Z = math.Max(max.Z, p.Z)
+	}
+	return NewAABBFromPoints(min, max)
+}
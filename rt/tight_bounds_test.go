@@ -0,0 +1,55 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRotatedMeshBoundsAreTight(t *testing.T) {
+	t.Cleanup(func() { SetTightTransformBounds(true) })
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+
+	// A thin strip of triangles along the XY diagonal; rotating it by -45
+	// degrees about Z lines it up with the X axis
+	var tris []Hittable
+	for i := range 10 {
+		a := Point3{X: float64(i), Y: float64(i)}
+		b := Point3{X: float64(i + 1), Y: float64(i + 1)}
+		tris = append(tris, NewTriangle(a, b, a.Add(Vec3{Z: 0.1}), mat))
+	}
+	mesh := NewBVHNode(tris, 0, len(tris))
+
+	SetTightTransformBounds(false)
+	loose := Rz(mesh, -45).BoundingBox()
+	SetTightTransformBounds(true)
+	tight := Rz(mesh, -45).BoundingBox()
+
+	if got := tight.Y.Size(); got > 0.01 {
+		t.Errorf("tight Y extent = %g, want ~0 for a strip aligned with X", got)
+	}
+	if loose.Y.Size() < 10 {
+		t.Errorf("loose Y extent = %g, expected the rotated-box bound to be much larger", loose.Y.Size())
+	}
+
+	// Every rotated vertex must still be inside the tight box
+	rz := Rz(mesh, -45)
+	for _, h := range tris {
+		tri := h.(*Triangle)
+		for _, v := range []Point3{tri.v0, tri.v1, tri.v2} {
+			p := rz.rotate(v)
+			if !tight.X.Contains(p.X) || !tight.Y.Contains(p.Y) || !tight.Z.Contains(p.Z) {
+				t.Fatalf("vertex %v rotates to %v outside %v", v, p, tight)
+			}
+		}
+	}
+}
+
+func TestRotatedSphereKeepsSphereBounds(t *testing.T) {
+	sphere := NewSphere(Point3{X: 2, Y: 0, Z: 0}, 1, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5}))
+	box := NewRotate(sphere, NewQuaternionFromEuler(Vec3{X: 30, Y: 45, Z: 20}, RotationXYZ)).BoundingBox()
+	for _, axis := range []Interval{box.X, box.Y, box.Z} {
+		if math.Abs(axis.Size()-2) > 1e-9 {
+			t.Errorf("rotated sphere box extent = %g, want the diameter 2", axis.Size())
+		}
+	}
+}
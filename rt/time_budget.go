@@ -0,0 +1,104 @@
+package rt
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// TIME-BUDGETED RENDERING
+// =============================================================================
+
+// budgetPassFraction sets how much of the total budget one accumulation pass
+// aims to take, so the display refreshes several times over a render
+const budgetPassFraction = 8
+
+// timeBudget replaces the fixed preview/medium/final passes with accumulation
+// passes that run until a wall-clock budget expires. Each pass traces a batch
+// of samples per pixel sized from the speed of the previous pass. The film
+// keeps per-pixel sample counts, so once one full pass is in, buckets that
+// would start after the deadline are skipped and the image is saved on time.
+type timeBudget struct {
+	budget   time.Duration
+	deadline time.Time
+	batch    int          // Samples per pixel for the next accumulation pass
+	samples  atomic.Int64 // Samples per pixel of all completed accumulation passes
+	passes   atomic.Int64
+}
+
+// SetTimeBudget makes the renderer accumulate samples until budget has
+// elapsed instead of rendering a fixed SPP (0 disables). The camera's
+// SamplesPerPixel is then only the starting point and is updated to the
+// samples per pixel actually reached.
+func (r *BucketRenderer) SetTimeBudget(budget time.Duration) *BucketRenderer {
+	r.budget = nil
+	if budget > 0 {
+		r.budget = &timeBudget{budget: budget, batch: 1}
+	}
+	return r
+}
+
+// start begins the budget clock
+func (b *timeBudget) start() {
+	if b != nil {
+		b.deadline = time.Now().Add(b.budget)
+	}
+}
+
+// expired reports whether the budget has run out
+func (b *timeBudget) expired() bool {
+	return b != nil && !time.Now().Before(b.deadline)
+}
+
+// skipBucket reports whether a bucket of pass should be left out because
+// the budget ran out. The preview and first accumulation pass always finish
+// so every pixel has samples in the film.
+func (b *timeBudget) skipBucket(pass int) bool {
+	return b != nil && pass > 0 && b.samples.Load() > 0 && b.expired()
+}
+
+// passSettings returns the samples, depth and film accumulation for a pass:
+// a biased 1 SPP preview, then accumulating batches at full depth
+func (b *timeBudget) passSettings(pass int, maxDepth int) (samples int, depth int, accumulate bool) {
+	if pass == 0 {
+		return 1, min(3, maxDepth), false
+	}
+	return b.batch, maxDepth, true
+}
+
+// passDone records a finished pass and sizes the next batch to take about
+// 1/budgetPassFraction of the budget, or what is left of it. Samples of a
+// pass cut short by the deadline are in the film but not counted, so the
+// reported SPP is what every pixel has.
+func (b *timeBudget) passDone(pass int, samples int, wall time.Duration, complete bool) {
+	if b == nil || pass == 0 {
+		return
+	}
+	b.passes.Add(1)
+	if !complete {
+		return
+	}
+	b.samples.Add(int64(samples))
+	if samples <= 0 || wall <= 0 {
+		return
+	}
+	target := min(b.budget/budgetPassFraction, time.Until(b.deadline))
+	perSample := wall / time.Duration(samples)
+	b.batch = max(1, int(target/perSample))
+}
+
+// status returns the overlay text for the budget
+func (b *timeBudget) status() string {
+	remaining := max(0, time.Until(b.deadline))
+	return fmt.Sprintf("Budget: %s left | %d SPP", FormatDuration(remaining), b.samples.Load())
+}
+
+// report prints what the budget achieved
+func (b *timeBudget) report() {
+	if b == nil {
+		return
+	}
+	fmt.Printf("Time budget %s: %d accumulation passes, %d samples per pixel\n",
+		FormatDuration(b.budget), b.passes.Load(), b.samples.Load())
+}
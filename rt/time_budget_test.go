@@ -0,0 +1,62 @@
+package rt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeBudgetAccumulatesUntilDeadline(t *testing.T) {
+	world := NewHittableList()
+	world.Add(NewSphere(Point3{Z: -1}, 0.5, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})))
+	camera := NewCameraBuilder().SetResolution(24, 1).SetQuality(4, 4).Build()
+
+	budget := 150 * time.Millisecond
+	r := NewBucketRenderer(camera, world, 8, 2).SetTimeBudget(budget)
+	defer r.Close()
+
+	// Drive the passes the way Update does
+	start := time.Now()
+	r.budget.start()
+	for {
+		r.renderPass()
+		r.currentPass++
+		if !r.morePasses() {
+			break
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 3*budget {
+		t.Fatalf("render took %v with a %v budget", elapsed, budget)
+	}
+
+	passes, samples := r.budget.passes.Load(), int(r.budget.samples.Load())
+	if passes < 2 || samples < 2 {
+		t.Fatalf("got %d passes and %d SPP, want the budget to allow several", passes, samples)
+	}
+	if samples <= int(passes) {
+		t.Errorf("%d SPP over %d passes, want batches sized up from the measured pass time", samples, passes)
+	}
+
+	// Every pixel has at least the reported SPP; a cut-short last pass only adds
+	for y := 0; y < camera.ImageHeight; y++ {
+		for x := 0; x < camera.ImageWidth; x++ {
+			if n := r.film.SampleCount(x, y); n < samples {
+				t.Fatalf("pixel (%d, %d) has %d samples, want at least %d", x, y, n, samples)
+			}
+		}
+	}
+}
+
+func TestTimeBudgetPassSettings(t *testing.T) {
+	b := &timeBudget{budget: time.Second, batch: 7}
+	if samples, depth, accumulate := b.passSettings(0, 10); samples != 1 || depth != 3 || accumulate {
+		t.Errorf("preview pass = (%d, %d, %v), want (1, 3, false)", samples, depth, accumulate)
+	}
+	if samples, depth, accumulate := b.passSettings(4, 10); samples != 7 || depth != 10 || !accumulate {
+		t.Errorf("accumulation pass = (%d, %d, %v), want (7, 10, true)", samples, depth, accumulate)
+	}
+
+	var disabled *timeBudget
+	if disabled.expired() || disabled.skipBucket(3) {
+		t.Error("a nil budget must never expire or skip buckets")
+	}
+}
@@ -0,0 +1,115 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// =============================================================================
+// DISPLAY TONE MAPPING
+// =============================================================================
+
+// ToneMapMode selects how HDR pixels are brought into the display range
+// before gamma encoding
+type ToneMapMode int
+
+const (
+	ToneMapClamp     ToneMapMode = iota // Clip each channel at 1 (the film keeps the HDR values)
+	ToneMapHighlight                    // Linear up to Knee, then a soft shoulder that keeps hue
+	ToneMapACES                         // Filmic ACES fit per channel
+	ToneMapAuto                         // Highlight with depth of field, clamp otherwise
+)
+
+var toneMapModeNames = []string{"clamp", "highlight", "aces", "auto"}
+
+func (m ToneMapMode) String() string {
+	if int(m) < 0 || int(m) >= len(toneMapModeNames) {
+		return "unknown"
+	}
+	return toneMapModeNames[m]
+}
+
+// ParseToneMapMode converts a mode name (e.g. "highlight") to a ToneMapMode
+func ParseToneMapMode(name string) (ToneMapMode, error) {
+	for i, n := range toneMapModeNames {
+		if strings.EqualFold(name, n) {
+			return ToneMapMode(i), nil
+		}
+	}
+	return ToneMapClamp, fmt.Errorf("unknown tone map: %s (use %s)",
+		name, strings.Join(toneMapModeNames, ", "))
+}
+
+// ToneMapConfig controls the display and PNG tone curve. The film, HDR
+// output and firefly filter work on the unmapped values.
+type ToneMapConfig struct {
+	Mode  ToneMapMode
+	Knee  float64 // Highlight: values below stay linear
+	White float64 // Highlight: value that reaches display white
+}
+
+// DefaultToneMapConfig returns the plain clamp, with a highlight shoulder
+// from 0.8 up to a white point of 16
+func DefaultToneMapConfig() ToneMapConfig {
+	return ToneMapConfig{
+		Mode:  ToneMapClamp,
+		Knee:  0.8,
+		White: 16,
+	}
+}
+
+// Apply maps an exposed HDR color into [0, 1]. Clamping happens per channel
+// at encode time, so for ToneMapClamp the color is returned unchanged.
+func (config ToneMapConfig) Apply(c Color) Color {
+	switch config.Mode {
+	case ToneMapHighlight:
+		return highlightShoulder(c, config.Knee, config.White)
+	case ToneMapACES:
+		return Color{X: acesFilm(c.X), Y: acesFilm(c.Y), Z: acesFilm(c.Z)}
+	}
+	return c
+}
+
+// highlightShoulder compresses the largest channel above knee with an
+// extended Reinhard curve reaching 1 at white, and scales the others with it.
+// Clamping channels separately turns the discs of defocused lights into flat
+// clipped blobs; scaling keeps their hue and the falloff toward their edges.
+func highlightShoulder(c Color, knee, white float64) Color {
+	knee = clampFloat(knee, 0, 0.99)
+	peak := math.Max(c.X, math.Max(c.Y, c.Z))
+	if peak <= knee {
+		return c
+	}
+	span := 1 - knee
+	limit := math.Max(white-knee, span) / span
+	excess := math.Min((peak-knee)/span, limit)
+	mapped := knee + span*excess*(1+excess/(limit*limit))/(1+excess)
+	return c.Scale(mapped / peak)
+}
+
+// acesFilm is Narkowicz's fit of the ACES reference rendering transform,
+// with his 0.6 exposure so that 1 stays a bright but unclipped white
+func acesFilm(x float64) float64 {
+	x = math.Max(0, x) * 0.6
+	return clampFloat(x*(2.51*x+0.03)/(x*(2.43*x+0.59)+0.14), 0, 1)
+}
+
+// resolve picks the mode ToneMapAuto stands for with camera
+func (config ToneMapConfig) resolve(camera *Camera) ToneMapConfig {
+	if config.Mode == ToneMapAuto {
+		config.Mode = ToneMapClamp
+		if camera != nil && (camera.DefocusAngle > 0 || camera.Aperture > 0) {
+			config.Mode = ToneMapHighlight
+		}
+	}
+	return config
+}
+
+// SetToneMap sets the tone curve of the displayed and saved image. With
+// ToneMapAuto, renders with depth of field get the highlight shoulder so
+// defocused lights bloom into bright discs instead of clipping.
+func (r *BucketRenderer) SetToneMap(config ToneMapConfig) *BucketRenderer {
+	r.toneMap = config.resolve(r.camera)
+	return r
+}
@@ -0,0 +1,44 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHighlightShoulder(t *testing.T) {
+	config := DefaultToneMapConfig()
+	config.Mode = ToneMapHighlight
+
+	// Below the knee nothing changes
+	dim := Color{X: 0.5, Y: 0.2, Z: 0.1}
+	if got := config.Apply(dim); got != dim {
+		t.Errorf("below the knee: %v, want %v", got, dim)
+	}
+
+	// Brighter inputs stay ordered and in range, keeping their hue
+	prev := 0.0
+	for _, v := range []float64{0.9, 1, 2, 4, 8, 15} {
+		c := config.Apply(Color{X: v, Y: v / 2, Z: v / 4})
+		if c.X <= prev || c.X > 1 {
+			t.Errorf("%g maps to %g (previous %g)", v, c.X, prev)
+		}
+		if math.Abs(c.Y/c.X-0.5) > 1e-9 || math.Abs(c.Z/c.X-0.25) > 1e-9 {
+			t.Errorf("%g: hue changed to %v", v, c)
+		}
+		prev = c.X
+	}
+	if white := config.Apply(Color{X: 16, Y: 16, Z: 16}); math.Abs(white.X-1) > 1e-9 {
+		t.Errorf("white point maps to %g, want 1", white.X)
+	}
+}
+
+func TestToneMapAutoFollowsDepthOfField(t *testing.T) {
+	config := DefaultToneMapConfig()
+	config.Mode = ToneMapAuto
+	if mode := config.resolve(NewCamera()).Mode; mode != ToneMapClamp {
+		t.Errorf("pinhole camera: %s, want clamp", mode)
+	}
+	if mode := config.resolve(NewCamera().SetDefocus(2, 5)).Mode; mode != ToneMapHighlight {
+		t.Errorf("defocused camera: %s, want highlight", mode)
+	}
+}
@@ -7,9 +7,12 @@ import "math"
 // =============================================================================
 
 type Transform struct {
-	Scale    Vec3
-	Rotation Vec3
-	Position Vec3
+	Scale         Vec3
+	Rotation      Vec3          // Euler angles in degrees
+	RotationOrder RotationOrder // Order the Euler angles are applied in
+	Orientation   *Quaternion   // When set, replaces Rotation/RotationOrder
+	Position      Vec3
+	Material      Material // Optional per-instance material override (nil keeps the object's own)
 }
 
 func NewTransform() *Transform {
@@ -20,22 +23,35 @@ func NewTransform() *Transform {
 	}
 }
 
-// Order: Scale -> Rotate (X->Y->Z) -> Translate
+// Order: Scale -> Rotate (Orientation, or Euler in RotationOrder) -> Translate
 func (t *Transform) Apply(obj Hittable) Hittable {
 	result := obj
 
+	if t.Material != nil {
+		result = NewMaterialOverride(result, t.Material)
+	}
+
 	if t.Scale.X != 1.0 || t.Scale.Y != 1.0 || t.Scale.Z != 1.0 {
 		result = NewScale(result, t.Scale)
 	}
 
-	if t.Rotation.X != 0 {
-		result = Rx(result, t.Rotation.X)
-	}
-	if t.Rotation.Y != 0 {
-		result = Ry(result, t.Rotation.Y)
-	}
-	if t.Rotation.Z != 0 {
-		result = Rz(result, t.Rotation.Z)
+	switch {
+	case t.Orientation != nil:
+		result = NewRotate(result, *t.Orientation)
+	case t.RotationOrder != RotationXYZ:
+		if t.Rotation != (Vec3{}) {
+			result = NewRotate(result, NewQuaternionFromEuler(t.Rotation, t.RotationOrder))
+		}
+	default:
+		if t.Rotation.X != 0 {
+			result = Rx(result, t.Rotation.X)
+		}
+		if t.Rotation.Y != 0 {
+			result = Ry(result, t.Rotation.Y)
+		}
+		if t.Rotation.Z != 0 {
+			result = Rz(result, t.Rotation.Z)
+		}
 	}
 
 	if t.Position.X != 0 || t.Position.Y != 0 || t.Position.Z != 0 {
@@ -65,11 +81,66 @@ func (t *Transform) SetRotationY(angle float64) *Transform {
 	return t
 }
 
+// SetRotationOrder sets the order the Euler angles are applied in, e.g.
+// RotationZXY to match transforms exported from another tool
+func (t *Transform) SetRotationOrder(order RotationOrder) *Transform {
+	t.RotationOrder = order
+	return t
+}
+
+// SetQuaternion rotates by q instead of the Euler angles
+func (t *Transform) SetQuaternion(q Quaternion) *Transform {
+	q = q.Normalized()
+	t.Orientation = &q
+	return t
+}
+
+// SetAxisAngle rotates by degrees around axis instead of the Euler angles
+func (t *Transform) SetAxisAngle(axis Vec3, degrees float64) *Transform {
+	return t.SetQuaternion(NewQuaternionAxisAngle(axis, degrees))
+}
+
 func (t *Transform) SetPosition(p Vec3) *Transform {
 	t.Position = p
 	return t
 }
 
+// SetMaterial overrides the material of the wrapped object for this instance
+func (t *Transform) SetMaterial(mat Material) *Transform {
+	t.Material = mat
+	return t
+}
+
+// =============================================================================
+// MATERIAL OVERRIDE
+// =============================================================================
+
+// MaterialOverride replaces the material of every hit on the wrapped object.
+// The wrapped object (e.g. a mesh BVH) is shared, so instances can differ in
+// material without duplicating geometry. With nested overrides the outermost
+// one wins.
+type MaterialOverride struct {
+	Obj Hittable
+	Mat Material
+}
+
+func NewMaterialOverride(obj Hittable, mat Material) *MaterialOverride {
+	return &MaterialOverride{Obj: obj, Mat: mat}
+}
+
+func (m *MaterialOverride) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	if !m.Obj.Hit(r, rayT, rec) {
+		return false
+	}
+
+	rec.Mat = m.Mat
+	return true
+}
+
+func (m *MaterialOverride) BoundingBox() AABB {
+	return m.Obj.BoundingBox()
+}
+
 // =============================================================================
 // INDIVIDUAL TRANSFORM PRIMITIVES
 // =============================================================================
@@ -91,7 +162,7 @@ func NewTranslate(obj Hittable, offset Vec3) *Translate {
 }
 
 func (t *Translate) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
-	offsetRay := NewRay(r.Origin().Sub(t.Offset), r.Direction(), r.Time())
+	offsetRay := r.reframed(r.Origin().Sub(t.Offset), r.Direction())
 
 	if !t.Obj.Hit(offsetRay, rayT, rec) {
 		return false
@@ -109,6 +180,41 @@ func (t *Translate) BoundingBox() AABB {
 // ROTATION TRANSFORMS
 // =============================================================================
 
+// Rotate rotates an object by an arbitrary quaternion
+type Rotate struct {
+	Obj      Hittable
+	Rotation Quaternion
+	inverse  Quaternion
+	bbox     AABB
+}
+
+func NewRotate(obj Hittable, q Quaternion) *Rotate {
+	q = q.Normalized()
+	return &Rotate{
+		Obj:      obj,
+		Rotation: q,
+		inverse:  q.Conjugate(),
+		bbox:     transformedBounds(obj, q.Rotate),
+	}
+}
+
+func (rot *Rotate) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	rotatedRay := r.reframed(rot.inverse.Rotate(r.Origin()), rot.inverse.Rotate(r.Direction()))
+
+	if !rot.Obj.Hit(rotatedRay, rayT, rec) {
+		return false
+	}
+
+	rec.P = rot.Rotation.Rotate(rec.P)
+	rec.Normal = rot.Rotation.Rotate(rec.Normal)
+
+	return true
+}
+
+func (rot *Rotate) BoundingBox() AABB {
+	return rot.bbox
+}
+
 // RotateY rotates an object around the Y-axis
 type RotateY struct {
 	Obj      Hittable
@@ -119,40 +225,21 @@ type RotateY struct {
 
 func Ry(obj Hittable, angle float64) *RotateY {
 	radians := DegreesToRadians(angle)
-	sinTheta := math.Sin(radians)
-	cosTheta := math.Cos(radians)
-
-	bbox := obj.BoundingBox()
-	min := Point3{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
-	max := Point3{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
-
-	for i := range 2 {
-		for j := range 2 {
-			for k := range 2 {
-				x := float64(i)*bbox.X.Max + float64(1-i)*bbox.X.Min
-				y := float64(j)*bbox.Y.Max + float64(1-j)*bbox.Y.Min
-				z := float64(k)*bbox.Z.Max + float64(1-k)*bbox.Z.Min
-
-				newX := cosTheta*x + sinTheta*z
-				newZ := -sinTheta*x + cosTheta*z
-
-				tester := Vec3{X: newX, Y: y, Z: newZ}
-
-				min.X = math.Min(min.X, tester.X)
-				max.X = math.Max(max.X, tester.X)
-				min.Y = math.Min(min.Y, tester.Y)
-				max.Y = math.Max(max.Y, tester.Y)
-				min.Z = math.Min(min.Z, tester.Z)
-				max.Z = math.Max(max.Z, tester.Z)
-			}
-		}
+	ry := &RotateY{
+		Obj:      obj,
+		SinTheta: math.Sin(radians),
+		CosTheta: math.Cos(radians),
 	}
+	ry.bbox = transformedBounds(obj, ry.rotate)
+	return ry
+}
 
-	return &RotateY{
-		Obj:      obj,
-		SinTheta: sinTheta,
-		CosTheta: cosTheta,
-		bbox:     NewAABBFromPoints(min, max),
+// rotate applies the rotation to an object-space point
+func (ry *RotateY) rotate(p Vec3) Vec3 {
+	return Vec3{
+		X: ry.CosTheta*p.X + ry.SinTheta*p.Z,
+		Y: p.Y,
+		Z: -ry.SinTheta*p.X + ry.CosTheta*p.Z,
 	}
 }
 
@@ -166,7 +253,7 @@ func (ry *RotateY) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 	direction.X = ry.CosTheta*r.Direction().X - ry.SinTheta*r.Direction().Z
 	direction.Z = ry.SinTheta*r.Direction().X + ry.CosTheta*r.Direction().Z
 
-	rotatedRay := NewRay(origin, direction, r.Time())
+	rotatedRay := r.reframed(origin, direction)
 
 	if !ry.Obj.Hit(rotatedRay, rayT, rec) {
 		return false
@@ -200,40 +287,21 @@ type RotateX struct {
 
 func Rx(obj Hittable, angle float64) *RotateX {
 	radians := DegreesToRadians(angle)
-	sinTheta := math.Sin(radians)
-	cosTheta := math.Cos(radians)
-
-	bbox := obj.BoundingBox()
-	min := Point3{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
-	max := Point3{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
-
-	for i := range 2 {
-		for j := range 2 {
-			for k := range 2 {
-				x := float64(i)*bbox.X.Max + float64(1-i)*bbox.X.Min
-				y := float64(j)*bbox.Y.Max + float64(1-j)*bbox.Y.Min
-				z := float64(k)*bbox.Z.Max + float64(1-k)*bbox.Z.Min
-
-				newY := cosTheta*y - sinTheta*z
-				newZ := sinTheta*y + cosTheta*z
-
-				tester := Vec3{X: x, Y: newY, Z: newZ}
-
-				min.X = math.Min(min.X, tester.X)
-				max.X = math.Max(max.X, tester.X)
-				min.Y = math.Min(min.Y, tester.Y)
-				max.Y = math.Max(max.Y, tester.Y)
-				min.Z = math.Min(min.Z, tester.Z)
-				max.Z = math.Max(max.Z, tester.Z)
-			}
-		}
+	rx := &RotateX{
+		Obj:      obj,
+		SinTheta: math.Sin(radians),
+		CosTheta: math.Cos(radians),
 	}
+	rx.bbox = transformedBounds(obj, rx.rotate)
+	return rx
+}
 
-	return &RotateX{
-		Obj:      obj,
-		SinTheta: sinTheta,
-		CosTheta: cosTheta,
-		bbox:     NewAABBFromPoints(min, max),
+// rotate applies the rotation to an object-space point
+func (rx *RotateX) rotate(p Vec3) Vec3 {
+	return Vec3{
+		X: p.X,
+		Y: rx.CosTheta*p.Y - rx.SinTheta*p.Z,
+		Z: rx.SinTheta*p.Y + rx.CosTheta*p.Z,
 	}
 }
 
@@ -241,13 +309,14 @@ func (rx *RotateX) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 	origin := r.Origin()
 	direction := r.Direction()
 
-	origin.Y = rx.CosTheta*r.Origin().Y - rx.SinTheta*r.Origin().Z
-	origin.Z = rx.SinTheta*r.Origin().Y + rx.CosTheta*r.Origin().Z
+	// Rotate the ray by -theta into object space
+	origin.Y = rx.CosTheta*r.Origin().Y + rx.SinTheta*r.Origin().Z
+	origin.Z = -rx.SinTheta*r.Origin().Y + rx.CosTheta*r.Origin().Z
 
-	direction.Y = rx.CosTheta*r.Direction().Y - rx.SinTheta*r.Direction().Z
-	direction.Z = rx.SinTheta*r.Direction().Y + rx.CosTheta*r.Direction().Z
+	direction.Y = rx.CosTheta*r.Direction().Y + rx.SinTheta*r.Direction().Z
+	direction.Z = -rx.SinTheta*r.Direction().Y + rx.CosTheta*r.Direction().Z
 
-	rotatedRay := NewRay(origin, direction, r.Time())
+	rotatedRay := r.reframed(origin, direction)
 
 	if !rx.Obj.Hit(rotatedRay, rayT, rec) {
 		return false
@@ -281,40 +350,21 @@ type RotateZ struct {
 
 func Rz(obj Hittable, angle float64) *RotateZ {
 	radians := DegreesToRadians(angle)
-	sinTheta := math.Sin(radians)
-	cosTheta := math.Cos(radians)
-
-	bbox := obj.BoundingBox()
-	min := Point3{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
-	max := Point3{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
-
-	for i := range 2 {
-		for j := range 2 {
-			for k := range 2 {
-				x := float64(i)*bbox.X.Max + float64(1-i)*bbox.X.Min
-				y := float64(j)*bbox.Y.Max + float64(1-j)*bbox.Y.Min
-				z := float64(k)*bbox.Z.Max + float64(1-k)*bbox.Z.Min
-
-				newX := cosTheta*x - sinTheta*y
-				newY := sinTheta*x + cosTheta*y
-
-				tester := Vec3{X: newX, Y: newY, Z: z}
-
-				min.X = math.Min(min.X, tester.X)
-				max.X = math.Max(max.X, tester.X)
-				min.Y = math.Min(min.Y, tester.Y)
-				max.Y = math.Max(max.Y, tester.Y)
-				min.Z = math.Min(min.Z, tester.Z)
-				max.Z = math.Max(max.Z, tester.Z)
-			}
-		}
+	rz := &RotateZ{
+		Obj:      obj,
+		SinTheta: math.Sin(radians),
+		CosTheta: math.Cos(radians),
 	}
+	rz.bbox = transformedBounds(obj, rz.rotate)
+	return rz
+}
 
-	return &RotateZ{
-		Obj:      obj,
-		SinTheta: sinTheta,
-		CosTheta: cosTheta,
-		bbox:     NewAABBFromPoints(min, max),
+// rotate applies the rotation to an object-space point
+func (rz *RotateZ) rotate(p Vec3) Vec3 {
+	return Vec3{
+		X: rz.CosTheta*p.X - rz.SinTheta*p.Y,
+		Y: rz.SinTheta*p.X + rz.CosTheta*p.Y,
+		Z: p.Z,
 	}
 }
 
@@ -322,13 +372,14 @@ func (rz *RotateZ) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 	origin := r.Origin()
 	direction := r.Direction()
 
-	origin.X = rz.CosTheta*r.Origin().X - rz.SinTheta*r.Origin().Y
-	origin.Y = rz.SinTheta*r.Origin().X + rz.CosTheta*r.Origin().Y
+	// Rotate the ray by -theta into object space
+	origin.X = rz.CosTheta*r.Origin().X + rz.SinTheta*r.Origin().Y
+	origin.Y = -rz.SinTheta*r.Origin().X + rz.CosTheta*r.Origin().Y
 
-	direction.X = rz.CosTheta*r.Direction().X - rz.SinTheta*r.Direction().Y
-	direction.Y = rz.SinTheta*r.Direction().X + rz.CosTheta*r.Direction().Y
+	direction.X = rz.CosTheta*r.Direction().X + rz.SinTheta*r.Direction().Y
+	direction.Y = -rz.SinTheta*r.Direction().X + rz.CosTheta*r.Direction().Y
 
-	rotatedRay := NewRay(origin, direction, r.Time())
+	rotatedRay := r.reframed(origin, direction)
 
 	if !rz.Obj.Hit(rotatedRay, rayT, rec) {
 		return false
@@ -417,7 +468,7 @@ func (s *Scale) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 		Z: r.Direction().Z * s.InvFactor.Z,
 	}
 
-	scaledRay := NewRay(origin, direction, r.Time())
+	scaledRay := r.reframed(origin, direction)
 
 	if !s.Obj.Hit(scaledRay, rayT, rec) {
 		return false
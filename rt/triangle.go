@@ -11,6 +11,8 @@ type Triangle struct {
 	mat        Material
 	bbox       AABB
 	D          float64 // Plane constant (unused - can be removed)
+	epsilon    float64 // Parallel-ray determinant threshold (see TriangleOptions)
+	cull       bool    // Skip back-face hits
 }
 
 // NewTriangle creates a new triangle from three vertices
@@ -23,11 +25,12 @@ func NewTriangle(v0, v1, v2 Point3, mat Material) *Triangle {
 	normal := Cross(edge1, edge2).Unit()
 
 	tri := &Triangle{
-		v0:     v0,
-		v1:     v1,
-		v2:     v2,
-		normal: normal,
-		mat:    mat,
+		v0:      v0,
+		v1:      v1,
+		v2:      v2,
+		normal:  normal,
+		mat:     mat,
+		epsilon: defaultTriangleEpsilon,
 	}
 
 	// Calculate plane constant
@@ -61,8 +64,9 @@ func (t *Triangle) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 	h := Cross(r.Direction(), edge2)
 	a := Dot(edge1, h)
 
-	// Ray is parallel to triangle
-	if math.Abs(a) < 1e-8 {
+	// Ray is parallel to triangle, or hits its back when culling (a is
+	// negative when the ray and the winding normal point the same way)
+	if a < t.epsilon && (t.cull || a > -t.epsilon) {
 		return false
 	}
 
@@ -91,6 +95,12 @@ func (t *Triangle) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 	}
 
 	// Valid intersection found
+	t.setHitRecord(r, hitT, u, v, rec)
+	return true
+}
+
+// setHitRecord fills rec for a hit at hitT with barycentrics (u, v)
+func (t *Triangle) setHitRecord(r Ray, hitT, u, v float64, rec *HitRecord) {
 	rec.T = hitT
 	rec.P = r.At(hitT)
 	rec.Mat = t.mat
@@ -99,6 +109,4 @@ func (t *Triangle) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 	// Set barycentric UV coordinates
 	rec.U = u
 	rec.V = v
-
-	return true
 }
@@ -0,0 +1,98 @@
+package rt
+
+// =============================================================================
+// TRIANGLE INTERSECTION OPTIONS
+// =============================================================================
+
+// defaultTriangleEpsilon is the Möller-Trumbore determinant below which a ray
+// counts as parallel to a triangle
+const defaultTriangleEpsilon = 1e-8
+
+// TriangleOptions controls ray-triangle intersection for a mesh
+type TriangleOptions struct {
+	// Cull skips triangles hit from behind (the side opposite the winding
+	// normal). Only for closed, opaque meshes: rays inside glass and open
+	// surfaces seen from the back need the back faces.
+	Cull bool
+	// Epsilon is the determinant below which a ray counts as parallel to a
+	// triangle and misses. It scales with triangle area, so very small or
+	// very large meshes may need a different value.
+	Epsilon float64
+}
+
+// DefaultTriangleOptions returns two-sided intersection with the standard
+// epsilon
+func DefaultTriangleOptions() TriangleOptions {
+	return TriangleOptions{Cull: false, Epsilon: defaultTriangleEpsilon}
+}
+
+// options returns the triangle's intersection options
+func (t *Triangle) options() TriangleOptions {
+	return TriangleOptions{Cull: t.cull, Epsilon: t.epsilon}
+}
+
+// SetOptions changes how rays intersect the triangle
+func (t *Triangle) SetOptions(opts TriangleOptions) *Triangle {
+	t.cull = opts.Cull
+	t.epsilon = opts.Epsilon
+	return t
+}
+
+// SetTriangleOptions applies opts to every triangle of mesh (a loaded OBJ or
+// PLY, a BVH, or any list or transform of them) and rebuilds the batched
+// leaf kernels so they pick the change up. It returns the number of
+// triangles changed. Cached meshes share triangles, so all instances of the
+// same file and material get the options.
+func SetTriangleOptions(mesh Hittable, opts TriangleOptions) int {
+	changed := 0
+	seen := make(map[Hittable]bool)
+	var walk func(Hittable)
+	walkAll := func(objects []Hittable) {
+		for _, obj := range objects {
+			walk(obj)
+		}
+	}
+	walk = func(object Hittable) {
+		if seen[object] {
+			return
+		}
+		seen[object] = true
+		switch obj := object.(type) {
+		case *Triangle:
+			obj.SetOptions(opts)
+			changed++
+		case *BVHNode:
+			if obj.left != nil {
+				walk(obj.left)
+				walk(obj.right)
+			}
+		case *BVHLeaf:
+			walkAll(obj.objects)
+			obj.kernel = newLeafKernel(obj.objects)
+		case *QuantizedBVH:
+			for _, leaf := range obj.leaves {
+				walk(leaf)
+			}
+		case *HittableList:
+			walkAll(obj.Objects)
+		case *MaterialOverride:
+			walk(obj.Obj)
+		case *Translate:
+			walk(obj.Obj)
+		case *Rotate:
+			walk(obj.Obj)
+		case *RotateX:
+			walk(obj.Obj)
+		case *RotateY:
+			walk(obj.Obj)
+		case *RotateZ:
+			walk(obj.Obj)
+		case *Scale:
+			walk(obj.Obj)
+		case HittableContainer:
+			walkAll(obj.Children())
+		}
+	}
+	walk(mesh)
+	return changed
+}
@@ -0,0 +1,131 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+// sphereMesh returns a closed, outward-wound triangle sphere of radius 1
+func sphereMesh(stacks, slices int) []Hittable {
+	mat := NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})
+	point := func(i, j int) Point3 {
+		theta := math.Pi * float64(i) / float64(stacks)
+		phi := 2 * math.Pi * float64(j) / float64(slices)
+		return Point3{X: math.Sin(theta) * math.Cos(phi), Y: math.Cos(theta), Z: math.Sin(theta) * math.Sin(phi)}
+	}
+	var triangles []Hittable
+	for i := 0; i < stacks; i++ {
+		for j := 0; j < slices; j++ {
+			a, b, c, d := point(i, j), point(i+1, j), point(i+1, j+1), point(i, j+1)
+			if i > 0 {
+				triangles = append(triangles, NewTriangle(a, d, c, mat))
+			}
+			if i < stacks-1 {
+				triangles = append(triangles, NewTriangle(a, c, b, mat))
+			}
+		}
+	}
+	return triangles
+}
+
+func TestBackfaceCulling(t *testing.T) {
+	mesh := NewBVHNodeFromList(&HittableList{Objects: sphereMesh(8, 16)})
+	rayT := NewInterval(0.001, math.Inf(1))
+	outside := NewRay(Point3{Z: 5}, Vec3{Z: -1}, 0)
+	inside := NewRay(Point3{}, Vec3{Z: -1}, 0)
+
+	var twoSided HitRecord
+	if !mesh.Hit(outside, rayT, &twoSided) || !mesh.Hit(inside, rayT, &HitRecord{}) {
+		t.Fatal("two-sided mesh missed from outside or inside")
+	}
+
+	opts := DefaultTriangleOptions()
+	opts.Cull = true
+	if n := SetTriangleOptions(NewTranslate(mesh, Vec3{}), opts); n != 8*16*2-2*16 {
+		t.Errorf("changed %d triangles, want all %d", n, 8*16*2-2*16)
+	}
+	var culled HitRecord
+	if !mesh.Hit(outside, rayT, &culled) || culled.T != twoSided.T {
+		t.Errorf("culled mesh from outside: hit at %g, want the front face at %g", culled.T, twoSided.T)
+	}
+	if mesh.Hit(inside, rayT, &HitRecord{}) {
+		t.Error("culled mesh hit from inside")
+	}
+}
+
+func TestTriangleEpsilon(t *testing.T) {
+	tri := NewTriangle(Point3{}, Point3{X: 1}, Point3{Y: 1}, NewLambertian(Color{X: 0.5}))
+	grazing := NewRay(Point3{X: 0.2 - 500, Y: 0.2, Z: 0.5}, Vec3{X: 1, Z: -1e-3}, 0)
+	if !tri.Hit(grazing, NewInterval(0.001, math.Inf(1)), &HitRecord{}) {
+		t.Fatal("default epsilon misses a grazing ray")
+	}
+	tri.SetOptions(TriangleOptions{Epsilon: 1e-2})
+	if tri.Hit(grazing, NewInterval(0.001, math.Inf(1)), &HitRecord{}) {
+		t.Error("a grazing ray below the epsilon still hits")
+	}
+}
+
+func TestCulledLeafKernelsMatchScalarHits(t *testing.T) {
+	if !leafKernelsEnabled {
+		t.Skip("batched leaf kernels are disabled in rtscalar builds")
+	}
+	SeedRandom(11)
+	t.Cleanup(func() { activeSeed.Store(nil) })
+
+	objects := randomTriangleSoup(8)
+	opts := DefaultTriangleOptions()
+	opts.Cull = true
+	for _, obj := range objects {
+		obj.(*Triangle).SetOptions(opts)
+	}
+	kernel := newLeafKernel(objects)
+	scalar := &BVHLeaf{objects: objects}
+	for i := range 2000 {
+		origin := RandomVec3Range(-8, 8)
+		r := NewRay(origin, RandomVec3Range(-3, 3).Sub(origin), 0)
+		var want, got HitRecord
+		wantHit := scalar.Hit(r, NewInterval(0.001, math.Inf(1)), &want)
+		if gotHit := kernel.hit(r, NewInterval(0.001, math.Inf(1)), &got); gotHit != wantHit || got.T != want.T {
+			t.Fatalf("ray %d: kernel (%v, %g), scalar (%v, %g)", i, gotHit, got.T, wantHit, want.T)
+		}
+	}
+
+	// Mixed options can't share a batch
+	objects[0].(*Triangle).SetOptions(DefaultTriangleOptions())
+	if newLeafKernel(objects) != nil {
+		t.Error("batched a leaf whose triangles disagree on culling")
+	}
+}
+
+// BenchmarkClosedMeshTraversal traces rays at a closed mesh from outside,
+// with and without back-face culling
+func BenchmarkClosedMeshTraversal(b *testing.B) {
+	SeedRandom(1)
+	defer activeSeed.Store(nil)
+
+	rays := make([]Ray, 1024)
+	for i := range rays {
+		origin := RandomUnitVector().Scale(3)
+		rays[i] = NewRay(origin, RandomVec3Range(-0.8, 0.8).Sub(origin).Unit(), 0)
+	}
+
+	for _, cull := range []bool{false, true} {
+		name := "two-sided"
+		if cull {
+			name = "culled"
+		}
+		b.Run(name, func(b *testing.B) {
+			opts := DefaultBVHOptions()
+			opts.LeafMaxSize = 8
+			triangles := sphereMesh(100, 200)
+			bvh := NewBVHNodeWithOptions(triangles, 0, len(triangles), opts)
+			SetTriangleOptions(bvh, TriangleOptions{Cull: cull, Epsilon: defaultTriangleEpsilon})
+
+			rec := &HitRecord{}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bvh.Hit(rays[i%len(rays)], NewInterval(0.001, math.Inf(1)), rec)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// UDIM TILED TEXTURES
+// =============================================================================
+
+// udimTokens mark where the tile number goes in a file name pattern: Mari's
+// <UDIM> and Houdini's %(UDIM)d
+var udimTokens = []string{"<UDIM>", "<udim>", "%(UDIM)d"}
+
+// udimColumns is the number of tiles across UV space; tile 1011 sits above 1001
+const udimColumns = 10
+
+// UDIMTile returns the tile number holding (u, v): 1001 for [0,1)x[0,1),
+// 1002 to its right and 1011 above it. Coordinates outside the ten columns
+// or below v = 0 have no tile and return 0.
+func UDIMTile(u, v float64) int {
+	col, row := math.Floor(u), math.Floor(v)
+	if col < 0 || col >= udimColumns || row < 0 || row >= 100 {
+		return 0
+	}
+	return 1001 + int(col) + udimColumns*int(row)
+}
+
+// NewUDIMTexture loads every tile of a multi-tile texture, e.g.
+// "skin.<UDIM>.png" finds skin.1001.png, skin.1002.png, ... in the texture
+// search paths. Each tile covers one unit square of UV space, so assets laid
+// out across several tiles are textured without stitching their maps.
+func NewUDIMTexture(pattern string) *ImageTexture {
+	tiles := make(map[int]*ImageLoader)
+	for _, path := range findUDIMTiles(pattern) {
+		img := NewImageLoader()
+		if !img.Load(path.file) {
+			fmt.Fprintf(os.Stderr, "ERROR: Could not load UDIM tile '%s'.\n", path.file)
+			continue
+		}
+		tiles[path.tile] = img
+	}
+	if len(tiles) == 0 {
+		fmt.Fprintf(os.Stderr, "ERROR: No UDIM tiles found for '%s'.\n", pattern)
+	}
+	return NewUDIMTextureFromTiles(tiles)
+}
+
+// NewUDIMTextureFromTiles creates a tiled texture from images by tile number
+func NewUDIMTextureFromTiles(tiles map[int]*ImageLoader) *ImageTexture {
+	if tiles == nil {
+		tiles = make(map[int]*ImageLoader)
+	}
+	return &ImageTexture{tiles: tiles}
+}
+
+type udimFile struct {
+	tile int
+	file string
+}
+
+// findUDIMTiles globs pattern in the first texture search path that has any
+// tiles, in tile order
+func findUDIMTiles(pattern string) []udimFile {
+	token := ""
+	for _, t := range udimTokens {
+		if strings.Contains(pattern, t) {
+			token = t
+			break
+		}
+	}
+	if token == "" {
+		return nil
+	}
+	_, suffix, _ := strings.Cut(pattern, token)
+
+	for _, candidate := range assetSearchPaths(pattern, "images") {
+		glob := strings.Replace(candidate, token, "[1-9][0-9][0-9][0-9]", 1)
+		matches, _ := filepath.Glob(glob)
+		var files []udimFile
+		for _, match := range matches {
+			name := strings.TrimSuffix(match, suffix)
+			tile, err := strconv.Atoi(name[len(name)-4:])
+			if err != nil {
+				continue
+			}
+			files = append(files, udimFile{tile: tile, file: match})
+		}
+		if len(files) > 0 {
+			sort.Slice(files, func(i, j int) bool { return files[i].tile < files[j].tile })
+			return files
+		}
+	}
+	return nil
+}
+
+// udimTile returns the tile image holding (u, v) and the coordinates within
+// it. UVs exactly on the far edge of a tile whose neighbour is missing, as
+// on a mesh laid out in [0,1], stay on that tile.
+func (tex *ImageTexture) udimTile(u, v float64) (*ImageLoader, float64, float64) {
+	col, row := math.Floor(u), math.Floor(v)
+	s, t := u-col, v-row
+	if s == 0 && col > 0 && tex.tiles[UDIMTile(col, row)] == nil {
+		col, s = col-1, 1
+	}
+	if t == 0 && row > 0 && tex.tiles[UDIMTile(col, row)] == nil {
+		row, t = row-1, 1
+	}
+	return tex.tiles[UDIMTile(col, row)], s, t
+}
+
+// udimImages returns the tile images in tile order, for scene statistics
+func (tex *ImageTexture) udimImages() []*ImageLoader {
+	numbers := make([]int, 0, len(tex.tiles))
+	for n := range tex.tiles {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	images := make([]*ImageLoader, len(numbers))
+	for i, n := range numbers {
+		images[i] = tex.tiles[n]
+	}
+	return images
+}
@@ -0,0 +1,71 @@
+package rt
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestUDIMTile(t *testing.T) {
+	cases := []struct {
+		u, v float64
+		want int
+	}{
+		{0.5, 0.5, 1001},
+		{1.5, 0.5, 1002},
+		{0.5, 1.5, 1011},
+		{9.99, 2.1, 1030},
+		{10.5, 0.5, 0},
+		{-0.1, 0.5, 0},
+	}
+	for _, c := range cases {
+		if got := UDIMTile(c.u, c.v); got != c.want {
+			t.Errorf("UDIMTile(%v, %v) = %d, want %d", c.u, c.v, got, c.want)
+		}
+	}
+}
+
+func TestUDIMTextureLoadsTiles(t *testing.T) {
+	dir := t.TempDir()
+	colors := map[int]color.RGBA{
+		1001: {255, 0, 0, 255},
+		1002: {0, 255, 0, 255},
+		1011: {0, 0, 255, 255},
+	}
+	for tile, c := range colors {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		for i := range img.Pix {
+			img.Pix[i] = []uint8{c.R, c.G, c.B, c.A}[i%4]
+		}
+		file, err := os.Create(filepath.Join(dir, "color."+strconv.Itoa(tile)+".png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(file, img); err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+	}
+
+	tex := NewUDIMTexture(filepath.Join(dir, "color.<UDIM>.png"))
+	if len(tex.tiles) != 3 {
+		t.Fatalf("loaded %d tiles, want 3", len(tex.tiles))
+	}
+	check := func(u, v float64, want Color) {
+		t.Helper()
+		if got := tex.Value(u, v, Point3{}); got.Sub(want).Len() > 1e-6 {
+			t.Errorf("Value(%v, %v) = %v, want %v", u, v, got, want)
+		}
+	}
+	check(0.5, 0.5, Color{X: 1})
+	check(1.5, 0.5, Color{Y: 1})
+	check(0.5, 1.5, Color{Z: 1})
+	// u = 2 is the far edge of 1002, since 1003 does not exist
+	check(2, 0.5, Color{Y: 1})
+	// Missing tiles show the debugging cyan
+	check(1.5, 1.5, Color{Y: 1, Z: 1})
+}
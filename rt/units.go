@@ -0,0 +1,153 @@
+package rt
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// SCENE UNITS
+// =============================================================================
+
+// UnitSystem names the length unit a scene is modelled in
+type UnitSystem int
+
+const (
+	UnitsMeters      UnitSystem = iota // 1-unit spheres, the atmosphere sky
+	UnitsCentimeters                   // Typical DCC export scale
+	UnitsMillimeters                   // The Cornell box (555 units wide)
+	UnitsInches
+	UnitsCustom // Any MetersPerUnit
+)
+
+var unitSystemNames = []string{"meters", "centimeters", "millimeters", "inches", "custom"}
+
+// unitSystemMeters is the length of one unit of each system in meters
+var unitSystemMeters = []float64{1, 0.01, 0.001, 0.0254, 1}
+
+func (u UnitSystem) String() string {
+	if int(u) < 0 || int(u) >= len(unitSystemNames) {
+		return "unknown"
+	}
+	return unitSystemNames[u]
+}
+
+// defaultRayEpsilonMeters is the self-intersection offset of rays leaving a
+// surface: 1 mm, whatever the scene's units
+const defaultRayEpsilonMeters = 1e-3
+
+// SceneUnits is the real-world size of one scene unit. Physical quantities
+// (light intensities at a distance, lens apertures, volume densities, ray
+// offsets) are given in meters and converted with it, so the same values work
+// for a 555-unit Cornell box in millimeters and a 1-unit sphere in meters.
+// The zero value is meters.
+type SceneUnits struct {
+	System        UnitSystem
+	MetersPerUnit float64 // Used by UnitsCustom; 0 = the system's own scale
+}
+
+// NewSceneUnits returns the units of a named system
+func NewSceneUnits(system UnitSystem) SceneUnits {
+	return SceneUnits{System: system}
+}
+
+// CustomUnits returns units where one scene unit is metersPerUnit meters
+func CustomUnits(metersPerUnit float64) SceneUnits {
+	return SceneUnits{System: UnitsCustom, MetersPerUnit: metersPerUnit}
+}
+
+// ParseSceneUnits converts a system name (e.g. "millimeters", "mm") or a
+// number of meters per unit (e.g. "0.3048" for feet) to SceneUnits
+func ParseSceneUnits(name string) (SceneUnits, error) {
+	aliases := map[string]UnitSystem{"m": UnitsMeters, "cm": UnitsCentimeters, "mm": UnitsMillimeters, "in": UnitsInches}
+	if system, ok := aliases[strings.ToLower(name)]; ok {
+		return NewSceneUnits(system), nil
+	}
+	for i, n := range unitSystemNames[:UnitsCustom] {
+		if strings.EqualFold(name, n) {
+			return NewSceneUnits(UnitSystem(i)), nil
+		}
+	}
+	if scale, err := strconv.ParseFloat(name, 64); err == nil && scale > 0 && !math.IsInf(scale, 0) {
+		return CustomUnits(scale), nil
+	}
+	return SceneUnits{}, fmt.Errorf("unknown units: %s (use %s, or meters per unit)",
+		name, strings.Join(unitSystemNames[:UnitsCustom], ", "))
+}
+
+func (u SceneUnits) String() string {
+	if u.System == UnitsCustom {
+		return fmt.Sprintf("%gm per unit", u.Meters())
+	}
+	return u.System.String()
+}
+
+// Meters returns the length of one scene unit in meters
+func (u SceneUnits) Meters() float64 {
+	if u.MetersPerUnit > 0 {
+		return u.MetersPerUnit
+	}
+	if int(u.System) < 0 || int(u.System) >= len(unitSystemMeters) {
+		return 1
+	}
+	return unitSystemMeters[u.System]
+}
+
+// FromMeters converts a length in meters to scene units
+func (u SceneUnits) FromMeters(meters float64) float64 {
+	return meters / u.Meters()
+}
+
+// ToMeters converts a length in scene units to meters
+func (u SceneUnits) ToMeters(length float64) float64 {
+	return length * u.Meters()
+}
+
+// Density converts a volume density (extinction) per meter to per scene
+// unit, e.g. for NewVolumeFromColor
+func (u SceneUnits) Density(perMeter float64) float64 {
+	return perMeter * u.Meters()
+}
+
+// Intensity converts a point or spot light intensity given for distances in
+// meters (irradiance at 1 m) to scene units, so the light is equally bright
+// at the same real distance. Area light radiance needs no conversion.
+func (u SceneUnits) Intensity(perMeter Color) Color {
+	m := u.Meters()
+	return perMeter.Scale(1 / (m * m))
+}
+
+// SetUnits sets the real-world size of a scene unit (see SceneUnits)
+func (c *Camera) SetUnits(units SceneUnits) *Camera {
+	c.Units = units
+	return c
+}
+
+// SetAperture sets the lens aperture diameter in meters. When set it replaces
+// DefocusAngle, so depth of field stays the same whatever the scene's units.
+// A 50 mm lens at f/2 has a 0.025 m aperture.
+func (c *Camera) SetAperture(diameterMeters float64) *Camera {
+	c.Aperture = math.Max(0, diameterMeters)
+	return c
+}
+
+// apertureDefocusAngle returns the defocus angle (degrees) of the physical
+// aperture focused at FocusDist
+func (c *Camera) apertureDefocusAngle() float64 {
+	if c.FocusDist <= 0 {
+		return 0
+	}
+	radius := c.Units.FromMeters(c.Aperture / 2)
+	return 2 * math.Atan(radius/c.FocusDist) * 180 / math.Pi
+}
+
+// rayEpsilon returns the distance a ray must travel from a surface before it
+// can hit something, in scene units
+func (c *Camera) rayEpsilon() float64 {
+	if c.RayEpsilon > 0 {
+		return c.RayEpsilon
+	}
+	return c.Units.FromMeters(defaultRayEpsilonMeters)
+}
@@ -0,0 +1,55 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseSceneUnits(t *testing.T) {
+	cases := []struct {
+		name   string
+		meters float64
+	}{
+		{"meters", 1},
+		{"CM", 0.01},
+		{"millimeters", 0.001},
+		{"in", 0.0254},
+		{"0.3048", 0.3048},
+	}
+	for _, tc := range cases {
+		units, err := ParseSceneUnits(tc.name)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if units.Meters() != tc.meters {
+			t.Errorf("%s: %g meters per unit, want %g", tc.name, units.Meters(), tc.meters)
+		}
+	}
+	for _, bad := range []string{"parsecs", "-1", "0", "custom"} {
+		if _, err := ParseSceneUnits(bad); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+// The same physical setup must behave identically in any units: a ray
+// offset of 1 mm, a point light as bright at 2 m, the same depth of field
+func TestSceneUnitsScalePhysicalQuantities(t *testing.T) {
+	meters, mm := NewCamera(), NewCamera().SetUnits(NewSceneUnits(UnitsMillimeters))
+	if meters.rayEpsilon() != 0.001 || mm.rayEpsilon() != 1 {
+		t.Errorf("ray epsilon %g m / %g mm, want 0.001 / 1", meters.rayEpsilon(), mm.rayEpsilon())
+	}
+
+	intensity := Color{X: 10, Y: 10, Z: 10}
+	irradianceMeters := intensity.X / (2 * 2)
+	irradianceMM := mm.Units.Intensity(intensity).X / (2000 * 2000)
+	if math.Abs(irradianceMM-irradianceMeters) > 1e-9 {
+		t.Errorf("irradiance at 2 m: %g in mm, %g in meters", irradianceMM, irradianceMeters)
+	}
+
+	meters.SetDefocus(0, 3).SetAperture(0.025)
+	mm.SetDefocus(0, 3000).SetAperture(0.025)
+	if a, b := meters.apertureDefocusAngle(), mm.apertureDefocusAngle(); math.Abs(a-b) > 1e-9 || a <= 0 {
+		t.Errorf("defocus angle %g in meters, %g in mm", a, b)
+	}
+}
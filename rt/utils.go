@@ -2,8 +2,11 @@ package rt
 
 import (
 	"fmt"
+	"image/color"
 	"math"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,7 +18,41 @@ func DegreesToRadians(degrees float64) float64 {
 	return degrees * Pi / 180.0
 }
 
+// seededRand is a mutex-guarded generator installed by SeedRandom
+type seededRand struct {
+	mu   sync.Mutex
+	rng  *rand.Rand
+	seed int64
+}
+
+// activeSeed is nil until SeedRandom is called; renders then draw from the
+// global (nondeterministic) generator
+var activeSeed atomic.Pointer[seededRand]
+
+// SeedRandom makes all subsequent random numbers come from a generator seeded
+// with seed. The renderers give every pixel sample its own stream derived
+// from the seed (see Sampler), so renders are reproducible with any number
+// of workers; other callers share this one stream and are only reproducible
+// from a single goroutine.
+func SeedRandom(seed int64) {
+	activeSeed.Store(&seededRand{rng: rand.New(rand.NewSource(seed)), seed: seed})
+}
+
+// RandomSeed returns the seed set by SeedRandom, if any
+func RandomSeed() (int64, bool) {
+	s := activeSeed.Load()
+	if s == nil {
+		return 0, false
+	}
+	return s.seed, true
+}
+
 func RandomDouble() float64 {
+	if s := activeSeed.Load(); s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.rng.Float64()
+	}
 	return rand.Float64()
 }
 
@@ -23,11 +60,16 @@ func RandomDoubleRange(min, max float64) float64 {
 	return min + (max-min)*RandomDouble()
 }
 func RandomInt(min, max int) int {
+	if s := activeSeed.Load(); s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return min + s.rng.Intn(max-min+1)
+	}
 	return min + rand.Intn(max-min+1)
 }
 
 // PrintRenderSettings displays all camera and scene settings before rendering
-func PrintRenderSettings(camera *Camera, objectCount int) {
+func PrintRenderSettings(camera *Camera, world Hittable) {
 	fmt.Println("\n========================================")
 	fmt.Println("           RENDER SETTINGS")
 	fmt.Println("========================================")
@@ -41,7 +83,8 @@ func PrintRenderSettings(camera *Camera, objectCount int) {
 	fmt.Printf("Camera Position:    (%.1f, %.1f, %.1f)\n", camera.LookFrom.X, camera.LookFrom.Y, camera.LookFrom.Z)
 	fmt.Printf("Camera Target:      (%.1f, %.1f, %.1f)\n", camera.LookAt.X, camera.LookAt.Y, camera.LookAt.Z)
 	fmt.Printf("Camera Motion Blur: %t\n", camera.CameraMotion)
-	fmt.Printf("Objects in Scene:   %d\n", objectCount)
+	fmt.Println("----------------------------------------")
+	CollectSceneStats(world, camera).Print()
 
 	fmt.Println("========================================")
 }
@@ -89,6 +132,30 @@ func LinearToGamma(linear float64) float64 {
 	return 0
 }
 
+// LinearToRGBA gamma-encodes a linear color into an 8-bit display pixel
+func LinearToRGBA(c Color) color.RGBA {
+	intensity := NewInterval(0.0, 0.999)
+	return color.RGBA{
+		R: uint8(256 * intensity.Clamp(LinearToGamma(c.X))),
+		G: uint8(256 * intensity.Clamp(LinearToGamma(c.Y))),
+		B: uint8(256 * intensity.Clamp(LinearToGamma(c.Z))),
+		A: 255,
+	}
+}
+
+// GammaToLinear inverts LinearToGamma (gamma 2)
+func GammaToLinear(gamma float64) float64 {
+	if gamma > 0 {
+		return gamma * gamma
+	}
+	return 0
+}
+
+// Luminance returns the Rec. 709 luminance of a linear color
+func Luminance(c Color) float64 {
+	return 0.2126*c.X + 0.7152*c.Y + 0.0722*c.Z
+}
+
 func PowerHeuristic(nf, pdfF, ng, pdfG float64) float64 {
 	f := nf * pdfF
 	g := ng * pdfG
@@ -42,38 +42,19 @@ func (v Vec3) NearZero() bool {
 	return math.Abs(v.X) < s && math.Abs(v.Y) < s && math.Abs(v.Z) < s
 }
 
+// RandomUnitVector returns a uniformly distributed unit vector (see SampleUniformSphere)
 func RandomUnitVector() Vec3 {
-	for {
-		p := RandomVec3Range(-1, 1)
-		lensq := p.Len2()
-
-		if 1e-160 < lensq && lensq <= 1 {
-			return p.Div(math.Sqrt(lensq))
-		}
-	}
+	return SampleUniformSphere()
 }
 
+// RandomOnHemiSphere returns a uniformly distributed unit vector on the side of normal
 func RandomOnHemiSphere(normal Vec3) Vec3 {
-	onUnitSphere := RandomUnitVector()
-	if Dot(onUnitSphere, normal) > 0.0 {
-		return onUnitSphere
-	} else {
-		return onUnitSphere.Neg()
-	}
-
+	return SampleUniformHemisphere(normal)
 }
 
+// RandomInUnitDisk returns a uniformly distributed point in the unit disk (Z = 0)
 func RandomInUnitDisk() Vec3 {
-	for {
-		p := Vec3{
-			X: RandomDoubleRange(-1, 1),
-			Y: RandomDoubleRange(-1, 1),
-			Z: 0,
-		}
-		if p.Len2() < 1 {
-			return p
-		}
-	}
+	return SampleConcentricDisk()
 }
 
 func Dot(a, b Vec3) float64 {
@@ -0,0 +1,132 @@
+package rt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// PER-OBJECT RAY VISIBILITY
+// =============================================================================
+
+// rayKind tells objects what traced a ray. The zero value is a secondary
+// ray, so rays scattered by materials need no marking.
+type rayKind uint8
+
+const (
+	raySecondary rayKind = iota // Reflection, refraction and bounce rays
+	rayCamera                   // Rays from the lens, before any scatter
+	rayShadow                   // NEE visibility tests toward a light
+)
+
+// withKind returns the ray marked as traced for kind
+func (r Ray) withKind(kind rayKind) Ray {
+	r.kind = kind
+	return r
+}
+
+// Visibility is a set of ray kinds an object shows up for
+type Visibility uint8
+
+const (
+	VisibleToCamera      Visibility = 1 << iota // Seen directly by the camera
+	VisibleInReflections                        // Seen in reflections and refractions, and lights other surfaces by bouncing
+	VisibleToShadows                            // Casts shadows
+
+	VisibleAll = VisibleToCamera | VisibleInReflections | VisibleToShadows
+)
+
+var visibilityNames = []string{"camera", "reflections", "shadows"}
+
+// sees reports whether rays of kind hit objects with visibility v
+func (v Visibility) sees(kind rayKind) bool {
+	switch kind {
+	case rayCamera:
+		return v&VisibleToCamera != 0
+	case rayShadow:
+		return v&VisibleToShadows != 0
+	}
+	return v&VisibleInReflections != 0
+}
+
+func (v Visibility) String() string {
+	var names []string
+	for i, name := range visibilityNames {
+		if v&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseVisibility converts a comma-separated list of ray kinds (e.g.
+// "reflections,shadows") to a Visibility. "all" and "none" are accepted.
+func ParseVisibility(list string) (Visibility, error) {
+	switch strings.ToLower(strings.TrimSpace(list)) {
+	case "all":
+		return VisibleAll, nil
+	case "none", "":
+		return 0, nil
+	}
+	var v Visibility
+	for _, name := range strings.Split(list, ",") {
+		found := false
+		for i, n := range visibilityNames {
+			if strings.EqualFold(strings.TrimSpace(name), n) {
+				v |= 1 << i
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown visibility: %s (use %s, all or none)",
+				name, strings.Join(visibilityNames, ", "))
+		}
+	}
+	return v, nil
+}
+
+// VisibilityOverride limits which rays can hit an object. Rays it is
+// invisible to pass straight through to whatever lies behind.
+type VisibilityOverride struct {
+	Obj        Hittable
+	Visibility Visibility
+}
+
+// WithVisibility wraps object so only the given rays see it. For a backplate
+// product shot, hide the CG ground from the camera but keep its reflections
+// and shadows: WithVisibility(ground, VisibleInReflections|VisibleToShadows),
+// together with Camera.SetPhantomHDRI to show the plate instead of the HDRI.
+func WithVisibility(object Hittable, visibility Visibility) *VisibilityOverride {
+	return &VisibilityOverride{Obj: object, Visibility: visibility}
+}
+
+// HiddenFromCamera wraps object so it only shows up indirectly: in
+// reflections, refractions, bounce light and shadows
+func HiddenFromCamera(object Hittable) *VisibilityOverride {
+	return WithVisibility(object, VisibleAll&^VisibleToCamera)
+}
+
+// CameraOnly wraps object so it is seen directly but neither reflects in
+// other surfaces nor casts shadows
+func CameraOnly(object Hittable) *VisibilityOverride {
+	return WithVisibility(object, VisibleToCamera)
+}
+
+func (v *VisibilityOverride) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
+	if !v.Visibility.sees(r.kind) {
+		return false
+	}
+	return v.Obj.Hit(r, rayT, rec)
+}
+
+func (v *VisibilityOverride) BoundingBox() AABB {
+	return v.Obj.BoundingBox()
+}
+
+// Children returns the wrapped object, for scene statistics
+func (v *VisibilityOverride) Children() []Hittable {
+	return []Hittable{v.Obj}
+}
@@ -0,0 +1,44 @@
+package rt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVisibilityByRayKind(t *testing.T) {
+	ground := HiddenFromCamera(NewQuad(Point3{X: -1, Z: -1}, Vec3{X: 2}, Vec3{Z: 2}, NewLambertian(Color{X: 1, Y: 1, Z: 1})))
+	down := Vec3{Y: -1}
+	hit := func(kind rayKind) bool {
+		ray := NewRay(Point3{Y: 1}, down, 0).withKind(kind)
+		return ground.Hit(ray, NewInterval(0.001, math.Inf(1)), &HitRecord{})
+	}
+	if hit(rayCamera) {
+		t.Error("camera ray hit an object hidden from the camera")
+	}
+	if !hit(raySecondary) || !hit(rayShadow) {
+		t.Error("reflection and shadow rays should still hit the object")
+	}
+
+	// Transforms keep the ray's kind
+	moved := NewTranslate(CameraOnly(NewSphere(Point3{}, 0.5, NewLambertian(Color{}))), Vec3{X: 3})
+	ray := NewRay(Point3{X: 3, Y: 2}, down, 0)
+	if moved.Hit(ray, NewInterval(0.001, math.Inf(1)), &HitRecord{}) {
+		t.Error("reflection ray hit a camera-only object")
+	}
+	if !moved.Hit(ray.withKind(rayCamera), NewInterval(0.001, math.Inf(1)), &HitRecord{}) {
+		t.Error("camera ray missed a camera-only object")
+	}
+}
+
+func TestParseVisibility(t *testing.T) {
+	v, err := ParseVisibility("Reflections, shadows")
+	if err != nil || v != VisibleInReflections|VisibleToShadows {
+		t.Errorf("got %s, %v", v, err)
+	}
+	if v, _ := ParseVisibility("all"); v != VisibleAll {
+		t.Errorf("all: got %s", v)
+	}
+	if _, err := ParseVisibility("camera,mirrors"); err == nil {
+		t.Error("expected an error for an unknown ray kind")
+	}
+}
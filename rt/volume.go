@@ -1,9 +1,6 @@
 package rt
 
-import (
-	"math"
-	"math/rand"
-)
+import "math"
 
 // Volume represents a constant density medium (fog, smoke, mist, etc.)
 type Volume struct {
@@ -63,7 +60,7 @@ func (v *Volume) Hit(r Ray, rayT Interval, rec *HitRecord) bool {
 
 	rayLength := r.Direction().Len()
 	distanceInsideBoundary := (rec2.T - rec1.T) * rayLength
-	hitDistance := v.negInvDensity * math.Log(rand.Float64())
+	hitDistance := v.negInvDensity * math.Log(r.sampler.Float64())
 
 	if hitDistance > distanceInsideBoundary {
 		return false
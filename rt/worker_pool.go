@@ -0,0 +1,87 @@
+package rt
+
+import "sync"
+
+// =============================================================================
+// PERSISTENT WORKER POOL
+// =============================================================================
+
+// poolTask is one unit of pool work (e.g. rendering a bucket). workerID is
+// the index of the goroutine running it, for per-worker bookkeeping.
+type poolTask func(workerID int)
+
+// workerPool runs tasks on a fixed set of long-lived goroutines fed from one
+// queue. A renderer keeps a single pool for all its passes instead of
+// spawning workers per pass. Tasks already running finish when the pool is
+// paused; queued tasks wait until it is resumed.
+type workerPool struct {
+	tasks   chan poolTask
+	workers sync.WaitGroup
+
+	mu      sync.Mutex
+	paused  bool
+	resumed *sync.Cond
+}
+
+func newWorkerPool(numWorkers int) *workerPool {
+	numWorkers = max(numWorkers, 1)
+	p := &workerPool{tasks: make(chan poolTask, numWorkers*2)}
+	p.resumed = sync.NewCond(&p.mu)
+	for id := 0; id < numWorkers; id++ {
+		p.workers.Add(1)
+		go p.work(id)
+	}
+	return p
+}
+
+func (p *workerPool) work(id int) {
+	defer p.workers.Done()
+	for task := range p.tasks {
+		p.waitWhilePaused()
+		task(id)
+	}
+}
+
+func (p *workerPool) waitWhilePaused() {
+	p.mu.Lock()
+	for p.paused {
+		p.resumed.Wait()
+	}
+	p.mu.Unlock()
+}
+
+// runAll queues tasks in order and blocks until every one has finished
+func (p *workerPool) runAll(tasks []poolTask) {
+	var done sync.WaitGroup
+	done.Add(len(tasks))
+	for _, task := range tasks {
+		p.tasks <- func(id int) {
+			defer done.Done()
+			task(id)
+		}
+	}
+	done.Wait()
+}
+
+// pause stops workers from starting new tasks
+func (p *workerPool) pause() {
+	p.mu.Lock()
+	p.paused = true
+	p.mu.Unlock()
+}
+
+// resume lets paused workers continue with the queue
+func (p *workerPool) resume() {
+	p.mu.Lock()
+	p.paused = false
+	p.mu.Unlock()
+	p.resumed.Broadcast()
+}
+
+// close stops the workers once the queue has drained. The pool can't be
+// used afterwards.
+func (p *workerPool) close() {
+	p.resume()
+	close(p.tasks)
+	p.workers.Wait()
+}
@@ -0,0 +1,64 @@
+package rt
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolRunsEveryTaskAcrossBatches(t *testing.T) {
+	pool := newWorkerPool(4)
+	defer pool.close()
+
+	for batch := 0; batch < 3; batch++ {
+		var ran atomic.Int64
+		var badWorker atomic.Bool
+		tasks := make([]poolTask, 100)
+		for i := range tasks {
+			tasks[i] = func(id int) {
+				if id < 0 || id >= 4 {
+					badWorker.Store(true)
+				}
+				ran.Add(1)
+			}
+		}
+		pool.runAll(tasks)
+		if ran.Load() != 100 {
+			t.Fatalf("batch %d: ran %d tasks, want 100", batch, ran.Load())
+		}
+		if badWorker.Load() {
+			t.Fatalf("batch %d: task got a worker id outside [0, 4)", batch)
+		}
+	}
+}
+
+func TestWorkerPoolPauseHoldsQueuedTasks(t *testing.T) {
+	pool := newWorkerPool(2)
+	defer pool.close()
+
+	pool.pause()
+	var ran atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		pool.runAll([]poolTask{
+			func(int) { ran.Add(1) },
+			func(int) { ran.Add(1) },
+		})
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if n := ran.Load(); n != 0 {
+		t.Fatalf("%d tasks ran while paused", n)
+	}
+
+	pool.resume()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("tasks did not finish after resume")
+	}
+	if ran.Load() != 2 {
+		t.Fatalf("ran %d tasks, want 2", ran.Load())
+	}
+}
@@ -0,0 +1,137 @@
+package rt
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// PER-WORKER LOAD BALANCING STATISTICS
+// =============================================================================
+
+// WorkerStats is what one render worker did during a pass (or a whole render)
+type WorkerStats struct {
+	Buckets int
+	Busy    time.Duration // Time spent rendering buckets
+	Samples int64
+}
+
+func (w *WorkerStats) add(other WorkerStats) {
+	w.Buckets += other.Buckets
+	w.Busy += other.Busy
+	w.Samples += other.Samples
+}
+
+// PassWorkerStats holds every worker's stats for one pass plus its wall time
+type PassWorkerStats struct {
+	Wall    time.Duration
+	Workers []WorkerStats
+}
+
+// Efficiency is the fraction of worker time spent rendering, i.e. total busy
+// time over workers × wall time. Idle time comes from workers waiting for the
+// slowest one to finish its last bucket.
+func (p PassWorkerStats) Efficiency() float64 {
+	if p.Wall <= 0 || len(p.Workers) == 0 {
+		return 0
+	}
+	var busy time.Duration
+	for _, w := range p.Workers {
+		busy += w.Busy
+	}
+	return float64(busy) / (float64(p.Wall) * float64(len(p.Workers)))
+}
+
+// Imbalance is the busiest worker's time relative to the mean (1 = perfect)
+func (p PassWorkerStats) Imbalance() float64 {
+	if len(p.Workers) == 0 {
+		return 0
+	}
+	var total, longest time.Duration
+	for _, w := range p.Workers {
+		total += w.Busy
+		longest = max(longest, w.Busy)
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(longest) * float64(len(p.Workers)) / float64(total)
+}
+
+// workerStats collects per-pass worker statistics. Workers count into their
+// own WorkerStats and merge once at the end of the pass.
+type workerStats struct {
+	mu         sync.Mutex
+	numWorkers int
+	passes     []PassWorkerStats
+}
+
+func newWorkerStats(numWorkers int) *workerStats {
+	return &workerStats{numWorkers: numWorkers}
+}
+
+// beginPass starts a new pass and returns its index
+func (s *workerStats) beginPass() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passes = append(s.passes, PassWorkerStats{Workers: make([]WorkerStats, s.numWorkers)})
+	return len(s.passes) - 1
+}
+
+func (s *workerStats) record(pass, worker int, stats WorkerStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passes[pass].Workers[worker].add(stats)
+}
+
+func (s *workerStats) endPass(pass int, wall time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passes[pass].Wall = wall
+}
+
+// snapshot returns a copy of the per-pass statistics
+func (s *workerStats) snapshot() []PassWorkerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	passes := make([]PassWorkerStats, len(s.passes))
+	for i, p := range s.passes {
+		passes[i] = PassWorkerStats{Wall: p.Wall, Workers: append([]WorkerStats(nil), p.Workers...)}
+	}
+	return passes
+}
+
+// report prints per-pass efficiency and per-worker totals
+func (s *workerStats) report() {
+	passes := s.snapshot()
+	if len(passes) == 0 {
+		return
+	}
+
+	fmt.Println("========================================")
+	fmt.Println("WORKER LOAD BALANCE")
+	fmt.Println("========================================")
+	totals := make([]WorkerStats, s.numWorkers)
+	var wall time.Duration
+	for i, p := range passes {
+		fmt.Printf("Pass %d: wall %s, efficiency %.0f%%, busiest worker %.2fx mean\n",
+			i+1, FormatDuration(p.Wall), 100*p.Efficiency(), p.Imbalance())
+		for w, stats := range p.Workers {
+			totals[w].add(stats)
+		}
+		wall += p.Wall
+	}
+
+	fmt.Printf("%-8s %8s %10s %6s %12s\n", "Worker", "Buckets", "Busy", "Util", "Samples")
+	for w, stats := range totals {
+		util := 0.0
+		if wall > 0 {
+			util = 100 * float64(stats.Busy) / float64(wall)
+		}
+		fmt.Printf("%-8d %8d %10s %5.0f%% %12d\n", w, stats.Buckets, FormatDuration(stats.Busy), util, stats.Samples)
+	}
+
+	overall := PassWorkerStats{Wall: wall, Workers: totals}
+	fmt.Printf("Overall: efficiency %.0f%%, busiest worker %.2fx mean\n", 100*overall.Efficiency(), overall.Imbalance())
+}
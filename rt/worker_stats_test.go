@@ -0,0 +1,56 @@
+package rt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketRendererWorkerStats(t *testing.T) {
+	world := NewHittableList()
+	world.Add(NewSphere(Point3{Z: -1}, 0.5, NewLambertian(Color{X: 0.5, Y: 0.5, Z: 0.5})))
+	camera := NewCameraBuilder().SetResolution(32, 1).SetQuality(4, 4).Build()
+
+	r := NewBucketRenderer(camera, world, 8, 3)
+	defer r.Close()
+	r.renderPass()
+
+	passes := r.WorkerStats()
+	if len(passes) != 1 {
+		t.Fatalf("got %d passes, want 1", len(passes))
+	}
+	pass := passes[0]
+	if len(pass.Workers) != 3 || pass.Wall <= 0 {
+		t.Fatalf("pass = %+v, want 3 workers and a wall time", pass)
+	}
+
+	buckets, samples := 0, int64(0)
+	for _, w := range pass.Workers {
+		buckets += w.Buckets
+		samples += w.Samples
+		if w.Busy > pass.Wall {
+			t.Errorf("worker busy %v exceeds pass wall time %v", w.Busy, pass.Wall)
+		}
+	}
+	if buckets != r.totalBuckets {
+		t.Errorf("workers rendered %d buckets, want %d", buckets, r.totalBuckets)
+	}
+	if want := int64(32 * 32); samples != want { // Preview pass: 1 spp
+		t.Errorf("workers traced %d samples, want %d", samples, want)
+	}
+}
+
+func TestPassWorkerStatsBalance(t *testing.T) {
+	pass := PassWorkerStats{
+		Wall: 4 * time.Second,
+		Workers: []WorkerStats{
+			{Busy: 4 * time.Second},
+			{Busy: 2 * time.Second},
+		},
+	}
+	if got := pass.Efficiency(); got != 0.75 {
+		t.Errorf("Efficiency = %v, want 0.75", got)
+	}
+	if got := pass.Imbalance(); got < 1.333 || got > 1.334 {
+		t.Errorf("Imbalance = %v, want 4/3", got)
+	}
+}